@@ -0,0 +1,372 @@
+// Package apitest provides an in-memory implementation of the NumberService API
+// for testing code that uses the generated client, without PostgreSQL.
+package apitest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+
+	api "golang-test-task/api"
+
+	"github.com/google/uuid"
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// Server is an in-memory api.StrictServerInterface. Like the real service it keeps
+// every number ever added, answers with the numbers around the one added in ascending
+// order unless the request asks for another response and rejects numbers outside the
+// int64 range with the same message. It is safe for concurrent use.
+type Server struct {
+	mu      sync.Mutex
+	numbers []int
+	// ids are the ids of the numbers, in the same order
+	ids []openapi_types.UUID
+	err error
+}
+
+var _ api.StrictServerInterface = (*Server)(nil)
+
+// NewServer returns a fake server already holding numbers
+func NewServer(numbers ...int) *Server {
+	s := &Server{}
+	for _, number := range numbers {
+		s.insert(int64(number))
+	}
+
+	return s
+}
+
+// windowSize is how many numbers a window holds on each side of the one added, as on
+// a server not configured otherwise
+const windowSize = 5
+
+// AddNumber inserts the number and returns where it stands with up to windowSize
+// numbers on each side of it, or what the response or full parameter asks for instead
+func (s *Server) AddNumber(ctx context.Context, request api.AddNumberRequestObject) (api.AddNumberResponseObject, error) {
+	number := request.Params.Number
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return api.AddNumber500JSONResponse{
+			Error: fmt.Sprintf("failed to insert number: %v", s.err),
+		}, nil
+	}
+
+	i, id := s.insert(number)
+
+	full := request.Params.Full != nil && *request.Params.Full
+	switch response := request.Params.Response; {
+	case response != nil && *response == api.AddNumberParamsResponseNone:
+		return api.AddNumber204Response{}, nil
+	case response != nil && *response == api.AddNumberParamsResponseCount:
+		count, position := int64(len(s.numbers)), int64(i)
+		return api.AddNumber200JSONResponse{Id: &id, Count: &count, Position: &position}, nil
+	case !full && (response == nil || *response == api.AddNumberParamsResponseWindow):
+		position := int64(i)
+		window := int64s(s.numbers[max(i-windowSize, 0):min(i+windowSize+1, len(s.numbers))])
+		return api.AddNumber200JSONResponse{Id: &id, Position: &position, Window: &window}, nil
+	}
+
+	result := int64s(s.numbers)
+	return api.AddNumber200JSONResponse{
+		Id:      &id,
+		Numbers: &result,
+	}, nil
+}
+
+// insert stores number with a new id, returning where it stands and the id. Equal
+// numbers are ordered by id, as the real service ranks them. The caller holds mu.
+func (s *Server) insert(number int64) (int, openapi_types.UUID) {
+	id := uuid.New()
+	i := sort.Search(len(s.numbers), func(j int) bool {
+		return s.numbers[j] > int(number) || s.numbers[j] == int(number) && bytes.Compare(s.ids[j][:], id[:]) > 0
+	})
+	s.numbers = slices.Insert(s.numbers, i, int(number))
+	s.ids = slices.Insert(s.ids, i, id)
+
+	return i, id
+}
+
+// AddNumberBatch inserts every number at once and returns how many there were with
+// the first page of the stored numbers they span, like the real service
+func (s *Server) AddNumberBatch(ctx context.Context, request api.AddNumberBatchRequestObject) (api.AddNumberBatchResponseObject, error) {
+	numbers := *request.Body
+	if len(numbers) == 0 || len(numbers) > 10000 {
+		return api.AddNumberBatch400JSONResponse{Error: fmt.Sprintf("a batch must hold between 1 and 10000 numbers, got %d", len(numbers))}, nil
+	}
+
+	s.mu.Lock()
+	if s.err != nil {
+		defer s.mu.Unlock()
+		return api.AddNumberBatch500JSONResponse{
+			Error: fmt.Sprintf("failed to insert numbers: %v", s.err),
+		}, nil
+	}
+	for _, number := range numbers {
+		s.insert(number)
+	}
+	s.mu.Unlock()
+
+	low, high := slices.Min(numbers), slices.Max(numbers)
+	resp, _ := s.ListNumbers(ctx, api.ListNumbersRequestObject{Params: api.ListNumbersParams{Min: &low, Max: &high}})
+	window, ok := resp.(api.ListNumbers200JSONResponse)
+	if !ok {
+		// Fail was called since the batch was stored
+		return api.AddNumberBatch500JSONResponse{Error: "failed to list numbers"}, nil
+	}
+
+	return api.AddNumberBatch200JSONResponse{Inserted: int64(len(numbers)), Window: api.NumbersPage(window)}, nil
+}
+
+// ListNumbers returns a page of the numbers in ascending order with the same limits
+// and range as the real service. Its cursors are the last number listed and how many
+// of its copies were, rather than a row.
+func (s *Server) ListNumbers(ctx context.Context, request api.ListNumbersRequestObject) (api.ListNumbersResponseObject, error) {
+	params := request.Params
+	low, high := int64(math.MinInt64), int64(math.MaxInt64)
+	if params.Min != nil {
+		low = *params.Min
+	}
+	if params.Max != nil {
+		high = *params.Max
+	}
+	if low > high {
+		return api.ListNumbers400JSONResponse{Error: fmt.Sprintf("min must not be greater than max, got %d and %d", low, high)}, nil
+	}
+	limit := int32(100)
+	if params.Limit != nil {
+		limit = *params.Limit
+	}
+	if limit < 1 || limit > 1000 {
+		return api.ListNumbers400JSONResponse{Error: fmt.Sprintf("limit must be between 1 and 1000, got %d", limit)}, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return api.ListNumbers500JSONResponse{
+			Error: fmt.Sprintf("failed to list numbers: %v", s.err),
+		}, nil
+	}
+
+	start, _ := slices.BinarySearch(s.numbers, int(low))
+	if params.After != nil {
+		var after, copies int
+		if _, err := fmt.Sscanf(*params.After, "%d.%d", &after, &copies); err != nil {
+			return api.ListNumbers400JSONResponse{Error: fmt.Sprintf("after must be the next_cursor of a page, got %q", *params.After)}, nil
+		}
+		i, _ := slices.BinarySearch(s.numbers, after)
+		start = max(start, i+copies)
+	}
+
+	page := api.NumbersPage{Numbers: []int64{}}
+	i := start
+	for ; i < len(s.numbers) && int64(s.numbers[i]) <= high && len(page.Numbers) < int(limit); i++ {
+		page.Numbers = append(page.Numbers, int64(s.numbers[i]))
+	}
+	if i < len(s.numbers) && int64(s.numbers[i]) <= high {
+		last := s.numbers[i-1]
+		first, _ := slices.BinarySearch(s.numbers, last)
+		next := fmt.Sprintf("%d.%d", last, i-first)
+		page.NextCursor = &next
+	}
+
+	return api.ListNumbers200JSONResponse(page), nil
+}
+
+// GetNumberStats aggregates the numbers with the same bounds, defaults and
+// interpolation as the real service
+func (s *Server) GetNumberStats(ctx context.Context, request api.GetNumberStatsRequestObject) (api.GetNumberStatsResponseObject, error) {
+	percentiles := []float64{90, 95, 99}
+	if request.Params.Percentiles != nil {
+		percentiles = *request.Params.Percentiles
+	}
+	if len(percentiles) > 20 {
+		return api.GetNumberStats400JSONResponse{Error: fmt.Sprintf("at most 20 percentiles may be asked for, got %d", len(percentiles))}, nil
+	}
+	for _, p := range percentiles {
+		if math.IsNaN(p) || p < 0 || p > 100 {
+			return api.GetNumberStats400JSONResponse{Error: fmt.Sprintf("percentiles must be between 0 and 100, got %v", p)}, nil
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return api.GetNumberStats500JSONResponse{
+			Error: fmt.Sprintf("failed to aggregate numbers: %v", s.err),
+		}, nil
+	}
+
+	stats := api.NumberStats{Count: int64(len(s.numbers)), Percentiles: make([]api.Percentile, len(percentiles))}
+	for i, p := range percentiles {
+		stats.Percentiles[i].Percentile = p
+	}
+	if len(s.numbers) == 0 {
+		return api.GetNumberStats200JSONResponse(stats), nil
+	}
+
+	sum := new(big.Int)
+	for _, n := range s.numbers {
+		sum.Add(sum, big.NewInt(int64(n)))
+	}
+	stats.Sum, _ = new(big.Float).SetInt(sum).Float64()
+	mean, _ := new(big.Rat).SetFrac(sum, big.NewInt(stats.Count)).Float64()
+	low, high, median := int64(s.numbers[0]), int64(s.numbers[len(s.numbers)-1]), s.percentile(50)
+	stats.Min, stats.Max, stats.Mean, stats.Median = &low, &high, &mean, &median
+	for i, p := range percentiles {
+		value := s.percentile(p)
+		stats.Percentiles[i].Value = &value
+	}
+
+	return api.GetNumberStats200JSONResponse(stats), nil
+}
+
+// percentile interpolates between the two numbers nearest to p percent of the way
+// through them, like percentile_cont. The caller holds mu and there is a number.
+func (s *Server) percentile(p float64) float64 {
+	position := p / 100 * float64(len(s.numbers)-1)
+	lower, upper := int(math.Floor(position)), int(math.Ceil(position))
+	low, high := float64(s.numbers[lower]), float64(s.numbers[upper])
+
+	return low + (high-low)*(position-float64(lower))
+}
+
+// StreamNumbers answers 503 like a server that does not follow the stored numbers
+func (s *Server) StreamNumbers(ctx context.Context, request api.StreamNumbersRequestObject) (api.StreamNumbersResponseObject, error) {
+	return api.StreamNumbers503JSONResponse{Error: "the server does not follow the stored numbers"}, nil
+}
+
+// DeleteNumber removes the number with the id an insert answered with, or answers 404
+// like the real service
+func (s *Server) DeleteNumber(ctx context.Context, request api.DeleteNumberRequestObject) (api.DeleteNumberResponseObject, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return api.DeleteNumber500JSONResponse{
+			Error: fmt.Sprintf("failed to delete number: %v", s.err),
+		}, nil
+	}
+
+	i := slices.Index(s.ids, request.Id)
+	if i < 0 {
+		return api.DeleteNumber404JSONResponse{Error: "not found"}, nil
+	}
+	s.numbers = slices.Delete(s.numbers, i, i+1)
+	s.ids = slices.Delete(s.ids, i, i+1)
+
+	return api.DeleteNumber204Response{}, nil
+}
+
+// ClearNumbers removes every number, as a server that lets every caller clear them
+func (s *Server) ClearNumbers(ctx context.Context, request api.ClearNumbersRequestObject) (api.ClearNumbersResponseObject, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return api.ClearNumbers500JSONResponse{
+			Error: fmt.Sprintf("failed to delete numbers: %v", s.err),
+		}, nil
+	}
+	s.numbers, s.ids = nil, nil
+
+	return api.ClearNumbers204Response{}, nil
+}
+
+// int64s returns numbers as the API sends them
+func int64s(numbers []int) []int64 {
+	result := make([]int64, len(numbers))
+	for i, n := range numbers {
+		result[i] = int64(n)
+	}
+
+	return result
+}
+
+// Numbers returns a copy of the stored numbers in ascending order
+func (s *Server) Numbers() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.numbers)
+}
+
+// Reset removes all stored numbers and clears any failure set by Fail
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.numbers, s.ids = nil, nil
+	s.err = nil
+}
+
+// Fail makes every following request fail with a 500 carrying err, like an
+// unexpected storage failure of the real service, which reports only "internal
+// error" and answers 503 when the database is unreachable. Fail(nil) restores normal
+// behavior.
+func (s *Server) Fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.err = err
+}
+
+// Handler serves the fake over HTTP, reporting malformed requests as JSON
+// ErrorResponse bodies like the real service does
+func (s *Server) Handler() http.Handler {
+	strictHandler := api.NewStrictHandlerWithOptions(s, nil, api.StrictHTTPServerOptions{
+		RequestErrorHandlerFunc:  errorHandler(http.StatusBadRequest),
+		ResponseErrorHandlerFunc: errorHandler(http.StatusInternalServerError),
+	})
+
+	return api.HandlerWithOptions(strictHandler, api.StdHTTPServerOptions{
+		ErrorHandlerFunc: errorHandler(http.StatusBadRequest),
+	})
+}
+
+// Start serves the fake on an httptest.Server, closed on test cleanup,
+// and returns a client pointed at it
+func (s *Server) Start(t testing.TB) *api.ClientWithResponses {
+	t.Helper()
+
+	srv := httptest.NewServer(s.Handler())
+	t.Cleanup(srv.Close)
+
+	client, err := api.NewClientWithResponses(srv.URL)
+	if err != nil {
+		t.Fatalf("failed to create API client: %v", err)
+	}
+
+	return client
+}
+
+func errorHandler(status int) func(w http.ResponseWriter, r *http.Request, err error) {
+	return func(w http.ResponseWriter, r *http.Request, err error) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+
+		message := err.Error()
+		var numErr *strconv.NumError
+		if errors.As(err, &numErr) && errors.Is(numErr, strconv.ErrRange) {
+			message = fmt.Sprintf("number %s is out of range [%d, %d]", numErr.Num, math.MinInt64, math.MaxInt64)
+		}
+		json.NewEncoder(w).Encode(api.ErrorResponse{Error: message})
+	}
+}
@@ -0,0 +1,233 @@
+package apitest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	api "golang-test-task/api"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_KeepsNumbersSorted(t *testing.T) {
+	fake := NewServer(9, 1)
+	client := fake.Start(t)
+	ctx := context.Background()
+	full := true
+
+	resp, err := client.AddNumberWithResponse(ctx, &api.AddNumberParams{Number: 5, Full: &full})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	require.NotNil(t, resp.JSON200)
+	assert.Equal(t, []int64{1, 5, 9}, *resp.JSON200.Numbers)
+
+	resp, err = client.AddNumberWithResponse(ctx, &api.AddNumberParams{Number: 5, Full: &full})
+	require.NoError(t, err)
+	require.NotNil(t, resp.JSON200)
+	assert.Equal(t, []int64{1, 5, 5, 9}, *resp.JSON200.Numbers)
+
+	assert.Equal(t, []int{1, 5, 5, 9}, fake.Numbers())
+}
+
+func TestServer_ResponseModes(t *testing.T) {
+	fake := NewServer(9, 1)
+	client := fake.Start(t)
+	ctx := context.Background()
+
+	resp, err := client.AddNumberWithResponse(ctx, &api.AddNumberParams{Number: 20})
+	require.NoError(t, err)
+	require.NotNil(t, resp.JSON200)
+	assert.Nil(t, resp.JSON200.Numbers)
+	assert.Equal(t, []int64{1, 9, 20}, *resp.JSON200.Window)
+	assert.Equal(t, int64(2), *resp.JSON200.Position)
+
+	count := api.AddNumberParamsResponseCount
+	resp, err = client.AddNumberWithResponse(ctx, &api.AddNumberParams{Number: 5, Response: &count})
+	require.NoError(t, err)
+	require.NotNil(t, resp.JSON200)
+	assert.Nil(t, resp.JSON200.Numbers)
+	assert.Equal(t, int64(4), *resp.JSON200.Count)
+	assert.Equal(t, int64(1), *resp.JSON200.Position)
+
+	none := api.AddNumberParamsResponseNone
+	resp, err = client.AddNumberWithResponse(ctx, &api.AddNumberParams{Number: 0, Response: &none})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode())
+	assert.Empty(t, resp.Body)
+
+	assert.Equal(t, []int{0, 1, 5, 9, 20}, fake.Numbers())
+}
+
+func TestServer_ListNumbers(t *testing.T) {
+	fake := NewServer(5, 3, 3, 3, 8, -1)
+	client := fake.Start(t)
+	ctx := context.Background()
+
+	limit, low := int32(2), int64(0)
+	params := &api.ListNumbersParams{Limit: &limit, Min: &low}
+	var listed []int64
+	for {
+		resp, err := client.ListNumbersWithResponse(ctx, params)
+		require.NoError(t, err)
+		require.NotNil(t, resp.JSON200)
+		listed = append(listed, resp.JSON200.Numbers...)
+		if resp.JSON200.NextCursor == nil {
+			break
+		}
+		params.After = resp.JSON200.NextCursor
+	}
+	assert.Equal(t, []int64{3, 3, 3, 5, 8}, listed)
+
+	limit = 0
+	resp, err := client.ListNumbersWithResponse(ctx, &api.ListNumbersParams{Limit: &limit})
+	require.NoError(t, err)
+	require.NotNil(t, resp.JSON400)
+	assert.Equal(t, "limit must be between 1 and 1000, got 0", resp.JSON400.Error)
+}
+
+func TestServer_AddNumberBatch(t *testing.T) {
+	fake := NewServer(1, 50)
+	client := fake.Start(t)
+	ctx := context.Background()
+
+	resp, err := client.AddNumberBatchWithResponse(ctx, api.Numbers{7, 3, 7})
+	require.NoError(t, err)
+	require.NotNil(t, resp.JSON200)
+	assert.Equal(t, int64(3), resp.JSON200.Inserted)
+	assert.Equal(t, []int64{3, 7, 7}, resp.JSON200.Window.Numbers)
+	assert.Equal(t, []int{1, 3, 7, 7, 50}, fake.Numbers())
+
+	resp, err = client.AddNumberBatchWithResponse(ctx, api.Numbers{})
+	require.NoError(t, err)
+	require.NotNil(t, resp.JSON400)
+	assert.Equal(t, "a batch must hold between 1 and 10000 numbers, got 0", resp.JSON400.Error)
+}
+
+func TestServer_Delete(t *testing.T) {
+	fake := NewServer(9, 1)
+	client := fake.Start(t)
+	ctx := context.Background()
+
+	added, err := client.AddNumberWithResponse(ctx, &api.AddNumberParams{Number: 5})
+	require.NoError(t, err)
+	require.NotNil(t, added.JSON200)
+	require.NotNil(t, added.JSON200.Id)
+
+	resp, err := client.DeleteNumberWithResponse(ctx, *added.JSON200.Id)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode())
+	assert.Equal(t, []int{1, 9}, fake.Numbers())
+
+	resp, err = client.DeleteNumberWithResponse(ctx, *added.JSON200.Id)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode())
+	require.NotNil(t, resp.JSON404)
+	assert.Equal(t, "not found", resp.JSON404.Error)
+
+	cleared, err := client.ClearNumbersWithResponse(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, cleared.StatusCode())
+	assert.Empty(t, fake.Numbers())
+}
+
+func TestServer_GetNumberStats(t *testing.T) {
+	fake := NewServer()
+	client := fake.Start(t)
+	ctx := context.Background()
+
+	resp, err := client.GetNumberStatsWithResponse(ctx, &api.GetNumberStatsParams{})
+	require.NoError(t, err)
+	require.NotNil(t, resp.JSON200)
+	assert.Equal(t, api.NumberStats{Percentiles: []api.Percentile{{Percentile: 90}, {Percentile: 95}, {Percentile: 99}}}, *resp.JSON200)
+
+	fake = NewServer(10, 1, 4, 3)
+	client = fake.Start(t)
+	resp, err = client.GetNumberStatsWithResponse(ctx, &api.GetNumberStatsParams{Percentiles: &[]float64{0, 50, 100}})
+	require.NoError(t, err)
+	require.NotNil(t, resp.JSON200)
+	stats := *resp.JSON200
+	assert.Equal(t, int64(4), stats.Count)
+	assert.Equal(t, int64(1), *stats.Min)
+	assert.Equal(t, int64(10), *stats.Max)
+	assert.Equal(t, 18.0, stats.Sum)
+	assert.Equal(t, 4.5, *stats.Mean)
+	assert.Equal(t, 3.5, *stats.Median)
+	require.Len(t, stats.Percentiles, 3)
+	assert.Equal(t, 1.0, *stats.Percentiles[0].Value)
+	assert.Equal(t, 3.5, *stats.Percentiles[1].Value)
+	assert.Equal(t, 10.0, *stats.Percentiles[2].Value)
+
+	resp, err = client.GetNumberStatsWithResponse(ctx, &api.GetNumberStatsParams{Percentiles: &[]float64{101}})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode())
+}
+
+func TestServer_RejectsOutOfRange(t *testing.T) {
+	fake := NewServer()
+
+	rec := httptest.NewRecorder()
+	fake.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/numbers?number=9223372036854775808", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"error"`)
+	assert.Empty(t, fake.Numbers())
+}
+
+func TestServer_MalformedRequest(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewServer().Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/numbers?number=abc", nil))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), `"error"`)
+}
+
+func TestServer_Fail(t *testing.T) {
+	fake := NewServer(1)
+	client := fake.Start(t)
+	ctx := context.Background()
+
+	fake.Fail(errors.New("connection refused"))
+
+	resp, err := client.AddNumberWithResponse(ctx, &api.AddNumberParams{Number: 2})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode())
+	require.NotNil(t, resp.JSON500)
+	assert.Contains(t, resp.JSON500.Error, "connection refused")
+
+	fake.Fail(nil)
+
+	resp, err = client.AddNumberWithResponse(ctx, &api.AddNumberParams{Number: 2})
+	require.NoError(t, err)
+	require.NotNil(t, resp.JSON200)
+	assert.Equal(t, []int64{1, 2}, *resp.JSON200.Window)
+
+	fake.Reset()
+	assert.Empty(t, fake.Numbers())
+}
+
+func TestServer_Concurrent(t *testing.T) {
+	fake := NewServer()
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := range 100 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := fake.AddNumber(ctx, api.AddNumberRequestObject{Params: api.AddNumberParams{Number: int64(100 - i)}})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	numbers := fake.Numbers()
+	require.Len(t, numbers, 100)
+	for i, number := range numbers {
+		assert.Equal(t, i+1, number)
+	}
+}
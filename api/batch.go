@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// maxBatch is the most numbers the server adds in one POST /numbers/batch
+const maxBatch = 10_000
+
+// AddBatchError reports a batch of numbers that AddNumbers could not add. Offset is
+// the index of its first number in the slice given to AddNumbers.
+type AddBatchError struct {
+	Offset  int
+	Numbers []int
+	Err     error
+}
+
+func (e *AddBatchError) Error() string {
+	return fmt.Sprintf("add numbers %d to %d: %v", e.Offset+1, e.Offset+len(e.Numbers), e.Err)
+}
+
+func (e *AddBatchError) Unwrap() error {
+	return e.Err
+}
+
+// AddNumbers adds every number through POST /numbers/batch, in batches of up to
+// 10,000 in input order, running at most concurrency requests at a time. It returns
+// the failed batches joined with errors.Join as *AddBatchError in input order.
+// Batches not yet sent when ctx is done fail with its error. A failed batch does not
+// stop the others.
+//
+// Each batch is stored whole or not at all, so a number the server refuses fails
+// every number of its batch, and the numbers of other batches are stored regardless.
+// To store a list all at once or not at all, send it with AddNumberBatch instead.
+func (c *ClientWithResponses) AddNumbers(ctx context.Context, numbers []int, concurrency int, reqEditors ...RequestEditorFn) error {
+	errs := make([]error, (len(numbers)+maxBatch-1)/maxBatch)
+	inFlight := make(chan struct{}, max(concurrency, 1))
+
+	var wg sync.WaitGroup
+	for i := range errs {
+		offset := i * maxBatch
+		batch := numbers[offset:min(offset+maxBatch, len(numbers))]
+		select {
+		case <-ctx.Done():
+		case inFlight <- struct{}{}:
+		}
+		if err := ctx.Err(); err != nil {
+			errs[i] = &AddBatchError{Offset: offset, Numbers: batch, Err: err}
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-inFlight }()
+
+			if err := c.addBatch(ctx, batch, reqEditors); err != nil {
+				errs[i] = &AddBatchError{Offset: offset, Numbers: batch, Err: err}
+			}
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// addBatch sends one batch and turns an error response into an error
+func (c *ClientWithResponses) addBatch(ctx context.Context, batch []int, reqEditors []RequestEditorFn) error {
+	body := make(Numbers, len(batch))
+	for i, number := range batch {
+		body[i] = int64(number)
+	}
+	resp, err := c.AddNumberBatchWithResponse(ctx, body, reqEditors...)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() == http.StatusOK {
+		return nil
+	}
+
+	return responseError(resp.Status(), resp.JSON400, resp.JSON401, resp.JSON403, resp.JSON500, resp.JSON503)
+}
+
+// responseError turns an error response into an error, with the message of the first
+// of bodies that was decoded
+func responseError(status string, bodies ...*ErrorResponse) error {
+	for _, body := range bodies {
+		if body != nil {
+			return fmt.Errorf("%s: %s", status, body.Error)
+		}
+	}
+
+	return errors.New(status)
+}
@@ -0,0 +1,113 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+
+	api "golang-test-task/api"
+	"golang-test-task/api/apitest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddNumbers(t *testing.T) {
+	fake := apitest.NewServer()
+	var batches []int
+	var mu sync.Mutex
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var batch []int64
+		body, _ := io.ReadAll(r.Body)
+		assert.NoError(t, json.Unmarshal(body, &batch))
+		mu.Lock()
+		batches = append(batches, len(batch))
+		mu.Unlock()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		fake.Handler().ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	client, err := api.NewClientWithResponses(srv.URL)
+	require.NoError(t, err)
+
+	numbers := make([]int, 25_000)
+	for i := range numbers {
+		numbers[i] = len(numbers) - i
+	}
+	require.NoError(t, client.AddNumbers(context.Background(), numbers, 2))
+
+	got := fake.Numbers()
+	assert.Len(t, got, 25_000)
+	assert.IsNonDecreasing(t, got)
+	assert.ElementsMatch(t, []int{10_000, 10_000, 5_000}, batches, "batches of up to 10,000")
+}
+
+func TestAddNumbers_AggregatesErrors(t *testing.T) {
+	fake := apitest.NewServer()
+	// Every int is a valid number, so batches with negative ones are rejected in front
+	// of the fake
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var batch []int64
+		body, _ := io.ReadAll(r.Body)
+		assert.NoError(t, json.Unmarshal(body, &batch))
+		if i := slices.IndexFunc(batch, func(n int64) bool { return n < 0 }); i >= 0 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(api.ErrorResponse{Error: fmt.Sprintf("number %d is negative", batch[i])})
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		fake.Handler().ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	client, err := api.NewClientWithResponses(srv.URL)
+	require.NoError(t, err)
+
+	numbers := make([]int, 40_000)
+	for i := range numbers {
+		numbers[i] = i
+	}
+	numbers[15_000] = math.MinInt64
+	numbers[39_999] = -1
+	err = client.AddNumbers(context.Background(), numbers, 2)
+	require.Error(t, err)
+
+	var offsets []int
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var batchErr *api.AddBatchError
+		require.ErrorAs(t, e, &batchErr)
+		assert.Len(t, batchErr.Numbers, 10_000)
+		offsets = append(offsets, batchErr.Offset)
+	}
+	assert.Equal(t, []int{10_000, 30_000}, offsets, "in input order")
+	assert.ErrorContains(t, err, "add numbers 10001 to 20000: 400 Bad Request: number -9223372036854775808 is negative")
+	assert.Len(t, fake.Numbers(), 20_000, "failures do not stop the other batches")
+}
+
+func TestAddNumbers_ServerError(t *testing.T) {
+	fake := apitest.NewServer()
+	fake.Fail(errors.New("connection refused"))
+	client := fake.Start(t)
+
+	err := client.AddNumbers(context.Background(), []int{1}, 1)
+	assert.ErrorContains(t, err, "add numbers 1 to 1: 500 Internal Server Error")
+}
+
+func TestAddNumbers_Cancelled(t *testing.T) {
+	client := apitest.NewServer().Start(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := client.AddNumbers(ctx, []int{1, 2, 3}, 1)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without sending the request while the circuit is open
+var ErrCircuitOpen = errors.New("circuit breaker is open: the service is failing, not sending the request")
+
+// BreakerPolicy controls when a CircuitBreaker opens and closes again
+type BreakerPolicy struct {
+	// FailureThreshold is the number of consecutive failures that opens the circuit
+	FailureThreshold int
+	// OpenTimeout is how long the circuit stays open before a single probe request
+	// is let through to check whether the service has recovered
+	OpenTimeout time.Duration
+}
+
+// DefaultBreakerPolicy opens after five consecutive failures and probes every ten seconds
+func DefaultBreakerPolicy() BreakerPolicy {
+	return BreakerPolicy{
+		FailureThreshold: 5,
+		OpenTimeout:      10 * time.Second,
+	}
+}
+
+// breakerState is the state of a CircuitBreaker
+type breakerState int
+
+const (
+	// closed sends every request
+	closed breakerState = iota
+	// open fails every request fast until OpenTimeout has passed
+	open
+	// halfOpen has let one probe request through and fails the others fast
+	halfOpen
+)
+
+// CircuitBreaker is an HttpRequestDoer that stops sending requests to a service that
+// keeps failing, so callers fail fast with ErrCircuitOpen instead of piling up
+// behind timeouts. Transport errors and 5xx responses count as failures; requests
+// abandoned by their own context do not. It is safe for concurrent use.
+//
+// Wrap it in a RetryingDoer, not the other way round, so every attempt is counted;
+// ErrCircuitOpen is never retried:
+//
+//	breaker := api.NewCircuitBreaker(http.DefaultClient, api.DefaultBreakerPolicy())
+//	client, err := api.NewRetryingClient(server, api.DefaultRetryPolicy(), api.WithHTTPClient(breaker))
+type CircuitBreaker struct {
+	doer   HttpRequestDoer
+	policy BreakerPolicy
+	now    func() time.Time
+
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+}
+
+// NewCircuitBreaker wraps doer with policy
+func NewCircuitBreaker(doer HttpRequestDoer, policy BreakerPolicy) *CircuitBreaker {
+	return &CircuitBreaker{doer: doer, policy: policy, now: time.Now}
+}
+
+// Do sends req unless the circuit is open
+func (b *CircuitBreaker) Do(req *http.Request) (*http.Response, error) {
+	if !b.allow() {
+		return nil, ErrCircuitOpen
+	}
+
+	resp, err := b.doer.Do(req)
+
+	switch {
+	case err != nil && errors.Is(req.Context().Err(), context.Canceled):
+		// The caller gave up; this says nothing about the service
+		b.abandon()
+	case err != nil, resp.StatusCode >= 500:
+		b.record(false)
+	default:
+		b.record(true)
+	}
+
+	return resp, err
+}
+
+// Open reports whether requests are currently failed fast
+func (b *CircuitBreaker) Open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case open:
+		return b.now().Sub(b.openedAt) < b.policy.OpenTimeout
+	case halfOpen:
+		return true
+	default:
+		return false
+	}
+}
+
+// allow reports whether a request may be sent, moving an open circuit whose timeout
+// has passed to half-open for a single probe
+func (b *CircuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case open:
+		if b.now().Sub(b.openedAt) < b.policy.OpenTimeout {
+			return false
+		}
+		b.state = halfOpen
+		return true
+	case halfOpen:
+		return false
+	default:
+		return true
+	}
+}
+
+// record updates the state with the outcome of a request
+func (b *CircuitBreaker) record(ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if ok {
+		b.state, b.failures = closed, 0
+		return
+	}
+
+	b.failures++
+	if b.state == halfOpen || b.failures >= b.policy.FailureThreshold {
+		b.state, b.openedAt = open, b.now()
+	}
+}
+
+// abandon lets the next request probe again when an abandoned request was the probe
+func (b *CircuitBreaker) abandon() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == halfOpen {
+		b.state = open
+	}
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDoer answers with status, or fails with err when it is set
+type fakeDoer struct {
+	status int
+	err    error
+	calls  int
+}
+
+func (f *fakeDoer) Do(*http.Request) (*http.Response, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	return &http.Response{StatusCode: f.status, Body: http.NoBody}, nil
+}
+
+func newTestBreaker(doer HttpRequestDoer) (*CircuitBreaker, *time.Time) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := NewCircuitBreaker(doer, BreakerPolicy{FailureThreshold: 3, OpenTimeout: 10 * time.Second})
+	b.now = func() time.Time { return now }
+
+	return b, &now
+}
+
+func send(t *testing.T, b *CircuitBreaker) error {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, "http://numbers.invalid/numbers", nil)
+	require.NoError(t, err)
+	_, err = b.Do(req)
+
+	return err
+}
+
+func TestCircuitBreaker_OpensAfterConsecutiveFailures(t *testing.T) {
+	doer := &fakeDoer{status: http.StatusInternalServerError}
+	b, _ := newTestBreaker(doer)
+
+	for range 3 {
+		assert.NoError(t, send(t, b), "5xx responses are returned to the caller")
+	}
+	assert.True(t, b.Open())
+
+	assert.ErrorIs(t, send(t, b), ErrCircuitOpen)
+	assert.Equal(t, 3, doer.calls, "an open circuit does not send")
+}
+
+func TestCircuitBreaker_SuccessResetsFailures(t *testing.T) {
+	doer := &fakeDoer{err: errors.New("connection refused")}
+	b, _ := newTestBreaker(doer)
+
+	send(t, b)
+	send(t, b)
+	doer.err, doer.status = nil, http.StatusOK
+	send(t, b)
+	doer.err = errors.New("connection refused")
+	send(t, b)
+	send(t, b)
+
+	assert.False(t, b.Open(), "failures were not consecutive")
+}
+
+func TestCircuitBreaker_HalfOpenProbe(t *testing.T) {
+	doer := &fakeDoer{status: http.StatusServiceUnavailable}
+	b, now := newTestBreaker(doer)
+	for range 3 {
+		send(t, b)
+	}
+
+	// A failed probe opens the circuit for another timeout
+	*now = now.Add(10 * time.Second)
+	assert.False(t, b.Open())
+	assert.NoError(t, send(t, b))
+	assert.ErrorIs(t, send(t, b), ErrCircuitOpen)
+	assert.Equal(t, 4, doer.calls)
+
+	// A successful probe closes it
+	*now = now.Add(10 * time.Second)
+	doer.status = http.StatusOK
+	assert.NoError(t, send(t, b))
+	assert.False(t, b.Open())
+	assert.NoError(t, send(t, b))
+}
+
+func TestCircuitBreaker_IgnoresCancelledRequests(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	b, _ := newTestBreaker(&fakeDoer{err: context.Canceled})
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://numbers.invalid/numbers", nil)
+	require.NoError(t, err)
+	for range 5 {
+		b.Do(req)
+	}
+
+	assert.False(t, b.Open())
+}
+
+func TestRetryingClient_DoesNotRetryOpenCircuit(t *testing.T) {
+	doer := &fakeDoer{err: errors.New("connection refused")}
+	breaker, _ := newTestBreaker(doer)
+
+	client, err := NewRetryingClient("http://numbers.invalid", fastPolicy(), WithHTTPClient(breaker))
+	require.NoError(t, err)
+
+	withKey := func(ctx context.Context, req *http.Request) error {
+		req.Header.Set("Idempotency-Key", "7f3c")
+		return nil
+	}
+	_, err = client.AddNumberWithResponse(context.Background(), &AddNumberParams{Number: 1}, withKey)
+	assert.Error(t, err)
+	assert.Equal(t, 3, doer.calls, "retries stop once the circuit opens")
+
+	_, err = client.AddNumberWithResponse(context.Background(), &AddNumberParams{Number: 1}, withKey)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, 3, doer.calls)
+}
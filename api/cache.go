@@ -0,0 +1,138 @@
+package api
+
+import (
+	"bytes"
+	"container/list"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// CachedResponses is how many URLs a CachingDoer remembers; the least recently used
+// is forgotten when another one is added
+const CachedResponses = 256
+
+// cachedResponse is the last 200 response with an ETag for a URL
+type cachedResponse struct {
+	url    string
+	etag   string
+	header http.Header
+	body   []byte
+}
+
+// CachingDoer is an HttpRequestDoer that makes GET requests conditional. It remembers
+// the ETag and body of the last successful response per URL, sends If-None-Match
+// with the next request and, when the server answers 304 Not Modified, returns the
+// remembered payload as a 200 response, so polling consumers only download what
+// changed. It remembers up to CachedResponses URLs, so following cursors through a
+// long list does not grow it without bound. Vary is not taken into account, so it suits clients whose requests to a
+// URL differ only in their credentials. It is safe for concurrent use.
+//
+// Wrap it around a RetryingDoer or CircuitBreaker, so a cached answer is served
+// without retries.
+type CachingDoer struct {
+	doer HttpRequestDoer
+
+	mu sync.Mutex
+	// entries holds the cachedResponse of every URL in recent, most recently used first
+	entries map[string]*list.Element
+	recent  *list.List
+}
+
+// NewCachingDoer wraps doer with a conditional GET cache
+func NewCachingDoer(doer HttpRequestDoer) *CachingDoer {
+	return &CachingDoer{doer: doer, entries: make(map[string]*list.Element), recent: list.New()}
+}
+
+// Do sends req, conditionally when it is a GET for a URL seen before
+func (d *CachingDoer) Do(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("If-None-Match") != "" {
+		return d.doer.Do(req)
+	}
+
+	key := req.URL.String()
+	entry, cached := d.get(key)
+
+	if cached {
+		req = req.Clone(req.Context())
+		req.Header.Set("If-None-Match", entry.etag)
+	}
+
+	resp, err := d.doer.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached:
+		resp.Body.Close()
+		return entry.response(req, resp), nil
+	case resp.StatusCode == http.StatusOK && resp.Header.Get("ETag") != "":
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		d.put(cachedResponse{url: key, etag: resp.Header.Get("ETag"), header: resp.Header.Clone(), body: body})
+
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		return resp, nil
+	default:
+		return resp, nil
+	}
+}
+
+// get returns the response cached for url, marking it used
+func (d *CachingDoer) get(url string) (cachedResponse, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	elem, ok := d.entries[url]
+	if !ok {
+		return cachedResponse{}, false
+	}
+	d.recent.MoveToFront(elem)
+
+	return elem.Value.(cachedResponse), true
+}
+
+// put caches entry, forgetting the least recently used URL when the cache is full
+func (d *CachingDoer) put(entry cachedResponse) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if elem, ok := d.entries[entry.url]; ok {
+		elem.Value = entry
+		d.recent.MoveToFront(elem)
+		return
+	}
+	d.entries[entry.url] = d.recent.PushFront(entry)
+	if d.recent.Len() > CachedResponses {
+		oldest := d.recent.Back()
+		d.recent.Remove(oldest)
+		delete(d.entries, oldest.Value.(cachedResponse).url)
+	}
+}
+
+// response rebuilds the cached 200 response, taking headers the 304 updated from it
+func (c cachedResponse) response(req *http.Request, notModified *http.Response) *http.Response {
+	header := c.header.Clone()
+	for k, v := range notModified.Header {
+		if k != "Content-Length" {
+			header[k] = v
+		}
+	}
+
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         notModified.Proto,
+		ProtoMajor:    notModified.ProtoMajor,
+		ProtoMinor:    notModified.ProtoMinor,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(c.body)),
+		ContentLength: int64(len(c.body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// etagServer serves body under etag, answering 304 to a matching If-None-Match
+func etagServer(t *testing.T, etag, body *string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var full atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", *etag)
+		if r.Header.Get("If-None-Match") == *etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, *body)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &full
+}
+
+func get(t *testing.T, d HttpRequestDoer, url string) (int, string) {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	resp, err := d.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	return resp.StatusCode, string(body)
+}
+
+func TestCachingDoer(t *testing.T) {
+	etag, body := `"v1"`, `{"numbers":[1]}`
+	srv, full := etagServer(t, &etag, &body)
+	d := NewCachingDoer(http.DefaultClient)
+
+	status, got := get(t, d, srv.URL)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, `{"numbers":[1]}`, got)
+
+	status, got = get(t, d, srv.URL)
+	assert.Equal(t, http.StatusOK, status, "304 is answered from the cache")
+	assert.Equal(t, `{"numbers":[1]}`, got)
+	assert.Equal(t, int32(1), full.Load())
+
+	etag, body = `"v2"`, `{"numbers":[1,2]}`
+	_, got = get(t, d, srv.URL)
+	assert.Equal(t, `{"numbers":[1,2]}`, got)
+	assert.Equal(t, int32(2), full.Load())
+
+	_, got = get(t, d, srv.URL+"?other")
+	assert.Equal(t, `{"numbers":[1,2]}`, got)
+	assert.Equal(t, int32(3), full.Load(), "cached per URL")
+}
+
+func TestCachingDoer_OnlyGET(t *testing.T) {
+	etag, body := `"v1"`, `{}`
+	srv, full := etagServer(t, &etag, &body)
+	d := NewCachingDoer(http.DefaultClient)
+
+	for range 2 {
+		req, err := http.NewRequest(http.MethodPost, srv.URL, nil)
+		require.NoError(t, err)
+		resp, err := d.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	assert.Equal(t, int32(2), full.Load())
+}
+
+func TestCachingDoer_Evicts(t *testing.T) {
+	etag, body := `"v1"`, `{}`
+	srv, full := etagServer(t, &etag, &body)
+	d := NewCachingDoer(http.DefaultClient)
+
+	for i := range CachedResponses + 1 {
+		get(t, d, fmt.Sprintf("%s/?after=%d", srv.URL, i))
+	}
+	assert.Len(t, d.entries, CachedResponses)
+	assert.Equal(t, CachedResponses, d.recent.Len())
+
+	get(t, d, srv.URL+"/?after=1")
+	assert.Equal(t, int32(CachedResponses+1), full.Load(), "recent URLs stay cached")
+	get(t, d, srv.URL+"/?after=0")
+	assert.Equal(t, int32(CachedResponses+2), full.Load(), "the least recently used URL is forgotten")
+}
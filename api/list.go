@@ -0,0 +1,37 @@
+package api
+
+import "context"
+
+// ForEachNumber lists the numbers of params page by page, following next_cursor, and
+// calls yield with each in ascending order. params.After starts the listing after a
+// page already read, and params.Limit sets the size of each page. An error of yield
+// stops the listing and is returned as it is.
+//
+// Pages are listed one after another, so numbers added or deleted meanwhile are seen
+// only if they fall after the page being read.
+func (c *ClientWithResponses) ForEachNumber(ctx context.Context, params *ListNumbersParams, yield func(number int64) error, reqEditors ...RequestEditorFn) error {
+	page := ListNumbersParams{}
+	if params != nil {
+		page = *params
+	}
+
+	for {
+		resp, err := c.ListNumbersWithResponse(ctx, &page, reqEditors...)
+		if err != nil {
+			return err
+		}
+		if resp.JSON200 == nil {
+			return responseError(resp.Status(), resp.JSON400, resp.JSON401, resp.JSON403, resp.JSON500, resp.JSON503)
+		}
+
+		for _, number := range resp.JSON200.Numbers {
+			if err := yield(number); err != nil {
+				return err
+			}
+		}
+		if resp.JSON200.NextCursor == nil {
+			return nil
+		}
+		page.After = resp.JSON200.NextCursor
+	}
+}
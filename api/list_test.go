@@ -0,0 +1,61 @@
+package api_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	api "golang-test-task/api"
+	"golang-test-task/api/apitest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForEachNumber(t *testing.T) {
+	fake := apitest.NewServer(5, 3, 3, 3, 8, -1, 12)
+	client := fake.Start(t)
+	ctx := context.Background()
+
+	limit, low, high := int32(2), int64(0), int64(10)
+	var listed []int64
+	err := client.ForEachNumber(ctx, &api.ListNumbersParams{Limit: &limit, Min: &low, Max: &high}, func(number int64) error {
+		listed = append(listed, number)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{3, 3, 3, 5, 8}, listed, "every page, in order")
+
+	listed = nil
+	require.NoError(t, client.ForEachNumber(ctx, nil, func(number int64) error {
+		listed = append(listed, number)
+		return nil
+	}))
+	assert.Equal(t, []int64{-1, 3, 3, 3, 5, 8, 12}, listed)
+
+	stop := errors.New("enough")
+	seen := 0
+	err = client.ForEachNumber(ctx, &api.ListNumbersParams{Limit: &limit}, func(int64) error {
+		seen++
+		if seen == 3 {
+			return stop
+		}
+		return nil
+	})
+	assert.Same(t, stop, err)
+	assert.Equal(t, 3, seen, "no number after yield fails")
+}
+
+func TestForEachNumber_Errors(t *testing.T) {
+	fake := apitest.NewServer(1, 2)
+	client := fake.Start(t)
+	ctx := context.Background()
+
+	limit := int32(0)
+	err := client.ForEachNumber(ctx, &api.ListNumbersParams{Limit: &limit}, func(int64) error { return nil })
+	assert.EqualError(t, err, "400 Bad Request: limit must be between 1 and 1000, got 0")
+
+	fake.Fail(errors.New("disk full"))
+	err = client.ForEachNumber(ctx, nil, func(int64) error { return nil })
+	assert.EqualError(t, err, "500 Internal Server Error: failed to list numbers: disk full")
+}
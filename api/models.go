@@ -3,21 +3,171 @@
 // Code generated by github.com/oapi-codegen/oapi-codegen/v2 version v2.5.1 DO NOT EDIT.
 package api
 
-// CreateNumberResponse defines model for CreateNumberResponse.
+import (
+	"time"
+
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+const (
+	ApiKeyScopes     = "apiKey.Scopes"
+	BearerAuthScopes = "bearerAuth.Scopes"
+)
+
+// Defines values for AddNumberParamsResponse.
+const (
+	AddNumberParamsResponseCount  AddNumberParamsResponse = "count"
+	AddNumberParamsResponseFull   AddNumberParamsResponse = "full"
+	AddNumberParamsResponseNone   AddNumberParamsResponse = "none"
+	AddNumberParamsResponseWindow AddNumberParamsResponse = "window"
+)
+
+// BatchResult What a batch added and where it stands among the stored numbers
+type BatchResult struct {
+	// Inserted How many numbers were added
+	Inserted int64 `json:"inserted" xml:"inserted"`
+
+	// Window The first page of the stored numbers from the smallest number added to the largest
+	Window NumbersPage `json:"window" xml:"window"`
+}
+
+// CreateNumberResponse Position and window are sent in the window response mode, numbers in the full mode, count and position in the count mode; id in every mode
 type CreateNumberResponse struct {
-	Numbers *Numbers `json:"numbers,omitempty"`
+	// Count How many numbers are stored
+	Count *int64 `json:"count,omitempty" xml:"count,omitempty"`
+
+	// Id The id of the row stored, which DELETE /numbers/{id} takes
+	Id      *openapi_types.UUID `json:"id,omitempty" xml:"id,omitempty"`
+	Meta    *ResponseMeta       `json:"meta,omitempty" xml:"meta,omitempty"`
+	Numbers *Numbers            `json:"numbers,omitempty" xml:"numbers>number"`
+
+	// Position How many stored numbers are smaller than the number added
+	Position *int64 `json:"position,omitempty" xml:"position,omitempty"`
+
+	// Window The number added with the stored numbers next to it on either side, as many as the server is configured for, in ascending order
+	Window *Numbers `json:"window,omitempty" xml:"window>number"`
 }
 
 // ErrorResponse defines model for ErrorResponse.
 type ErrorResponse struct {
-	Error string `json:"error"`
+	Error string `json:"error" xml:"error"`
+}
+
+// NumberEvent defines model for NumberEvent.
+type NumberEvent struct {
+	CreatedAt time.Time          `json:"created_at"`
+	Id        openapi_types.UUID `json:"id"`
+	Number    int64              `json:"number"`
+}
+
+// NumberStats The statistics of the stored numbers. Min, max, mean and median are absent when none is stored, as are the values of the percentiles.
+type NumberStats struct {
+	// Count How many numbers are stored
+	Count int64    `json:"count" xml:"count"`
+	Max   *int64   `json:"max,omitempty" xml:"max,omitempty"`
+	Mean  *float64 `json:"mean,omitempty" xml:"mean,omitempty"`
+
+	// Median The 50th percentile
+	Median *float64 `json:"median,omitempty" xml:"median,omitempty"`
+	Min    *int64   `json:"min,omitempty" xml:"min,omitempty"`
+
+	// Percentiles The percentiles asked for, in the order they were
+	Percentiles []Percentile `json:"percentiles" xml:"percentiles>percentile"`
+
+	// Sum The sum of the numbers, which may be beyond the int64 range. It is summed exactly and rounded to a double, so it is exact up to 2^53.
+	Sum float64 `json:"sum" xml:"sum"`
 }
 
 // Numbers defines model for Numbers.
-type Numbers = []int
+type Numbers = []int64
+
+// NumbersPage A page of the stored numbers
+type NumbersPage struct {
+	// NextCursor Where the next page starts. Absent on the last page.
+	NextCursor *string `json:"next_cursor,omitempty" xml:"next_cursor,omitempty"`
+	Numbers    Numbers `json:"numbers" xml:"numbers>number"`
+}
+
+// Percentile defines model for Percentile.
+type Percentile struct {
+	// Percentile The percentile, from 0 to 100
+	Percentile float64 `json:"percentile" xml:"percentile"`
+
+	// Value The value at the percentile, absent when no number is stored
+	Value *float64 `json:"value,omitempty" xml:"value,omitempty"`
+}
+
+// ResponseMeta Sent with a list when the request asks for the envelope
+type ResponseMeta struct {
+	// ElapsedMs How long the server took to store the number and read the list
+	ElapsedMs int64 `json:"elapsed_ms" xml:"elapsed_ms"`
+
+	// NextCursor Where the next page starts. Absent while every list is returned whole.
+	NextCursor *string `json:"next_cursor,omitempty" xml:"next_cursor,omitempty"`
+
+	// RequestId The X-Request-Id header of the request, or an id the server made up
+	RequestId string `json:"request_id" xml:"request_id"`
+
+	// Returned How many numbers the response holds
+	Returned int64 `json:"returned" xml:"returned"`
+
+	// Total How many numbers are stored
+	Total int64 `json:"total" xml:"total"`
+}
+
+// Forbidden defines model for Forbidden.
+type Forbidden = ErrorResponse
+
+// Unauthorized defines model for Unauthorized.
+type Unauthorized = ErrorResponse
+
+// ListNumbersParams defines parameters for ListNumbers.
+type ListNumbersParams struct {
+	// Limit The most numbers the page holds
+	Limit *int32 `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// After The next_cursor of the previous page; the first page is listed without it. A cursor only makes sense with the min and max it was listed with.
+	After *string `form:"after,omitempty" json:"after,omitempty"`
+
+	// Min The smallest number listed
+	Min *int64 `form:"min,omitempty" json:"min,omitempty"`
+
+	// Max The largest number listed
+	Max *int64 `form:"max,omitempty" json:"max,omitempty"`
+}
 
 // AddNumberParams defines parameters for AddNumber.
 type AddNumberParams struct {
 	// Number The number to add
-	Number int `form:"number" json:"number"`
+	Number int64 `form:"number" json:"number"`
+
+	// Envelope Whether the response carries meta, the counts and diagnostics of the list. Sending the header Prefer: envelope (RFC 7240) does the same.
+	Envelope *bool `form:"envelope,omitempty" json:"envelope,omitempty"`
+
+	// Response What the response holds: window, where the number stands and the stored numbers around it; full, every stored number; count, how many are stored and where the number stands among them; or none, no body at all (204). Defaults to the mode the server is configured with, window unless changed.
+	Response *AddNumberParamsResponse `form:"response,omitempty" json:"response,omitempty"`
+
+	// Full Whether the response holds every stored number, the same as response=full. Reading the whole list takes time in proportion to it, so only small datasets should be asked for it.
+	Full *bool `form:"full,omitempty" json:"full,omitempty"`
 }
+
+// AddNumberParamsResponse defines parameters for AddNumber.
+type AddNumberParamsResponse string
+
+// AddNumberBatchJSONBody defines parameters for AddNumberBatch.
+type AddNumberBatchJSONBody = Numbers
+
+// GetNumberStatsParams defines parameters for GetNumberStats.
+type GetNumberStatsParams struct {
+	// Percentiles The percentiles to compute, each from 0 to 100, such as percentiles=90&percentiles=99. Each interpolates between the two numbers nearest to it, as percentile_cont does. Defaults to 90, 95 and 99.
+	Percentiles *[]float64 `form:"percentiles,omitempty" json:"percentiles,omitempty"`
+}
+
+// StreamNumbersParams defines parameters for StreamNumbers.
+type StreamNumbersParams struct {
+	// Snapshot Also send every stored number in ascending order as a snapshot event, a Numbers array, when the stream starts and after every change. Changes that come together are followed by one snapshot. Each snapshot reads the whole list, so it is meant for short lists.
+	Snapshot *bool `form:"snapshot,omitempty" json:"snapshot,omitempty"`
+}
+
+// AddNumberBatchJSONRequestBody defines body for AddNumberBatch for application/json ContentType.
+type AddNumberBatchJSONRequestBody = AddNumberBatchJSONBody
@@ -0,0 +1,162 @@
+// Package otelclient instruments the transport of the generated API client with
+// OpenTelemetry, so services calling the API get distributed traces across the
+// boundary: every call gets a client span, the trace context travels in the request
+// headers, and the call duration is recorded as the http.client.request.duration
+// histogram.
+package otelclient
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// scope is the instrumentation scope of the spans and metrics
+const scope = "golang-test-task/api/otelclient"
+
+// Transport is an http.RoundTripper that traces and measures every request
+type Transport struct {
+	base       http.RoundTripper
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+	duration   metric.Float64Histogram
+}
+
+// Option configures a Transport
+type Option func(*options)
+
+type options struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	propagator     propagation.TextMapPropagator
+}
+
+// WithTracerProvider records spans with tp instead of the global provider
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(o *options) { o.tracerProvider = tp }
+}
+
+// WithMeterProvider records metrics with mp instead of the global provider
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(o *options) { o.meterProvider = mp }
+}
+
+// WithPropagator injects the trace context with p instead of the global propagator
+func WithPropagator(p propagation.TextMapPropagator) Option {
+	return func(o *options) { o.propagator = p }
+}
+
+// NewTransport wraps base, http.DefaultTransport when nil. Without options it uses
+// the global tracer and meter providers and propagator, so it picks up whatever the
+// service has installed with otel.SetTracerProvider and friends.
+func NewTransport(base http.RoundTripper, opts ...Option) (*Transport, error) {
+	o := options{
+		tracerProvider: otel.GetTracerProvider(),
+		meterProvider:  otel.GetMeterProvider(),
+		propagator:     otel.GetTextMapPropagator(),
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	duration, err := o.meterProvider.Meter(scope).Float64Histogram("http.client.request.duration",
+		metric.WithUnit("s"),
+		metric.WithDescription("Duration of HTTP client requests."),
+		metric.WithExplicitBucketBoundaries(0.005, 0.01, 0.025, 0.05, 0.075, 0.1, 0.25, 0.5, 0.75, 1, 2.5, 5, 7.5, 10))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the duration histogram: %w", err)
+	}
+
+	return &Transport{
+		base:       base,
+		tracer:     o.tracerProvider.Tracer(scope),
+		propagator: o.propagator,
+		duration:   duration,
+	}, nil
+}
+
+// NewClient returns an http.Client using a Transport over http.DefaultTransport, to
+// pass to api.WithHTTPClient
+func NewClient(opts ...Option) (*http.Client, error) {
+	t, err := NewTransport(nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Client{Transport: t}, nil
+}
+
+// RoundTrip sends req in a client span named after its method. The span ends once
+// the response headers arrive; reading the body is not included.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	attrs := requestAttributes(req)
+	ctx, span := t.tracer.Start(req.Context(), req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+		trace.WithAttributes(semconv.URLFull(redactedURL(req))))
+	defer span.End()
+
+	// A RoundTripper must not modify the caller's request
+	req = req.Clone(ctx)
+	t.propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	elapsed := time.Since(start)
+
+	var result []attribute.KeyValue
+	switch {
+	case err != nil:
+		result = append(result, semconv.ErrorType(err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	case resp.StatusCode >= 400:
+		result = append(result, semconv.HTTPResponseStatusCode(resp.StatusCode), semconv.ErrorTypeKey.String(strconv.Itoa(resp.StatusCode)))
+		span.SetStatus(codes.Error, "")
+	default:
+		result = append(result, semconv.HTTPResponseStatusCode(resp.StatusCode))
+	}
+	span.SetAttributes(result...)
+	t.duration.Record(ctx, elapsed.Seconds(), metric.WithAttributes(append(attrs, result...)...))
+
+	return resp, err
+}
+
+// requestAttributes are the attributes shared by the span and the metric; they
+// leave out the full URL, which would give the metric unbounded cardinality
+func requestAttributes(req *http.Request) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{semconv.HTTPRequestMethodKey.String(req.Method)}
+
+	host, port := req.URL.Hostname(), req.URL.Port()
+	if port == "" {
+		port = map[string]string{"http": "80", "https": "443"}[req.URL.Scheme]
+	}
+	if host != "" {
+		attrs = append(attrs, semconv.ServerAddress(host))
+	}
+	if n, err := strconv.Atoi(port); err == nil {
+		attrs = append(attrs, semconv.ServerPort(n))
+	}
+
+	return attrs
+}
+
+// redactedURL is the request URL without credentials
+func redactedURL(req *http.Request) string {
+	u := *req.URL
+	u.User = nil
+
+	return u.String()
+}
@@ -0,0 +1,133 @@
+package otelclient_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang-test-task/api"
+	"golang-test-task/api/apitest"
+	"golang-test-task/api/otelclient"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumented returns a client of srv whose calls are recorded by the returned
+// exporter and reader
+func instrumented(t *testing.T, srv *httptest.Server) (*api.ClientWithResponses, *sdktrace.TracerProvider, *tracetest.InMemoryExporter, *sdkmetric.ManualReader) {
+	t.Helper()
+
+	spans := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(spans))
+	reader := sdkmetric.NewManualReader()
+
+	httpClient, err := otelclient.NewClient(
+		otelclient.WithTracerProvider(tp),
+		otelclient.WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+		otelclient.WithPropagator(propagation.TraceContext{}))
+	require.NoError(t, err)
+
+	client, err := api.NewClientWithResponses(srv.URL, api.WithHTTPClient(httpClient))
+	require.NoError(t, err)
+
+	return client, tp, spans, reader
+}
+
+func TestTransport_PropagatesTheTrace(t *testing.T) {
+	var traceparent string
+	fake := apitest.NewServer()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("Traceparent")
+		fake.Handler().ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	client, tp, spans, _ := instrumented(t, srv)
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+	resp, err := client.AddNumberWithResponse(ctx, &api.AddNumberParams{Number: 5})
+	parent.End()
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+
+	got := spans.GetSpans()
+	require.Len(t, got, 2)
+	call := got[0]
+	assert.Equal(t, "POST", call.Name)
+	assert.Equal(t, trace.SpanKindClient, call.SpanKind)
+	assert.Equal(t, parent.SpanContext().TraceID(), call.SpanContext.TraceID(), "the call is part of the caller's trace")
+	assert.Contains(t, traceparent, call.SpanContext.SpanID().String(), "the server sees the call span as its parent")
+	assert.Contains(t, call.Attributes, attribute.Int("http.response.status_code", 200))
+	assert.Equal(t, codes.Unset, call.Status.Code)
+}
+
+func TestTransport_RecordsFailures(t *testing.T) {
+	fake := apitest.NewServer()
+	fake.Fail(errors.New("database is down"))
+	srv := httptest.NewServer(fake.Handler())
+	defer srv.Close()
+
+	client, _, spans, reader := instrumented(t, srv)
+
+	resp, err := client.AddNumberWithResponse(context.Background(), &api.AddNumberParams{Number: 5})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusInternalServerError, resp.StatusCode())
+
+	srv.Close()
+	_, err = client.AddNumberWithResponse(context.Background(), &api.AddNumberParams{Number: 5})
+	require.Error(t, err)
+
+	got := spans.GetSpans()
+	require.Len(t, got, 2)
+	assert.Equal(t, codes.Error, got[0].Status.Code, "a 5xx response fails the span")
+	assert.Equal(t, codes.Error, got[1].Status.Code)
+	assert.Len(t, got[1].Events, 1, "the transport error is recorded")
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	require.Len(t, rm.ScopeMetrics[0].Metrics, 1)
+	duration := rm.ScopeMetrics[0].Metrics[0]
+	assert.Equal(t, "http.client.request.duration", duration.Name)
+
+	points := duration.Data.(metricdata.Histogram[float64]).DataPoints
+	require.Len(t, points, 2, "one series per outcome")
+	var errorTypes []string
+	for _, p := range points {
+		assert.Equal(t, uint64(1), p.Count)
+		v, ok := p.Attributes.Value("error.type")
+		require.True(t, ok)
+		errorTypes = append(errorTypes, v.AsString())
+		_, hasURL := p.Attributes.Value("url.full")
+		assert.False(t, hasURL, "the URL would give the metric unbounded cardinality")
+	}
+	assert.Contains(t, errorTypes, "500")
+}
+
+func TestTransport_LeavesTheRequestAlone(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer srv.Close()
+
+	tr, err := otelclient.NewTransport(nil, otelclient.WithPropagator(propagation.TraceContext{}),
+		otelclient.WithTracerProvider(sdktrace.NewTracerProvider()))
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, srv.URL, nil)
+	req.RequestURI = ""
+	resp, err := tr.RoundTrip(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Empty(t, req.Header.Get("Traceparent"), "headers are set on a clone")
+}
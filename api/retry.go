@@ -0,0 +1,170 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how NewRetryingClient retries failed requests
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first
+	MaxAttempts int
+	// BaseDelay is the backoff before the first retry; it doubles on every retry
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff. A Retry-After longer than this is not waited for and
+	// the response is returned as is.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy makes up to four attempts within about a second
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 4,
+		BaseDelay:   100 * time.Millisecond,
+		MaxDelay:    5 * time.Second,
+	}
+}
+
+// RetryingDoer is an HttpRequestDoer that retries requests when that is known to be
+// safe. Idempotent requests are retried on transport errors and on 502, 503 and 504;
+// any request is retried on 429 and 503 carrying Retry-After, since the server has
+// declined to process it. Backoff is exponential with full jitter, and Retry-After is
+// honoured when present.
+type RetryingDoer struct {
+	doer   HttpRequestDoer
+	policy RetryPolicy
+	// jitter returns a random duration in [0, d]
+	jitter func(d time.Duration) time.Duration
+}
+
+// NewRetryingDoer wraps doer with policy
+func NewRetryingDoer(doer HttpRequestDoer, policy RetryPolicy) *RetryingDoer {
+	return &RetryingDoer{
+		doer:   doer,
+		policy: policy,
+		jitter: func(d time.Duration) time.Duration { return rand.N(d + 1) },
+	}
+}
+
+// NewRetryingClient creates a ClientWithResponses whose requests are retried
+// according to policy. opts apply as for NewClientWithResponses; the retries wrap
+// the doer set by WithHTTPClient, if any.
+func NewRetryingClient(server string, policy RetryPolicy, opts ...ClientOption) (*ClientWithResponses, error) {
+	client, err := NewClient(server, opts...)
+	if err != nil {
+		return nil, err
+	}
+	client.Client = NewRetryingDoer(client.Client, policy)
+
+	return &ClientWithResponses{client}, nil
+}
+
+// Do sends req, retrying as described on RetryingDoer. A request whose body cannot
+// be replayed is sent once.
+func (d *RetryingDoer) Do(req *http.Request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		resp, err := d.doer.Do(req)
+
+		if attempt >= d.policy.MaxAttempts || (req.Body != nil && req.GetBody == nil) {
+			return resp, err
+		}
+		delay, retry := d.delay(req, resp, err, attempt)
+		if !retry {
+			return resp, err
+		}
+
+		if resp != nil {
+			// Drain the body so the connection can be reused
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10))
+			resp.Body.Close()
+		}
+		if err := sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// delay reports whether the outcome of attempt is worth retrying and after how long
+func (d *RetryingDoer) delay(req *http.Request, resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	if req.Context().Err() != nil || errors.Is(err, ErrCircuitOpen) {
+		return 0, false
+	}
+
+	if resp != nil {
+		if after, ok := retryAfter(resp); ok && (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable) {
+			return after, after <= d.policy.MaxDelay
+		}
+	}
+
+	if !idempotent(req) {
+		return 0, false
+	}
+	if err == nil {
+		switch resp.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		default:
+			return 0, false
+		}
+	}
+
+	backoff := d.policy.BaseDelay << (attempt - 1)
+	if backoff <= 0 || backoff > d.policy.MaxDelay {
+		backoff = d.policy.MaxDelay
+	}
+
+	return d.jitter(backoff), true
+}
+
+// idempotent follows net/http: safe methods, PUT and DELETE, and requests carrying
+// an idempotency key
+func idempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	return req.Header.Get("Idempotency-Key") != "" || req.Header.Get("X-Idempotency-Key") != ""
+}
+
+// retryAfter parses the Retry-After header, given in seconds or as an HTTP date
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0), true
+	}
+
+	return 0, false
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scripted answers with the given statuses in turn, then with 200, counting requests
+func scripted(t *testing.T, header http.Header, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1))
+		if n > len(statuses) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"numbers":[1]}`))
+			return
+		}
+		for k, v := range header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(statuses[n-1])
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &calls
+}
+
+func fastPolicy() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 4, BaseDelay: time.Millisecond, MaxDelay: 50 * time.Millisecond}
+}
+
+func TestRetryingClient_RetriesRetryAfter(t *testing.T) {
+	srv, calls := scripted(t, http.Header{"Retry-After": {"0"}}, http.StatusTooManyRequests, http.StatusServiceUnavailable)
+
+	client, err := NewRetryingClient(srv.URL, fastPolicy())
+	require.NoError(t, err)
+
+	resp, err := client.AddNumberWithResponse(context.Background(), &AddNumberParams{Number: 1})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode())
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestRetryingClient_DoesNotRetryNonIdempotent(t *testing.T) {
+	// POST /numbers without Retry-After may have been applied
+	srv, calls := scripted(t, nil, http.StatusServiceUnavailable)
+
+	client, err := NewRetryingClient(srv.URL, fastPolicy())
+	require.NoError(t, err)
+
+	resp, err := client.AddNumberWithResponse(context.Background(), &AddNumberParams{Number: 1})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode())
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestRetryingClient_RetriesWithIdempotencyKey(t *testing.T) {
+	srv, calls := scripted(t, nil, http.StatusBadGateway)
+
+	client, err := NewRetryingClient(srv.URL, fastPolicy())
+	require.NoError(t, err)
+
+	withKey := func(ctx context.Context, req *http.Request) error {
+		req.Header.Set("Idempotency-Key", "7f3c")
+		return nil
+	}
+	resp, err := client.AddNumberWithResponse(context.Background(), &AddNumberParams{Number: 1}, withKey)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode())
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestRetryingDoer_Idempotent(t *testing.T) {
+	tests := []struct {
+		name      string
+		statuses  []int
+		wantCode  int
+		wantCalls int32
+	}{
+		{name: "recovers", statuses: []int{502, 503, 504}, wantCode: 200, wantCalls: 4},
+		{name: "gives up after max attempts", statuses: []int{503, 503, 503, 503, 503}, wantCode: 503, wantCalls: 4},
+		{name: "not on server errors", statuses: []int{500}, wantCode: 500, wantCalls: 1},
+		{name: "not on client errors", statuses: []int{404}, wantCode: 404, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, calls := scripted(t, nil, tt.statuses...)
+			doer := NewRetryingDoer(http.DefaultClient, fastPolicy())
+
+			req, err := http.NewRequest(http.MethodPut, srv.URL, strings.NewReader("body"))
+			require.NoError(t, err)
+			resp, err := doer.Do(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			assert.Equal(t, tt.wantCode, resp.StatusCode)
+			assert.Equal(t, tt.wantCalls, calls.Load())
+		})
+	}
+}
+
+func TestRetryingDoer_TransportErrors(t *testing.T) {
+	get := &fakeDoer{err: errors.New("connection refused")}
+	req, err := http.NewRequest(http.MethodGet, "http://numbers.invalid", nil)
+	require.NoError(t, err)
+	_, err = NewRetryingDoer(get, fastPolicy()).Do(req)
+	assert.Error(t, err)
+	assert.Equal(t, 4, get.calls)
+
+	post := &fakeDoer{err: errors.New("connection refused")}
+	req, err = http.NewRequest(http.MethodPost, "http://numbers.invalid", nil)
+	require.NoError(t, err)
+	_, err = NewRetryingDoer(post, fastPolicy()).Do(req)
+	assert.Error(t, err)
+	assert.Equal(t, 1, post.calls)
+}
+
+func TestRetryingDoer_LongRetryAfterIsNotWaited(t *testing.T) {
+	srv, calls := scripted(t, http.Header{"Retry-After": {"120"}}, http.StatusTooManyRequests)
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+	resp, err := NewRetryingDoer(http.DefaultClient, fastPolicy()).Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestRetryingDoer_StopsWhenCancelled(t *testing.T) {
+	srv, _ := scripted(t, nil, 503, 503, 503)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	policy := RetryPolicy{MaxAttempts: 4, BaseDelay: time.Hour, MaxDelay: time.Hour}
+	doer := NewRetryingDoer(http.DefaultClient, policy)
+	doer.jitter = func(d time.Duration) time.Duration { return d }
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+
+	time.AfterFunc(10*time.Millisecond, cancel)
+	_, err = doer.Do(req)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestRetryAfter(t *testing.T) {
+	resp := &http.Response{Header: http.Header{}}
+	_, ok := retryAfter(resp)
+	assert.False(t, ok)
+
+	resp.Header.Set("Retry-After", "3")
+	d, ok := retryAfter(resp)
+	assert.True(t, ok)
+	assert.Equal(t, 3*time.Second, d)
+
+	resp.Header.Set("Retry-After", time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat))
+	d, ok = retryAfter(resp)
+	assert.True(t, ok)
+	assert.Zero(t, d, "a date in the past means now")
+
+	resp.Header.Set("Retry-After", "soon")
+	_, ok = retryAfter(resp)
+	assert.False(t, ok)
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// The headers of a signed request
+const (
+	SignatureHeader          = "X-Signature"
+	SignatureTimestampHeader = "X-Signature-Timestamp"
+	SignatureNonceHeader     = "X-Signature-Nonce"
+)
+
+// Signature is the hex HMAC-SHA256 under secret of a request's method, path and
+// query, timestamp (Unix seconds), nonce and body, one per line with the body as
+// its hex SHA-256. The host is left out, since proxies may rewrite it.
+func Signature(secret []byte, method, requestURI, timestamp, nonce string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+
+	mac := hmac.New(sha256.New, secret)
+	io.WriteString(mac, method+"\n"+requestURI+"\n"+timestamp+"\n"+nonce+"\n"+hex.EncodeToString(bodyHash[:]))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SigningDoer is an HttpRequestDoer that signs every request with a shared secret,
+// for servers that verify signatures because TLS is terminated outside their
+// control. Each request gets a fresh timestamp and nonce, which the server uses to
+// reject replays, so it must sit inside a RetryingDoer: pass it to
+// NewRetryingClient with WithHTTPClient, and every attempt is signed anew.
+type SigningDoer struct {
+	doer   HttpRequestDoer
+	secret []byte
+	now    func() time.Time
+}
+
+// NewSigningDoer wraps doer, signing with secret
+func NewSigningDoer(doer HttpRequestDoer, secret []byte) *SigningDoer {
+	return &SigningDoer{doer: doer, secret: secret, now: time.Now}
+}
+
+// Do signs a copy of req and sends it
+func (d *SigningDoer) Do(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	nonce := make([]byte, 16)
+	rand.Read(nonce)
+	timestamp := strconv.FormatInt(d.now().Unix(), 10)
+
+	req = req.Clone(req.Context())
+	if body != nil {
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
+	}
+	req.Header.Set(SignatureTimestampHeader, timestamp)
+	req.Header.Set(SignatureNonceHeader, hex.EncodeToString(nonce))
+	req.Header.Set(SignatureHeader, Signature(d.secret, req.Method, req.URL.RequestURI(), timestamp, hex.EncodeToString(nonce), body))
+
+	return d.doer.Do(req)
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignature(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	sig := Signature(secret, "POST", "/numbers?number=5", "1700000000", "n1", nil)
+
+	assert.Len(t, sig, 64)
+	assert.Equal(t, sig, Signature(secret, "POST", "/numbers?number=5", "1700000000", "n1", []byte{}), "no body and an empty body sign alike")
+	for _, other := range []string{
+		Signature([]byte("another secret"), "POST", "/numbers?number=5", "1700000000", "n1", nil),
+		Signature(secret, "GET", "/numbers?number=5", "1700000000", "n1", nil),
+		Signature(secret, "POST", "/numbers?number=6", "1700000000", "n1", nil),
+		Signature(secret, "POST", "/numbers?number=5", "1700000001", "n1", nil),
+		Signature(secret, "POST", "/numbers?number=5", "1700000000", "n2", nil),
+		Signature(secret, "POST", "/numbers?number=5", "1700000000", "n1", []byte("{}")),
+	} {
+		assert.NotEqual(t, sig, other)
+	}
+}
+
+func TestSigningDoer_SignsEveryAttempt(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	var nonces []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, nonce := r.Header.Get(SignatureTimestampHeader), r.Header.Get(SignatureNonceHeader)
+		assert.Equal(t, Signature(secret, r.Method, r.RequestURI, timestamp, nonce, body), r.Header.Get(SignatureHeader))
+		assert.Equal(t, `{"n":1}`, string(body))
+
+		nonces = append(nonces, nonce)
+		if len(nonces) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	signer := NewSigningDoer(http.DefaultClient, secret)
+	signer.now = func() time.Time { return time.Unix(1700000000, 0) }
+	doer := NewRetryingDoer(signer, RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond})
+
+	req, err := http.NewRequest(http.MethodPut, srv.URL+"/numbers?number=5", strings.NewReader(`{"n":1}`))
+	require.NoError(t, err)
+	resp, err := doer.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Len(t, nonces, 2)
+	assert.NotEqual(t, nonces[0], nonces[1], "a retry is not a replay")
+	assert.Empty(t, req.Header.Get(SignatureHeader), "the caller's request is left alone")
+}
@@ -0,0 +1,174 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Writer is a backup destination. Nothing is visible at the location until Close
+// succeeds; Abort discards what was written.
+type Writer interface {
+	io.Writer
+	Close() error
+	Abort(err error)
+}
+
+// Create opens location for writing a backup. location is a file path, - for
+// stdout, or s3://bucket/key, with AWS credentials resolved the standard way.
+func Create(ctx context.Context, location string) (Writer, error) {
+	if location == "-" {
+		return stdout{os.Stdout}, nil
+	}
+	if bucket, key, ok, err := parseS3(location); ok || err != nil {
+		if err != nil {
+			return nil, err
+		}
+		client, err := s3Client(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return newS3Writer(ctx, manager.NewUploader(client), bucket, key), nil
+	}
+
+	return createFile(location)
+}
+
+// Open opens location, as accepted by Create, for reading a backup. - is stdin.
+func Open(ctx context.Context, location string) (io.ReadCloser, error) {
+	if location == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	if bucket, key, ok, err := parseS3(location); ok || err != nil {
+		if err != nil {
+			return nil, err
+		}
+		client, err := s3Client(ctx)
+		if err != nil {
+			return nil, err
+		}
+		out, err := client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read s3://%s/%s: %w", bucket, key, err)
+		}
+		return out.Body, nil
+	}
+
+	return os.Open(location)
+}
+
+// parseS3 splits an s3://bucket/key location; ok is false for anything else
+func parseS3(location string) (bucket, key string, ok bool, err error) {
+	if !strings.HasPrefix(location, "s3://") {
+		return "", "", false, nil
+	}
+
+	u, err := url.Parse(location)
+	if err != nil || u.Host == "" || strings.Trim(u.Path, "/") == "" {
+		return "", "", true, fmt.Errorf("%q must be s3://bucket/key", location)
+	}
+
+	return u.Host, strings.TrimPrefix(u.Path, "/"), true, nil
+}
+
+func s3Client(ctx context.Context) (*s3.Client, error) {
+	cfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS configuration: %w", err)
+	}
+
+	return s3.NewFromConfig(cfg), nil
+}
+
+// stdout writes straight through; a failed backup leaves a truncated stream, which
+// gzip readers reject
+type stdout struct{ io.Writer }
+
+func (stdout) Close() error  { return nil }
+func (stdout) Abort(_ error) {}
+
+// fileWriter writes next to the target and renames into place on Close, so an
+// existing backup is never replaced by a partial one
+type fileWriter struct {
+	*os.File
+	path string
+}
+
+func createFile(path string) (*fileWriter, error) {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return nil, err
+	}
+
+	return &fileWriter{File: f, path: path}, nil
+}
+
+func (w *fileWriter) Close() error {
+	if err := w.File.Close(); err != nil {
+		os.Remove(w.Name())
+		return err
+	}
+
+	return os.Rename(w.Name(), w.path)
+}
+
+func (w *fileWriter) Abort(_ error) {
+	w.File.Close()
+	os.Remove(w.Name())
+}
+
+// uploader is the part of manager.Uploader s3Writer uses
+type uploader interface {
+	Upload(ctx context.Context, input *s3.PutObjectInput, opts ...func(*manager.Uploader)) (*manager.UploadOutput, error)
+}
+
+// s3Writer streams into a multipart upload; the object appears once the upload
+// completes on Close, and an aborted upload leaves nothing behind
+type s3Writer struct {
+	pw   *io.PipeWriter
+	done chan error
+}
+
+func newS3Writer(ctx context.Context, u uploader, bucket, key string) *s3Writer {
+	pr, pw := io.Pipe()
+	w := &s3Writer{pw: pw, done: make(chan error, 1)}
+
+	go func() {
+		_, err := u.Upload(ctx, &s3.PutObjectInput{Bucket: aws.String(bucket), Key: aws.String(key), Body: pr})
+		if err != nil {
+			err = fmt.Errorf("failed to upload s3://%s/%s: %w", bucket, key, err)
+		}
+		pr.CloseWithError(err)
+		w.done <- err
+	}()
+
+	return w
+}
+
+func (w *s3Writer) Write(p []byte) (int, error) {
+	return w.pw.Write(p)
+}
+
+func (w *s3Writer) Close() error {
+	w.pw.Close()
+
+	return <-w.done
+}
+
+func (w *s3Writer) Abort(err error) {
+	if err == nil {
+		err = errors.New("backup aborted")
+	}
+	w.pw.CloseWithError(err)
+	<-w.done
+}
@@ -0,0 +1,104 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseS3(t *testing.T) {
+	tests := []struct {
+		location    string
+		bucket, key string
+		ok, wantErr bool
+	}{
+		{location: "s3://backups/numbers/2024-01-01.csv.gz", bucket: "backups", key: "numbers/2024-01-01.csv.gz", ok: true},
+		{location: "/var/backups/numbers.csv.gz"},
+		{location: "numbers.csv.gz"},
+		{location: "s3://backups", ok: true, wantErr: true},
+		{location: "s3:///numbers.csv.gz", ok: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.location, func(t *testing.T) {
+			bucket, key, ok, err := parseS3(tt.location)
+			assert.Equal(t, tt.ok, ok)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.bucket, bucket)
+			assert.Equal(t, tt.key, key)
+		})
+	}
+}
+
+func TestFileWriter(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "numbers.csv.gz")
+	require.NoError(t, os.WriteFile(path, []byte("previous"), 0o644))
+
+	w, err := Create(context.Background(), path)
+	require.NoError(t, err)
+	_, err = w.Write([]byte("partial"))
+	require.NoError(t, err)
+	w.Abort(errors.New("connection lost"))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "previous", string(data), "an aborted backup does not replace the previous one")
+
+	w, err = Create(context.Background(), path)
+	require.NoError(t, err)
+	_, err = w.Write([]byte("complete"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "complete", string(data))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary files are left behind")
+}
+
+// fakeUploader reads the body like manager.Uploader and keeps what it uploaded
+type fakeUploader struct {
+	uploaded []byte
+}
+
+func (u *fakeUploader) Upload(ctx context.Context, input *s3.PutObjectInput, opts ...func(*manager.Uploader)) (*manager.UploadOutput, error) {
+	body, err := io.ReadAll(input.Body)
+	if err != nil {
+		return nil, err
+	}
+	u.uploaded = body
+
+	return &manager.UploadOutput{}, nil
+}
+
+func TestS3Writer(t *testing.T) {
+	u := &fakeUploader{}
+	w := newS3Writer(context.Background(), u, "backups", "numbers.csv.gz")
+	_, err := w.Write([]byte("complete"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	assert.Equal(t, "complete", string(u.uploaded))
+
+	u = &fakeUploader{}
+	w = newS3Writer(context.Background(), u, "backups", "numbers.csv.gz")
+	_, err = w.Write([]byte("partial"))
+	require.NoError(t, err)
+	w.Abort(errors.New("connection lost"))
+	assert.Nil(t, u.uploaded, "an aborted upload is not completed")
+}
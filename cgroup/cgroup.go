@@ -0,0 +1,130 @@
+// Package cgroup reads the memory limit and CPU quota a container runs under,
+// from cgroup v2 or, failing that, cgroup v1.
+package cgroup
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Limits reads cgroup files relative to a root, /sys/fs/cgroup in production
+type Limits struct {
+	fsys fs.FS
+}
+
+// New returns Limits for the cgroup filesystem mounted at /sys/fs/cgroup
+func New() *Limits {
+	return &Limits{fsys: os.DirFS("/sys/fs/cgroup")}
+}
+
+// MemoryLimit returns the memory limit in bytes. ok is false when the process is not limited.
+func (l *Limits) MemoryLimit() (limit int64, ok bool, err error) {
+	// cgroup v2: "max" or a byte count
+	value, err := l.read("memory.max")
+	if err == nil {
+		if value == "max" {
+			return 0, false, nil
+		}
+		return parsePositive("memory.max", value)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return 0, false, err
+	}
+
+	// cgroup v1: a huge number close to the maximum int64 means unlimited
+	value, err = l.read("memory/memory.limit_in_bytes")
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	limit, ok, err = parsePositive("memory.limit_in_bytes", value)
+	if err != nil || limit >= 1<<62 {
+		return 0, false, err
+	}
+
+	return limit, ok, nil
+}
+
+// CPUQuota returns the number of CPUs the quota allows per period, possibly fractional.
+// ok is false when the process is not limited.
+func (l *Limits) CPUQuota() (cpus float64, ok bool, err error) {
+	// cgroup v2: "<quota> <period>" with quota "max" when unlimited
+	value, err := l.read("cpu.max")
+	if err == nil {
+		fields := strings.Fields(value)
+		if len(fields) != 2 {
+			return 0, false, fmt.Errorf("unexpected cpu.max content %q", value)
+		}
+		if fields[0] == "max" {
+			return 0, false, nil
+		}
+		return quota("cpu.max", fields[0], fields[1])
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return 0, false, err
+	}
+
+	// cgroup v1: quota -1 when unlimited
+	q, err := l.read("cpu/cpu.cfs_quota_us")
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	if q == "-1" {
+		return 0, false, nil
+	}
+	period, err := l.read("cpu/cpu.cfs_period_us")
+	if err != nil {
+		return 0, false, err
+	}
+
+	return quota("cpu.cfs_quota_us", q, period)
+}
+
+// read returns the first line of name
+func (l *Limits) read(name string) (string, error) {
+	f, err := l.fsys.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Scan()
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(scanner.Text()), nil
+}
+
+func quota(name, quota, period string) (float64, bool, error) {
+	q, _, err := parsePositive(name, quota)
+	if err != nil {
+		return 0, false, err
+	}
+	p, _, err := parsePositive(name, period)
+	if err != nil {
+		return 0, false, err
+	}
+
+	return float64(q) / float64(p), true, nil
+}
+
+func parsePositive(name, value string) (int64, bool, error) {
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, false, fmt.Errorf("unexpected %s content %q", name, value)
+	}
+
+	return n, true, nil
+}
@@ -0,0 +1,72 @@
+package cgroup
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func limits(files map[string]string) *Limits {
+	fsys := fstest.MapFS{}
+	for name, content := range files {
+		fsys[name] = &fstest.MapFile{Data: []byte(content)}
+	}
+
+	return &Limits{fsys: fsys}
+}
+
+func TestMemoryLimit(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     map[string]string
+		wantLimit int64
+		wantOK    bool
+	}{
+		{name: "v2 limited", files: map[string]string{"memory.max": "536870912\n"}, wantLimit: 512 << 20, wantOK: true},
+		{name: "v2 unlimited", files: map[string]string{"memory.max": "max\n"}},
+		{name: "v1 limited", files: map[string]string{"memory/memory.limit_in_bytes": "268435456\n"}, wantLimit: 256 << 20, wantOK: true},
+		{name: "v1 unlimited", files: map[string]string{"memory/memory.limit_in_bytes": "9223372036854771712\n"}},
+		{name: "no cgroup", files: map[string]string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limit, ok, err := limits(tt.files).MemoryLimit()
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantLimit, limit)
+		})
+	}
+
+	_, _, err := limits(map[string]string{"memory.max": "lots"}).MemoryLimit()
+	assert.Error(t, err)
+}
+
+func TestCPUQuota(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		wantCPUs float64
+		wantOK   bool
+	}{
+		{name: "v2 limited", files: map[string]string{"cpu.max": "150000 100000\n"}, wantCPUs: 1.5, wantOK: true},
+		{name: "v2 unlimited", files: map[string]string{"cpu.max": "max 100000\n"}},
+		{name: "v1 limited", files: map[string]string{"cpu/cpu.cfs_quota_us": "200000\n", "cpu/cpu.cfs_period_us": "100000\n"}, wantCPUs: 2, wantOK: true},
+		{name: "v1 unlimited", files: map[string]string{"cpu/cpu.cfs_quota_us": "-1\n", "cpu/cpu.cfs_period_us": "100000\n"}},
+		{name: "no cgroup", files: map[string]string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cpus, ok, err := limits(tt.files).CPUQuota()
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.InDelta(t, tt.wantCPUs, cpus, 1e-9)
+		})
+	}
+
+	_, _, err := limits(map[string]string{"cpu.max": "100000"}).CPUQuota()
+	assert.Error(t, err)
+}
@@ -0,0 +1,152 @@
+# Code generated by clientgen from openapi.yaml. DO NOT EDIT.
+
+"""Client for the NumberService API 0.0.1, using only the Python 3.11+ standard library."""
+
+from __future__ import annotations
+
+import json
+import urllib.error
+import urllib.parse
+import urllib.request
+from typing import Any, List, NotRequired, TypedDict
+
+
+class BatchResult(TypedDict):
+    """What a batch added and where it stands among the stored numbers"""
+
+    inserted: int
+    window: "NumbersPage"
+
+
+class CreateNumberResponse(TypedDict):
+    """Position and window are sent in the window response mode, numbers in the full mode, count and position in the count mode; id in every mode"""
+
+    count: NotRequired[int]
+    id: NotRequired[str]
+    meta: NotRequired["ResponseMeta"]
+    numbers: NotRequired["Numbers"]
+    position: NotRequired[int]
+    window: NotRequired["Numbers"]
+
+
+class ErrorResponse(TypedDict):
+    error: str
+
+
+class NumberEvent(TypedDict):
+    created_at: str
+    id: str
+    number: int
+
+
+class NumberStats(TypedDict):
+    """The statistics of the stored numbers. Min, max, mean and median are absent when none is stored, as are the values of the percentiles."""
+
+    count: int
+    max: NotRequired[int]
+    mean: NotRequired[float]
+    median: NotRequired[float]
+    min: NotRequired[int]
+    percentiles: List["Percentile"]
+    sum: float
+
+
+Numbers = List[int]
+
+
+class NumbersPage(TypedDict):
+    """A page of the stored numbers"""
+
+    next_cursor: NotRequired[str]
+    numbers: "Numbers"
+
+
+class Percentile(TypedDict):
+    percentile: float
+    value: NotRequired[float]
+
+
+class ResponseMeta(TypedDict):
+    """Sent with a list when the request asks for the envelope"""
+
+    elapsed_ms: int
+    next_cursor: NotRequired[str]
+    request_id: str
+    returned: int
+    total: int
+
+
+class ApiError(Exception):
+    """Raised for responses outside 2xx, with the decoded body when it is JSON."""
+
+    def __init__(self, status: int, body: Any) -> None:
+        super().__init__(f"request failed with status {status}")
+        self.status = status
+        self.body = body
+
+
+class Client:
+    def __init__(self, base_url: str, timeout: float = 30.0) -> None:
+        self.base_url = base_url.rstrip("/")
+        self.timeout = timeout
+
+    def add_number(self, number: int, envelope: bool | None = None, response: str | None = None, full: bool | None = None) -> "CreateNumberResponse":
+        """Add a number to the list"""
+        path = "/numbers"
+        query = {"number": number, "envelope": envelope, "response": response, "full": full}
+        return self._request("POST", path, query)
+
+    def add_number_batch(self, body: "Numbers") -> "BatchResult":
+        """Add every number of the list in one transaction: either all of them are stored or, on any failure, none is. The response counts them and holds the first page of the stored numbers from the smallest of them to the largest, which GET /numbers continues with the same min, max and the cursor."""
+        path = "/numbers/batch"
+        query = {}
+        return self._request("POST", path, query, body)
+
+    def clear_numbers(self) -> Any:
+        """Delete every stored number. Only an admin API key may, so a server without server.api_keys set lets no one."""
+        path = "/numbers"
+        query = {}
+        return self._request("DELETE", path, query)
+
+    def delete_number(self, id: str) -> Any:
+        """Delete the stored number with the id its insert answered with"""
+        path = "/numbers/{id}".format(id=urllib.parse.quote(str(id), safe=""))
+        query = {}
+        return self._request("DELETE", path, query)
+
+    def get_number_stats(self, percentiles: List[float] | None = None) -> "NumberStats":
+        """Aggregate the stored numbers: how many there are, their min, max, sum, mean, median and percentiles. The database computes them in one scan of the table, so no number is sent to the server, however many are stored."""
+        path = "/numbers/stats"
+        query = {"percentiles": percentiles}
+        return self._request("GET", path, query)
+
+    def list_numbers(self, limit: int | None = None, after: str | None = None, min: int | None = None, max: int | None = None) -> "NumbersPage":
+        """List the stored numbers in ascending order, a page at a time. Numbers that are equal are ordered by their id, so a page never repeats or skips a row."""
+        path = "/numbers"
+        query = {"limit": limit, "after": after, "min": min, "max": max}
+        return self._request("GET", path, query)
+
+    def _request(self, method: str, path: str, query: dict[str, Any], body: Any = None) -> Any:
+        params = {k: str(v).lower() if isinstance(v, bool) else v for k, v in query.items() if v is not None}
+        url = self.base_url + path
+        if params:
+            url += "?" + urllib.parse.urlencode(params, doseq=True)
+
+        headers = {"Accept": "application/json"}
+        data = None
+        if body is not None:
+            headers["Content-Type"] = "application/json"
+            data = json.dumps(body).encode()
+        request = urllib.request.Request(url, data=data, method=method, headers=headers)
+        try:
+            with urllib.request.urlopen(request, timeout=self.timeout) as response:
+                return _decode(response)
+        except urllib.error.HTTPError as e:
+            raise ApiError(e.code, _decode(e)) from None
+
+
+def _decode(response: Any) -> Any:
+    body = response.read()
+    if "json" in (response.headers.get("Content-Type") or ""):
+        return json.loads(body)
+    return body.decode()
@@ -0,0 +1,205 @@
+// Code generated by clientgen from openapi.yaml. DO NOT EDIT.
+
+// Client for the NumberService API 0.0.1. It only needs the standard fetch API,
+// available in browsers and Node.js 18+.
+
+/** What a batch added and where it stands among the stored numbers */
+export interface BatchResult {
+  /** How many numbers were added */
+  inserted: number;
+  /** The first page of the stored numbers from the smallest number added to the largest */
+  window: NumbersPage;
+}
+
+/** Position and window are sent in the window response mode, numbers in the full mode, count and position in the count mode; id in every mode */
+export interface CreateNumberResponse {
+  /** How many numbers are stored */
+  count?: number;
+  /** The id of the row stored, which DELETE /numbers/{id} takes */
+  id?: string;
+  meta?: ResponseMeta;
+  numbers?: Numbers;
+  /** How many stored numbers are smaller than the number added */
+  position?: number;
+  /** The number added with the stored numbers next to it on either side, as many as the server is configured for, in ascending order */
+  window?: Numbers;
+}
+
+export interface ErrorResponse {
+  error: string;
+}
+
+export interface NumberEvent {
+  created_at: string;
+  id: string;
+  number: number;
+}
+
+/** The statistics of the stored numbers. Min, max, mean and median are absent when none is stored, as are the values of the percentiles. */
+export interface NumberStats {
+  /** How many numbers are stored */
+  count: number;
+  max?: number;
+  mean?: number;
+  /** The 50th percentile */
+  median?: number;
+  min?: number;
+  /** The percentiles asked for, in the order they were */
+  percentiles: Percentile[];
+  /** The sum of the numbers, which may be beyond the int64 range. It is summed exactly and rounded to a double, so it is exact up to 2^53. */
+  sum: number;
+}
+
+export type Numbers = number[];
+
+/** A page of the stored numbers */
+export interface NumbersPage {
+  /** Where the next page starts. Absent on the last page. */
+  next_cursor?: string;
+  numbers: Numbers;
+}
+
+export interface Percentile {
+  /** The percentile, from 0 to 100 */
+  percentile: number;
+  /** The value at the percentile, absent when no number is stored */
+  value?: number;
+}
+
+/** Sent with a list when the request asks for the envelope */
+export interface ResponseMeta {
+  /** How long the server took to store the number and read the list */
+  elapsed_ms: number;
+  /** Where the next page starts. Absent while every list is returned whole. */
+  next_cursor?: string;
+  /** The X-Request-Id header of the request, or an id the server made up */
+  request_id: string;
+  /** How many numbers the response holds */
+  returned: number;
+  /** How many numbers are stored */
+  total: number;
+}
+
+/** ApiError is thrown for responses outside 2xx, with the decoded body when it is JSON */
+export class ApiError extends Error {
+  constructor(
+    readonly status: number,
+    readonly body: unknown,
+  ) {
+    super(`request failed with status ${status}`);
+    this.name = "ApiError";
+  }
+}
+
+export interface AddNumberParams {
+  /** The number to add */
+  number: number;
+  /** Whether the response carries meta, the counts and diagnostics of the list. Sending the header Prefer: envelope (RFC 7240) does the same. */
+  envelope?: boolean;
+  /** What the response holds: window, where the number stands and the stored numbers around it; full, every stored number; count, how many are stored and where the number stands among them; or none, no body at all (204). Defaults to the mode the server is configured with, window unless changed. */
+  response?: string;
+  /** Whether the response holds every stored number, the same as response=full. Reading the whole list takes time in proportion to it, so only small datasets should be asked for it. */
+  full?: boolean;
+}
+
+export interface DeleteNumberParams {
+  /** The id of the row */
+  id: string;
+}
+
+export interface GetNumberStatsParams {
+  /** The percentiles to compute, each from 0 to 100, such as percentiles=90&percentiles=99. Each interpolates between the two numbers nearest to it, as percentile_cont does. Defaults to 90, 95 and 99. */
+  percentiles?: number[];
+}
+
+export interface ListNumbersParams {
+  /** The most numbers the page holds */
+  limit?: number;
+  /** The next_cursor of the previous page; the first page is listed without it. A cursor only makes sense with the min and max it was listed with. */
+  after?: string;
+  /** The smallest number listed */
+  min?: number;
+  /** The largest number listed */
+  max?: number;
+}
+
+export class Client {
+  private readonly baseUrl: string;
+
+  constructor(
+    baseUrl: string,
+    private readonly fetchFn: typeof fetch = globalThis.fetch.bind(globalThis),
+  ) {
+    this.baseUrl = baseUrl.replace(/\/+$/, "");
+  }
+
+  /** Add a number to the list */
+  async addNumber(params: AddNumberParams, init?: RequestInit): Promise<CreateNumberResponse> {
+    const query = new URLSearchParams();
+    query.set("number", String(params.number));
+    if (params.envelope !== undefined) query.set("envelope", String(params.envelope));
+    if (params.response !== undefined) query.set("response", String(params.response));
+    if (params.full !== undefined) query.set("full", String(params.full));
+    const path = `/numbers`;
+    return (await this.request("POST", path, query, undefined, init)) as CreateNumberResponse;
+  }
+
+  /** Add every number of the list in one transaction: either all of them are stored or, on any failure, none is. The response counts them and holds the first page of the stored numbers from the smallest of them to the largest, which GET /numbers continues with the same min, max and the cursor. */
+  async addNumberBatch(body: Numbers, init?: RequestInit): Promise<BatchResult> {
+    const query = new URLSearchParams();
+    const path = `/numbers/batch`;
+    return (await this.request("POST", path, query, body, init)) as BatchResult;
+  }
+
+  /** Delete every stored number. Only an admin API key may, so a server without server.api_keys set lets no one. */
+  async clearNumbers(init?: RequestInit): Promise<unknown> {
+    const query = new URLSearchParams();
+    const path = `/numbers`;
+    return (await this.request("DELETE", path, query, undefined, init)) as unknown;
+  }
+
+  /** Delete the stored number with the id its insert answered with */
+  async deleteNumber(params: DeleteNumberParams, init?: RequestInit): Promise<unknown> {
+    const query = new URLSearchParams();
+    const path = `/numbers/{id}`.replace("{id}", encodeURIComponent(String(params.id)));
+    return (await this.request("DELETE", path, query, undefined, init)) as unknown;
+  }
+
+  /** Aggregate the stored numbers: how many there are, their min, max, sum, mean, median and percentiles. The database computes them in one scan of the table, so no number is sent to the server, however many are stored. */
+  async getNumberStats(params: GetNumberStatsParams, init?: RequestInit): Promise<NumberStats> {
+    const query = new URLSearchParams();
+    for (const value of params.percentiles ?? []) query.append("percentiles", String(value));
+    const path = `/numbers/stats`;
+    return (await this.request("GET", path, query, undefined, init)) as NumberStats;
+  }
+
+  /** List the stored numbers in ascending order, a page at a time. Numbers that are equal are ordered by their id, so a page never repeats or skips a row. */
+  async listNumbers(params: ListNumbersParams, init?: RequestInit): Promise<NumbersPage> {
+    const query = new URLSearchParams();
+    if (params.limit !== undefined) query.set("limit", String(params.limit));
+    if (params.after !== undefined) query.set("after", String(params.after));
+    if (params.min !== undefined) query.set("min", String(params.min));
+    if (params.max !== undefined) query.set("max", String(params.max));
+    const path = `/numbers`;
+    return (await this.request("GET", path, query, undefined, init)) as NumbersPage;
+  }
+
+  private async request(method: string, path: string, query: URLSearchParams, payload: unknown, init?: RequestInit): Promise<unknown> {
+    const headers = new Headers(init?.headers);
+    headers.set("Accept", "application/json");
+    const requestInit: RequestInit = { ...init, method, headers };
+    if (payload !== undefined) {
+      headers.set("Content-Type", "application/json");
+      requestInit.body = JSON.stringify(payload);
+    }
+
+    const search = query.toString();
+    const response = await this.fetchFn(this.baseUrl + path + (search ? `?${search}` : ""), requestInit);
+    const body = response.headers.get("Content-Type")?.includes("json") ? await response.json() : await response.text();
+    if (!response.ok) {
+      throw new ApiError(response.status, body);
+    }
+
+    return body;
+  }
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"golang-test-task/api"
+
+	"github.com/spf13/cobra"
+)
+
+func newAddCmd(opts *globalOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "add NUMBER",
+		Short: "Add a number and print every stored number",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return usageError{fmt.Errorf("add takes exactly one number, got %d arguments", len(args))}
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			number, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return usageError{fmt.Errorf("%q is not an integer", args[0])}
+			}
+
+			client, err := opts.client()
+			if err != nil {
+				return err
+			}
+			// The list is printed, so it is asked for whatever the server answers by default
+			full := api.AddNumberParamsResponseFull
+			resp, err := client.AddNumberWithResponse(cmd.Context(), &api.AddNumberParams{Number: number, Response: &full})
+			if err != nil {
+				return err
+			}
+			if resp.JSON200 == nil {
+				return responseError(resp, resp.Body)
+			}
+
+			numbers := []int64{}
+			if resp.JSON200.Numbers != nil {
+				numbers = *resp.JSON200.Numbers
+			}
+			return opts.print(cmd.OutOrStdout(), resp.JSON200, func(w io.Writer) {
+				for _, n := range numbers {
+					fmt.Fprintln(w, n)
+				}
+			})
+		},
+	}
+}
+
+// batchResult is the output of add-batch
+type batchResult struct {
+	Added  int           `json:"added"`
+	Failed []batchFailed `json:"failed"`
+}
+
+// batchFailed is a batch of numbers that failed, from the first to the last value of
+// the input, counted from 1
+type batchFailed struct {
+	First int    `json:"first"`
+	Last  int    `json:"last"`
+	Error string `json:"error"`
+}
+
+func newAddBatchCmd(opts *globalOptions) *cobra.Command {
+	var concurrency int
+
+	cmd := &cobra.Command{
+		Use:   "add-batch [FILE]",
+		Short: "Add whitespace-separated numbers from FILE, or from stdin when FILE is - or absent",
+		Long: `Add whitespace-separated numbers from FILE, or from stdin when FILE is - or absent.
+
+The numbers are sent in batches of up to 10,000, each stored whole or not at all, so
+a number the server refuses fails its whole batch. Failed batches are reported by the
+first and last value of the input they hold, counted from 1.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 1 {
+				return usageError{fmt.Errorf("add-batch takes at most one file, got %d", len(args))}
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if concurrency < 1 {
+				return usageError{fmt.Errorf("--concurrency must be at least 1, got %d", concurrency)}
+			}
+
+			in := cmd.InOrStdin()
+			if len(args) == 1 && args[0] != "-" {
+				f, err := os.Open(args[0])
+				if err != nil {
+					return usageError{err}
+				}
+				defer f.Close()
+				in = f
+			}
+			numbers, err := readNumbers(in)
+			if err != nil {
+				return err
+			}
+
+			client, err := opts.client()
+			if err != nil {
+				return err
+			}
+			err = client.AddNumbers(cmd.Context(), numbers, concurrency)
+
+			result := batchResult{Added: len(numbers), Failed: []batchFailed{}}
+			failed := 0
+			if joined, ok := err.(interface{ Unwrap() []error }); ok {
+				for _, e := range joined.Unwrap() {
+					var batchErr *api.AddBatchError
+					if errors.As(e, &batchErr) {
+						result.Failed = append(result.Failed, batchFailed{
+							First: batchErr.Offset + 1,
+							Last:  batchErr.Offset + len(batchErr.Numbers),
+							Error: batchErr.Err.Error(),
+						})
+						failed += len(batchErr.Numbers)
+					}
+				}
+				result.Added -= failed
+			}
+
+			if err := opts.print(cmd.OutOrStdout(), result, func(w io.Writer) {
+				fmt.Fprintf(w, "added %d of %d numbers\n", result.Added, len(numbers))
+				if len(result.Failed) > 0 {
+					tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+					fmt.Fprintln(tw, "VALUES\tERROR")
+					for _, f := range result.Failed {
+						fmt.Fprintf(tw, "%d-%d\t%s\n", f.First, f.Last, f.Error)
+					}
+					tw.Flush()
+				}
+			}); err != nil {
+				return err
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d of %d numbers failed", failed, len(numbers))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().IntVar(&concurrency, "concurrency", 8, "batches in flight at once")
+
+	return cmd
+}
+
+// readNumbers reads whitespace-separated integers
+func readNumbers(r io.Reader) ([]int, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Split(bufio.ScanWords)
+
+	var numbers []int
+	for scanner.Scan() {
+		n, err := strconv.Atoi(scanner.Text())
+		if err != nil {
+			return nil, usageError{fmt.Errorf("value %d: %q is not an integer", len(numbers)+1, scanner.Text())}
+		}
+		numbers = append(numbers, n)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read numbers: %w", err)
+	}
+
+	return numbers, nil
+}
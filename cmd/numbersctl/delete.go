@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+// deleteResult is the output of delete
+type deleteResult struct {
+	ID  string `json:"id,omitempty"`
+	All bool   `json:"all,omitempty"`
+}
+
+func newDeleteCmd(opts *globalOptions) *cobra.Command {
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   "delete ID | delete --all",
+		Short: "Delete the number stored with ID, as add prints it, or with --all every number",
+		Args: func(cmd *cobra.Command, args []string) error {
+			switch {
+			case all && len(args) != 0:
+				return usageError{fmt.Errorf("delete --all takes no id, got %d arguments", len(args))}
+			case !all && len(args) != 1:
+				return usageError{fmt.Errorf("delete takes exactly one id, got %d arguments", len(args))}
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var id uuid.UUID
+			if !all {
+				var err error
+				if id, err = uuid.Parse(args[0]); err != nil {
+					return usageError{fmt.Errorf("%q is not an id", args[0])}
+				}
+			}
+
+			client, err := opts.client()
+			if err != nil {
+				return err
+			}
+			result := deleteResult{All: all}
+			if all {
+				resp, err := client.ClearNumbersWithResponse(cmd.Context())
+				if err != nil {
+					return err
+				}
+				if resp.StatusCode() != http.StatusNoContent {
+					return responseError(resp, resp.Body)
+				}
+			} else {
+				resp, err := client.DeleteNumberWithResponse(cmd.Context(), id)
+				if err != nil {
+					return err
+				}
+				if resp.StatusCode() != http.StatusNoContent {
+					return responseError(resp, resp.Body)
+				}
+				result.ID = id.String()
+			}
+
+			return opts.print(cmd.OutOrStdout(), result, func(w io.Writer) {
+				if all {
+					fmt.Fprintln(w, "deleted every number")
+					return
+				}
+				fmt.Fprintf(w, "deleted %s\n", result.ID)
+			})
+		},
+	}
+	cmd.Flags().BoolVar(&all, "all", false, "delete every number; the server only lets admin API keys do so")
+
+	return cmd
+}
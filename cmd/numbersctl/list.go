@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	"golang-test-task/api"
+
+	"github.com/spf13/cobra"
+)
+
+func newListCmd(opts *globalOptions) *cobra.Command {
+	var (
+		limit    int32
+		after    string
+		min, max int64
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "Print a page of the stored numbers in ascending order",
+		Args:  noArgs("list"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			params := &api.ListNumbersParams{}
+			flags := cmd.Flags()
+			if flags.Changed("limit") {
+				params.Limit = &limit
+			}
+			if after != "" {
+				params.After = &after
+			}
+			if flags.Changed("min") {
+				params.Min = &min
+			}
+			if flags.Changed("max") {
+				params.Max = &max
+			}
+
+			client, err := opts.client()
+			if err != nil {
+				return err
+			}
+			resp, err := client.ListNumbersWithResponse(cmd.Context(), params)
+			if err != nil {
+				return err
+			}
+			if resp.JSON200 == nil {
+				return responseError(resp, resp.Body)
+			}
+
+			page := resp.JSON200
+			return opts.print(cmd.OutOrStdout(), page, func(w io.Writer) {
+				for _, n := range page.Numbers {
+					fmt.Fprintln(w, n)
+				}
+				// The cursor goes to stderr, so the numbers can be piped on their own
+				if page.NextCursor != nil {
+					fmt.Fprintf(cmd.ErrOrStderr(), "more numbers follow: list them with --after %s\n", *page.NextCursor)
+				}
+			})
+		},
+	}
+	flags := cmd.Flags()
+	flags.Int32Var(&limit, "limit", 100, "most numbers listed, up to 1000")
+	flags.StringVar(&after, "after", "", "cursor of the previous page, to list the next one")
+	flags.Int64Var(&min, "min", 0, "smallest number listed")
+	flags.Int64Var(&max, "max", 0, "largest number listed")
+
+	return cmd
+}
+
+func newStatsCmd(opts *globalOptions) *cobra.Command {
+	var percentiles []float64
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Print the count, sum, extremes, mean, median and percentiles of the stored numbers",
+		Args:  noArgs("stats"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			params := &api.GetNumberStatsParams{}
+			if cmd.Flags().Changed("percentile") {
+				params.Percentiles = &percentiles
+			}
+
+			client, err := opts.client()
+			if err != nil {
+				return err
+			}
+			resp, err := client.GetNumberStatsWithResponse(cmd.Context(), params)
+			if err != nil {
+				return err
+			}
+			if resp.JSON200 == nil {
+				return responseError(resp, resp.Body)
+			}
+
+			stats := resp.JSON200
+			return opts.print(cmd.OutOrStdout(), stats, func(w io.Writer) {
+				tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+				fmt.Fprintf(tw, "count\t%d\n", stats.Count)
+				fmt.Fprintf(tw, "sum\t%s\n", formatFloat(stats.Sum))
+				if stats.Count > 0 {
+					fmt.Fprintf(tw, "min\t%d\n", *stats.Min)
+					fmt.Fprintf(tw, "max\t%d\n", *stats.Max)
+					fmt.Fprintf(tw, "mean\t%s\n", formatFloat(*stats.Mean))
+					fmt.Fprintf(tw, "median\t%s\n", formatFloat(*stats.Median))
+					for _, p := range stats.Percentiles {
+						fmt.Fprintf(tw, "p%s\t%s\n", formatFloat(p.Percentile), formatFloat(*p.Value))
+					}
+				}
+				tw.Flush()
+			})
+		},
+	}
+	cmd.Flags().Float64SliceVar(&percentiles, "percentile", nil, "percentile to compute, from 0 to 100, repeatable (default 90, 95 and 99)")
+
+	return cmd
+}
+
+// noArgs rejects positional arguments to the command named name
+func noArgs(name string) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 {
+			return usageError{fmt.Errorf("%s takes no arguments, got %d", name, len(args))}
+		}
+		return nil
+	}
+}
+
+// formatFloat writes f in as few digits as read back the same
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
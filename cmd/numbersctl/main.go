@@ -0,0 +1,156 @@
+// Command numbersctl talks to a running number service through the generated client,
+// so operators do not have to craft curl commands.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"golang-test-task/api"
+
+	"github.com/spf13/cobra"
+)
+
+// Exit codes, as for the server binary
+const (
+	exitFailure = 1 // a request failed
+	exitUsage   = 2 // the command line is invalid
+)
+
+// usageError is a mistake on the command line, reported with exitUsage
+type usageError struct{ error }
+
+func (e usageError) Unwrap() error { return e.error }
+
+func main() {
+	os.Exit(execute(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// execute runs the command named by args and returns the process exit code
+func execute(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	root := newRootCmd()
+	root.SetArgs(args)
+	root.SetIn(stdin)
+	root.SetOut(stdout)
+	root.SetErr(stderr)
+
+	err := root.Execute()
+	var usage usageError
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &usage):
+		fmt.Fprintf(stderr, "Error: %v\nRun '%s --help' for usage.\n", err, root.Name())
+		return exitUsage
+	default:
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitFailure
+	}
+}
+
+// globalOptions are the flags shared by every command
+type globalOptions struct {
+	server  string
+	output  string
+	timeout time.Duration
+	// secret signs every request when set, and apiKey is sent with every request when
+	// set, from NUMBERSCTL_SIGNING_SECRET and NUMBERSCTL_API_KEY only, so that they do
+	// not show up in the process list
+	secret string
+	apiKey string
+}
+
+// client returns a client for the service that retries transient failures
+func (o *globalOptions) client() (*api.ClientWithResponses, error) {
+	return o.clientWith(&http.Client{Timeout: o.timeout})
+}
+
+// clientWith returns a client as client does that sends its requests with doer
+func (o *globalOptions) clientWith(doer api.HttpRequestDoer) (*api.ClientWithResponses, error) {
+	if o.secret != "" {
+		doer = api.NewSigningDoer(doer, []byte(o.secret))
+	}
+
+	clientOpts := []api.ClientOption{api.WithHTTPClient(doer)}
+	if o.apiKey != "" {
+		clientOpts = append(clientOpts, api.WithRequestEditorFn(func(ctx context.Context, req *http.Request) error {
+			req.Header.Set("X-API-Key", o.apiKey)
+			return nil
+		}))
+	}
+
+	return api.NewRetryingClient(o.server, api.DefaultRetryPolicy(), clientOpts...)
+}
+
+// response is what every generated response has
+type response interface {
+	Status() string
+	StatusCode() int
+}
+
+// responseError describes an error response, with the message from its ErrorResponse
+// body when it has one. A 400 is the command line's mistake.
+func responseError(resp response, body []byte) error {
+	var decoded api.ErrorResponse
+	err := fmt.Errorf("unexpected response %s", resp.Status())
+	if json.Unmarshal(body, &decoded) == nil && decoded.Error != "" {
+		err = fmt.Errorf("%s: %s", resp.Status(), decoded.Error)
+	}
+	if resp.StatusCode() == http.StatusBadRequest {
+		return usageError{err}
+	}
+
+	return err
+}
+
+// print writes v as indented JSON, or calls table for the table format
+func (o *globalOptions) print(w io.Writer, v any, table func(w io.Writer)) error {
+	if o.output == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+
+	table(w)
+	return nil
+}
+
+func newRootCmd() *cobra.Command {
+	opts := &globalOptions{}
+
+	root := &cobra.Command{
+		Use:   "numbersctl",
+		Short: "Command-line client for the number service",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if opts.output != "table" && opts.output != "json" {
+				return usageError{fmt.Errorf("invalid --output %q, want table or json", opts.output)}
+			}
+			return nil
+		},
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+	root.CompletionOptions.DisableDefaultCmd = true
+	root.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error { return usageError{err} })
+
+	server := os.Getenv("NUMBERSCTL_SERVER")
+	if server == "" {
+		server = "http://localhost:8080"
+	}
+	opts.secret = os.Getenv("NUMBERSCTL_SIGNING_SECRET")
+	opts.apiKey = os.Getenv("NUMBERSCTL_API_KEY")
+	flags := root.PersistentFlags()
+	flags.StringVar(&opts.server, "server", server, "service base URL (NUMBERSCTL_SERVER)")
+	flags.StringVarP(&opts.output, "output", "o", "table", "output format: table or json")
+	flags.DurationVar(&opts.timeout, "timeout", 10*time.Second, "timeout of each request")
+
+	root.AddCommand(newAddCmd(opts), newAddBatchCmd(opts), newListCmd(opts), newStatsCmd(opts), newDeleteCmd(opts), newWatchCmd(opts))
+
+	return root
+}
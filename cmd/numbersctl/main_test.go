@@ -0,0 +1,287 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"golang-test-task/api/apitest"
+	"golang-test-task/internal/service"
+	"golang-test-task/internal/storage/feed"
+	"golang-test-task/internal/storage/memstore"
+	"golang-test-task/internal/storage/sqlc"
+	"golang-test-task/internal/transport/server"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// run executes numbersctl against fake with stdin and returns the exit code and output
+func run(t *testing.T, fake *apitest.Server, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+
+	srv := httptest.NewServer(fake.Handler())
+	t.Cleanup(srv.Close)
+
+	var stdout, stderr bytes.Buffer
+	code := execute(append([]string{"--server", srv.URL}, args...), strings.NewReader(stdin), &stdout, &stderr)
+
+	return code, stdout.String(), stderr.String()
+}
+
+func TestAdd(t *testing.T) {
+	code, out, _ := run(t, apitest.NewServer(1, 9), "", "add", "5")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "1\n5\n9\n", out)
+
+	code, out, _ = run(t, apitest.NewServer(1), "", "-o", "json", "add", "2")
+	assert.Equal(t, 0, code)
+	var added struct {
+		ID      string  `json:"id"`
+		Numbers []int64 `json:"numbers"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &added))
+	assert.Len(t, added.ID, 36)
+	assert.Equal(t, []int64{1, 2}, added.Numbers)
+}
+
+func TestAdd_Errors(t *testing.T) {
+	code, _, stderr := run(t, apitest.NewServer(), "", "add", "9223372036854775808")
+	assert.Equal(t, exitUsage, code)
+	assert.Contains(t, stderr, `"9223372036854775808" is not an integer`)
+
+	code, _, _ = run(t, apitest.NewServer(), "", "add", "five")
+	assert.Equal(t, exitUsage, code)
+
+	code, _, _ = run(t, apitest.NewServer(), "", "add")
+	assert.Equal(t, exitUsage, code)
+
+	code, _, _ = run(t, apitest.NewServer(), "", "-o", "yaml", "add", "1")
+	assert.Equal(t, exitUsage, code)
+}
+
+func TestAdd_Signs(t *testing.T) {
+	secret := strings.Repeat("s", 32)
+	srv := httptest.NewServer(server.VerifySignatures(apitest.NewServer().Handler(), [][]byte{[]byte(secret)}, time.Minute))
+	defer srv.Close()
+
+	var stdout, stderr bytes.Buffer
+	code := execute([]string{"--server", srv.URL, "add", "5"}, strings.NewReader(""), &stdout, &stderr)
+	assert.Equal(t, exitFailure, code, "unsigned requests are refused")
+
+	t.Setenv("NUMBERSCTL_SIGNING_SECRET", secret)
+	stdout.Reset()
+	code = execute([]string{"--server", srv.URL, "add", "5"}, strings.NewReader(""), &stdout, &stderr)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "5\n", stdout.String())
+}
+
+func TestAddBatch(t *testing.T) {
+	fake := apitest.NewServer()
+	code, out, _ := run(t, fake, "3 1\n2\n", "add-batch")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "added 3 of 3 numbers\n", out)
+	assert.Equal(t, []int{1, 2, 3}, fake.Numbers())
+
+	file := filepath.Join(t.TempDir(), "numbers.txt")
+	require.NoError(t, os.WriteFile(file, []byte("7\n8\n"), 0o644))
+	code, _, _ = run(t, fake, "", "add-batch", "--concurrency", "1", file)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, []int{1, 2, 3, 7, 8}, fake.Numbers())
+}
+
+func TestAddBatch_ReportsFailures(t *testing.T) {
+	fake := apitest.NewServer()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var batch []int64
+		if json.Unmarshal(body, &batch) == nil && slices.Contains(batch, 13) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			io.WriteString(w, `{"error":"13 is unlucky"}`)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		fake.Handler().ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	// The first batch of 10,000 ones is stored and the second, which ends with 13, fails
+	input := strings.Repeat("1 ", 10_001) + "13"
+	var stdout, stderr bytes.Buffer
+	code := execute([]string{"--server", srv.URL, "-o", "json", "add-batch"}, strings.NewReader(input), &stdout, &stderr)
+	assert.Equal(t, exitFailure, code)
+	assert.JSONEq(t, `{"added":10000,"failed":[{"first":10001,"last":10002,"error":"400 Bad Request: 13 is unlucky"}]}`, stdout.String())
+	assert.Contains(t, stderr.String(), "2 of 10002 numbers failed")
+	assert.Equal(t, 10_000, len(fake.Numbers()))
+
+	stdout.Reset()
+	execute([]string{"--server", srv.URL, "add-batch"}, strings.NewReader("13"), &stdout, &stderr)
+	assert.Equal(t, "added 0 of 1 numbers\nVALUES  ERROR\n1-1     400 Bad Request: 13 is unlucky\n", stdout.String())
+
+	code, _, _ = run(t, fake, "1 two", "add-batch")
+	assert.Equal(t, exitUsage, code, "nothing is sent when the input is invalid")
+	assert.Equal(t, 10_000, len(fake.Numbers()))
+}
+
+func TestList(t *testing.T) {
+	fake := apitest.NewServer(5, 1, 9, 3)
+	code, out, stderr := run(t, fake, "", "list", "--limit", "2")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "1\n3\n", out)
+	require.Contains(t, stderr, "more numbers follow: list them with --after ")
+	cursor := strings.TrimSpace(strings.TrimPrefix(stderr, "more numbers follow: list them with --after "))
+
+	code, out, stderr = run(t, fake, "", "list", "--limit", "2", "--after", cursor)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "5\n9\n", out)
+	assert.Empty(t, stderr, "the last page has no cursor")
+
+	code, out, _ = run(t, fake, "", "-o", "json", "list", "--min", "2", "--max", "5")
+	assert.Equal(t, 0, code)
+	assert.JSONEq(t, `{"numbers":[3,5]}`, out)
+
+	code, _, stderr = run(t, fake, "", "list", "--limit", "1001")
+	assert.Equal(t, exitUsage, code, "a limit the server refuses is a usage error")
+	assert.Contains(t, stderr, "400 Bad Request")
+}
+
+func TestStats(t *testing.T) {
+	code, out, _ := run(t, apitest.NewServer(10, 1, 4, 3), "", "stats", "--percentile", "50", "--percentile", "100")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "count   4\nsum     18\nmin     1\nmax     10\nmean    4.5\nmedian  3.5\np50     3.5\np100    10\n", out)
+
+	code, out, _ = run(t, apitest.NewServer(), "", "-o", "json", "stats")
+	assert.Equal(t, 0, code)
+	assert.JSONEq(t, `{"count":0,"sum":0,"percentiles":[{"percentile":90},{"percentile":95},{"percentile":99}]}`, out)
+
+	code, out, _ = run(t, apitest.NewServer(), "", "stats")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "count  0\nsum    0\n", out)
+
+	code, _, _ = run(t, apitest.NewServer(), "", "stats", "--percentile", "101")
+	assert.Equal(t, exitUsage, code)
+}
+
+func TestDelete(t *testing.T) {
+	fake := apitest.NewServer(1)
+	_, out, _ := run(t, fake, "", "-o", "json", "add", "2")
+	var added struct{ ID string }
+	require.NoError(t, json.Unmarshal([]byte(out), &added))
+
+	code, out, _ := run(t, fake, "", "delete", added.ID)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "deleted "+added.ID+"\n", out)
+	assert.Equal(t, []int{1}, fake.Numbers())
+
+	code, _, stderr := run(t, fake, "", "delete", added.ID)
+	assert.Equal(t, exitFailure, code)
+	assert.Contains(t, stderr, "404 Not Found: not found")
+
+	code, out, _ = run(t, fake, "", "-o", "json", "delete", "--all")
+	assert.Equal(t, 0, code)
+	assert.JSONEq(t, `{"all":true}`, out)
+	assert.Empty(t, fake.Numbers())
+
+	for _, args := range [][]string{{"delete"}, {"delete", "5"}, {"delete", "--all", added.ID}} {
+		code, _, _ = run(t, fake, "", args...)
+		assert.Equal(t, exitUsage, code, "%v", args)
+	}
+}
+
+func TestAPIKey(t *testing.T) {
+	fake := apitest.NewServer()
+	var key string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key = r.Header.Get("X-API-Key")
+		fake.Handler().ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	t.Setenv("NUMBERSCTL_API_KEY", "secret-key")
+	var stdout, stderr bytes.Buffer
+	code := execute([]string{"--server", srv.URL, "stats"}, strings.NewReader(""), &stdout, &stderr)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "secret-key", key)
+}
+
+// watch runs numbersctl watch --snapshot with args against a server that follows
+// queries through f, and returns its output by line and its exit code once f closes
+func watch(t *testing.T, f *feed.Feed, queries sqlc.Querier, args ...string) (*bufio.Scanner, <-chan int, *bytes.Buffer) {
+	t.Helper()
+
+	srv := httptest.NewServer(server.NewHandler(server.NewServer(service.New(queries), server.WithFeed(f))))
+	t.Cleanup(srv.Close)
+
+	stdout, w := io.Pipe()
+	t.Cleanup(func() { stdout.Close() })
+	stderr := &bytes.Buffer{}
+	done := make(chan int, 1)
+	go func() {
+		defer w.Close()
+		done <- execute(append([]string{"--server", srv.URL}, append(args, "watch", "--snapshot")...), strings.NewReader(""), w, stderr)
+	}()
+
+	return bufio.NewScanner(stdout), done, stderr
+}
+
+func TestWatch(t *testing.T) {
+	ctx := context.Background()
+	for _, output := range []string{"table", "json"} {
+		t.Run(output, func(t *testing.T) {
+			f := feed.New()
+			queries := f.Wrap(memstore.New())
+			_, err := queries.InsertNumber(ctx, 5)
+			require.NoError(t, err)
+			lines, done, stderr := watch(t, f, queries, "-o", output)
+			next := func() string {
+				t.Helper()
+				require.True(t, lines.Scan())
+				return lines.Text()
+			}
+
+			first := next()
+			row, err := queries.InsertNumber(ctx, 3)
+			require.NoError(t, err)
+			number, snapshot := next(), next()
+			_, err = queries.DeleteNumber(ctx, row.ID)
+			require.NoError(t, err)
+			deleted, after := next(), next()
+			created := row.CreatedAt.Time.Format(time.RFC3339Nano)
+			if output == "table" {
+				assert.Equal(t, "snapshot 5", first)
+				assert.Equal(t, "number 3 "+row.ID.String()+" "+created, number)
+				assert.Equal(t, "snapshot 3 5", snapshot)
+				assert.Equal(t, "delete 3 "+row.ID.String()+" "+created, deleted)
+				assert.Equal(t, "snapshot 5", after)
+			} else {
+				assert.JSONEq(t, `{"event":"snapshot","numbers":[5]}`, first)
+				assert.JSONEq(t, `{"event":"number","id":"`+row.ID.String()+`","number":3,"created_at":"`+created+`"}`, number)
+				assert.JSONEq(t, `{"event":"snapshot","numbers":[3,5]}`, snapshot)
+				assert.JSONEq(t, `{"event":"delete","id":"`+row.ID.String()+`","number":3,"created_at":"`+created+`"}`, deleted)
+				assert.JSONEq(t, `{"event":"snapshot","numbers":[5]}`, after)
+			}
+
+			f.Close()
+			assert.False(t, lines.Scan())
+			assert.Equal(t, exitFailure, <-done, "the end of the stream is a failure")
+			assert.Contains(t, stderr.String(), "the server ended the stream")
+		})
+	}
+}
+
+func TestWatch_Unavailable(t *testing.T) {
+	code, _, stderr := run(t, apitest.NewServer(), "", "watch")
+	assert.Equal(t, exitFailure, code)
+	assert.Contains(t, stderr, "503 Service Unavailable: the server does not follow the stored numbers")
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang-test-task/api"
+
+	"github.com/spf13/cobra"
+)
+
+// watchEvent is an event of the stream as watch prints it
+type watchEvent struct {
+	Event     string     `json:"event"`
+	ID        string     `json:"id,omitempty"`
+	Number    *int64     `json:"number,omitempty"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	Numbers   []int64    `json:"numbers,omitempty"`
+}
+
+func newWatchCmd(opts *globalOptions) *cobra.Command {
+	var snapshot bool
+
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Print every number stored from now on, as GET /numbers/stream sends it, until interrupted",
+		Long: `Print every number stored from now on, as GET /numbers/stream sends it, until interrupted.
+
+Each number is printed as "number N ID CREATED_AT", and each row deleted as
+"delete N ID CREATED_AT". "reset" says the numbers changed in a way not sent number
+by number, such as clearing them all, and with --snapshot every change is followed
+by "snapshot" and every stored number. With -o json each event is one JSON object
+per line. When the server ends the stream, as on shutdown, watch
+fails, so that a script can start it again.`,
+		Args: noArgs("watch"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			params := &api.StreamNumbersParams{}
+			if snapshot {
+				params.Snapshot = &snapshot
+			}
+
+			// The stream lasts until interrupted, so --timeout only bounds the wait for
+			// its headers
+			transport := http.DefaultTransport.(*http.Transport).Clone()
+			transport.ResponseHeaderTimeout = opts.timeout
+			client, err := opts.clientWith(&http.Client{Transport: transport})
+			if err != nil {
+				return err
+			}
+			resp, err := client.StreamNumbers(cmd.Context(), params)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				parsed, err := api.ParseStreamNumbersResponse(resp)
+				if err != nil {
+					return err
+				}
+				return responseError(parsed, parsed.Body)
+			}
+
+			out := cmd.OutOrStdout()
+			err = readEvents(resp.Body, func(name, id, data string) error {
+				event, err := decodeEvent(name, id, data)
+				if err != nil || event == nil {
+					return err
+				}
+				return opts.printEvent(out, *event)
+			})
+			if err != nil {
+				return err
+			}
+
+			return errors.New("the server ended the stream")
+		},
+	}
+	cmd.Flags().BoolVar(&snapshot, "snapshot", false, "also print every stored number when the stream starts and after every change; each reads the whole list")
+
+	return cmd
+}
+
+// readEvents calls dispatch with the name, id and data of every server-sent event of
+// r until it ends, skipping comments and fields that are not used
+func readEvents(r io.Reader, dispatch func(name, id, data string) error) error {
+	lines := bufio.NewReader(r)
+	var name, id string
+	var data []string
+	for {
+		line, err := lines.ReadString('\n')
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "":
+			if line != "" || len(data) == 0 && name == "" {
+				// A comment, or a blank line with no event before it
+				continue
+			}
+			if err := dispatch(name, id, strings.Join(data, "\n")); err != nil {
+				return err
+			}
+			name, id, data = "", "", nil
+		case "event":
+			name = value
+		case "id":
+			id = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+}
+
+// decodeEvent decodes an event of the stream, or returns nil for one watch does not
+// know, which a newer server may send
+func decodeEvent(name, id, data string) (*watchEvent, error) {
+	event := &watchEvent{Event: name, ID: id}
+	switch name {
+	case "number", "delete":
+		var number api.NumberEvent
+		if err := json.Unmarshal([]byte(data), &number); err != nil {
+			return nil, fmt.Errorf("decode %s event: %w", name, err)
+		}
+		event.Number, event.CreatedAt = &number.Number, &number.CreatedAt
+	case "snapshot":
+		if err := json.Unmarshal([]byte(data), &event.Numbers); err != nil {
+			return nil, fmt.Errorf("decode snapshot event: %w", err)
+		}
+	case "reset":
+	default:
+		return nil, nil
+	}
+
+	return event, nil
+}
+
+// printEvent writes event as a line, of JSON or of its fields, as soon as it comes
+func (o *globalOptions) printEvent(w io.Writer, event watchEvent) error {
+	if o.output == "json" {
+		return json.NewEncoder(w).Encode(event)
+	}
+
+	fields := []string{event.Event}
+	switch event.Event {
+	case "number", "delete":
+		fields = append(fields, strconv.FormatInt(*event.Number, 10), event.ID, event.CreatedAt.Format(time.RFC3339Nano))
+	case "snapshot":
+		for _, n := range event.Numbers {
+			fields = append(fields, strconv.FormatInt(n, 10))
+		}
+	}
+	_, err := fmt.Fprintln(w, strings.Join(fields, " "))
+
+	return err
+}
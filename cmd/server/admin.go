@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang-test-task/internal/storage"
+	"golang-test-task/internal/storage/sqlc"
+
+	"github.com/spf13/cobra"
+)
+
+// adminBatch bounds how many rows a single delete statement removes, so purging a
+// large table never holds locks for long
+const adminBatch = 10_000
+
+// adminOptions are the flags of the admin command
+type adminOptions struct {
+	olderThan time.Duration
+	min, max  *int64
+	dryRun    bool
+}
+
+func (o *adminOptions) register(fs *flag.FlagSet) {
+	fs.DurationVar(&o.olderThan, "older-than", 0, "purge: delete numbers created longer ago than this, e.g. 720h")
+	fs.Func("min", "delete-range: smallest number to delete", int64Flag(&o.min))
+	fs.Func("max", "delete-range: largest number to delete", int64Flag(&o.max))
+	fs.BoolVar(&o.dryRun, "dry-run", false, "purge, delete-range: count the numbers that would be deleted without deleting them")
+}
+
+// int64Flag parses a flag value into a newly allocated int64, so unset stays nil
+func int64Flag(p **int64) func(string) error {
+	return func(s string) error {
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a 64-bit integer", s)
+		}
+		*p = &n
+		return nil
+	}
+}
+
+func newAdminCmd() *cobra.Command {
+	opts := &adminOptions{}
+
+	return withConfigFlags(&cobra.Command{
+		Use:   "admin purge|delete-range|vacuum|analyze [flags]",
+		Short: "Delete old or unwanted numbers and maintain the table, e.g. from cron",
+		Long: "Retention and maintenance operations, run against the database directly so that no\n" +
+			"destructive HTTP endpoint has to exist.\n\n" +
+			"  purge         delete numbers created longer ago than -older-than\n" +
+			"  delete-range  delete numbers between -min and -max, inclusive\n" +
+			"  vacuum        reclaim space from deleted rows and refresh planner statistics\n" +
+			"  analyze       refresh planner statistics only\n\n" +
+			"Deletes run in batches of 10000 rows; -dry-run only counts.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			action := ""
+			if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+				action, args = args[0], args[1:]
+			}
+
+			cfg, ok, err := loadConfig(cmd, args, opts.register)
+			if !ok {
+				return err
+			}
+			if _, err := setupLogging(cfg); err != nil {
+				return err
+			}
+
+			var run func(ctx context.Context, db sqlc.DBTX, out io.Writer) error
+			switch action {
+			case "purge":
+				if opts.olderThan <= 0 {
+					return usageError{fmt.Errorf("purge needs a positive -older-than, got %s", opts.olderThan)}
+				}
+				run = opts.purge
+			case "delete-range":
+				if opts.min == nil || opts.max == nil {
+					return usageError{errors.New("delete-range needs both -min and -max")}
+				}
+				if *opts.min > *opts.max {
+					return usageError{fmt.Errorf("-min %d is greater than -max %d", *opts.min, *opts.max)}
+				}
+				run = opts.deleteRange
+			case "vacuum":
+				run = maintenance("vacuum (analyze) numbers")
+			case "analyze":
+				run = maintenance("analyze numbers")
+			default:
+				return usageError{fmt.Errorf("unknown admin action %q, want purge, delete-range, vacuum or analyze", action)}
+			}
+			if cfg.Storage != "postgres" {
+				return usageError{fmt.Errorf("admin needs postgres storage, got %q", cfg.Storage)}
+			}
+
+			ctx, stop := signalContext()
+			defer stop()
+
+			pool, err := storage.ConnectPostgres(ctx, cfg)
+			if err != nil {
+				return fmt.Errorf("failed to connect to database: %w", err)
+			}
+			defer pool.Close()
+
+			return run(ctx, pool, cmd.OutOrStdout())
+		},
+	}, opts.register)
+}
+
+func (o *adminOptions) purge(ctx context.Context, db sqlc.DBTX, out io.Writer) error {
+	before := time.Now().Add(-o.olderThan)
+
+	n, err := o.delete(ctx, db, "created_at < $1", before)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "%s %d numbers created before %s\n", o.verb(), n, before.UTC().Format(time.RFC3339))
+	return nil
+}
+
+func (o *adminOptions) deleteRange(ctx context.Context, db sqlc.DBTX, out io.Writer) error {
+	n, err := o.delete(ctx, db, "number between $1 and $2", *o.min, *o.max)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "%s %d numbers between %d and %d\n", o.verb(), n, *o.min, *o.max)
+	return nil
+}
+
+// verb describes what happened to the matching rows
+func (o *adminOptions) verb() string {
+	if o.dryRun {
+		return "would delete"
+	}
+
+	return "deleted"
+}
+
+// delete removes the rows matching where in batches of adminBatch and returns how
+// many there were; with -dry-run it only counts them
+func (o *adminOptions) delete(ctx context.Context, db sqlc.DBTX, where string, args ...any) (int64, error) {
+	if o.dryRun {
+		var n int64
+		if err := db.QueryRow(ctx, "select count(*) from numbers where "+where, args...).Scan(&n); err != nil {
+			return 0, fmt.Errorf("failed to count numbers: %w", err)
+		}
+		return n, nil
+	}
+
+	query := fmt.Sprintf("delete from numbers where id in (select id from numbers where %s limit %d)", where, adminBatch)
+	var total int64
+	for {
+		tag, err := db.Exec(ctx, query, args...)
+		if err != nil {
+			return total, fmt.Errorf("failed to delete numbers after %d: %w", total, err)
+		}
+		total += tag.RowsAffected()
+		if tag.RowsAffected() < adminBatch {
+			return total, nil
+		}
+		slog.Info("Deleted numbers", "deleted", total)
+	}
+}
+
+// maintenance runs a statement that takes no arguments and reports how long it took
+func maintenance(statement string) func(ctx context.Context, db sqlc.DBTX, out io.Writer) error {
+	return func(ctx context.Context, db sqlc.DBTX, out io.Writer) error {
+		start := time.Now()
+		if _, err := db.Exec(ctx, statement); err != nil {
+			return fmt.Errorf("failed to %s: %w", statement, err)
+		}
+		fmt.Fprintf(out, "%s took %s\n", statement, time.Since(start).Round(time.Millisecond))
+		return nil
+	}
+}
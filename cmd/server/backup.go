@@ -0,0 +1,142 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"golang-test-task/backup"
+	"golang-test-task/internal/storage"
+
+	"github.com/spf13/cobra"
+)
+
+// locationArg splits off the leading non-flag argument, the backup location. It
+// is empty when there is none, so that -h still prints the help.
+func locationArg(args []string) (location string, rest []string) {
+	if len(args) > 0 && (args[0] == "-" || !strings.HasPrefix(args[0], "-")) {
+		return args[0], args[1:]
+	}
+
+	return "", args
+}
+
+// errNoLocation is returned when a backup or restore command is given no location
+var errNoLocation = usageError{errors.New("a location is required: a file path, - or s3://bucket/key")}
+
+func newBackupCmd() *cobra.Command {
+	return withConfigFlags(&cobra.Command{
+		Use:   "backup LOCATION [flags]",
+		Short: "Write a compressed snapshot of the numbers table to a file or S3",
+		Long: "Write a consistent snapshot of the numbers table as gzip-compressed CSV to LOCATION:\n" +
+			"a file path, - for stdout, or s3://bucket/key. A file or S3 object only appears once\n" +
+			"the backup is complete.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			location, args := locationArg(args)
+			cfg, ok, err := loadConfig(cmd, args, nil)
+			if !ok {
+				return err
+			}
+			if location == "" {
+				return errNoLocation
+			}
+			if _, err := setupLogging(cfg); err != nil {
+				return err
+			}
+			if cfg.Storage != "postgres" {
+				return usageError{fmt.Errorf("backup needs postgres storage, got %q", cfg.Storage)}
+			}
+
+			ctx, stop := signalContext()
+			defer stop()
+
+			pool, err := storage.ConnectPostgres(ctx, cfg)
+			if err != nil {
+				return fmt.Errorf("failed to connect to database: %w", err)
+			}
+			defer pool.Close()
+
+			w, err := backup.Create(ctx, location)
+			if err != nil {
+				return fmt.Errorf("failed to create backup: %w", err)
+			}
+
+			start := time.Now()
+			rows, err := backup.Write(ctx, pool.Current(), w)
+			if err != nil {
+				w.Abort(err)
+				return err
+			}
+			if err := w.Close(); err != nil {
+				return fmt.Errorf("failed to finish backup: %w", err)
+			}
+			slog.Info("Backup written", "location", location, "rows", rows, "elapsed", time.Since(start).Round(time.Millisecond))
+
+			return nil
+		},
+	}, nil)
+}
+
+// restoreOptions are the flags of the restore command
+type restoreOptions struct {
+	replace bool
+}
+
+func (o *restoreOptions) register(fs *flag.FlagSet) {
+	fs.BoolVar(&o.replace, "replace", false, "delete the current numbers first; without it the table must be empty")
+}
+
+func newRestoreCmd() *cobra.Command {
+	opts := &restoreOptions{}
+
+	return withConfigFlags(&cobra.Command{
+		Use:   "restore LOCATION [flags]",
+		Short: "Load a backup written by the backup command",
+		Long: "Load a backup from LOCATION (a file path, - for stdin, or s3://bucket/key) in a single\n" +
+			"transaction. The numbers table must be empty unless -replace is given; a failed\n" +
+			"restore leaves the table as it was.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			location, args := locationArg(args)
+			cfg, ok, err := loadConfig(cmd, args, opts.register)
+			if !ok {
+				return err
+			}
+			if location == "" {
+				return errNoLocation
+			}
+			if _, err := setupLogging(cfg); err != nil {
+				return err
+			}
+			if cfg.Storage != "postgres" {
+				return usageError{fmt.Errorf("restore needs postgres storage, got %q", cfg.Storage)}
+			}
+
+			ctx, stop := signalContext()
+			defer stop()
+
+			pool, err := storage.ConnectPostgres(ctx, cfg)
+			if err != nil {
+				return fmt.Errorf("failed to connect to database: %w", err)
+			}
+			defer pool.Close()
+
+			r, err := backup.Open(ctx, location)
+			if err != nil {
+				return fmt.Errorf("failed to open backup: %w", err)
+			}
+			defer r.Close()
+
+			start := time.Now()
+			rows, err := backup.Restore(ctx, pool.Current(), r, opts.replace)
+			if err != nil {
+				return err
+			}
+			slog.Info("Backup restored", "location", location, "rows", rows, "elapsed", time.Since(start).Round(time.Millisecond))
+
+			return nil
+		},
+	}, opts.register)
+}
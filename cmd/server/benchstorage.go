@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"golang-test-task/datagen"
+	"golang-test-task/internal/config"
+	"golang-test-task/internal/storage"
+	"golang-test-task/internal/storage/memstore"
+	"golang-test-task/internal/storage/sqlc"
+	"golang-test-task/storagebench"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/spf13/cobra"
+)
+
+// benchBackends are the storage backends bench-storage can compare
+var benchBackends = []string{"memory", "postgres"}
+
+// benchStorageOptions are the flags of the bench-storage command
+type benchStorageOptions struct {
+	backends     string
+	writes       int
+	reads        int
+	concurrency  int
+	distribution string
+}
+
+func (o *benchStorageOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.backends, "backends", strings.Join(benchBackends, ","), fmt.Sprintf("comma-separated backends to compare: %v", benchBackends))
+	fs.IntVar(&o.writes, "writes", 10_000, "how many numbers to insert into each backend")
+	fs.IntVar(&o.reads, "reads", 100, "how many times to read the sorted list back")
+	fs.IntVar(&o.concurrency, "concurrency", 8, "operations in flight at once")
+	fs.StringVar(&o.distribution, "distribution", string(datagen.Uniform), fmt.Sprintf("value distribution: %v", datagen.Distributions))
+}
+
+// parseBackends splits the -backends flag, rejecting unknown and repeated names
+func parseBackends(s string) ([]string, error) {
+	var backends []string
+	for name := range strings.SplitSeq(s, ",") {
+		name = strings.TrimSpace(name)
+		if !slices.Contains(benchBackends, name) {
+			return nil, fmt.Errorf("unknown backend %q, want one of %v", name, benchBackends)
+		}
+		if slices.Contains(backends, name) {
+			return nil, fmt.Errorf("backend %q is listed twice", name)
+		}
+		backends = append(backends, name)
+	}
+
+	return backends, nil
+}
+
+func newBenchStorageCmd() *cobra.Command {
+	opts := &benchStorageOptions{}
+
+	return withConfigFlags(&cobra.Command{
+		Use:   "bench-storage [flags]",
+		Short: "Compare the throughput and latency of the storage backends",
+		Long: "Run the same workload against each storage backend through the queries the server\n" +
+			"uses: -writes concurrent inserts, then -reads reads of the sorted list. Prints one\n" +
+			"row per backend and operation. Numbers inserted into postgres are deleted afterwards;\n" +
+			"run it against a scratch database, as the reads include any existing rows.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Without postgres the database settings are not needed
+			cfg, ok, err := loadUnvalidatedConfig(cmd, args, opts.register)
+			if !ok {
+				return err
+			}
+
+			backends, err := parseBackends(opts.backends)
+			if err != nil {
+				return usageError{fmt.Errorf("invalid -backends: %w", err)}
+			}
+			if slices.Contains(backends, "postgres") {
+				if err := validateConfig(cfg); err != nil {
+					return err
+				}
+			}
+			if _, err := setupLogging(cfg); err != nil {
+				return err
+			}
+
+			distribution, err := datagen.ParseDistribution(opts.distribution)
+			if err != nil {
+				return usageError{fmt.Errorf("invalid -distribution: %w", err)}
+			}
+			if opts.writes < 0 || opts.reads < 0 {
+				return usageError{fmt.Errorf("-writes and -reads must not be negative, got %d and %d", opts.writes, opts.reads)}
+			}
+			if opts.concurrency < 1 {
+				return usageError{fmt.Errorf("-concurrency must be at least 1, got %d", opts.concurrency)}
+			}
+
+			bench := storagebench.Config{
+				Writes:      opts.writes,
+				Reads:       opts.reads,
+				Concurrency: opts.concurrency,
+				Values:      datagen.DefaultConfig(),
+			}
+			bench.Values.Distribution = distribution
+
+			ctx, stop := signalContext()
+			defer stop()
+
+			var results []storagebench.Result
+			for _, backend := range backends {
+				slog.Info("Benchmarking storage", "backend", backend, "writes", bench.Writes, "reads", bench.Reads)
+				result, err := benchBackend(ctx, cfg, backend, bench)
+				if err != nil {
+					return err
+				}
+				results = append(results, result)
+			}
+
+			return storagebench.WriteTable(cmd.OutOrStdout(), results)
+		},
+	}, opts.register)
+}
+
+// benchBackend runs the workload against one backend, removing what it inserted
+// from postgres afterwards
+func benchBackend(ctx context.Context, cfg config.Config, backend string, bench storagebench.Config) (storagebench.Result, error) {
+	if backend == "memory" {
+		result, _, err := storagebench.Run(ctx, backend, memstore.New(), bench)
+		return result, err
+	}
+
+	pool, err := storage.ConnectPostgres(ctx, cfg)
+	if err != nil {
+		return storagebench.Result{}, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer pool.Close()
+
+	result, inserted, err := storagebench.Run(ctx, backend, sqlc.New(pool), bench)
+
+	ids := make([]pgtype.UUID, len(inserted))
+	for i, n := range inserted {
+		ids[i] = n.ID
+	}
+	// The benchmark may have been interrupted; clean up regardless
+	if _, cleanupErr := pool.Exec(context.WithoutCancel(ctx), "delete from numbers where id = any($1)", ids); cleanupErr != nil {
+		slog.Error("Failed to delete the benchmark numbers", "error", cleanupErr, "count", len(ids))
+	}
+
+	return result, err
+}
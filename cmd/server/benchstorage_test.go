@@ -0,0 +1,33 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBackends(t *testing.T) {
+	backends, err := parseBackends("postgres, memory")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"postgres", "memory"}, backends)
+
+	for _, s := range []string{"sqlite", "memory,memory", ""} {
+		_, err := parseBackends(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestBenchStorageCmd_MemoryNeedsNoDatabase(t *testing.T) {
+	unsetenv(t, "POSTGRES_DSN")
+
+	var out bytes.Buffer
+	root := newRootCmd()
+	root.SetArgs([]string{"bench-storage", "-backends", "memory", "-writes", "50", "-reads", "5"})
+	root.SetOut(&out)
+
+	require.NoError(t, root.Execute())
+	assert.Contains(t, out.String(), "memory")
+	assert.Contains(t, out.String(), "ops/s")
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"crypto/tls"
+	"sync/atomic"
+)
+
+// certificate is the keypair HTTPS and gRPC are served with. It is swapped
+// atomically, so the reloader can replace it while handshakes read it.
+type certificate struct {
+	keyPair atomic.Pointer[tls.Certificate]
+}
+
+// load reads the PEM files and serves their keypair from then on. On error the
+// keypair served so far is kept.
+func (c *certificate) load(certFile, keyFile string) error {
+	keyPair, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return err
+	}
+	c.keyPair.Store(&keyPair)
+	return nil
+}
+
+// get is the tls.Config.GetCertificate of the servers
+func (c *certificate) get(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return c.keyPair.Load(), nil
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+
+	"golang-test-task/cgroup"
+	"golang-test-task/internal/config"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+)
+
+// Exit codes, so supervisors can tell a bad deployment from a failure at runtime
+const (
+	exitFailure = 1 // the command failed or the server stopped with an error
+	exitConfig  = 2 // the command line or configuration is invalid
+)
+
+// usageError is a mistake in the command line or configuration, reported with exitConfig
+type usageError struct{ error }
+
+func (e usageError) Unwrap() error { return e.error }
+
+// execute runs the command named by args and returns the process exit code
+func execute(args []string) int {
+	root := newRootCmd()
+	root.SetArgs(args)
+
+	err := root.Execute()
+	var usage usageError
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &usage):
+		fmt.Fprintln(os.Stderr, err)
+		return exitConfig
+	default:
+		slog.Error("command failed", "error", err)
+		return exitFailure
+	}
+}
+
+// newRootCmd builds the command tree. Without a subcommand the binary serves, so
+// `server [flags]` keeps working as `server serve [flags]`.
+func newRootCmd() *cobra.Command {
+	serve := newServeCmd()
+
+	root := withConfigFlags(&cobra.Command{
+		Use:   "server [command] [flags]",
+		Short: "Number service: stores numbers and returns them sorted",
+		Long: "Number service: stores numbers and returns them sorted.\n\n" +
+			"Without a command it serves HTTP, like `server serve`. Every command that talks to\n" +
+			"the database accepts the configuration flags below, environment variables and -config.",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+				return usageError{fmt.Errorf("unknown command %q for %q", args[0], cmd.CommandPath())}
+			}
+			return nil
+		},
+		RunE:          serve.RunE,
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}, nil)
+	root.CompletionOptions.DisableDefaultCmd = true
+
+	root.AddCommand(serve, newMigrateCmd(), newSeedCmd(), newAdminCmd(), newBackupCmd(), newRestoreCmd(), newBenchStorageCmd(), newVersionCmd(), newHealthcheckCmd(), newWaitCmd(), newLoadgenCmd())
+
+	return root
+}
+
+// withConfigFlags makes cmd pass its arguments to config.LoadWithFlags instead of
+// parsing them with cobra, and lists the configuration flags in its usage
+func withConfigFlags(cmd *cobra.Command, register func(fs *flag.FlagSet)) *cobra.Command {
+	cmd.DisableFlagParsing = true
+	cmd.SetUsageFunc(func(c *cobra.Command) error {
+		w := c.OutOrStderr()
+		fmt.Fprintf(w, "Usage:\n  %s\n", c.UseLine())
+		if c.HasAvailableSubCommands() {
+			fmt.Fprintln(w, "\nCommands:")
+			for _, sub := range c.Commands() {
+				if sub.IsAvailableCommand() {
+					fmt.Fprintf(w, "  %-12s %s\n", sub.Name(), sub.Short)
+				}
+			}
+		}
+		fmt.Fprintln(w, "\nFlags:")
+		config.Usage(w, register)
+		return nil
+	})
+
+	return cmd
+}
+
+// loadConfig loads and validates the configuration for a command created with
+// withConfigFlags. ok is false after -h, once the help has been printed.
+func loadConfig(cmd *cobra.Command, args []string, register func(fs *flag.FlagSet)) (cfg config.Config, ok bool, err error) {
+	cfg, ok, err = loadUnvalidatedConfig(cmd, args, register)
+	if !ok {
+		return cfg, false, err
+	}
+	if err := validateConfig(cfg); err != nil {
+		return cfg, false, err
+	}
+
+	return cfg, true, nil
+}
+
+// loadUnvalidatedConfig is loadConfig for commands that validate only the settings
+// they use
+func loadUnvalidatedConfig(cmd *cobra.Command, args []string, register func(fs *flag.FlagSet)) (cfg config.Config, ok bool, err error) {
+	cfg, err = config.LoadWithFlags(args, register)
+	if errors.Is(err, flag.ErrHelp) {
+		return cfg, false, cmd.Help()
+	}
+	if err != nil {
+		return cfg, false, usageError{fmt.Errorf("failed to load configuration: %w", err)}
+	}
+
+	return cfg, true, nil
+}
+
+// validateConfig reports every configuration problem as a usageError
+func validateConfig(cfg config.Config) error {
+	if err := cfg.Validate(); err != nil {
+		return usageError{fmt.Errorf("invalid configuration:\n  %s", strings.ReplaceAll(err.Error(), "\n", "\n  "))}
+	}
+
+	return nil
+}
+
+// setupLogging installs the default logger on the configured output and returns its
+// level for SIGHUP reloads
+func setupLogging(cfg config.Config) (*slog.LevelVar, error) {
+	out, err := openLogOutput(cfg.Log)
+	if err != nil {
+		return nil, err
+	}
+
+	level := new(slog.LevelVar)
+	level.Set(cfg.Log.SlogLevel())
+	slog.SetDefault(slog.New(newLogHandler(out, cfg.Log.Format, level)))
+
+	return level, nil
+}
+
+// signalContext is cancelled by the platform's shutdown signals
+func signalContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), shutdownSignals...)
+}
+
+func newServeCmd() *cobra.Command {
+	return withConfigFlags(&cobra.Command{
+		Use:   "serve [flags]",
+		Short: "Serve the HTTP API (the default)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, ok, err := loadConfig(cmd, args, nil)
+			if !ok {
+				return err
+			}
+
+			level, err := setupLogging(cfg)
+			if err != nil {
+				return err
+			}
+
+			limits := cgroup.New()
+			setMemoryLimit(limits, cfg.Runtime.MemoryLimitRatio)
+			setMaxProcs(limits)
+
+			ctx, stop := signalContext()
+			defer stop()
+
+			cert := new(certificate)
+			if len(reloadSignals) > 0 {
+				hup := make(chan os.Signal, 1)
+				signal.Notify(hup, reloadSignals...)
+				defer signal.Stop(hup)
+				go newReloader(args, cfg, level, cert).run(ctx, hup)
+			}
+
+			return withService(ctx, func(ctx context.Context) error { return run(ctx, cfg, fx.Replace(cert)) })
+		},
+	}, nil)
+}
+
+func newHealthcheckCmd() *cobra.Command {
+	return withConfigFlags(&cobra.Command{
+		Use:   "healthcheck [flags]",
+		Short: "Probe GET /healthz of a running server on loopback, for Docker HEALTHCHECK",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Only the listen address matters, so the rest is not validated
+			cfg, err := config.Load(args)
+			if errors.Is(err, flag.ErrHelp) {
+				return cmd.Help()
+			}
+			if err != nil {
+				return usageError{fmt.Errorf("failed to load configuration: %w", err)}
+			}
+
+			if err := healthcheck(cfg.Server); err != nil {
+				return fmt.Errorf("healthcheck failed: %w", err)
+			}
+			return nil
+		},
+	}, nil)
+}
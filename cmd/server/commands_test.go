@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecute_UsageErrorsExitWithConfigCode(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "unknown flag", args: []string{"-no-such-flag"}},
+		{name: "invalid configuration", args: []string{"-storage", "redis"}},
+		{name: "unknown command", args: []string{"bogus"}},
+		{name: "unknown migrate action", args: []string{"migrate", "sideways"}},
+		{name: "seed without postgres", args: []string{"seed", "-profile", "dev", "-storage", "memory"}},
+		{name: "seed with unknown distribution", args: []string{"seed", "-distribution", "normal", "-postgres-dsn", "postgres://localhost/db"}},
+		{name: "admin without action", args: []string{"admin", "-postgres-dsn", "postgres://localhost/db"}},
+		{name: "purge without age", args: []string{"admin", "purge", "-postgres-dsn", "postgres://localhost/db"}},
+		{name: "delete-range without max", args: []string{"admin", "delete-range", "-min", "1", "-postgres-dsn", "postgres://localhost/db"}},
+		{name: "delete-range with min above max", args: []string{"admin", "delete-range", "-min", "9", "-max", "1", "-postgres-dsn", "postgres://localhost/db"}},
+		{name: "backup without location", args: []string{"backup", "-postgres-dsn", "postgres://localhost/db"}},
+		{name: "restore without postgres", args: []string{"restore", "numbers.csv.gz", "-profile", "dev", "-storage", "memory"}},
+		{name: "bench-storage with unknown backend", args: []string{"bench-storage", "-backends", "memory,sqlite"}},
+		{name: "bench-storage postgres without dsn", args: []string{"bench-storage", "-backends", "postgres", "-profile", "prod", "-postgres-dsn", ""}},
+		{name: "loadgen with ramp-up beyond duration", args: []string{"loadgen", "-duration", "10s", "-ramp-up", "1m"}},
+		{name: "loadgen with invalid url", args: []string{"loadgen", "-url", "localhost:8080"}},
+		{name: "delete-range bound out of range", args: []string{"admin", "delete-range", "-min", "1", "-max", "9223372036854775808", "-postgres-dsn", "postgres://localhost/db"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, exitConfig, execute(tt.args))
+		})
+	}
+}
+
+func TestRootCmd_Help(t *testing.T) {
+	for _, args := range [][]string{{"-h"}, {"seed", "-h"}} {
+		var out bytes.Buffer
+		root := newRootCmd()
+		root.SetArgs(args)
+		root.SetOut(&out)
+
+		require.NoError(t, root.Execute())
+		assert.Contains(t, out.String(), "-postgres-dsn", "configuration flags are listed")
+		if args[0] == "seed" {
+			assert.Contains(t, out.String(), "-distribution")
+		} else {
+			assert.Contains(t, out.String(), "migrate")
+		}
+	}
+}
+
+func TestVersionCmd(t *testing.T) {
+	var out bytes.Buffer
+	root := newRootCmd()
+	root.SetArgs([]string{"version"})
+	root.SetOut(&out)
+
+	require.NoError(t, root.Execute())
+	assert.True(t, strings.HasPrefix(out.String(), "version: "), out.String())
+	assert.Contains(t, out.String(), "go:      go")
+}
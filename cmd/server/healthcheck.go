@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"golang-test-task/internal/config"
+)
+
+const healthcheckTimeout = 3 * time.Second
+
+// healthcheck requests /healthz from the server cfg configures. It exists so images
+// without curl or wget can still declare a Docker HEALTHCHECK.
+func healthcheck(cfg config.ServerConfig) error {
+	url, err := healthcheckURL(cfg)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), healthcheckTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := (&http.Client{Transport: loopbackTransport(cfg)}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+
+	return nil
+}
+
+// healthcheckURL turns a listen address into the /healthz URL reachable from inside the container
+func healthcheckURL(cfg config.ServerConfig) (string, error) {
+	return loopbackURL(cfg, "/healthz")
+}
+
+// loopbackURL turns the listen address of cfg into the URL of path on the local
+// server, https when it serves TLS
+func loopbackURL(cfg config.ServerConfig, path string) (string, error) {
+	host, port, err := net.SplitHostPort(cfg.Addr)
+	if err != nil {
+		return "", fmt.Errorf("invalid server address %q: %w", cfg.Addr, err)
+	}
+
+	// Wildcard listeners are reached through loopback
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "127.0.0.1"
+	}
+
+	scheme := "http"
+	if cfg.TLS() {
+		scheme = "https"
+	}
+
+	return scheme + "://" + net.JoinHostPort(host, port) + path, nil
+}
+
+// loopbackTransport is the transport to reach the local server of cfg through. Over
+// TLS it does not verify the certificate, which names the server's public host
+// rather than loopback: probes only check that the server answers.
+func loopbackTransport(cfg config.ServerConfig) http.RoundTripper {
+	if !cfg.TLS() {
+		return http.DefaultTransport
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+
+	return transport
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang-test-task/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthcheckURL(t *testing.T) {
+	tests := []struct {
+		addr string
+		want string
+	}{
+		{addr: ":8080", want: "http://127.0.0.1:8080/healthz"},
+		{addr: "0.0.0.0:8080", want: "http://127.0.0.1:8080/healthz"},
+		{addr: "[::]:8080", want: "http://127.0.0.1:8080/healthz"},
+		{addr: "localhost:9000", want: "http://localhost:9000/healthz"},
+		{addr: "[::1]:9000", want: "http://[::1]:9000/healthz"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			got, err := healthcheckURL(config.ServerConfig{Addr: tt.addr})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := healthcheckURL(config.ServerConfig{Addr: "8080"})
+	assert.Error(t, err)
+}
+
+func TestHealthcheck(t *testing.T) {
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/healthz", r.URL.Path)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	cfg := config.ServerConfig{Addr: strings.TrimPrefix(srv.URL, "http://")}
+
+	assert.NoError(t, healthcheck(cfg))
+
+	status = http.StatusServiceUnavailable
+	assert.Error(t, healthcheck(cfg))
+
+	srv.Close()
+	assert.Error(t, healthcheck(cfg))
+}
+
+// TestHealthcheck_TLS tests that a server serving HTTPS is probed over TLS, whatever
+// host its certificate names
+func TestHealthcheck_TLS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	cfg := config.ServerConfig{Addr: strings.TrimPrefix(srv.URL, "https://"), TLSCertFile: "cert.pem", TLSKeyFile: "key.pem"}
+
+	url, err := healthcheckURL(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, srv.URL+"/healthz", url)
+	assert.NoError(t, healthcheck(cfg))
+
+	cfg.TLSCertFile, cfg.TLSKeyFile = "", ""
+	assert.Error(t, healthcheck(cfg), "plain HTTP is refused")
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"log/slog"
+	"math"
+	"os"
+	"runtime"
+	"runtime/debug"
+
+	"golang-test-task/cgroup"
+)
+
+// memoryLimiter reports the container memory limit; *cgroup.Limits in production
+type memoryLimiter interface {
+	MemoryLimit() (limit int64, ok bool, err error)
+}
+
+// cpuLimiter reports the container CPU quota; *cgroup.Limits in production
+type cpuLimiter interface {
+	CPUQuota() (cpus float64, ok bool, err error)
+}
+
+var (
+	_ memoryLimiter = (*cgroup.Limits)(nil)
+	_ cpuLimiter    = (*cgroup.Limits)(nil)
+)
+
+// memoryLimit returns the Go soft memory limit for a container limited by limits,
+// keeping 1-ratio of it as headroom for stacks, buffers and other non-heap memory.
+// ok is false when GOMEMLIMIT is set explicitly, ratio is 0 or the container has no limit.
+func memoryLimit(limits memoryLimiter, ratio float64) (limit int64, ok bool, err error) {
+	if _, set := os.LookupEnv("GOMEMLIMIT"); set || ratio == 0 {
+		return 0, false, nil
+	}
+
+	containerLimit, ok, err := limits.MemoryLimit()
+	if err != nil || !ok {
+		return 0, false, err
+	}
+
+	return int64(float64(containerLimit) * ratio), true, nil
+}
+
+// setMemoryLimit applies memoryLimit so the GC works harder as the heap approaches the
+// container limit, instead of the process being OOM-killed
+func setMemoryLimit(limits memoryLimiter, ratio float64) {
+	limit, ok, err := memoryLimit(limits, ratio)
+	if err != nil {
+		slog.Warn("failed to read the container memory limit", "error", err)
+		return
+	}
+	if !ok {
+		return
+	}
+
+	debug.SetMemoryLimit(limit)
+	slog.Info("set memory limit from the container limit", "limit_bytes", limit, "ratio", ratio)
+}
+
+// maxProcs returns GOMAXPROCS for a container limited by limits: the CPU quota rounded
+// down, at least 1 and at most the number of CPUs. ok is false when GOMAXPROCS is set
+// explicitly or the container has no quota.
+func maxProcs(limits cpuLimiter) (procs int, ok bool, err error) {
+	if _, set := os.LookupEnv("GOMAXPROCS"); set {
+		return 0, false, nil
+	}
+
+	cpus, ok, err := limits.CPUQuota()
+	if err != nil || !ok {
+		return 0, false, err
+	}
+
+	return min(max(int(math.Floor(cpus)), 1), runtime.NumCPU()), true, nil
+}
+
+// setMaxProcs applies maxProcs, so the scheduler does not run more threads than the
+// quota allows and get throttled by the kernel mid-request
+func setMaxProcs(limits cpuLimiter) {
+	procs, ok, err := maxProcs(limits)
+	if err != nil {
+		slog.Warn("failed to read the container CPU quota", "error", err)
+		return
+	}
+	if !ok {
+		return
+	}
+
+	previous := runtime.GOMAXPROCS(procs)
+	slog.Info("set GOMAXPROCS from the container CPU quota", "procs", procs, "previous", previous)
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeLimits struct {
+	memory int64
+	cpus   float64
+	err    error
+}
+
+func (f fakeLimits) MemoryLimit() (int64, bool, error) {
+	return f.memory, f.memory > 0, f.err
+}
+
+func (f fakeLimits) CPUQuota() (float64, bool, error) {
+	return f.cpus, f.cpus > 0, f.err
+}
+
+// unsetenv removes key for the duration of the test
+func unsetenv(t *testing.T, key string) {
+	t.Setenv(key, "")
+	os.Unsetenv(key)
+}
+
+func TestMemoryLimit(t *testing.T) {
+	unsetenv(t, "GOMEMLIMIT")
+
+	limit, ok, err := memoryLimit(fakeLimits{memory: 1000 << 20}, 0.9)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, int64(900<<20), limit)
+
+	_, ok, err = memoryLimit(fakeLimits{}, 0.9)
+	require.NoError(t, err)
+	assert.False(t, ok, "no container limit")
+
+	_, ok, err = memoryLimit(fakeLimits{memory: 1000 << 20}, 0)
+	require.NoError(t, err)
+	assert.False(t, ok, "disabled by a zero ratio")
+
+	_, _, err = memoryLimit(fakeLimits{err: errors.New("unreadable")}, 0.9)
+	assert.Error(t, err)
+
+	t.Setenv("GOMEMLIMIT", "256MiB")
+	_, ok, err = memoryLimit(fakeLimits{memory: 1000 << 20}, 0.9)
+	require.NoError(t, err)
+	assert.False(t, ok, "an explicit GOMEMLIMIT wins")
+}
+
+func TestMaxProcs(t *testing.T) {
+	unsetenv(t, "GOMAXPROCS")
+
+	tests := []struct {
+		name   string
+		limits fakeLimits
+		want   int
+		wantOK bool
+	}{
+		{name: "whole cpus", limits: fakeLimits{cpus: 1}, want: 1, wantOK: true},
+		{name: "rounded down", limits: fakeLimits{cpus: 1.5}, want: 1, wantOK: true},
+		{name: "at least one", limits: fakeLimits{cpus: 0.25}, want: 1, wantOK: true},
+		{name: "at most the machine", limits: fakeLimits{cpus: float64(runtime.NumCPU() + 8)}, want: runtime.NumCPU(), wantOK: true},
+		{name: "no quota", limits: fakeLimits{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			procs, ok, err := maxProcs(tt.limits)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, procs)
+		})
+	}
+
+	_, _, err := maxProcs(fakeLimits{err: errors.New("unreadable")})
+	assert.Error(t, err)
+
+	t.Setenv("GOMAXPROCS", "4")
+	_, ok, err := maxProcs(fakeLimits{cpus: 2})
+	require.NoError(t, err)
+	assert.False(t, ok, "an explicit GOMAXPROCS wins")
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"time"
+
+	"golang-test-task/datagen"
+	"golang-test-task/loadtest"
+
+	"github.com/spf13/cobra"
+)
+
+// loadgenOptions are the flags of the loadgen command
+type loadgenOptions struct {
+	url          string
+	rps          int
+	duration     time.Duration
+	rampUp       time.Duration
+	readRatio    float64
+	readPath     string
+	maxInFlight  int
+	timeout      time.Duration
+	distribution string
+	seed         uint64
+}
+
+func (o *loadgenOptions) register(fs *flag.FlagSet) {
+	defaults := loadtest.DefaultConfig()
+	fs.StringVar(&o.url, "url", "", "base URL of the server under load; defaults to the configured port on loopback")
+	fs.IntVar(&o.rps, "rps", defaults.RPS, "target requests per second")
+	fs.DurationVar(&o.duration, "duration", defaults.Duration, "how long to generate load, ramp-up included")
+	fs.DurationVar(&o.rampUp, "ramp-up", 0, "grow the rate linearly from zero to -rps over this long")
+	fs.Float64Var(&o.readRatio, "read-ratio", defaults.ReadRatio, "fraction of requests in [0, 1] that are reads")
+	fs.StringVar(&o.readPath, "read-path", defaults.ReadPath, "path requested with GET for reads")
+	fs.IntVar(&o.maxInFlight, "max-in-flight", defaults.MaxInFlight, "maximum concurrent requests; requests beyond it are dropped")
+	fs.DurationVar(&o.timeout, "timeout", defaults.Timeout, "timeout of a single request")
+	fs.StringVar(&o.distribution, "distribution", string(datagen.Uniform), fmt.Sprintf("distribution of written values: %v", datagen.Distributions))
+	fs.Uint64Var(&o.seed, "seed", defaults.Seed, "random seed; the same seed sends the same requests")
+}
+
+func newLoadgenCmd() *cobra.Command {
+	opts := &loadgenOptions{}
+
+	return withConfigFlags(&cobra.Command{
+		Use:   "loadgen [flags]",
+		Short: "Send sustained traffic to a server and report latency percentiles",
+		Long: "Send -rps requests per second to a running server for -duration, after a linear\n" +
+			"-ramp-up, writing numbers drawn from -distribution and reading -read-path for\n" +
+			"-read-ratio of them. Prints request counts, error rates and p50/p90/p99/max latency\n" +
+			"per operation, also when interrupted.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Only the listen address matters, so the rest is not validated
+			cfg, ok, err := loadUnvalidatedConfig(cmd, args, opts.register)
+			if !ok {
+				return err
+			}
+			if _, err := setupLogging(cfg); err != nil {
+				return err
+			}
+
+			distribution, err := datagen.ParseDistribution(opts.distribution)
+			if err != nil {
+				return usageError{fmt.Errorf("invalid -distribution: %w", err)}
+			}
+
+			target := opts.url
+			if target == "" {
+				if target, err = loopbackURL(cfg.Server, ""); err != nil {
+					return usageError{err}
+				}
+			} else if u, err := url.Parse(target); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return usageError{fmt.Errorf("-url %q must be an http or https URL", target)}
+			}
+
+			load := loadtest.DefaultConfig()
+			load.URL = target
+			load.RPS = opts.rps
+			load.Duration = opts.duration
+			load.RampUp = opts.rampUp
+			load.ReadRatio = opts.readRatio
+			load.ReadPath = opts.readPath
+			load.MaxInFlight = opts.maxInFlight
+			load.Timeout = opts.timeout
+			load.Seed = opts.seed
+			load.Values.Distribution = distribution
+			if err := validateLoad(load); err != nil {
+				return usageError{err}
+			}
+
+			// Interrupting ends the run early, and the report covers what was sent
+			ctx, stop := signalContext()
+			defer stop()
+
+			slog.Info("Generating load", "url", target, "rps", load.RPS, "duration", load.Duration, "ramp_up", load.RampUp)
+			report, err := loadtest.Run(ctx, load)
+			if err != nil {
+				return err
+			}
+			fmt.Fprint(cmd.OutOrStdout(), report)
+
+			return nil
+		},
+	}, opts.register)
+}
+
+// validateLoad checks the flags loadtest.Run would reject, so they are reported as
+// usage errors
+func validateLoad(cfg loadtest.Config) error {
+	switch {
+	case cfg.RPS <= 0:
+		return fmt.Errorf("-rps must be positive, got %d", cfg.RPS)
+	case cfg.Duration <= 0:
+		return fmt.Errorf("-duration must be positive, got %s", cfg.Duration)
+	case cfg.RampUp < 0 || cfg.RampUp > cfg.Duration:
+		return fmt.Errorf("-ramp-up must be within [0, -duration], got %s", cfg.RampUp)
+	case cfg.ReadRatio < 0 || cfg.ReadRatio > 1:
+		return fmt.Errorf("-read-ratio must be within [0, 1], got %v", cfg.ReadRatio)
+	case cfg.MaxInFlight <= 0:
+		return fmt.Errorf("-max-in-flight must be positive, got %d", cfg.MaxInFlight)
+	case cfg.Timeout <= 0:
+		return fmt.Errorf("-timeout must be positive, got %s", cfg.Timeout)
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadgenCmd(t *testing.T) {
+	var reads, writes atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			reads.Add(1)
+			return
+		}
+		writes.Add(1)
+	}))
+	defer srv.Close()
+
+	var out bytes.Buffer
+	root := newRootCmd()
+	root.SetArgs([]string{"loadgen", "-url", srv.URL, "-rps", "200", "-duration", "300ms", "-ramp-up", "100ms",
+		"-read-ratio", "0.5", "-distribution", "zipf"})
+	root.SetOut(&out)
+
+	require.NoError(t, root.Execute())
+	assert.Positive(t, reads.Load())
+	assert.Positive(t, writes.Load())
+	assert.Contains(t, out.String(), "p99")
+	assert.Contains(t, out.String(), "achieved")
+}
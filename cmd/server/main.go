@@ -2,117 +2,128 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"log/slog"
-	"net/http"
+	"net"
 	"os"
-	"os/signal"
-	"syscall"
-	"time"
 
-	api "golang-test-task/api"
-	"golang-test-task/sqlc"
+	"golang-test-task/internal/config"
+	"golang-test-task/logfile"
 
-	"github.com/jackc/pgx/v5/pgxpool"
-)
-
-const (
-	maxConns          = 60
-	minConns          = 10
-	maxConnLifetime   = 120 * time.Second
-	maxConnIdleTime   = 20 * time.Second
-	healthCheckPeriod = 30 * time.Second
+	"github.com/nats-io/nats.go"
+	"go.uber.org/dig"
+	"go.uber.org/fx"
 )
 
 func main() {
-	dsn := getEnv("POSTGRES_DSN", "")
-	addr := getEnv("SERVER_ADDR", ":8080")
+	os.Exit(execute(os.Args[1:]))
+}
 
-	if dsn == "" {
-		slog.Error("POSTGRES_DSN is not set")
-		return
+// run applies the pending migrations with cfg.MigrateOnStart, opens the storage and
+// serves HTTP on cfg.Server.Addr, gRPC on cfg.Server.GRPCAddr when set, and consumes
+// the NATS subject, RabbitMQ queue, MQTT topic and SQS queue when configured, until
+// ctx is cancelled, then shuts down gracefully. cfg must have passed Validate. opts
+// are added to the container, as serve shares the certificate with the reloader.
+func run(ctx context.Context, cfg config.Config, opts ...fx.Option) error {
+	if cfg.MigrateOnStart {
+		// Before anything opens the storage, which may load the table into memory
+		if err := migrate(ctx, cfg, "up", io.Discard); err != nil {
+			return err
+		}
 	}
 
-	pool, err := NewPostgresDB(dsn)
-	if err != nil {
-		slog.Error("failed to connect to database", "error", err)
-		return
+	var a *app
+	container := newContainer(ctx, cfg, append(opts, fx.Populate(&a))...)
+	if err := container.Start(ctx); err != nil {
+		// Not the chain of constructors that needed the one that failed
+		return dig.RootCause(err)
 	}
-	defer pool.Close()
-
-	slog.Info("Successfully connected to database")
-
-	queries := sqlc.New(pool)
-
-	server := NewServer(queries)
-
-	strictHandler := api.NewStrictHandler(server, nil)
-
-	handler := api.Handler(strictHandler)
-
-	srv := &http.Server{
-		Addr:    addr,
-		Handler: handler,
-	}
-
-	serverErrors := make(chan error, 1)
-
-	go func() {
-		slog.Info("Starting server", "address", addr)
-		serverErrors <- srv.ListenAndServe()
-	}()
-
-	shutdown := make(chan os.Signal, 1)
-	signal.Notify(shutdown, os.Interrupt, syscall.SIGTERM)
-
-	select {
-	case err := <-serverErrors:
-		if err != nil && err != http.ErrServerClosed {
-			slog.Error("server failed", "error", err)
-			return
+	defer func() {
+		if err := container.Stop(context.WithoutCancel(ctx)); err != nil {
+			slog.Error("Failed to close resources", "error", err)
 		}
-	case sig := <-shutdown:
-		slog.Info("Received shutdown signal", "signal", sig.String())
-
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
+	}()
 
-		if err := srv.Shutdown(ctx); err != nil {
-			slog.Error("Failed to shutdown server gracefully", "error", err)
-			srv.Close()
+	ln, err := (&net.ListenConfig{KeepAlive: cfg.Server.TCPKeepAlive}).Listen(ctx, "tcp", cfg.Server.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", cfg.Server.Addr, err)
+	}
+	if a.grpc != nil {
+		if a.grpcListener, err = net.Listen("tcp", cfg.Server.GRPCAddr); err != nil {
+			ln.Close()
+			return fmt.Errorf("failed to listen on %s: %w", cfg.Server.GRPCAddr, err)
 		}
-
-		slog.Info("Server stopped gracefully")
 	}
-}
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
+	return a.serve(ctx, ln)
 }
 
-func NewPostgresDB(dsn string) (*pgxpool.Pool, error) {
-	config, err := pgxpool.ParseConfig(dsn)
-	if err != nil {
-		return nil, err
+// newLogHandler returns a text or JSON handler on w, filtered by level
+func newLogHandler(w io.Writer, format string, level slog.Leveler) slog.Handler {
+	opts := &slog.HandlerOptions{Level: level}
+	if format == "json" {
+		return slog.NewJSONHandler(w, opts)
 	}
 
-	config.MaxConns = maxConns
-	config.MinConns = minConns
-	config.MaxConnLifetime = maxConnLifetime
-	config.MaxConnIdleTime = maxConnIdleTime
-	config.HealthCheckPeriod = healthCheckPeriod
+	return slog.NewTextHandler(w, opts)
+}
 
-	pool, err := pgxpool.NewWithConfig(context.Background(), config)
-	if err != nil {
-		return nil, err
+// openLogOutput returns the configured log destination. A log file is opened right
+// away so that an unwritable path fails at startup rather than losing every line.
+func openLogOutput(cfg config.LogConfig) (io.Writer, error) {
+	switch cfg.Output {
+	case "file":
+		w := logfile.New(cfg.File, logfile.Options{
+			MaxSizeMB:  int(cfg.MaxSizeMB),
+			MaxAge:     cfg.MaxAge,
+			MaxBackups: int(cfg.MaxBackups),
+		})
+		if _, err := w.Write(nil); err != nil {
+			return nil, fmt.Errorf("failed to open log file: %w", err)
+		}
+		return w, nil
+	case "syslog":
+		network, addr, err := cfg.SyslogTarget()
+		if err != nil {
+			return nil, err
+		}
+		w, err := dialSyslog(network, addr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to syslog: %w", err)
+		}
+		return w, nil
+	default:
+		return os.Stderr, nil
 	}
+}
 
-	if err = pool.Ping(context.Background()); err != nil {
-		pool.Close()
+// connectNATS connects to the configured servers, reconnecting for as long as the
+// server runs once the first connection has succeeded
+func connectNATS(cfg config.NATSConfig) (*nats.Conn, error) {
+	conn, err := nats.Connect(cfg.URL,
+		nats.Name("golang-test-task"),
+		nats.MaxReconnects(-1),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			if err != nil {
+				slog.Warn("Disconnected from NATS, reconnecting", "error", err)
+			}
+		}),
+		nats.ReconnectHandler(func(conn *nats.Conn) {
+			slog.Info("Reconnected to NATS", "server", conn.ConnectedUrlRedacted())
+		}),
+		nats.ErrorHandler(func(_ *nats.Conn, sub *nats.Subscription, err error) {
+			if sub != nil {
+				slog.Error("NATS subscription failed", "subject", sub.Subject, "error", err)
+				return
+			}
+			slog.Error("NATS connection failed", "error", err)
+		}),
+	)
+	if err != nil {
 		return nil, err
 	}
+	slog.Info("Successfully connected to NATS", "server", conn.ConnectedUrlRedacted())
 
-	return pool, nil
+	return conn, nil
 }
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang-test-task/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// freeAddr returns a loopback address that was free a moment ago
+func freeAddr(t *testing.T) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	return ln.Addr().String()
+}
+
+// memoryConfig is a valid configuration that needs no database
+func memoryConfig(t *testing.T) config.Config {
+	cfg := config.Default()
+	cfg.Profile, cfg.Storage = "dev", "memory"
+	cfg.Server.Addr = freeAddr(t)
+
+	return cfg
+}
+
+func TestRun_ServesUntilCancelled(t *testing.T) {
+	cfg := memoryConfig(t)
+	require.NoError(t, cfg.Validate())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- run(ctx, cfg) }()
+
+	require.Eventually(t, func() bool {
+		resp, err := http.Post("http://"+cfg.Server.Addr+"/numbers?number=1", "", nil)
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("run did not return after cancellation")
+	}
+}
+
+func TestRun_StartupErrors(t *testing.T) {
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer busy.Close()
+
+	cfg := memoryConfig(t)
+	cfg.Server.Addr = busy.Addr().String()
+	assert.ErrorContains(t, run(context.Background(), cfg), "failed to listen")
+
+	cfg = memoryConfig(t)
+	cfg.Server.TLSCertFile, cfg.Server.TLSKeyFile = filepath.Join(t.TempDir(), "cert.pem"), filepath.Join(t.TempDir(), "key.pem")
+	assert.ErrorContains(t, run(context.Background(), cfg), "failed to load TLS certificate")
+
+	cfg = config.Default()
+	cfg.Postgres.DSN = "postgres://app@" + freeAddr(t) + "/numbers?sslmode=disable&connect_timeout=2"
+	assert.ErrorContains(t, run(context.Background(), cfg), "failed to connect to database")
+}
+
+func TestRun_ServesTLS(t *testing.T) {
+	cfg := memoryConfig(t)
+	cfg.Server.TLSCertFile, cfg.Server.TLSKeyFile = writeCertificate(t)
+	require.NoError(t, cfg.Validate())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- run(ctx, cfg) }()
+
+	require.Eventually(t, func() bool { return healthcheck(cfg.Server) == nil }, 5*time.Second, 10*time.Millisecond)
+	resp, err := http.Get("http://" + cfg.Server.Addr + "/healthz")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode, "plain HTTP is refused")
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("run did not return after cancellation")
+	}
+}
+
+// writeCertificate writes a self-signed certificate for 127.0.0.1 and its key to PEM
+// files and returns their paths
+func writeCertificate(t *testing.T) (certFile, keyFile string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+
+	return certFile, keyFile
+}
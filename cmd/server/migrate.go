@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"golang-test-task/internal/config"
+	"golang-test-task/internal/storage"
+	"golang-test-task/migrations"
+
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+	"github.com/spf13/cobra"
+)
+
+func newMigrateCmd() *cobra.Command {
+	return withConfigFlags(&cobra.Command{
+		Use:   "migrate [up|down|status] [flags]",
+		Short: "Apply, roll back or list the embedded database migrations",
+		Long: "Apply, roll back or list the database migrations built into the binary.\n\n" +
+			"  up      apply every pending migration (the default)\n" +
+			"  down    roll back the most recent migration\n" +
+			"  status  list applied and pending migrations with the checksum of each file,\n" +
+			"          exiting with status 1 when the schema is dirty",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			action := "up"
+			if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+				action, args = args[0], args[1:]
+			}
+			if action != "up" && action != "down" && action != "status" {
+				return usageError{fmt.Errorf("unknown migrate action %q, want up, down or status", action)}
+			}
+
+			cfg, ok, err := loadConfig(cmd, args, nil)
+			if !ok {
+				return err
+			}
+			if _, err := setupLogging(cfg); err != nil {
+				return err
+			}
+
+			ctx, stop := signalContext()
+			defer stop()
+
+			return migrate(ctx, cfg, action, cmd.OutOrStdout())
+		},
+	}, nil)
+}
+
+// migrate runs action against the configured database, writing the status table to out
+func migrate(ctx context.Context, cfg config.Config, action string, out io.Writer) error {
+	if cfg.Storage != "postgres" {
+		return usageError{fmt.Errorf("migrate needs postgres storage, got %q", cfg.Storage)}
+	}
+
+	// The pool's failover checks and Vault renewals run until ctx is done, which for
+	// migrations on start is the life of the server, so they are stopped on return,
+	// before the pool is closed
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	pool, err := storage.ConnectPostgres(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer func() {
+		cancel()
+		pool.Close()
+	}()
+
+	db := stdlib.OpenDBFromPool(pool.Current())
+	defer db.Close()
+
+	// Replicas that migrate on start take turns, so each applies only what the one
+	// before it left pending
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		return fmt.Errorf("failed to create migration lock: %w", err)
+	}
+	provider, err := goose.NewProvider(goose.DialectPostgres, db, migrations.FS, goose.WithSessionLocker(locker))
+	if err != nil {
+		return fmt.Errorf("failed to load migrations: %w", err)
+	}
+
+	sums, err := migrationChecksums(provider.ListSources())
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case "up":
+		results, err := provider.Up(ctx)
+		for _, result := range results {
+			logMigration(result)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to apply migrations: %w", err)
+		}
+		if len(results) == 0 {
+			slog.Info("Database is up to date")
+		}
+		if err := recordChecksums(ctx, db, sums); err != nil {
+			return fmt.Errorf("failed to record migration checksums: %w", err)
+		}
+	case "down":
+		result, err := provider.Down(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to roll back migration: %w", err)
+		}
+		logMigration(result)
+		if err := forgetChecksum(ctx, db, result.Source.Version); err != nil {
+			return fmt.Errorf("failed to forget migration checksum: %w", err)
+		}
+	case "status":
+		statuses, err := provider.Status(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get migration status: %w", err)
+		}
+		applied, err := appliedVersions(ctx, db)
+		if err != nil {
+			return fmt.Errorf("failed to list applied versions: %w", err)
+		}
+		recorded, err := recordedChecksums(ctx, db)
+		if err != nil {
+			return fmt.Errorf("failed to read migration checksums: %w", err)
+		}
+
+		report := buildMigrationReport(statuses, applied, sums, recorded)
+		if err := writeMigrationReport(out, report); err != nil {
+			return err
+		}
+		if len(report.Problems) > 0 {
+			return errDirtySchema
+		}
+	}
+
+	return nil
+}
+
+func logMigration(result *goose.MigrationResult) {
+	slog.Info("Migrated", "direction", result.Direction, "version", result.Source.Version,
+		"file", result.Source.Path, "duration", result.Duration)
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"golang-test-task/migrations"
+
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/database"
+)
+
+// checksumTable records the checksum of every migration file when it is applied,
+// which goose itself does not, so status can tell when a file changed since
+const checksumTable = "goose_db_checksums"
+
+// The states status reports on top of goose's applied and pending
+const (
+	stateModified   = "modified"
+	stateOutOfOrder = "out of order"
+	stateMissing    = "missing"
+)
+
+// migrationRow is a line of the status table
+type migrationRow struct {
+	Version   int64
+	State     string
+	AppliedAt time.Time
+	Checksum  string
+	File      string
+}
+
+// migrationReport is what status prints: a row per migration, known to the binary or
+// to the database, and the problems to solve before the next deploy
+type migrationReport struct {
+	Rows             []migrationRow
+	Applied, Pending int
+	Unrecorded       int
+	Problems         []string
+}
+
+// buildMigrationReport compares the migrations embedded in the binary with the
+// versions applied to the database. sums are the checksums of the embedded files and
+// recorded the ones stored when each version was applied. The database is dirty when
+// an applied file changed since, when a version was applied that this binary does not
+// know (a newer build migrated it), or when a pending migration is older than an
+// applied one, which up refuses to apply.
+func buildMigrationReport(statuses []*goose.MigrationStatus, applied []int64, sums, recorded map[int64]string) migrationReport {
+	var report migrationReport
+
+	latest := int64(0)
+	if len(applied) > 0 {
+		latest = slices.Max(applied)
+	}
+
+	known := make(map[int64]bool, len(statuses))
+	for _, s := range statuses {
+		known[s.Source.Version] = true
+		row := migrationRow{
+			Version:   s.Source.Version,
+			State:     string(s.State),
+			AppliedAt: s.AppliedAt,
+			Checksum:  sums[s.Source.Version],
+			File:      s.Source.Path,
+		}
+
+		switch want, ok := recorded[row.Version]; {
+		case s.State == goose.StatePending && row.Version < latest:
+			row.State = stateOutOfOrder
+			report.Pending++
+			report.Problems = append(report.Problems, fmt.Sprintf(
+				"%s is pending but older than applied version %d, so up refuses to apply it", row.File, latest))
+		case s.State == goose.StatePending:
+			report.Pending++
+		case ok && want != row.Checksum:
+			row.State = stateModified
+			report.Applied++
+			report.Problems = append(report.Problems, fmt.Sprintf(
+				"%s changed since it was applied: checksum %s, was %s", row.File, shortChecksum(row.Checksum), shortChecksum(want)))
+		case !ok:
+			report.Applied++
+			report.Unrecorded++
+		default:
+			report.Applied++
+		}
+		report.Rows = append(report.Rows, row)
+	}
+
+	for _, version := range applied {
+		if known[version] {
+			continue
+		}
+		report.Applied++
+		report.Rows = append(report.Rows, migrationRow{Version: version, State: stateMissing, Checksum: recorded[version]})
+		report.Problems = append(report.Problems, fmt.Sprintf(
+			"version %d is applied but not embedded in this binary, which is older than the schema", version))
+	}
+	slices.SortFunc(report.Rows, func(a, b migrationRow) int { return cmp.Compare(a.Version, b.Version) })
+
+	return report
+}
+
+// writeMigrationReport prints report as a table followed by a summary and the problems
+func writeMigrationReport(out io.Writer, report migrationReport) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tSTATE\tAPPLIED AT\tCHECKSUM\tFILE")
+	for _, row := range report.Rows {
+		appliedAt := "-"
+		if !row.AppliedAt.IsZero() {
+			appliedAt = row.AppliedAt.UTC().Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", row.Version, row.State, appliedAt, orDash(shortChecksum(row.Checksum)), orDash(row.File))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "\n%d applied, %d pending\n", report.Applied, report.Pending)
+	if report.Unrecorded > 0 {
+		fmt.Fprintf(out, "%d applied migrations have no recorded checksum; the next migrate up records the current one\n", report.Unrecorded)
+	}
+	for _, problem := range report.Problems {
+		fmt.Fprintf(out, "dirty: %s\n", problem)
+	}
+
+	return nil
+}
+
+// shortChecksum is enough of a checksum to compare by eye
+func shortChecksum(sum string) string {
+	return sum[:min(len(sum), 12)]
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// migrationChecksums returns the SHA-256 of every embedded migration by version
+func migrationChecksums(sources []*goose.Source) (map[int64]string, error) {
+	sums := make(map[int64]string, len(sources))
+	for _, source := range sources {
+		data, err := fs.ReadFile(migrations.FS, source.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", source.Path, err)
+		}
+		sum := sha256.Sum256(data)
+		sums[source.Version] = hex.EncodeToString(sum[:])
+	}
+
+	return sums, nil
+}
+
+// appliedVersions lists the versions goose recorded as applied
+func appliedVersions(ctx context.Context, db *sql.DB) ([]int64, error) {
+	store, err := database.NewStore(database.DialectPostgres, goose.DefaultTablename)
+	if err != nil {
+		return nil, err
+	}
+	results, err := store.ListMigrations(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []int64
+	for _, result := range results {
+		if result.IsApplied && result.Version > 0 {
+			versions = append(versions, result.Version)
+		}
+	}
+
+	return versions, nil
+}
+
+// recordedChecksums reads the checksum table, empty until the first migrate up
+// that records one
+func recordedChecksums(ctx context.Context, db *sql.DB) (map[int64]string, error) {
+	recorded := map[int64]string{}
+	if exists, err := checksumTableExists(ctx, db); err != nil || !exists {
+		return recorded, err
+	}
+
+	rows, err := db.QueryContext(ctx, "select version_id, checksum from "+checksumTable)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var version int64
+		var sum string
+		if err := rows.Scan(&version, &sum); err != nil {
+			return nil, err
+		}
+		recorded[version] = sum
+	}
+
+	return recorded, rows.Err()
+}
+
+func checksumTableExists(ctx context.Context, db *sql.DB) (bool, error) {
+	var exists bool
+	err := db.QueryRowContext(ctx, "select to_regclass($1) is not null", checksumTable).Scan(&exists)
+
+	return exists, err
+}
+
+// recordChecksums stores the checksum of every applied migration that has none
+// yet. Migrations applied before checksums were recorded get the current file's,
+// trusted on first use.
+func recordChecksums(ctx context.Context, db *sql.DB, sums map[int64]string) error {
+	_, err := db.ExecContext(ctx, "create table if not exists "+checksumTable+` (
+		version_id bigint primary key,
+		checksum text not null,
+		recorded_at timestamptz not null default now()
+	)`)
+	if err != nil {
+		return err
+	}
+
+	applied, err := appliedVersions(ctx, db)
+	if err != nil {
+		return err
+	}
+	for _, version := range applied {
+		sum, ok := sums[version]
+		if !ok {
+			continue
+		}
+		_, err := db.ExecContext(ctx, "insert into "+checksumTable+" (version_id, checksum) values ($1, $2) on conflict do nothing", version, sum)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// forgetChecksum drops the checksum of a rolled back migration, so a fixed file can
+// be applied again
+func forgetChecksum(ctx context.Context, db *sql.DB, version int64) error {
+	if exists, err := checksumTableExists(ctx, db); err != nil || !exists {
+		return err
+	}
+	_, err := db.ExecContext(ctx, "delete from "+checksumTable+" where version_id = $1", version)
+
+	return err
+}
+
+// errDirtySchema makes status exit non-zero, so a deploy can check for it
+var errDirtySchema = errors.New("the database schema is dirty")
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/pressly/goose/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func migrationStatus(version int64, file string, state goose.State) *goose.MigrationStatus {
+	s := &goose.MigrationStatus{Source: &goose.Source{Version: version, Path: file}, State: state}
+	if state == goose.StateApplied {
+		s.AppliedAt = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	}
+
+	return s
+}
+
+func TestMigrationChecksums_CoverEmbeddedMigrations(t *testing.T) {
+	sources := []*goose.Source{{Version: 20260119205450, Path: "20260119205450_init.sql"}}
+
+	sums, err := migrationChecksums(sources)
+	require.NoError(t, err)
+	assert.Len(t, sums[20260119205450], 64)
+
+	_, err = migrationChecksums([]*goose.Source{{Version: 1, Path: "1_absent.sql"}})
+	assert.Error(t, err)
+}
+
+func TestBuildMigrationReport_Clean(t *testing.T) {
+	statuses := []*goose.MigrationStatus{
+		migrationStatus(1, "1_init.sql", goose.StateApplied),
+		migrationStatus(2, "2_next.sql", goose.StateApplied),
+		migrationStatus(3, "3_new.sql", goose.StatePending),
+	}
+	sums := map[int64]string{1: "aaaa", 2: "bbbb", 3: "cccc"}
+
+	report := buildMigrationReport(statuses, []int64{1, 2}, sums, map[int64]string{1: "aaaa"})
+
+	assert.Empty(t, report.Problems)
+	assert.Equal(t, 2, report.Applied)
+	assert.Equal(t, 1, report.Pending)
+	assert.Equal(t, 1, report.Unrecorded, "version 2 was applied before checksums were recorded")
+
+	var out bytes.Buffer
+	require.NoError(t, writeMigrationReport(&out, report))
+	assert.Equal(t, `VERSION  STATE    APPLIED AT           CHECKSUM  FILE
+1        applied  2026-03-01 12:00:00  aaaa      1_init.sql
+2        applied  2026-03-01 12:00:00  bbbb      2_next.sql
+3        pending  -                    cccc      3_new.sql
+
+2 applied, 1 pending
+1 applied migrations have no recorded checksum; the next migrate up records the current one
+`, out.String())
+}
+
+func TestBuildMigrationReport_Dirty(t *testing.T) {
+	statuses := []*goose.MigrationStatus{
+		migrationStatus(1, "1_init.sql", goose.StateApplied),
+		migrationStatus(2, "2_late.sql", goose.StatePending),
+		migrationStatus(3, "3_next.sql", goose.StateApplied),
+	}
+	sums := map[int64]string{1: "aaaa-changed", 2: "bbbb", 3: "cccc"}
+	recorded := map[int64]string{1: "aaaa", 3: "cccc", 4: "dddd"}
+
+	report := buildMigrationReport(statuses, []int64{1, 3, 4}, sums, recorded)
+
+	states := map[int64]string{}
+	for _, row := range report.Rows {
+		states[row.Version] = row.State
+	}
+	assert.Equal(t, map[int64]string{1: stateModified, 2: stateOutOfOrder, 3: "applied", 4: stateMissing}, states)
+	assert.Len(t, report.Problems, 3)
+	assert.Equal(t, 3, report.Applied)
+	assert.Equal(t, 1, report.Pending)
+
+	var out bytes.Buffer
+	require.NoError(t, writeMigrationReport(&out, report))
+	assert.Contains(t, out.String(), "dirty: 1_init.sql changed since it was applied: checksum aaaa-changed, was aaaa\n")
+	assert.Contains(t, out.String(), "dirty: 2_late.sql is pending but older than applied version 4")
+	assert.Contains(t, out.String(), "dirty: version 4 is applied but not embedded in this binary")
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync"
+
+	"golang-test-task/internal/config"
+)
+
+// reloader re-reads the configuration on SIGHUP and applies the settings that are
+// safe to change at runtime. Everything else is reported and left for a restart.
+type reloader struct {
+	args  []string
+	load  func(args []string) (config.Config, error)
+	level *slog.LevelVar
+	cert  *certificate
+
+	mu      sync.Mutex
+	current config.Config
+}
+
+func newReloader(args []string, current config.Config, level *slog.LevelVar, cert *certificate) *reloader {
+	return &reloader{
+		args:    args,
+		load:    config.Load,
+		level:   level,
+		cert:    cert,
+		current: current,
+	}
+}
+
+// run reloads on every signal from hup until ctx is done
+func (r *reloader) run(ctx context.Context, hup <-chan os.Signal) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			r.reload()
+		}
+	}
+}
+
+// reload loads and validates the configuration, logs what changed and applies the reloadable part.
+// An invalid configuration is rejected as a whole and the running one is kept.
+func (r *reloader) reload() {
+	cfg, err := r.load(r.args)
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if err != nil {
+		slog.Error("Configuration reload rejected, keeping the running configuration", "error", err)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	changes := config.Diff(r.current, cfg)
+	if len(changes) == 0 {
+		slog.Info("Configuration reloaded, nothing changed")
+	}
+	for _, change := range changes {
+		if !change.Reloadable {
+			slog.Warn("Configuration change requires a restart", "key", change.Key, "old", change.Old, "new", change.New)
+			continue
+		}
+		slog.Info("Configuration changed", "key", change.Key, "old", change.Old, "new", change.New)
+	}
+
+	r.level.Set(cfg.Log.SlogLevel())
+	r.current.Log = cfg.Log
+	r.reloadCertificate(cfg.Server)
+}
+
+// reloadCertificate reads the keypair again even when its paths are unchanged, since a
+// renewed certificate usually replaces the old files. If it cannot be read, the served
+// one is kept. Turning TLS on or off needs a restart, as the listeners are set up for one.
+func (r *reloader) reloadCertificate(server config.ServerConfig) {
+	if r.current.Server.TLS() != server.TLS() {
+		slog.Warn("Turning TLS on or off requires a restart")
+		return
+	}
+	if !server.TLS() {
+		return
+	}
+	if err := r.cert.load(server.TLSCertFile, server.TLSKeyFile); err != nil {
+		slog.Error("Failed to reload TLS certificate, keeping the served one", "error", err)
+		return
+	}
+	r.current.Server.TLSCertFile, r.current.Server.TLSKeyFile = server.TLSCertFile, server.TLSKeyFile
+	slog.Info("TLS certificate reloaded", "cert_file", server.TLSCertFile)
+}
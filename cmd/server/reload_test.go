@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"golang-test-task/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testReloader(next config.Config, loadErr error) *reloader {
+	current := config.Default()
+	current.Postgres.DSN = "postgres://localhost/testdb"
+
+	level := new(slog.LevelVar)
+	r := newReloader(nil, current, level, new(certificate))
+	r.load = func([]string) (config.Config, error) { return next, loadErr }
+
+	return r
+}
+
+func TestReloader_AppliesLogLevel(t *testing.T) {
+	next := config.Default()
+	next.Postgres.DSN = "postgres://localhost/testdb"
+	next.Log.Level = "debug"
+	// Not reloadable, must not be applied
+	next.Server.Addr = ":9999"
+
+	r := testReloader(next, nil)
+	r.reload()
+
+	assert.Equal(t, slog.LevelDebug, r.level.Level())
+	assert.Equal(t, "debug", r.current.Log.Level)
+	assert.Equal(t, ":8080", r.current.Server.Addr)
+}
+
+func TestReloader_RejectsInvalidConfig(t *testing.T) {
+	next := config.Default()
+	next.Log.Level = "debug"
+	// Missing DSN fails validation
+
+	r := testReloader(next, nil)
+	r.reload()
+
+	assert.Equal(t, slog.LevelInfo, r.level.Level())
+	assert.Equal(t, "info", r.current.Log.Level)
+}
+
+func TestReloader_KeepsConfigOnLoadError(t *testing.T) {
+	r := testReloader(config.Config{}, errors.New("bad file"))
+	r.reload()
+
+	assert.Equal(t, slog.LevelInfo, r.level.Level())
+}
+
+func TestReloader_ReloadsCertificate(t *testing.T) {
+	certFile, keyFile := writeCertificate(t)
+	r := testReloader(config.Config{}, nil)
+	r.current.Server.TLSCertFile, r.current.Server.TLSKeyFile = certFile, keyFile
+	require.NoError(t, r.cert.load(certFile, keyFile))
+	served, _ := r.cert.get(nil)
+
+	next := r.current
+	next.Server.TLSCertFile, next.Server.TLSKeyFile = writeCertificate(t)
+	r.load = func([]string) (config.Config, error) { return next, nil }
+	r.reload()
+
+	renewed, _ := r.cert.get(nil)
+	assert.NotEqual(t, served.Certificate, renewed.Certificate)
+	assert.Equal(t, next.Server.TLSCertFile, r.current.Server.TLSCertFile)
+
+	// A renewal over the same files is read as well
+	require.NoError(t, os.Rename(certFile, next.Server.TLSCertFile))
+	require.NoError(t, os.Rename(keyFile, next.Server.TLSKeyFile))
+	r.reload()
+	reread, _ := r.cert.get(nil)
+	assert.Equal(t, served.Certificate, reread.Certificate)
+
+	// An unreadable one is not served
+	missing := next
+	missing.Server.TLSCertFile = filepath.Join(t.TempDir(), "cert.pem")
+	r.load = func([]string) (config.Config, error) { return missing, nil }
+	r.reload()
+	kept, _ := r.cert.get(nil)
+	assert.Equal(t, reread, kept)
+	assert.Equal(t, next.Server.TLSCertFile, r.current.Server.TLSCertFile)
+}
+
+func TestReloader_Run(t *testing.T) {
+	next := config.Default()
+	next.Postgres.DSN = "postgres://localhost/testdb"
+	next.Log.Level = "warn"
+
+	r := testReloader(next, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	hup := make(chan os.Signal, 1)
+	go r.run(ctx, hup)
+	hup <- syscall.SIGHUP
+
+	assert.Eventually(t, func() bool { return r.level.Level() == slog.LevelWarn }, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"golang-test-task/api"
+	"golang-test-task/datagen"
+	"golang-test-task/internal/storage"
+	"golang-test-task/internal/storage/sqlc"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// seedBatch bounds how many numbers a single insert statement carries
+	seedBatch = 10_000
+	// seedAPIBatch is how many numbers are sent through the API between progress
+	// reports, enough for ten requests of the batch endpoint
+	seedAPIBatch = 100_000
+)
+
+// seedOptions are the flags of the seed command
+type seedOptions struct {
+	count        int
+	distribution string
+	seed         uint64
+	api          string
+	concurrency  int
+}
+
+func (o *seedOptions) register(fs *flag.FlagSet) {
+	fs.IntVar(&o.count, "count", 1000, "how many numbers to insert")
+	fs.StringVar(&o.distribution, "distribution", string(datagen.Uniform), fmt.Sprintf("value distribution: %v", datagen.Distributions))
+	fs.Uint64Var(&o.seed, "seed", 1, "random seed; the same seed inserts the same numbers")
+	fs.StringVar(&o.api, "api", "", "base URL of a running server to add the numbers through, instead of the database")
+	fs.IntVar(&o.concurrency, "concurrency", 8, "batch requests in flight at once with -api")
+}
+
+func newSeedCmd() *cobra.Command {
+	opts := &seedOptions{}
+
+	return withConfigFlags(&cobra.Command{
+		Use:   "seed [flags]",
+		Short: "Insert generated demo numbers into the database",
+		Long: "Insert generated demo numbers into the database, or with -api through a running\n" +
+			"server, whatever its storage. Progress is logged after every batch.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Through the API the database settings are not needed
+			cfg, ok, err := loadUnvalidatedConfig(cmd, args, opts.register)
+			if !ok {
+				return err
+			}
+			if opts.api == "" {
+				if err := validateConfig(cfg); err != nil {
+					return err
+				}
+			}
+			if _, err := setupLogging(cfg); err != nil {
+				return err
+			}
+
+			distribution, err := datagen.ParseDistribution(opts.distribution)
+			if err != nil {
+				return usageError{fmt.Errorf("invalid -distribution: %w", err)}
+			}
+			if opts.count < 0 {
+				return usageError{fmt.Errorf("-count must not be negative, got %d", opts.count)}
+			}
+			if opts.concurrency < 1 {
+				return usageError{fmt.Errorf("-concurrency must be at least 1, got %d", opts.concurrency)}
+			}
+			if opts.api == "" && cfg.Storage != "postgres" {
+				return usageError{fmt.Errorf("seed needs postgres storage or -api, got storage %q", cfg.Storage)}
+			}
+
+			values := datagen.DefaultConfig()
+			values.Distribution, values.Seed = distribution, opts.seed
+			g, err := datagen.New(values)
+			if err != nil {
+				return err
+			}
+
+			ctx, stop := signalContext()
+			defer stop()
+
+			if opts.api != "" {
+				client, err := api.NewRetryingClient(opts.api, api.DefaultRetryPolicy(),
+					api.WithHTTPClient(&http.Client{Timeout: 30 * time.Second}))
+				if err != nil {
+					return usageError{fmt.Errorf("invalid -api: %w", err)}
+				}
+				return seed(ctx, apiInserter(client, opts.concurrency), g, opts.count, seedAPIBatch)
+			}
+
+			pool, err := storage.ConnectPostgres(ctx, cfg)
+			if err != nil {
+				return fmt.Errorf("failed to connect to database: %w", err)
+			}
+			defer pool.Close()
+
+			return seed(ctx, dbInserter(pool), g, opts.count, seedBatch)
+		},
+	}, opts.register)
+}
+
+// inserter stores a batch of numbers
+type inserter func(ctx context.Context, numbers []int64) error
+
+// dbInserter inserts a batch with a single statement
+func dbInserter(db sqlc.DBTX) inserter {
+	return func(ctx context.Context, numbers []int64) error {
+		if _, err := db.Exec(ctx, "insert into numbers (number) select unnest($1::bigint[])", numbers); err != nil {
+			return fmt.Errorf("failed to insert numbers: %w", err)
+		}
+		return nil
+	}
+}
+
+// apiInserter adds a batch through the batch endpoint of the API, concurrency
+// requests at a time
+func apiInserter(client *api.ClientWithResponses, concurrency int) inserter {
+	return func(ctx context.Context, numbers []int64) error {
+		values := make([]int, len(numbers))
+		for i, n := range numbers {
+			values[i] = int(n)
+		}
+		return client.AddNumbers(ctx, values, concurrency)
+	}
+}
+
+// seed inserts count numbers from g in batches, logging progress after each
+func seed(ctx context.Context, insert inserter, g *datagen.Generator, count, batch int) error {
+	start := time.Now()
+	for inserted := 0; inserted < count; {
+		values := g.Take(min(batch, count-inserted))
+		if err := insert(ctx, values); err != nil {
+			return err
+		}
+		inserted += len(values)
+
+		elapsed := time.Since(start)
+		rate := float64(inserted) / max(elapsed.Seconds(), 1e-9)
+		remaining := time.Duration(float64(count-inserted) / rate * float64(time.Second))
+		slog.Info("Seeded numbers", "inserted", inserted, "total", count,
+			"percent", inserted*100/count, "per_second", int(rate), "remaining", remaining.Round(time.Second))
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"golang-test-task/api/apitest"
+	"golang-test-task/datagen"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testGenerator(t *testing.T) *datagen.Generator {
+	t.Helper()
+
+	g, err := datagen.New(datagen.DefaultConfig())
+	require.NoError(t, err)
+
+	return g
+}
+
+func TestSeed_Batches(t *testing.T) {
+	var batches []int
+	insert := func(ctx context.Context, numbers []int64) error {
+		batches = append(batches, len(numbers))
+		return nil
+	}
+
+	require.NoError(t, seed(context.Background(), insert, testGenerator(t), 25, 10))
+	assert.Equal(t, []int{10, 10, 5}, batches)
+}
+
+func TestSeed_StopsOnError(t *testing.T) {
+	calls := 0
+	insert := func(ctx context.Context, numbers []int64) error {
+		calls++
+		return errors.New("connection refused")
+	}
+
+	assert.Error(t, seed(context.Background(), insert, testGenerator(t), 25, 10))
+	assert.Equal(t, 1, calls)
+}
+
+func TestSeed_ThroughAPI(t *testing.T) {
+	fake := apitest.NewServer()
+	client := fake.Start(t)
+
+	require.NoError(t, seed(context.Background(), apiInserter(client, 4), testGenerator(t), 30, 8))
+
+	want := testGenerator(t).Take(30)
+	got := fake.Numbers()
+	require.Len(t, got, 30)
+	assert.IsNonDecreasing(t, got)
+	assert.ElementsMatch(t, want, toInt64(got), "the same seed adds the same numbers")
+}
+
+func TestSeedCmd_ThroughAPINeedsNoDatabase(t *testing.T) {
+	unsetenv(t, "POSTGRES_DSN")
+	fake := apitest.NewServer()
+	srv := httptest.NewServer(fake.Handler())
+	defer srv.Close()
+
+	assert.Equal(t, 0, execute([]string{"seed", "-api", srv.URL, "-count", "5"}))
+	assert.Len(t, fake.Numbers(), 5)
+}
+
+func toInt64(values []int) []int64 {
+	out := make([]int64, len(values))
+	for i, v := range values {
+		out[i] = int64(v)
+	}
+
+	return out
+}
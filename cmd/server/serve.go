@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang-test-task/internal/config"
+	"golang-test-task/internal/transport/server"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+)
+
+// app is the HTTP server together with the resources it owns
+type app struct {
+	srv       *http.Server
+	readiness *server.Readiness
+	// grpc, when set, serves on grpcListener and shuts down along with srv
+	grpc         *grpc.Server
+	grpcListener net.Listener
+	// grpcHealth, when set, turns NOT_SERVING along with readiness
+	grpcHealth *health.Server
+	// ingesters store numbers from message brokers until shutdown begins
+	ingesters []ingester
+	// pool is closed only after every in-flight request has finished
+	pool interface{ Close() }
+	// preStopDelay keeps serving after the shutdown signal, with readiness failing,
+	// while load balancers and Kubernetes endpoints stop routing to this instance
+	preStopDelay time.Duration
+	// shutdownTimeout bounds how long in-flight requests may take once shutdown starts
+	shutdownTimeout time.Duration
+}
+
+// ingester stores numbers from a message broker until ctx is done, and returns once
+// it no longer uses the storage
+type ingester interface {
+	Run(ctx context.Context)
+}
+
+// connections counts the connections of the HTTP server by state, published as the
+// expvar http_connections
+var connections = new(server.ConnMetrics)
+
+func init() {
+	expvar.Publish("http_connections", connections)
+}
+
+// newApp mounts handler next to the GET /readyz readiness endpoint
+func newApp(handler http.Handler, pool interface{ Close() }) *app {
+	readiness := &server.Readiness{}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /readyz", readiness)
+	mux.Handle("/", handler)
+
+	return &app{
+		srv:             &http.Server{Handler: mux, ConnState: connections.Track},
+		readiness:       readiness,
+		pool:            pool,
+		shutdownTimeout: config.Default().Server.ShutdownTimeout,
+	}
+}
+
+// serve accepts connections on ln, and on grpcListener with grpc, and runs the
+// ingesters until ctx is cancelled, then shuts down gracefully: readiness flips to
+// 503 and the ingesters stop, requests are still served for preStopDelay, the
+// listeners stop accepting, in-flight requests run to completion and finally, once
+// the ingesters have returned too, the pool is closed
+func (a *app) serve(ctx context.Context, ln net.Listener) error {
+	defer a.pool.Close()
+
+	ingestCtx, stopIngesting := context.WithCancel(ctx)
+	var ingesting sync.WaitGroup
+	defer ingesting.Wait()
+	defer stopIngesting()
+	for _, in := range a.ingesters {
+		ingesting.Add(1)
+		go func() {
+			defer ingesting.Done()
+			in.Run(ingestCtx)
+		}()
+	}
+
+	serverErrors := make(chan error, 2)
+	go func() {
+		if a.srv.TLSConfig != nil {
+			slog.Info("Starting server", "address", ln.Addr().String(), "tls", true)
+			serverErrors <- a.srv.ServeTLS(ln, "", "")
+			return
+		}
+		slog.Info("Starting server", "address", ln.Addr().String())
+		serverErrors <- a.srv.Serve(ln)
+	}()
+	if a.grpc != nil {
+		go func() {
+			slog.Info("Starting gRPC server", "address", a.grpcListener.Addr().String())
+			serverErrors <- a.grpc.Serve(a.grpcListener)
+		}()
+	}
+
+	select {
+	case err := <-serverErrors:
+		if a.grpc != nil {
+			a.grpc.Stop()
+		}
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("Received shutdown signal")
+	a.readiness.Drain()
+	if a.grpcHealth != nil {
+		a.grpcHealth.Shutdown()
+	}
+
+	if a.preStopDelay > 0 {
+		slog.Info("Serving until load balancers stop routing here", "delay", a.preStopDelay)
+		time.Sleep(a.preStopDelay)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), a.shutdownTimeout)
+	defer cancel()
+
+	grpcStopped := a.stopGRPC(shutdownCtx)
+	defer func() { <-grpcStopped }()
+
+	if err := a.srv.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Failed to shutdown server gracefully", "error", err)
+		a.srv.Close()
+		return err
+	}
+
+	slog.Info("Server stopped gracefully")
+	return nil
+}
+
+// stopGRPC stops the gRPC server, if any, letting in-flight calls finish until ctx is
+// done and cutting off the rest, and closes the returned channel once it has stopped
+func (a *app) stopGRPC(ctx context.Context) <-chan struct{} {
+	stopped := make(chan struct{})
+	if a.grpc == nil {
+		close(stopped)
+		return stopped
+	}
+
+	graceful := make(chan struct{})
+	go func() {
+		a.grpc.GracefulStop()
+		close(graceful)
+	}()
+	go func() {
+		defer close(stopped)
+		select {
+		case <-graceful:
+		case <-ctx.Done():
+			a.grpc.Stop()
+			<-graceful
+		}
+	}()
+
+	return stopped
+}
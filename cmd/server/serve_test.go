@@ -0,0 +1,359 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang-test-task/internal/service"
+	"golang-test-task/internal/storage/memstore"
+	"golang-test-task/internal/transport/server"
+	"golang-test-task/numberspb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+)
+
+// fakePool reports its Close to the fixture so tests can check it happens last
+type fakePool struct {
+	closed atomic.Bool
+	record func(event string)
+}
+
+func (p *fakePool) Close() {
+	p.closed.Store(true)
+	p.record("pool closed")
+}
+
+// shutdownFixture runs an app whose /slow handler blocks until release is closed
+type shutdownFixture struct {
+	app     *app
+	pool    *fakePool
+	url     string
+	addr    string
+	started chan struct{}
+	release chan struct{}
+	cancel  context.CancelFunc
+	done    chan error
+
+	mu     sync.Mutex
+	events []string
+}
+
+func (f *shutdownFixture) record(event string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.events = append(f.events, event)
+}
+
+func startShutdownFixture(t *testing.T) *shutdownFixture {
+	t.Helper()
+
+	f := &shutdownFixture{
+		started: make(chan struct{}),
+		release: make(chan struct{}),
+		done:    make(chan error, 1),
+	}
+	f.pool = &fakePool{record: f.record}
+
+	handler := http.NewServeMux()
+	handler.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		close(f.started)
+		<-f.release
+
+		f.record("request finished")
+		io.WriteString(w, "done")
+	})
+
+	f.app = newApp(handler, f.pool)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	f.addr = ln.Addr().String()
+	f.url = "http://" + f.addr
+
+	ctx, cancel := context.WithCancel(context.Background())
+	f.cancel = cancel
+	t.Cleanup(cancel)
+
+	go func() { f.done <- f.app.serve(ctx, ln) }()
+
+	return f
+}
+
+// slowRequest issues a request to /slow in the background and waits until the handler runs.
+// The channel yields nil if the request failed.
+func (f *shutdownFixture) slowRequest(t *testing.T) <-chan *http.Response {
+	t.Helper()
+
+	responses := make(chan *http.Response, 1)
+	go func() {
+		resp, err := http.Get(f.url + "/slow")
+		if err != nil {
+			responses <- nil
+			return
+		}
+		responses <- resp
+	}()
+
+	select {
+	case <-f.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("slow request did not reach the handler")
+	}
+
+	return responses
+}
+
+func TestServe_ReadyBeforeShutdown(t *testing.T) {
+	f := startShutdownFixture(t)
+
+	require.Eventually(t, func() bool {
+		resp, err := http.Get(f.url + "/readyz")
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestServe_InFlightRequestCompletes(t *testing.T) {
+	f := startShutdownFixture(t)
+	responses := f.slowRequest(t)
+
+	f.cancel()
+
+	// Readiness flips before in-flight requests drain
+	require.Eventually(t, func() bool {
+		rec := httptest.NewRecorder()
+		f.app.srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		return rec.Code == http.StatusServiceUnavailable
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// New connections are refused while the old one drains
+	require.Eventually(t, func() bool {
+		conn, err := net.DialTimeout("tcp", f.addr, 100*time.Millisecond)
+		if err != nil {
+			return true
+		}
+		conn.Close()
+		return false
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.False(t, f.pool.closed.Load(), "pool closed while a request was still running")
+
+	close(f.release)
+
+	resp := <-responses
+	require.NotNil(t, resp, "in-flight request failed")
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "done", string(body))
+
+	select {
+	case err := <-f.done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve did not return after shutdown")
+	}
+
+	assert.True(t, f.pool.closed.Load())
+	assert.Equal(t, []string{"request finished", "pool closed"}, f.events)
+}
+
+func TestServe_ShutdownTimeout(t *testing.T) {
+	f := startShutdownFixture(t)
+	f.app.shutdownTimeout = 50 * time.Millisecond
+	defer close(f.release)
+
+	f.slowRequest(t)
+	f.cancel()
+
+	select {
+	case err := <-f.done:
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve did not give up after the shutdown timeout")
+	}
+
+	assert.True(t, f.pool.closed.Load())
+}
+
+func TestServe_PreStopDelay(t *testing.T) {
+	f := startShutdownFixture(t)
+	f.app.preStopDelay = 300 * time.Millisecond
+	close(f.release)
+
+	f.cancel()
+	cancelled := time.Now()
+
+	// New requests are still served during the delay, with readiness failing
+	require.Eventually(t, func() bool {
+		resp, err := http.Get(f.url + "/readyz")
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode == http.StatusServiceUnavailable
+	}, 5*time.Second, 10*time.Millisecond)
+
+	select {
+	case err := <-f.done:
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, time.Since(cancelled), f.app.preStopDelay)
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve did not return after the pre-stop delay")
+	}
+}
+
+func TestServe_GRPC(t *testing.T) {
+	pool := &fakePool{record: func(string) {}}
+	a := newApp(http.NewServeMux(), pool)
+	a.grpc = grpc.NewServer()
+	numberspb.RegisterNumbersServiceServer(a.grpc, server.NewGRPCServer(service.New(memstore.New())))
+
+	var err error
+	a.grpcListener, err = net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- a.serve(ctx, ln) }()
+
+	conn, err := grpc.NewClient(a.grpcListener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	resp, err := numberspb.NewNumbersServiceClient(conn).AddNumber(ctx, &numberspb.AddNumberRequest{Number: 7})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{7}, resp.GetNumbers())
+
+	cancel()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve did not return after shutdown")
+	}
+	assert.True(t, pool.closed.Load())
+
+	_, err = net.DialTimeout("tcp", a.grpcListener.Addr().String(), 100*time.Millisecond)
+	assert.Error(t, err, "the gRPC listener is closed")
+}
+
+func TestServe_GRPCHealthAndReflection(t *testing.T) {
+	a := newApp(http.NewServeMux(), &fakePool{record: func(string) {}})
+	a.grpc, a.grpcHealth = server.NewGRPC(server.NewGRPCServer(service.New(memstore.New())))
+	a.preStopDelay = time.Second
+
+	var err error
+	a.grpcListener, err = net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- a.serve(ctx, ln) }()
+
+	conn, err := grpc.NewClient(a.grpcListener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	checkCtx := t.Context()
+
+	health := healthpb.NewHealthClient(conn)
+	for _, service := range []string{"", "numbers.v1.NumbersService"} {
+		resp, err := health.Check(checkCtx, &healthpb.HealthCheckRequest{Service: service})
+		require.NoError(t, err, service)
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus(), service)
+	}
+
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(checkCtx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	}))
+	listed, err := stream.Recv()
+	require.NoError(t, err)
+	var services []string
+	for _, s := range listed.GetListServicesResponse().GetService() {
+		services = append(services, s.GetName())
+	}
+	assert.Contains(t, services, "numbers.v1.NumbersService")
+	assert.Contains(t, services, "grpc.health.v1.Health")
+	require.NoError(t, stream.CloseSend())
+
+	// During the pre-stop delay the port still serves, but reports NOT_SERVING
+	cancel()
+	assert.EventuallyWithT(t, func(c *assert.CollectT) {
+		resp, err := health.Check(checkCtx, &healthpb.HealthCheckRequest{})
+		if assert.NoError(c, err) {
+			assert.Equal(c, healthpb.HealthCheckResponse_NOT_SERVING, resp.GetStatus())
+		}
+	}, 900*time.Millisecond, 10*time.Millisecond)
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve did not return after shutdown")
+	}
+}
+
+// slowIngester takes a while to stop after its context is done
+type slowIngester struct {
+	record func(event string)
+}
+
+func (i slowIngester) Run(ctx context.Context) {
+	<-ctx.Done()
+	time.Sleep(50 * time.Millisecond)
+	i.record("ingester stopped")
+}
+
+func TestServe_IngestersStopBeforePool(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+	record := func(event string) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	}
+
+	a := newApp(http.NewServeMux(), &fakePool{record: record})
+	a.ingesters = []ingester{slowIngester{record: record}}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- a.serve(ctx, ln) }()
+
+	cancel()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve did not return after shutdown")
+	}
+
+	assert.Equal(t, []string{"ingester stopped", "pool closed"}, events)
+}
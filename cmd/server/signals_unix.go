@@ -0,0 +1,21 @@
+//go:build !windows
+
+package main
+
+import (
+	"context"
+	"os"
+	"syscall"
+)
+
+// shutdownSignals start a graceful shutdown: Ctrl+C and the SIGTERM sent by Docker,
+// Kubernetes and systemd
+var shutdownSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// reloadSignals re-read the configuration
+var reloadSignals = []os.Signal{syscall.SIGHUP}
+
+// withService runs serve directly; only Windows has a service manager to integrate with
+func withService(ctx context.Context, serve func(ctx context.Context) error) error {
+	return serve(ctx)
+}
@@ -0,0 +1,73 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/windows/svc"
+)
+
+// shutdownSignals start a graceful shutdown. The runtime delivers Ctrl+C and Ctrl+Break
+// as os.Interrupt, and closing the console, logoff and system shutdown as SIGTERM.
+var shutdownSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// reloadSignals is empty: Windows has no SIGHUP, so a restart applies configuration changes
+var reloadSignals []os.Signal
+
+// serviceName is reported to the service control manager
+const serviceName = "NumberService"
+
+// withService runs serve under the service control manager when the process was started
+// as a Windows service, stopping it gracefully on Stop and Shutdown requests, and runs
+// serve directly otherwise
+func withService(ctx context.Context, serve func(ctx context.Context) error) error {
+	isService, err := svc.IsWindowsService()
+	if err != nil || !isService {
+		return serve(ctx)
+	}
+
+	h := &serviceHandler{ctx: ctx, serve: serve}
+	if err := svc.Run(serviceName, h); err != nil {
+		return err
+	}
+
+	return h.err
+}
+
+// serviceHandler is the svc.Handler running serve
+type serviceHandler struct {
+	ctx   context.Context
+	serve func(ctx context.Context) error
+	err   error
+}
+
+func (h *serviceHandler) Execute(_ []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (svcSpecificEC bool, exitCode uint32) {
+	ctx, cancel := context.WithCancel(h.ctx)
+	defer cancel()
+
+	status <- svc.Status{State: svc.StartPending}
+	done := make(chan error, 1)
+	go func() { done <- h.serve(ctx) }()
+	status <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+
+	for {
+		select {
+		case h.err = <-done:
+			if h.err != nil {
+				return true, exitFailure
+			}
+			return false, 0
+		case req := <-requests:
+			switch req.Cmd {
+			case svc.Interrogate:
+				status <- req.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				status <- svc.Status{State: svc.StopPending}
+				cancel()
+			}
+		}
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !windows
+
+package main
+
+import (
+	"io"
+	"log/syslog"
+)
+
+// dialSyslog connects to the syslog daemon at addr over network, or to the local one
+// when both are empty, tagging lines with the program name. Every line is sent with
+// the daemon facility at info priority; the slog level stays part of the message.
+func dialSyslog(network, addr string) (io.Writer, error) {
+	return syslog.Dial(network, addr, syslog.LOG_INFO|syslog.LOG_DAEMON, "")
+}
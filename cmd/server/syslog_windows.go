@@ -0,0 +1,11 @@
+package main
+
+import (
+	"errors"
+	"io"
+)
+
+// dialSyslog is unavailable: Windows has no syslog, run as a service and log to a file
+func dialSyslog(network, addr string) (io.Writer, error) {
+	return nil, errors.New("syslog is not supported on Windows, use log.output file")
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/spf13/cobra"
+)
+
+// version is set at build time with -ldflags "-X main.version=v1.2.3"
+var version = "dev"
+
+// buildInfo describes the running binary
+type buildInfo struct {
+	Version   string
+	Commit    string
+	Time      string
+	Modified  bool
+	GoVersion string
+}
+
+// readBuildInfo combines version with the VCS details the Go toolchain stamps into the binary
+func readBuildInfo() buildInfo {
+	info := buildInfo{Version: version, GoVersion: runtime.Version()}
+
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	if info.Version == "dev" && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+		info.Version = bi.Main.Version
+	}
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			info.Commit = s.Value
+		case "vcs.time":
+			info.Time = s.Value
+		case "vcs.modified":
+			info.Modified = s.Value == "true"
+		}
+	}
+
+	return info
+}
+
+func (b buildInfo) write(w io.Writer) {
+	commit := b.Commit
+	if commit == "" {
+		commit = "unknown"
+	} else if b.Modified {
+		commit += " (modified)"
+	}
+	built := b.Time
+	if built == "" {
+		built = "unknown"
+	}
+
+	fmt.Fprintf(w, "version: %s\ncommit:  %s\nbuilt:   %s\ngo:      %s\n", b.Version, commit, built, b.GoVersion)
+}
+
+func newVersionCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "version",
+		Short: "Print the version, commit and Go version of the binary",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			readBuildInfo().write(cmd.OutOrStdout())
+		},
+	}
+}
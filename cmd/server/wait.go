@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// waitOptions are the flags of the wait command
+type waitOptions struct {
+	url      string
+	timeout  time.Duration
+	interval time.Duration
+}
+
+func (o *waitOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.url, "url", "", "readiness URL to poll, e.g. http://app:8080/readyz; defaults to /readyz on the configured port on loopback")
+	fs.DurationVar(&o.timeout, "timeout", time.Minute, "give up after this long")
+	fs.DurationVar(&o.interval, "interval", time.Second, "time between attempts")
+}
+
+func newWaitCmd() *cobra.Command {
+	opts := &waitOptions{}
+
+	return withConfigFlags(&cobra.Command{
+		Use:   "wait [flags]",
+		Short: "Wait until a server is ready to serve, for compose files and CI scripts",
+		Long: "Poll GET /readyz until it answers 200 and exit 0, or exit 1 once -timeout elapses.\n" +
+			"A server only listens once it has connected to its database, so a ready server\n" +
+			"also means a reachable database.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Only the listen address matters, so the rest is not validated
+			cfg, ok, err := loadUnvalidatedConfig(cmd, args, opts.register)
+			if !ok {
+				return err
+			}
+			if _, err := setupLogging(cfg); err != nil {
+				return err
+			}
+			if opts.timeout <= 0 || opts.interval <= 0 {
+				return usageError{fmt.Errorf("-timeout and -interval must be positive, got %s and %s", opts.timeout, opts.interval)}
+			}
+
+			target, transport := opts.url, http.DefaultTransport
+			if target == "" {
+				transport = loopbackTransport(cfg.Server)
+				if target, err = loopbackURL(cfg.Server, "/readyz"); err != nil {
+					return usageError{err}
+				}
+			} else if u, err := url.Parse(target); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return usageError{fmt.Errorf("-url %q must be an http or https URL", target)}
+			}
+
+			ctx, stop := signalContext()
+			defer stop()
+			ctx, cancel := context.WithTimeout(ctx, opts.timeout)
+			defer cancel()
+
+			start := time.Now()
+			if err := waitReady(ctx, transport, target, opts.interval); err != nil {
+				return err
+			}
+			slog.Info("Server is ready", "url", target, "elapsed", time.Since(start).Round(time.Millisecond))
+
+			return nil
+		},
+	}, opts.register)
+}
+
+// waitReady polls url through transport every interval until it answers 200, or
+// fails with the last reason it was not ready once ctx is done
+func waitReady(ctx context.Context, transport http.RoundTripper, url string, interval time.Duration) error {
+	client := &http.Client{Transport: transport, Timeout: min(interval, healthcheckTimeout)}
+
+	var last error
+	for {
+		err := probeReady(ctx, client, url)
+		if err == nil {
+			return nil
+		}
+		// A probe cut short by the deadline says nothing about the server
+		if ctx.Err() == nil || last == nil {
+			last = err
+			slog.Info("Waiting for the server", "url", url, "reason", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return fmt.Errorf("%s is not ready: %w", url, last)
+			}
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+func probeReady(ctx context.Context, client *http.Client, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("answered %s", resp.Status)
+	}
+
+	return nil
+}
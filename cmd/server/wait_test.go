@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitReady(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/readyz", r.URL.Path)
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	require.NoError(t, waitReady(context.Background(), http.DefaultTransport, srv.URL+"/readyz", time.Millisecond))
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestWaitReady_TimesOut(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := waitReady(ctx, http.DefaultTransport, srv.URL+"/readyz", time.Millisecond)
+	assert.ErrorContains(t, err, "503 Service Unavailable", "the last reason is reported")
+}
+
+func TestWaitCmd(t *testing.T) {
+	ready := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer ready.Close()
+	assert.Equal(t, 0, execute([]string{"wait", "-url", ready.URL + "/readyz"}))
+
+	ready.Close()
+	assert.Equal(t, exitFailure, execute([]string{"wait", "-url", ready.URL + "/readyz", "-timeout", "50ms", "-interval", "10ms"}))
+
+	assert.Equal(t, exitConfig, execute([]string{"wait", "-url", "app:8080"}))
+	assert.Equal(t, exitConfig, execute([]string{"wait", "-interval", "0s"}))
+}
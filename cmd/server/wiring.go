@@ -0,0 +1,308 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	api "golang-test-task/api"
+	"golang-test-task/internal/config"
+	"golang-test-task/internal/service"
+	"golang-test-task/internal/storage"
+	"golang-test-task/internal/storage/feed"
+	"golang-test-task/internal/storage/index"
+	"golang-test-task/internal/storage/sqlc"
+	"golang-test-task/internal/transport/gqlapi"
+	"golang-test-task/internal/transport/ingest"
+	"golang-test-task/internal/transport/server"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxevent"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// newContainer is the composition root of the serve command: it assembles the
+// storage, the service, the middleware chain, the servers and the ingesters from cfg
+// into an app. Constructors run when something needs what they provide, so each
+// runs in dependency order; options in opts come last, so tests can swap any part,
+// such as the storage, with fx.Replace or fx.Decorate. Resources are closed by the
+// lifecycle in reverse order of opening when the container stops, or when starting
+// it fails.
+func newContainer(ctx context.Context, cfg config.Config, opts ...fx.Option) *fx.App {
+	return fx.New(
+		// Failures are returned, and reported by the caller, so fx only logs for debugging
+		fx.WithLogger(func() fxevent.Logger {
+			logger := &fxevent.SlogLogger{Logger: slog.Default()}
+			logger.UseLogLevel(slog.LevelDebug)
+			logger.UseErrorLevel(slog.LevelDebug)
+			return logger
+		}),
+		fx.Supply(cfg, fx.Annotate(ctx, fx.As(new(context.Context))), new(certificate)),
+		fx.Provide(
+			provideStore,
+			provideNATS,
+			provideFeed,
+			provideQueries,
+			provideMetrics,
+			service.New,
+			provideHandler,
+			provideIngesters,
+			provideApp,
+		),
+		fx.Options(opts...),
+	)
+}
+
+// store is the opened storage, before the wrappers the APIs insert through
+type store struct {
+	sqlc.Querier
+	storage.Closer
+}
+
+// provideStore opens the storage of cfg. serve closes it once the last request has
+// finished; the lifecycle closes it too, in case starting fails, which is harmless
+// since closing a pool twice does nothing.
+func provideStore(ctx context.Context, cfg config.Config, lc fx.Lifecycle) (store, error) {
+	queries, pool, err := storage.Open(ctx, cfg)
+	if err != nil {
+		return store{}, err
+	}
+	lc.Append(fx.StopHook(pool.Close))
+
+	return store{queries, pool}, nil
+}
+
+// provideNATS connects to NATS when configured, and is nil otherwise. The connection
+// is closed after serve, once nothing publishes or consumes any more.
+func provideNATS(cfg config.Config, lc fx.Lifecycle) (*nats.Conn, error) {
+	if !cfg.NATS.Enabled() {
+		return nil, nil
+	}
+	conn, err := connectNATS(cfg.NATS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+	lc.Append(fx.StopHook(conn.Close))
+
+	return conn, nil
+}
+
+// databasePool is the pool of the database of s, which storage without one, such as
+// memory storage, does not have
+func databasePool(s store) (func() *pgxpool.Pool, bool) {
+	pool, ok := s.Closer.(interface{ Current() *pgxpool.Pool })
+	if !ok {
+		return nil, false
+	}
+
+	return pool.Current, true
+}
+
+// provideFeed follows the stored numbers for GET /numbers/stream. With a database it
+// listens for the notifications of its triggers on a connection of its own until ctx
+// is done, so the numbers other instances store are streamed too; otherwise
+// provideQueries wraps the storage in it.
+func provideFeed(ctx context.Context, s store) *feed.Feed {
+	f := feed.New()
+	if current, ok := databasePool(s); ok {
+		go f.Run(ctx, index.FromPool(current))
+	}
+
+	return f
+}
+
+// provideQueries wraps s in what every API inserts through: the feed when it cannot
+// follow the database, the GraphQL notifier, so that subscribers see all numbers,
+// and the NATS publisher. The notifier is nil unless GraphQL is enabled.
+func provideQueries(cfg config.Config, s store, f *feed.Feed, conn *nats.Conn) (sqlc.Querier, *gqlapi.Notifier) {
+	var queries sqlc.Querier = s.Querier
+	if _, ok := databasePool(s); !ok {
+		queries = f.Wrap(queries)
+	}
+
+	var notifier *gqlapi.Notifier
+	if cfg.Server.GraphQL {
+		notifier = gqlapi.NewNotifier(queries)
+		queries = notifier
+	}
+	if conn != nil && cfg.NATS.PublishSubject != "" {
+		queries = ingest.NewPublisher(queries, conn, cfg.NATS.PublishSubject)
+	}
+
+	return queries, notifier
+}
+
+// provideMetrics returns the registry of the Prometheus metrics served at /metrics,
+// with the runtime's and the connection pool's, or nil when server.metrics is off
+func provideMetrics(cfg config.Config, s store) *prometheus.Registry {
+	if !cfg.Server.Metrics {
+		return nil
+	}
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	if pool := storage.NewPoolCollector(s.Closer); pool != nil {
+		reg.MustRegister(pool)
+	}
+
+	return reg
+}
+
+// provideHandler serves the APIs enabled in cfg behind the HTTP middleware
+func provideHandler(cfg config.Config, numbers *service.Numbers, f *feed.Feed, notifier *gqlapi.Notifier, reg *prometheus.Registry) http.Handler {
+	numberServer := server.NewServer(numbers,
+		server.WithWriteResponse(server.WriteResponse(cfg.Server.WriteResponse)),
+		server.WithWindowSize(cfg.Server.WindowSize),
+		server.WithFeed(f),
+		// Without API keys no caller is an admin, so no one may clear the numbers
+		server.WithClear(server.Admin),
+	)
+
+	var middlewares []api.StrictMiddlewareFunc
+	if keys := apiKeys(cfg); len(keys) > 0 {
+		middlewares = append(middlewares, server.Authenticate(server.APIKeys(keys, cfg.Server.PublicReads)))
+	}
+
+	var handler http.Handler
+	if cfg.Server.Router == "chi" {
+		handler = server.NewChiHandler(numberServer, chi.NewRouter(), middlewares...)
+	} else {
+		handler = server.NewHandler(numberServer, middlewares...)
+	}
+	if cfg.Server.Connect {
+		handler = server.WithConnect(handler, server.NewConnectServer(numbers))
+	}
+	if notifier != nil || cfg.Server.DebugVars {
+		mux := http.NewServeMux()
+		if notifier != nil {
+			mux.Handle("/graphql", gqlapi.NewHandler(gqlapi.NewSchema(notifier)))
+		}
+		if cfg.Server.DebugVars {
+			mux.Handle("GET /debug/vars", expvar.Handler())
+		}
+		mux.Handle("/", handler)
+		handler = mux
+	}
+	if secrets := cfg.Server.Secrets(); len(secrets) > 0 {
+		handler = server.VerifySignatures(handler, secrets, cfg.Server.SignatureMaxSkew)
+	}
+	handler = server.CORS(handler, cfg.Server.AllowedOrigins())
+	if reg != nil {
+		handler = server.NewHTTPMetrics(reg).Wrap(handler)
+	}
+	if cfg.Server.AccessLog {
+		handler = server.LogRequests(handler, slog.Default())
+	}
+
+	return server.RealIP(handler, cfg.Server.TrustedProxyPrefixes())
+}
+
+// apiKeys are the keys of cfg as the server checks them, none when every operation
+// is public
+func apiKeys(cfg config.Config) []server.APIKey {
+	keys := make([]server.APIKey, len(cfg.Server.Keys()))
+	for i, key := range cfg.Server.Keys() {
+		keys[i] = server.APIKey{Key: key.Key, ReadOnly: key.ReadOnly, Admin: key.Admin}
+	}
+
+	return keys
+}
+
+// provideIngesters returns a consumer for each message broker and queue configured
+func provideIngesters(ctx context.Context, cfg config.Config, queries sqlc.Querier, conn *nats.Conn) ([]ingester, error) {
+	var ingesters []ingester
+	if conn != nil && cfg.NATS.Subject != "" {
+		ingesters = append(ingesters, ingest.NewNATSSubscriber(queries, conn, cfg.NATS.Subject, cfg.NATS.Queue))
+	}
+	if cfg.AMQP.Enabled() {
+		ingesters = append(ingesters, ingest.NewAMQPConsumer(queries, ingest.AMQPOptions{
+			URL:         cfg.AMQP.URL,
+			Queue:       cfg.AMQP.Queue,
+			Prefetch:    int(cfg.AMQP.Prefetch),
+			MaxAttempts: int(cfg.AMQP.MaxAttempts),
+		}))
+	}
+	if cfg.MQTT.Enabled() {
+		ingesters = append(ingesters, ingest.NewMQTTConsumer(queries, ingest.MQTTOptions{
+			Broker:   cfg.MQTT.Broker,
+			Topic:    cfg.MQTT.Topic,
+			QoS:      byte(cfg.MQTT.QoS),
+			ClientID: cfg.MQTT.ClientID,
+			Username: cfg.MQTT.Username,
+			Password: cfg.MQTT.Password,
+		}))
+	}
+	if cfg.SQS.Enabled() {
+		consumer, err := ingest.NewSQSConsumer(ctx, queries, ingest.SQSOptions{
+			QueueURL:          cfg.SQS.QueueURL,
+			VisibilityTimeout: cfg.SQS.VisibilityTimeout,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up SQS: %w", err)
+		}
+		ingesters = append(ingesters, consumer)
+	}
+
+	return ingesters, nil
+}
+
+// provideApp assembles the app serving handler, with the gRPC server when
+// server.grpc_addr is set; run opens the listeners. Like /readyz, /metrics is served
+// outside the middleware, so scrapes are neither signed, logged nor counted. Streams
+// of numbers end when shutdown starts, rather than holding it up.
+func provideApp(cfg config.Config, handler http.Handler, s store, numbers *service.Numbers, f *feed.Feed, ingesters []ingester, reg *prometheus.Registry, cert *certificate) (*app, error) {
+	if reg != nil {
+		mux := http.NewServeMux()
+		mux.Handle("GET /metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+		mux.Handle("/", handler)
+		handler = mux
+	}
+	a := newApp(handler, s.Closer)
+	a.readiness.Check = func(ctx context.Context) error { return storage.Ping(ctx, s.Closer) }
+	a.srv.RegisterOnShutdown(f.Close)
+	if cfg.Server.TLS() {
+		if err := cert.load(cfg.Server.TLSCertFile, cfg.Server.TLSKeyFile); err != nil {
+			return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+		}
+		// Handshakes read the certificate, so the reloader can swap it on SIGHUP
+		a.srv.TLSConfig = &tls.Config{GetCertificate: cert.get, MinVersion: tls.VersionTLS12}
+	}
+	if cfg.Server.GRPCAddr != "" {
+		var opts []grpc.ServerOption
+		if keys := apiKeys(cfg); len(keys) > 0 {
+			opts = server.GRPCAPIKeys(keys, cfg.Server.PublicReads)
+		}
+		if a.srv.TLSConfig != nil {
+			// The gRPC port is encrypted with the certificate of the HTTP one
+			opts = append(opts, grpc.Creds(credentials.NewTLS(a.srv.TLSConfig)))
+		}
+		a.grpc, a.grpcHealth = server.NewGRPC(server.NewGRPCServer(numbers), opts...)
+	}
+	a.ingesters = ingesters
+	if cfg.Server.Connect {
+		// gRPC clients need HTTP/2, which a plain-text server only speaks when allowed
+		a.srv.Protocols = new(http.Protocols)
+		a.srv.Protocols.SetHTTP1(true)
+		a.srv.Protocols.SetHTTP2(true)
+		a.srv.Protocols.SetUnencryptedHTTP2(true)
+	}
+	a.preStopDelay = cfg.Server.PreStopDelay
+	a.shutdownTimeout = cfg.Server.ShutdownTimeout
+	a.srv.ReadHeaderTimeout = cfg.Server.ReadHeaderTimeout
+	a.srv.ReadTimeout = cfg.Server.ReadTimeout
+	a.srv.WriteTimeout = cfg.Server.WriteTimeout
+	a.srv.IdleTimeout = cfg.Server.IdleTimeout
+	a.srv.MaxHeaderBytes = int(cfg.Server.MaxHeaderBytes)
+	a.srv.SetKeepAlivesEnabled(cfg.Server.KeepAlives)
+
+	return a, nil
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang-test-task/internal/config"
+	"golang-test-task/internal/storage/memstore"
+	"golang-test-task/numberspb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestContainer_ReplacesStorage(t *testing.T) {
+	// The default configuration points at a database that is not running
+	cfg := config.Default()
+	cfg.Server.GRPCAddr = "127.0.0.1:0"
+	fake := memstore.New()
+	pool := &fakePool{record: func(string) {}}
+
+	var a *app
+	container := newContainer(t.Context(), cfg, fx.Replace(store{fake, pool}), fx.Populate(&a))
+	require.NoError(t, container.Start(t.Context()))
+
+	rec := httptest.NewRecorder()
+	a.srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/numbers?number=3", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rows, err := fake.GetAllNumbersSorted(t.Context())
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, int64(3), rows[0].Number)
+	assert.NotNil(t, a.grpc, "the gRPC server is assembled along with the HTTP one")
+
+	require.NoError(t, container.Stop(context.Background()))
+	assert.False(t, pool.closed.Load(), "a replaced storage is closed by whoever opened it")
+}
+
+func TestContainer_ConstructorErrors(t *testing.T) {
+	cfg := memoryConfig(t)
+	cfg.NATS.URL = "nats://" + freeAddr(t)
+
+	err := newContainer(t.Context(), cfg, fx.Populate(new(*app))).Start(t.Context())
+	assert.ErrorContains(t, err, "failed to connect to NATS")
+}
+
+func TestContainer_ConnectionSettings(t *testing.T) {
+	cfg := memoryConfig(t)
+	cfg.Server.MaxHeaderBytes = 4096
+	cfg.Server.DebugVars = true
+
+	var a *app
+	container := newContainer(t.Context(), cfg, fx.Populate(&a))
+	require.NoError(t, container.Start(t.Context()))
+	t.Cleanup(func() { container.Stop(context.Background()) })
+	assert.Equal(t, 4096, a.srv.MaxHeaderBytes)
+	require.NotNil(t, a.srv.ConnState)
+
+	rec := httptest.NewRecorder()
+	a.srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/vars", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"http_connections": {"open":`)
+}
+
+// pingPool is a storage pool whose database answers pings with err
+type pingPool struct {
+	fakePool
+	err error
+}
+
+func (p *pingPool) Ping(context.Context) error { return p.err }
+
+func TestContainer_Observability(t *testing.T) {
+	cfg := memoryConfig(t)
+	pool := &pingPool{fakePool: fakePool{record: func(string) {}}}
+
+	var a *app
+	container := newContainer(t.Context(), cfg, fx.Replace(store{memstore.New(), pool}), fx.Populate(&a))
+	require.NoError(t, container.Start(t.Context()))
+	t.Cleanup(func() { container.Stop(context.Background()) })
+	serve := func(method, target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		a.srv.Handler.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+		return rec
+	}
+
+	rec := serve(http.MethodPost, "/numbers?number=3")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotEmpty(t, rec.Header().Get("X-Request-Id"))
+
+	rec = serve(http.MethodGet, "/metrics")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `http_requests_total{code="200",method="POST",operation="AddNumber"} 1`)
+	assert.Contains(t, rec.Body.String(), "go_goroutines")
+	assert.NotContains(t, rec.Body.String(), "pgxpool_", "memory storage has no pool")
+
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/readyz").Code)
+	pool.err = errors.New("database is down")
+	assert.Equal(t, http.StatusServiceUnavailable, serve(http.MethodGet, "/readyz").Code, "readiness pings the database")
+}
+
+func TestContainer_APIKeys(t *testing.T) {
+	cfg := memoryConfig(t)
+	cfg.Server.APIKeys = strings.Repeat("k", 32) + "," + strings.Repeat("r", 32) + ":read," + strings.Repeat("a", 32) + ":admin"
+	cfg.Server.PublicReads = true
+	cfg.Server.GRPCAddr = "127.0.0.1:0"
+	require.NoError(t, cfg.Validate())
+
+	var a *app
+	container := newContainer(t.Context(), cfg, fx.Populate(&a))
+	require.NoError(t, container.Start(t.Context()))
+	t.Cleanup(func() { container.Stop(context.Background()) })
+	serve := func(method, target, key string) int {
+		req := httptest.NewRequest(method, target, nil)
+		if key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+		rec := httptest.NewRecorder()
+		a.srv.Handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodPost, "/numbers?number=1", ""))
+	assert.Equal(t, http.StatusForbidden, serve(http.MethodPost, "/numbers?number=1", strings.Repeat("r", 32)))
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/numbers?number=1", strings.Repeat("k", 32)))
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/numbers", ""))
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/readyz", ""))
+	assert.Equal(t, http.StatusForbidden, serve(http.MethodDelete, "/numbers", strings.Repeat("k", 32)), "clearing needs an admin key")
+	assert.Equal(t, http.StatusNoContent, serve(http.MethodDelete, "/numbers", strings.Repeat("a", 32)))
+
+	ln := bufconn.Listen(1 << 20)
+	go a.grpc.Serve(ln)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return ln.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := numberspb.NewNumbersServiceClient(conn)
+	_, err = client.AddNumber(t.Context(), &numberspb.AddNumberRequest{Number: 1})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "the gRPC port checks the same keys")
+	_, err = client.ListNumbers(t.Context(), &numberspb.ListNumbersRequest{})
+	assert.NoError(t, err, "and lets reads through as public")
+}
+
+func TestContainer_GRPCServesTLS(t *testing.T) {
+	cfg := memoryConfig(t)
+	cfg.Server.TLSCertFile, cfg.Server.TLSKeyFile = writeCertificate(t)
+	cfg.Server.GRPCAddr = "127.0.0.1:0"
+	require.NoError(t, cfg.Validate())
+
+	var a *app
+	container := newContainer(t.Context(), cfg, fx.Populate(&a))
+	require.NoError(t, container.Start(t.Context()))
+	t.Cleanup(func() { container.Stop(context.Background()) })
+
+	ln := bufconn.Listen(1 << 20)
+	go a.grpc.Serve(ln)
+	dial := func(creds credentials.TransportCredentials) error {
+		conn, err := grpc.NewClient("passthrough:///bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return ln.DialContext(ctx) }),
+			grpc.WithTransportCredentials(creds))
+		require.NoError(t, err)
+		defer conn.Close()
+		_, err = numberspb.NewNumbersServiceClient(conn).AddNumber(t.Context(), &numberspb.AddNumberRequest{Number: 1})
+		return err
+	}
+
+	assert.Error(t, dial(insecure.NewCredentials()), "plain text is refused")
+	assert.NoError(t, dial(credentials.NewTLS(&tls.Config{InsecureSkipVerify: true})), "the certificate of HTTPS is served")
+}
+
+func TestContainer_StreamsNumbers(t *testing.T) {
+	var a *app
+	container := newContainer(t.Context(), memoryConfig(t), fx.Populate(&a))
+	require.NoError(t, container.Start(t.Context()))
+	t.Cleanup(func() { container.Stop(context.Background()) })
+	srv := httptest.NewServer(a.srv.Handler)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/numbers/stream")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	added, err := http.Post(srv.URL+"/numbers?number=42&response=none", "", nil)
+	require.NoError(t, err)
+	added.Body.Close()
+	events := bufio.NewReader(resp.Body)
+	var lines []string
+	for len(lines) < 3 {
+		line, err := events.ReadString('\n')
+		require.NoError(t, err)
+		lines = append(lines, line)
+	}
+	assert.Equal(t, "event: number\n", lines[1])
+	assert.Contains(t, lines[2], `"number":42`, "numbers stored in memory are streamed")
+
+	require.NoError(t, a.srv.Shutdown(t.Context()))
+	_, err = events.ReadString('\n')
+	require.NoError(t, err)
+	_, err = events.ReadString('\n')
+	assert.Error(t, err, "shutting down ends the stream")
+}
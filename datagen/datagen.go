@@ -0,0 +1,154 @@
+// Package datagen generates reproducible streams of numbers with a chosen
+// distribution. Test fixtures and the load harness share it, so a dataset or a
+// load profile is fully described by its Config.
+package datagen
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+)
+
+// Distribution is the shape of the generated values
+type Distribution string
+
+const (
+	// Uniform draws every value in [Min, Max] with equal probability
+	Uniform Distribution = "uniform"
+	// Zipf makes values near Min far more frequent than values near Max,
+	// like a handful of hot keys
+	Zipf Distribution = "zipf"
+	// Clustered draws values around a few fixed centers, producing many duplicates
+	Clustered Distribution = "clustered"
+)
+
+// Distributions lists every supported distribution
+var Distributions = []Distribution{Uniform, Zipf, Clustered}
+
+// ParseDistribution converts a name such as "zipf" into a Distribution
+func ParseDistribution(name string) (Distribution, error) {
+	for _, d := range Distributions {
+		if string(d) == name {
+			return d, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown distribution %q, expected one of %v", name, Distributions)
+}
+
+// Config describes a generated stream
+type Config struct {
+	Distribution Distribution
+	// Seed makes the stream reproducible: equal configs always produce equal streams
+	Seed uint64
+	// Min and Max bound every generated value, inclusive
+	Min, Max int64
+	// ZipfS is the Zipf exponent, must be greater than 1; larger values skew harder
+	ZipfS float64
+	// Clusters is the number of cluster centers for Clustered
+	Clusters int
+	// Spread is how far from its center a Clustered value may land; 0 yields exact duplicates
+	Spread int64
+}
+
+// DefaultConfig returns a uniform stream over the int32 range, which keeps generated
+// tables small enough to compare with their indexes
+func DefaultConfig() Config {
+	return Config{
+		Distribution: Uniform,
+		Seed:         1,
+		Min:          math.MinInt32,
+		Max:          math.MaxInt32,
+		ZipfS:        1.1,
+		Clusters:     10,
+		Spread:       0,
+	}
+}
+
+// Generator produces the values of a Config. It is not safe for concurrent use.
+type Generator struct {
+	cfg     Config
+	rng     *rand.Rand
+	zipf    *rand.Zipf
+	centers []int64
+}
+
+// New validates cfg and returns a generator at the start of its stream
+func New(cfg Config) (*Generator, error) {
+	if cfg.Min > cfg.Max {
+		return nil, fmt.Errorf("min %d is greater than max %d", cfg.Min, cfg.Max)
+	}
+
+	g := &Generator{
+		cfg: cfg,
+		rng: rand.New(rand.NewPCG(cfg.Seed, cfg.Seed)),
+	}
+
+	switch cfg.Distribution {
+	case Uniform:
+	case Zipf:
+		if cfg.ZipfS <= 1 {
+			return nil, fmt.Errorf("zipf exponent must be greater than 1, got %g", cfg.ZipfS)
+		}
+		g.zipf = rand.NewZipf(g.rng, cfg.ZipfS, 1, g.span()-1)
+	case Clustered:
+		if cfg.Clusters <= 0 {
+			return nil, fmt.Errorf("clusters must be positive, got %d", cfg.Clusters)
+		}
+		if cfg.Spread < 0 || cfg.Spread > math.MaxInt64/2 {
+			return nil, fmt.Errorf("spread must be within [0, %d], got %d", int64(math.MaxInt64/2), cfg.Spread)
+		}
+		g.centers = make([]int64, cfg.Clusters)
+		for i := range g.centers {
+			g.centers[i] = g.uniform()
+		}
+	default:
+		return nil, fmt.Errorf("unknown distribution %q, expected one of %v", cfg.Distribution, Distributions)
+	}
+
+	return g, nil
+}
+
+// Next returns the next value of the stream
+func (g *Generator) Next() int64 {
+	switch g.cfg.Distribution {
+	case Zipf:
+		return g.cfg.Min + int64(g.zipf.Uint64())
+	case Clustered:
+		center := g.centers[g.rng.IntN(len(g.centers))]
+		offset := g.rng.Int64N(2*g.cfg.Spread+1) - g.cfg.Spread
+		// Clamped before adding, since the sum may not fit in int64
+		if offset > 0 && center > g.cfg.Max-offset {
+			return g.cfg.Max
+		}
+		if offset < 0 && center < g.cfg.Min-offset {
+			return g.cfg.Min
+		}
+		return center + offset
+	default:
+		return g.uniform()
+	}
+}
+
+// Take returns the next n values of the stream
+func (g *Generator) Take(n int) []int64 {
+	values := make([]int64, n)
+	for i := range values {
+		values[i] = g.Next()
+	}
+
+	return values
+}
+
+func (g *Generator) uniform() int64 {
+	if g.span() == 0 {
+		return int64(g.rng.Uint64())
+	}
+	return g.cfg.Min + int64(g.rng.Uint64N(g.span()))
+}
+
+// span is the number of distinct values in [Min, Max], 0 for the whole int64 range,
+// whose 2^64 values do not fit
+func (g *Generator) span() uint64 {
+	return uint64(g.cfg.Max) - uint64(g.cfg.Min) + 1
+}
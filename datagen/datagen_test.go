@@ -0,0 +1,119 @@
+package datagen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func take(t *testing.T, cfg Config, n int) []int64 {
+	t.Helper()
+
+	g, err := New(cfg)
+	require.NoError(t, err)
+
+	return g.Take(n)
+}
+
+func TestGenerator_Reproducible(t *testing.T) {
+	for _, d := range Distributions {
+		t.Run(string(d), func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Distribution = d
+
+			first := take(t, cfg, 1000)
+			assert.Equal(t, first, take(t, cfg, 1000), "same seed produced a different stream")
+
+			cfg.Seed++
+			assert.NotEqual(t, first, take(t, cfg, 1000), "different seeds produced the same stream")
+		})
+	}
+}
+
+func TestGenerator_StaysInBounds(t *testing.T) {
+	for _, d := range Distributions {
+		t.Run(string(d), func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Distribution = d
+			cfg.Min, cfg.Max = -10, 10
+			cfg.Spread = 5
+
+			for _, v := range take(t, cfg, 10_000) {
+				require.GreaterOrEqual(t, v, int64(-10))
+				require.LessOrEqual(t, v, int64(10))
+			}
+		})
+	}
+}
+
+func TestGenerator_FullRange(t *testing.T) {
+	for _, d := range Distributions {
+		t.Run(string(d), func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Distribution = d
+			cfg.Spread = 1 << 20
+
+			// Must not overflow at the edges of int64
+			assert.Len(t, take(t, cfg, 10_000), 10_000)
+		})
+	}
+}
+
+func TestGenerator_ZipfIsSkewed(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Distribution = Zipf
+	cfg.Min, cfg.Max = 0, 1000
+
+	counts := map[int64]int{}
+	for _, v := range take(t, cfg, 10_000) {
+		counts[v]++
+	}
+
+	assert.Greater(t, counts[0], counts[500]*10, "the smallest value should dominate")
+}
+
+func TestGenerator_ClusteredDuplicates(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Distribution = Clustered
+	cfg.Clusters = 3
+
+	distinct := map[int64]bool{}
+	for _, v := range take(t, cfg, 1000) {
+		distinct[v] = true
+	}
+
+	assert.LessOrEqual(t, len(distinct), 3)
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+	}{
+		{name: "min above max", modify: func(c *Config) { c.Min, c.Max = 1, 0 }},
+		{name: "unknown distribution", modify: func(c *Config) { c.Distribution = "normal" }},
+		{name: "zipf exponent", modify: func(c *Config) { c.Distribution, c.ZipfS = Zipf, 1 }},
+		{name: "no clusters", modify: func(c *Config) { c.Distribution, c.Clusters = Clustered, 0 }},
+		{name: "negative spread", modify: func(c *Config) { c.Distribution, c.Spread = Clustered, -1 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			tt.modify(&cfg)
+
+			_, err := New(cfg)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseDistribution(t *testing.T) {
+	d, err := ParseDistribution("zipf")
+	require.NoError(t, err)
+	assert.Equal(t, Zipf, d)
+
+	_, err = ParseDistribution("gaussian")
+	assert.Error(t, err)
+}
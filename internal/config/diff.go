@@ -0,0 +1,63 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// secretKeys are settings whose values must never be logged
+var secretKeys = map[string]bool{
+	"amqp.url":               true,
+	"mqtt.password":          true,
+	"nats.url":               true,
+	"postgres.dsn":           true,
+	"postgres.password":      true,
+	"server.api_keys":        true,
+	"server.signing_secrets": true,
+	"vault.token":            true,
+}
+
+// Change is a setting whose value differs between two configurations
+type Change struct {
+	Key      string
+	Old, New string
+	// Reloadable changes take effect on SIGHUP; others are ignored until restart
+	Reloadable bool
+}
+
+// Diff lists the settings that differ between old and new.
+// Values of secret settings are redacted.
+func Diff(old, new Config) []Change {
+	var changes []Change
+	for _, o := range options {
+		before, after := format(o.field(&old)), format(o.field(&new))
+		if before == after {
+			continue
+		}
+		if secretKeys[o.key] {
+			before, after = "[redacted]", "[redacted]"
+		}
+		changes = append(changes, Change{Key: o.key, Old: before, New: after, Reloadable: o.reloadable})
+	}
+
+	return changes
+}
+
+// format renders the setting field points at
+func format(field any) string {
+	switch p := field.(type) {
+	case *string:
+		return *p
+	case *bool:
+		return strconv.FormatBool(*p)
+	case *int32:
+		return fmt.Sprint(*p)
+	case *float64:
+		return strconv.FormatFloat(*p, 'g', -1, 64)
+	case *time.Duration:
+		return p.String()
+	default:
+		panic(fmt.Sprintf("config: unsupported setting type %T", field))
+	}
+}
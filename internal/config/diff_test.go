@@ -0,0 +1,24 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	old := validConfig()
+	assert.Empty(t, Diff(old, old))
+
+	updated := old
+	updated.Log.Level = "debug"
+	updated.Server.ShutdownTimeout = 30 * time.Second
+	updated.Postgres.DSN = "postgres://other"
+
+	assert.Equal(t, []Change{
+		{Key: "server.shutdown_timeout", Old: "10s", New: "30s", Reloadable: false},
+		{Key: "postgres.dsn", Old: "[redacted]", New: "[redacted]", Reloadable: false},
+		{Key: "log.level", Old: "info", New: "debug", Reloadable: true},
+	}, Diff(old, updated))
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+const (
+	// dotEnvFile is read from the working directory for local development
+	dotEnvFile = ".env"
+	// appEnv disables the .env file when set to production
+	appEnv = "APP_ENV"
+)
+
+// withDotEnv extends lookupEnv with the variables from the .env file at path.
+// Variables already present in the environment win over the file, and the file
+// is skipped entirely when it does not exist or APP_ENV is production.
+func withDotEnv(path string, lookupEnv func(string) (string, bool)) (func(string) (string, bool), error) {
+	if env, _ := lookupEnv(appEnv); env == "production" {
+		return lookupEnv, nil
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return lookupEnv, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	vars, err := parseDotEnv(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	return func(key string) (string, bool) {
+		if value, ok := lookupEnv(key); ok {
+			return value, true
+		}
+		value, ok := vars[key]
+		return value, ok
+	}, nil
+}
+
+// parseDotEnv reads KEY=value lines. Blank lines and lines starting with # are skipped,
+// an optional "export " prefix is allowed, single-quoted values are taken literally,
+// double-quoted values understand \n, \t, \" and \\, and unquoted values end at " #".
+func parseDotEnv(r io.Reader) (map[string]string, error) {
+	vars := map[string]string{}
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("line %d: expected KEY=value", n)
+		}
+
+		value, err := parseDotEnvValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s: %w", n, key, err)
+		}
+		vars[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return vars, nil
+}
+
+func parseDotEnvValue(raw string) (string, error) {
+	if raw == "" {
+		return "", nil
+	}
+
+	switch quote := raw[0]; quote {
+	case '\'':
+		end := strings.IndexByte(raw[1:], '\'')
+		if end < 0 {
+			return "", errors.New("unterminated single quote")
+		}
+		return raw[1 : end+1], nil
+	case '"':
+		var b strings.Builder
+		for i := 1; i < len(raw); i++ {
+			switch c := raw[i]; {
+			case c == '"':
+				return b.String(), nil
+			case c == '\\' && i+1 < len(raw):
+				i++
+				switch raw[i] {
+				case 'n':
+					b.WriteByte('\n')
+				case 't':
+					b.WriteByte('\t')
+				default:
+					b.WriteByte(raw[i])
+				}
+			default:
+				b.WriteByte(c)
+			}
+		}
+		return "", errors.New("unterminated double quote")
+	default:
+		if i := strings.Index(raw, " #"); i >= 0 {
+			raw = raw[:i]
+		}
+		return strings.TrimSpace(raw), nil
+	}
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"flag"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// presets hold the settings each profile changes from the defaults. The config file,
+// environment and flags still override any of them.
+var presets = map[string]func(c *Config){
+	// dev favours readable output and a browser front end on another port
+	"dev": func(c *Config) {
+		c.Log.Level = "debug"
+		c.Log.Format = "text"
+		c.Server.CORSOrigins = "*"
+	},
+	// prod emits logs for a collector and drops slow or idle clients early
+	"prod": func(c *Config) {
+		c.Log.Format = "json"
+		c.Server.ReadHeaderTimeout = 2 * time.Second
+		c.Server.ReadTimeout = 5 * time.Second
+		c.Server.WriteTimeout = 10 * time.Second
+		c.Server.IdleTimeout = 60 * time.Second
+	},
+}
+
+// profiles are the valid Profile values besides the empty default
+var profiles = []string{"dev", "prod"}
+
+// profileOf finds the profile before any layer is applied, with the usual
+// precedence: the -profile flag, then APP_PROFILE, then the config file
+func profileOf(fs *flag.FlagSet, lookupEnv func(string) (string, bool), file []byte) (string, error) {
+	var fromFlag *string
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "profile" {
+			value := f.Value.String()
+			fromFlag = &value
+		}
+	})
+	if fromFlag != nil {
+		return *fromFlag, nil
+	}
+
+	if value, ok, err := lookupWithFile(lookupEnv, "APP_PROFILE"); err != nil || ok {
+		return value, err
+	}
+
+	// Errors in the file are reported once it is decoded in full
+	var fromFile struct {
+		Profile string `yaml:"profile"`
+	}
+	yaml.Unmarshal(file, &fromFile)
+
+	return fromFile.Profile, nil
+}
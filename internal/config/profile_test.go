@@ -0,0 +1,53 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad_Profiles(t *testing.T) {
+	dev, err := load([]string{"-profile", "dev"}, env{}.lookup, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "dev", dev.Profile)
+	assert.Equal(t, "debug", dev.Log.Level)
+	assert.Equal(t, "text", dev.Log.Format)
+	assert.Equal(t, []string{"*"}, dev.Server.AllowedOrigins())
+
+	prod, err := load(nil, env{"APP_PROFILE": "prod"}.lookup, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "prod", prod.Profile)
+	assert.Equal(t, "json", prod.Log.Format)
+	assert.Equal(t, 5*time.Second, prod.Server.ReadTimeout)
+	assert.Empty(t, prod.Server.AllowedOrigins())
+}
+
+func TestLoad_ProfilePrecedence(t *testing.T) {
+	path := writeFile(t, "profile: prod\nserver:\n  write_timeout: 1m\n")
+
+	cfg, err := load([]string{"-config", path}, env{}.lookup, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "json", cfg.Log.Format, "profile from the file")
+	assert.Equal(t, time.Minute, cfg.Server.WriteTimeout, "the file overrides the preset")
+
+	cfg, err = load([]string{"-config", path}, env{"APP_PROFILE": "dev", "LOG_FORMAT": "json"}.lookup, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "dev", cfg.Profile, "env overrides the file")
+	assert.Equal(t, "*", cfg.Server.CORSOrigins)
+	assert.Equal(t, "json", cfg.Log.Format, "env overrides the preset")
+
+	cfg, err = load([]string{"-profile", "prod", "-log-format", "text"}, env{"APP_PROFILE": "dev"}.lookup, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "prod", cfg.Profile, "the flag overrides env")
+	assert.Equal(t, "text", cfg.Log.Format, "flags override the preset")
+}
+
+func TestLoad_UnknownProfile(t *testing.T) {
+	cfg, err := load([]string{"-profile", "staging"}, env{}.lookup, nil)
+	require.NoError(t, err)
+
+	cfg.Postgres.DSN = validConfig().Postgres.DSN
+	assert.ErrorContains(t, cfg.Validate(), `profile (APP_PROFILE, -profile): "staging" must be one of dev, prod`)
+}
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// fileSuffix marks a variable holding the path of a file with the actual value,
+// the convention for Docker and Kubernetes secrets mounted as files
+const fileSuffix = "_FILE"
+
+// lookupWithFile returns the value of the environment variable key or, when key_FILE
+// is set instead, the contents of the file it names without the trailing newline.
+// Setting both is an error, since it is unclear which one is meant.
+func lookupWithFile(lookupEnv func(string) (string, bool), key string) (string, bool, error) {
+	value, ok := lookupEnv(key)
+	ok = ok && value != ""
+
+	path, fromFile := lookupEnv(key + fileSuffix)
+	if !fromFile || path == "" {
+		return value, ok, nil
+	}
+	if ok {
+		return "", false, fmt.Errorf("%s and %s%s are both set, use only one", key, key, fileSuffix)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read %s%s: %w", key, fileSuffix, err)
+	}
+
+	return strings.TrimRight(string(content), "\r\n"), true, nil
+}
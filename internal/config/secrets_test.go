@@ -0,0 +1,53 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSecret(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "secret")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func TestLoad_FileSecrets(t *testing.T) {
+	cfg, err := load(nil, env{
+		"POSTGRES_DSN_FILE": writeSecret(t, "postgres://app:secret@db/numbers\n"),
+	}.lookup, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "postgres://app:secret@db/numbers", cfg.Postgres.DSN)
+
+	cfg, err = load(nil, env{
+		"POSTGRES_HOST":          "db",
+		"POSTGRES_PASSWORD_FILE": writeSecret(t, "s3cr3t\r\n"),
+	}.lookup, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", cfg.Postgres.Password)
+}
+
+func TestLoad_FileSecretsErrors(t *testing.T) {
+	_, err := load(nil, env{
+		"POSTGRES_DSN":      "postgres://env",
+		"POSTGRES_DSN_FILE": writeSecret(t, "postgres://file"),
+	}.lookup, nil)
+	assert.ErrorContains(t, err, "POSTGRES_DSN and POSTGRES_DSN_FILE are both set")
+
+	_, err = load(nil, env{"POSTGRES_DSN_FILE": "/does/not/exist"}.lookup, nil)
+	assert.ErrorContains(t, err, "failed to read POSTGRES_DSN_FILE")
+}
+
+func TestLoad_FlagsOverrideFileSecrets(t *testing.T) {
+	cfg, err := load([]string{"-postgres-dsn", "postgres://flag"}, env{
+		"POSTGRES_DSN_FILE": writeSecret(t, "postgres://file"),
+	}.lookup, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "postgres://flag", cfg.Postgres.DSN)
+}
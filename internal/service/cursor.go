@@ -0,0 +1,35 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+
+	"golang-test-task/internal/storage/sqlc"
+)
+
+// cursorLen is the length of a decoded cursor: the number, then the id
+const cursorLen = 8 + 16
+
+// EncodeCursor returns the cursor of the page that starts right after row, which
+// every API hands out for Page. Cursors are opaque to clients, who only hand them
+// back.
+func EncodeCursor(row sqlc.Number) string {
+	var cursor [cursorLen]byte
+	binary.BigEndian.PutUint64(cursor[:8], uint64(row.Number))
+	copy(cursor[8:], row.ID.Bytes[:])
+
+	return base64.RawURLEncoding.EncodeToString(cursor[:])
+}
+
+// DecodeCursor returns the number and id of the row a cursor from EncodeCursor was
+// made from, and whether cursor is one
+func DecodeCursor(cursor string) (int64, [16]byte, bool) {
+	var id [16]byte
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(data) != cursorLen {
+		return 0, id, false
+	}
+	copy(id[:], data[8:])
+
+	return int64(binary.BigEndian.Uint64(data[:8])), id, true
+}
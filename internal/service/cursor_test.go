@@ -0,0 +1,26 @@
+package service
+
+import (
+	"testing"
+
+	"golang-test-task/internal/storage/sqlc"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCursor tests that a cursor decodes to the row it was made from, and that
+// anything else is refused
+func TestCursor(t *testing.T) {
+	row := sqlc.Number{Number: -42}
+	row.ID.Bytes = [16]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+
+	number, id, ok := DecodeCursor(EncodeCursor(row))
+	assert.True(t, ok)
+	assert.Equal(t, int64(-42), number)
+	assert.Equal(t, row.ID.Bytes, id)
+
+	for _, cursor := range []string{"!!", "AAAA", ""} {
+		_, _, ok := DecodeCursor(cursor)
+		assert.False(t, ok, cursor)
+	}
+}
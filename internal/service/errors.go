@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// The domain errors the service returns, wrapped with what failed. Transports map
+// them to their status codes with errors.Is; any other error is an internal failure.
+var (
+	// ErrNotFound is returned when the number asked for does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrDuplicate is returned when storing would break a uniqueness constraint
+	ErrDuplicate = errors.New("already exists")
+	// ErrStorageUnavailable is returned when the storage cannot be reached or is
+	// overloaded; retrying later may succeed
+	ErrStorageUnavailable = errors.New("storage unavailable")
+)
+
+// wrap describes a storage failure as the domain error it amounts to. Cancellation
+// by the caller is returned as is, since the caller is gone.
+func wrap(err error, format string, args ...any) error {
+	msg := fmt.Sprintf(format, args...)
+	if kind := classify(err); kind != nil {
+		return fmt.Errorf("%s: %w: %w", msg, kind, err)
+	}
+
+	return fmt.Errorf("%s: %w", msg, err)
+}
+
+// classify returns the domain error of a storage failure, or nil when it is none
+// of them
+func classify(err error) error {
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch {
+		case pgErr.Code == "23505": // unique_violation
+			return ErrDuplicate
+		// Connection exceptions, insufficient resources, and operator intervention
+		// such as a shutdown or a statement timeout
+		case strings.HasPrefix(pgErr.Code, "08"), strings.HasPrefix(pgErr.Code, "53"), strings.HasPrefix(pgErr.Code, "57"):
+			return ErrStorageUnavailable
+		}
+		return nil
+	}
+
+	var connectErr *pgconn.ConnectError
+	var opErr *net.OpError
+	if errors.As(err, &connectErr) || errors.As(err, &opErr) || pgconn.Timeout(err) ||
+		errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return ErrStorageUnavailable
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassify(t *testing.T) {
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{name: "no rows", err: pgx.ErrNoRows, want: ErrNotFound},
+		{name: "unique violation", err: &pgconn.PgError{Code: "23505"}, want: ErrDuplicate},
+		{name: "connection refused", err: fmt.Errorf("failed to connect: %w", refused), want: ErrStorageUnavailable},
+		{name: "too many connections", err: &pgconn.PgError{Code: "53300"}, want: ErrStorageUnavailable},
+		{name: "admin shutdown", err: &pgconn.PgError{Code: "57P01"}, want: ErrStorageUnavailable},
+		{name: "deadline", err: context.DeadlineExceeded, want: ErrStorageUnavailable},
+		{name: "caller cancelled", err: context.Canceled},
+		{name: "syntax error", err: &pgconn.PgError{Code: "42601"}},
+		{name: "unknown", err: errors.New("boom")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, classify(tt.err))
+		})
+	}
+}
+
+func TestWrap(t *testing.T) {
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+
+	err := wrap(refused, "failed to insert number")
+	assert.ErrorIs(t, err, ErrStorageUnavailable)
+	assert.ErrorIs(t, err, refused)
+	assert.EqualError(t, err, "failed to insert number: storage unavailable: dial tcp: connection refused")
+
+	err = wrap(errors.New("boom"), "failed to get numbers")
+	assert.EqualError(t, err, "failed to get numbers: boom")
+}
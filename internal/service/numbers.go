@@ -0,0 +1,210 @@
+// Package service holds what the service does with numbers, independent of the
+// transport a request arrives through and of the storage behind sqlc.Querier. The
+// HTTP, Connect and gRPC APIs all go through it, so they agree on behaviour and
+// error messages.
+package service
+
+import (
+	"context"
+
+	"golang-test-task/internal/storage"
+	"golang-test-task/internal/storage/sqlc"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Numbers stores numbers and lists them sorted by value. Every error it returns is a
+// storage failure, wrapping one of the domain errors when it amounts to one.
+type Numbers struct {
+	queries sqlc.Querier
+}
+
+func New(queries sqlc.Querier) *Numbers {
+	return &Numbers{queries: queries}
+}
+
+// Add stores number and returns every number stored, itself included
+func (s *Numbers) Add(ctx context.Context, number int64) ([]sqlc.Number, error) {
+	if err := s.Insert(ctx, number); err != nil {
+		return nil, err
+	}
+
+	return s.List(ctx)
+}
+
+// Insert stores number
+func (s *Numbers) Insert(ctx context.Context, number int64) error {
+	_, err := s.InsertRow(ctx, number)
+	return err
+}
+
+// InsertRow stores number and returns the row it is stored as
+func (s *Numbers) InsertRow(ctx context.Context, number int64) (sqlc.Number, error) {
+	row, err := s.queries.InsertNumber(ctx, number)
+	if err != nil {
+		return sqlc.Number{}, wrap(err, "failed to insert number")
+	}
+
+	return row, nil
+}
+
+// InsertAll stores every number of numbers, or none of them on failure
+func (s *Numbers) InsertAll(ctx context.Context, numbers []int64) error {
+	if _, err := s.queries.InsertNumbers(ctx, numbers); err != nil {
+		return wrap(err, "failed to insert numbers")
+	}
+
+	return nil
+}
+
+// Delete removes the row of id and returns it, failing with ErrNotFound when no row
+// has the id
+func (s *Numbers) Delete(ctx context.Context, id pgtype.UUID) (sqlc.Number, error) {
+	row, err := s.queries.DeleteNumber(ctx, id)
+	if err != nil {
+		return sqlc.Number{}, wrap(err, "failed to delete number")
+	}
+
+	return row, nil
+}
+
+// Clear removes every stored number
+func (s *Numbers) Clear(ctx context.Context) error {
+	if err := s.queries.DeleteAllNumbers(ctx); err != nil {
+		return wrap(err, "failed to delete numbers")
+	}
+
+	return nil
+}
+
+// List returns every number stored, sorted by value
+func (s *Numbers) List(ctx context.Context) ([]sqlc.Number, error) {
+	numbers, err := s.queries.GetAllNumbersSorted(ctx)
+	if err != nil {
+		return nil, wrap(err, "failed to get numbers")
+	}
+
+	return numbers, nil
+}
+
+// Page returns the numbers params selects, ordered by value and then by id, so that
+// a page can start right after the last row of the one before it
+func (s *Numbers) Page(ctx context.Context, params sqlc.ListNumbersPageParams) ([]sqlc.Number, error) {
+	numbers, err := s.queries.ListNumbersPage(ctx, params)
+	if err != nil {
+		return nil, wrap(err, "failed to list numbers")
+	}
+
+	return numbers, nil
+}
+
+// Window returns row with up to size stored rows on each side of it, in the order of
+// Page. It reads no more than that, however many numbers are stored.
+func (s *Numbers) Window(ctx context.Context, row sqlc.Number, size int32) ([]sqlc.Number, error) {
+	numbers, err := s.queries.NumberWindow(ctx, sqlc.NumberWindowParams{Number: row.Number, ID: row.ID, Size: size})
+	if err != nil {
+		return nil, wrap(err, "failed to get numbers")
+	}
+
+	return numbers, nil
+}
+
+// Values returns every number stored, sorted, without its id and creation time
+func (s *Numbers) Values(ctx context.Context) ([]int64, error) {
+	numbers, err := storage.SortedNumbers(ctx, s.queries)
+	if err != nil {
+		return nil, wrap(err, "failed to get numbers")
+	}
+
+	return numbers, nil
+}
+
+// Count returns how many numbers are stored
+func (s *Numbers) Count(ctx context.Context) (int64, error) {
+	count, err := s.queries.CountNumbers(ctx)
+	if err != nil {
+		return 0, wrap(err, "failed to count numbers")
+	}
+
+	return count, nil
+}
+
+// Rank returns how many stored numbers are smaller than number, which is where it
+// stands in the sorted list
+func (s *Numbers) Rank(ctx context.Context, number int64) (int64, error) {
+	rank, err := s.queries.RankNumber(ctx, number)
+	if err != nil {
+		return 0, wrap(err, "failed to rank number")
+	}
+
+	return rank, nil
+}
+
+// Position returns how many stored rows come before row in the order of Page, which is
+// where row stands among copies of its number too
+func (s *Numbers) Position(ctx context.Context, row sqlc.Number) (int64, error) {
+	position, err := s.queries.RankRow(ctx, sqlc.RankRowParams{Number: row.Number, ID: row.ID})
+	if err != nil {
+		return 0, wrap(err, "failed to rank number")
+	}
+
+	return position, nil
+}
+
+// Contains reports whether number is stored
+func (s *Numbers) Contains(ctx context.Context, number int64) (bool, error) {
+	found, err := s.queries.ContainsNumber(ctx, number)
+	if err != nil {
+		return false, wrap(err, "failed to look up number")
+	}
+
+	return found, nil
+}
+
+// Stats aggregates the stored numbers where they are stored, with the percentiles at
+// fractions, each between 0 and 1, in the same order
+func (s *Numbers) Stats(ctx context.Context, fractions []float64) (sqlc.NumberStatsRow, error) {
+	stats, err := s.queries.NumberStats(ctx, fractions)
+	if err != nil {
+		return sqlc.NumberStatsRow{}, wrap(err, "failed to aggregate numbers")
+	}
+
+	return stats, nil
+}
+
+// Stream calls yield with every number stored, sorted by value, reading them from the
+// storage one at a time where it can. It stops at the first error yield returns and
+// returns it as it is; any other error is a storage failure.
+func (s *Numbers) Stream(ctx context.Context, yield func(number int64) error) error {
+	var yieldErr error
+	err := storage.StreamNumbersSorted(ctx, s.queries, func(number int64) error {
+		yieldErr = yield(number)
+		return yieldErr
+	})
+	if yieldErr != nil {
+		return yieldErr
+	}
+	if err != nil {
+		return wrap(err, "failed to get numbers")
+	}
+
+	return nil
+}
+
+// StreamRows calls yield with every row stored, sorted by value, reading them from the
+// storage a batch at a time where it can. Errors are returned as by Stream.
+func (s *Numbers) StreamRows(ctx context.Context, yield func(row sqlc.Number) error) error {
+	var yieldErr error
+	err := storage.StreamRowsSorted(ctx, s.queries, func(row sqlc.Number) error {
+		yieldErr = yield(row)
+		return yieldErr
+	})
+	if yieldErr != nil {
+		return yieldErr
+	}
+	if err != nil {
+		return wrap(err, "failed to get numbers")
+	}
+
+	return nil
+}
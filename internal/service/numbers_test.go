@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"golang-test-task/internal/storage/memstore"
+	"golang-test-task/internal/storage/sqlc"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingStore fails the queries it has an error for
+type failingStore struct {
+	*memstore.Store
+	insertErr, listErr error
+}
+
+func (f failingStore) InsertNumber(ctx context.Context, number int64) (sqlc.Number, error) {
+	if f.insertErr != nil {
+		return sqlc.Number{}, f.insertErr
+	}
+	return f.Store.InsertNumber(ctx, number)
+}
+
+func (f failingStore) GetAllNumbersSorted(ctx context.Context) ([]sqlc.Number, error) {
+	if f.listErr != nil {
+		return nil, f.listErr
+	}
+	return f.Store.GetAllNumbersSorted(ctx)
+}
+
+func (f failingStore) StreamNumbersSorted(ctx context.Context, yield func(int64) error) error {
+	if f.listErr != nil {
+		return f.listErr
+	}
+	return f.Store.StreamNumbersSorted(ctx, yield)
+}
+
+func (f failingStore) ContainsNumber(ctx context.Context, number int64) (bool, error) {
+	if f.listErr != nil {
+		return false, f.listErr
+	}
+	return f.Store.ContainsNumber(ctx, number)
+}
+
+func values(rows []sqlc.Number) []int64 {
+	numbers := make([]int64, len(rows))
+	for i, row := range rows {
+		numbers[i] = row.Number
+	}
+	return numbers
+}
+
+func TestNumbers_Add(t *testing.T) {
+	ctx := context.Background()
+	s := New(memstore.New())
+
+	for _, n := range []int64{3, -1} {
+		_, err := s.Add(ctx, n)
+		require.NoError(t, err)
+	}
+	rows, err := s.Add(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{-1, 2, 3}, values(rows))
+
+	rows, err = s.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{-1, 2, 3}, values(rows))
+
+	numbers, err := s.Values(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{-1, 2, 3}, numbers)
+
+	numbers, err = New(memstore.New()).Values(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{}, numbers, "an empty list is not nil, so it encodes as []")
+}
+
+func TestNumbers_Delete(t *testing.T) {
+	ctx := context.Background()
+	s := New(memstore.New())
+	for _, n := range []int64{3, -1, 3} {
+		require.NoError(t, s.Insert(ctx, n))
+	}
+	rows, err := s.List(ctx)
+	require.NoError(t, err)
+
+	deleted, err := s.Delete(ctx, rows[1].ID)
+	require.NoError(t, err)
+	assert.Equal(t, rows[1], deleted)
+	numbers, err := s.Values(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{-1, 3}, numbers)
+
+	_, err = s.Delete(ctx, rows[1].ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.EqualError(t, err, "failed to delete number: not found: no rows in result set")
+
+	require.NoError(t, s.Clear(ctx))
+	count, err := s.Count(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestNumbers_Stats(t *testing.T) {
+	ctx := context.Background()
+	s := New(memstore.New())
+	stats, err := s.Stats(ctx, []float64{0.5})
+	require.NoError(t, err)
+	assert.Equal(t, sqlc.NumberStatsRow{Percentiles: []float64{}}, stats, "an empty table has only a count")
+
+	require.NoError(t, s.InsertAll(ctx, []int64{4, 1, 10, 3, math.MaxInt64, math.MaxInt64}))
+	stats, err = s.Stats(ctx, []float64{0, 0.9, 1})
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), stats.Count)
+	assert.Equal(t, int64(1), stats.Min)
+	assert.Equal(t, int64(math.MaxInt64), stats.Max)
+	assert.Equal(t, 2*float64(math.MaxInt64)+18, stats.Sum, "the sum overflows no int64")
+	assert.Equal(t, stats.Sum/6, stats.Mean)
+	assert.Equal(t, 7.0, stats.Median)
+	assert.Equal(t, []float64{1, math.MaxInt64, math.MaxInt64}, stats.Percentiles)
+}
+
+func TestNumbers_StorageErrors(t *testing.T) {
+	ctx := context.Background()
+	down := errors.New("connection refused")
+
+	_, err := New(failingStore{Store: memstore.New(), insertErr: down}).Add(ctx, 1)
+	assert.ErrorIs(t, err, down)
+	assert.EqualError(t, err, "failed to insert number: connection refused")
+
+	_, err = New(failingStore{Store: memstore.New(), listErr: down}).Add(ctx, 1)
+	assert.EqualError(t, err, "failed to get numbers: connection refused")
+
+	_, err = New(failingStore{Store: memstore.New(), listErr: down}).Values(ctx)
+	assert.EqualError(t, err, "failed to get numbers: connection refused")
+}
+
+func TestNumbers_Stream(t *testing.T) {
+	ctx := context.Background()
+	s := New(memstore.New())
+	for _, n := range []int64{3, -1, 2} {
+		require.NoError(t, s.Insert(ctx, n))
+	}
+
+	var streamed []int64
+	require.NoError(t, s.Stream(ctx, func(number int64) error {
+		streamed = append(streamed, number)
+		return nil
+	}))
+	assert.Equal(t, []int64{-1, 2, 3}, streamed)
+
+	stop := errors.New("client went away")
+	err := s.Stream(ctx, func(int64) error { return stop })
+	assert.Same(t, stop, err, "errors of yield are not storage failures")
+
+	err = New(failingStore{Store: memstore.New(), listErr: errors.New("connection refused")}).Stream(ctx, func(int64) error { return nil })
+	assert.EqualError(t, err, "failed to get numbers: connection refused")
+}
+
+// TestNumbers_RankAndContains tests that lookups are answered by the storage
+func TestNumbers_RankAndContains(t *testing.T) {
+	ctx := context.Background()
+	store := memstore.New()
+	for _, n := range []int64{5, -2, 5, 9} {
+		_, err := store.InsertNumber(ctx, n)
+		require.NoError(t, err)
+	}
+
+	s := New(store)
+	for number, want := range map[int64]int64{-3: 0, -2: 0, 0: 1, 5: 1, 6: 3, 9: 3, 10: 4} {
+		rank, err := s.Rank(ctx, number)
+		require.NoError(t, err)
+		assert.Equal(t, want, rank, "rank of %d", number)
+	}
+	for number, want := range map[int64]bool{-2: true, 5: true, 9: true, 0: false, 10: false} {
+		found, err := s.Contains(ctx, number)
+		require.NoError(t, err)
+		assert.Equal(t, want, found, "contains %d", number)
+	}
+
+	_, err := New(failingStore{Store: memstore.New(), listErr: errors.New("connection refused")}).Contains(ctx, 1)
+	assert.EqualError(t, err, "failed to look up number: connection refused")
+}
+
+// TestNumbers_Position tests that a row is ranked among copies of its number in the
+// order of Page
+func TestNumbers_Position(t *testing.T) {
+	ctx := context.Background()
+	s := New(memstore.New())
+	for _, n := range []int64{3, 1, 3, 3, 5, 3} {
+		_, err := s.InsertRow(ctx, n)
+		require.NoError(t, err)
+	}
+
+	rows, err := s.Page(ctx, sqlc.ListNumbersPageParams{MinNumber: math.MinInt64, MaxNumber: math.MaxInt64, PageLimit: 10})
+	require.NoError(t, err)
+	for i, row := range rows {
+		position, err := s.Position(ctx, row)
+		require.NoError(t, err)
+		assert.Equal(t, int64(i), position, "position of %d", row.Number)
+	}
+}
+
+// TestNumbers_Window tests that a window is the row with its neighbours, cut short at
+// either end of the list
+func TestNumbers_Window(t *testing.T) {
+	ctx := context.Background()
+	s := New(memstore.New())
+	rows := make(map[int64]sqlc.Number)
+	for _, n := range []int64{4, 1, 3, 5, 2} {
+		row, err := s.InsertRow(ctx, n)
+		require.NoError(t, err)
+		rows[n] = row
+	}
+
+	for number, want := range map[int64][]int64{1: {1, 2, 3}, 3: {1, 2, 3, 4, 5}, 5: {3, 4, 5}} {
+		window, err := s.Window(ctx, rows[number], 2)
+		require.NoError(t, err)
+		assert.Equal(t, want, values(window), "window of %d", number)
+	}
+}
@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"golang-test-task/internal/storage/sqlc"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// StreamRowsSorted calls yield with every stored row in the order of
+// GetAllNumbersSorted, stopping at the first error yield returns and returning it.
+// Queries that cannot stream whole rows yield them from GetAllNumbersSorted.
+func StreamRowsSorted(ctx context.Context, queries sqlc.Querier, yield func(row sqlc.Number) error) error {
+	if s, ok := queries.(interface {
+		StreamRowsSorted(ctx context.Context, yield func(row sqlc.Number) error) error
+	}); ok {
+		return s.StreamRowsSorted(ctx, yield)
+	}
+
+	rows, err := queries.GetAllNumbersSorted(ctx)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := yield(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// cursorFetch is how many rows a cursor hands over at a time
+const cursorFetch = 10_000
+
+// declareRowsSorted declares a cursor over the rows in the order of
+// GetAllNumbersSorted. A cursor is planned to be read a batch at a time, and the
+// database holds only the batch being fetched.
+const declareRowsSorted = `DECLARE rows_sorted NO SCROLL CURSOR FOR SELECT id, number, created_at FROM numbers ORDER BY number ASC`
+
+var fetchRowsSorted = fmt.Sprintf(`FETCH FORWARD %d FROM rows_sorted`, cursorFetch)
+
+// StreamRowsSorted reads the rows through a server-side cursor, cursorFetch at a time,
+// so an export of any size takes a batch of memory in the database and here. The
+// cursor lives in a transaction of its own, which also makes the rows one snapshot.
+func (q *Queries) StreamRowsSorted(ctx context.Context, yield func(row sqlc.Number) error) error {
+	beginner, ok := q.db.(interface {
+		Begin(ctx context.Context) (pgx.Tx, error)
+	})
+	if !ok {
+		return StreamRowsSorted(ctx, q.Queries, yield)
+	}
+
+	tx, err := beginner.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	// Nothing is written, so the transaction is only ever rolled back, which also
+	// closes the cursor
+	defer tx.Rollback(context.WithoutCancel(ctx))
+
+	if _, err := tx.Exec(ctx, declareRowsSorted); err != nil {
+		return fmt.Errorf("failed to declare cursor: %w", err)
+	}
+	for {
+		rows, err := tx.Query(ctx, fetchRowsSorted)
+		if err != nil {
+			return err
+		}
+		fetched, err := yieldRows(rows, yield)
+		if err != nil || fetched < cursorFetch {
+			return err
+		}
+	}
+}
+
+// yieldRows yields every row of rows, closing it, and returns how many there were
+func yieldRows(rows pgx.Rows, yield func(row sqlc.Number) error) (int, error) {
+	defer rows.Close()
+
+	fetched := 0
+	for rows.Next() {
+		var row sqlc.Number
+		if err := rows.Scan(&row.ID, &row.Number, &row.CreatedAt); err != nil {
+			return fetched, err
+		}
+		fetched++
+		if err := yield(row); err != nil {
+			return fetched, err
+		}
+	}
+
+	return fetched, rows.Err()
+}
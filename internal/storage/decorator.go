@@ -0,0 +1,30 @@
+package storage
+
+import (
+	"context"
+
+	"golang-test-task/internal/storage/sqlc"
+)
+
+// Decorator hands every query to the sqlc.Querier it embeds, along with the ways of
+// reading the numbers that are not part of sqlc.Querier, such as streaming them,
+// which embedding the interface alone would hide. Queries that add to some queries,
+// such as publishing what is inserted, embed it and override only those.
+type Decorator struct {
+	sqlc.Querier
+}
+
+// StreamRowsSorted streams the rows of the wrapped queries
+func (d Decorator) StreamRowsSorted(ctx context.Context, yield func(row sqlc.Number) error) error {
+	return StreamRowsSorted(ctx, d.Querier, yield)
+}
+
+// StreamNumbersSorted streams the numbers of the wrapped queries
+func (d Decorator) StreamNumbersSorted(ctx context.Context, yield func(number int64) error) error {
+	return StreamNumbersSorted(ctx, d.Querier, yield)
+}
+
+// SortedNumbers lists the numbers of the wrapped queries
+func (d Decorator) SortedNumbers(ctx context.Context) ([]int64, error) {
+	return SortedNumbers(ctx, d.Querier)
+}
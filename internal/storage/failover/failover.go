@@ -0,0 +1,180 @@
+// Package failover spreads a PostgreSQL connection over a primary and warm standbys.
+// It connects to the first healthy database in order and moves to the next one when
+// the current database keeps failing health checks.
+package failover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"golang-test-task/internal/storage/sqlc"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Conn is a connection pool to one database; *pgxpool.Pool in production
+type Conn interface {
+	sqlc.DBTX
+	Ping(ctx context.Context) error
+	Reset()
+	Close()
+}
+
+// OpenFunc opens a pool for dsn and checks that the database answers
+type OpenFunc[C Conn] func(ctx context.Context, dsn string) (C, error)
+
+// Pool routes queries to the current database. It is a sqlc.DBTX.
+type Pool[C Conn] struct {
+	dsns      []string
+	open      OpenFunc[C]
+	interval  time.Duration
+	threshold int
+
+	mu      sync.RWMutex
+	current C
+	index   int
+}
+
+var _ sqlc.DBTX = (*Pool[Conn])(nil)
+
+// New connects to the first of dsns that open succeeds for. Run checks the current
+// database every interval and fails over after threshold consecutive failures.
+// Errors name databases by position, since DSNs may contain passwords.
+func New[C Conn](ctx context.Context, dsns []string, open OpenFunc[C], interval time.Duration, threshold int) (*Pool[C], error) {
+	p := &Pool[C]{dsns: dsns, open: open, interval: interval, threshold: threshold}
+
+	var errs []error
+	for i, dsn := range dsns {
+		c, err := open(ctx, dsn)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("database %d of %d: %w", i+1, len(dsns), err))
+			continue
+		}
+		if i > 0 {
+			slog.Warn("Primary database unavailable, using a fallback", "database", i+1, "error", errors.Join(errs...))
+		}
+		p.current, p.index = c, i
+		return p, nil
+	}
+	if len(errs) == 0 {
+		return nil, errors.New("no database configured")
+	}
+
+	return nil, errors.Join(errs...)
+}
+
+// Current returns the pool of the database in use
+func (p *Pool[C]) Current() C {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.current
+}
+
+func (p *Pool[C]) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	return p.Current().Exec(ctx, sql, args...)
+}
+
+func (p *Pool[C]) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return p.Current().Query(ctx, sql, args...)
+}
+
+func (p *Pool[C]) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return p.Current().QueryRow(ctx, sql, args...)
+}
+
+// Ping checks that the current database answers
+func (p *Pool[C]) Ping(ctx context.Context) error {
+	return p.Current().Ping(ctx)
+}
+
+// Reset replaces the connections of the current pool as they are released
+func (p *Pool[C]) Reset() {
+	p.Current().Reset()
+}
+
+// Close closes the current pool
+func (p *Pool[C]) Close() {
+	p.Current().Close()
+}
+
+// Run health-checks the current database until ctx is done. With a single DSN there
+// is nothing to fail over to and it returns immediately.
+func (p *Pool[C]) Run(ctx context.Context) {
+	if len(p.dsns) < 2 {
+		return
+	}
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	failures := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		pingCtx, cancel := context.WithTimeout(ctx, p.interval)
+		err := p.Current().Ping(pingCtx)
+		cancel()
+		if err == nil || ctx.Err() != nil {
+			failures = 0
+			continue
+		}
+
+		failures++
+		slog.Warn("Database health check failed", "database", p.position(), "failures", failures, "error", err)
+		if failures >= p.threshold && p.failover(ctx) {
+			failures = 0
+		}
+	}
+}
+
+// failover switches to the first healthy database after the current one, wrapping
+// around, and reports whether it found one
+func (p *Pool[C]) failover(ctx context.Context) bool {
+	from := p.position() - 1
+
+	for step := 1; step < len(p.dsns); step++ {
+		i := (from + step) % len(p.dsns)
+		c, err := p.open(ctx, p.dsns[i])
+		if err != nil {
+			slog.Warn("Standby database unavailable", "database", i+1, "error", err)
+			continue
+		}
+		if ctx.Err() != nil {
+			// Run was stopped while the standby opened, and the pool may be closed
+			// already, so nothing would close this one
+			c.Close()
+			return false
+		}
+
+		p.mu.Lock()
+		old := p.current
+		p.current, p.index = c, i
+		p.mu.Unlock()
+
+		slog.Warn("Failed over to another database", "from", from+1, "to", i+1)
+		// Close waits for queries still running on the old pool
+		go old.Close()
+		return true
+	}
+
+	slog.Error("No standby database available, staying on the current one", "database", from+1)
+	return false
+}
+
+// position is the 1-based index of the current database in the DSN list
+func (p *Pool[C]) position() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.index + 1
+}
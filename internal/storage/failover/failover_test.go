@@ -0,0 +1,123 @@
+package failover
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeConn is a database whose health the test switches; Exec reports its name
+type fakeConn struct {
+	name    string
+	healthy atomic.Bool
+	closed  atomic.Bool
+	resets  atomic.Int32
+}
+
+func (c *fakeConn) Exec(context.Context, string, ...any) (pgconn.CommandTag, error) {
+	return pgconn.NewCommandTag(c.name), nil
+}
+
+func (c *fakeConn) Query(context.Context, string, ...any) (pgx.Rows, error) { return nil, nil }
+
+func (c *fakeConn) QueryRow(context.Context, string, ...any) pgx.Row { return nil }
+
+func (c *fakeConn) Ping(context.Context) error {
+	if !c.healthy.Load() {
+		return errors.New(c.name + " is down")
+	}
+	return nil
+}
+
+func (c *fakeConn) Reset() { c.resets.Add(1) }
+
+func (c *fakeConn) Close() { c.closed.Store(true) }
+
+// databases returns healthy fake databases and an OpenFunc that fails for unhealthy ones
+func databases(names ...string) (map[string]*fakeConn, OpenFunc[*fakeConn]) {
+	dbs := map[string]*fakeConn{}
+	for _, name := range names {
+		dbs[name] = &fakeConn{name: name}
+		dbs[name].healthy.Store(true)
+	}
+
+	return dbs, func(ctx context.Context, dsn string) (*fakeConn, error) {
+		db := dbs[dsn]
+		if err := db.Ping(ctx); err != nil {
+			return nil, err
+		}
+		return db, nil
+	}
+}
+
+func current(t *testing.T, p *Pool[*fakeConn]) string {
+	t.Helper()
+
+	tag, err := p.Exec(context.Background(), "select 1")
+	require.NoError(t, err)
+
+	return tag.String()
+}
+
+func TestNew_FirstHealthy(t *testing.T) {
+	dbs, open := databases("primary", "standby")
+
+	p, err := New(context.Background(), []string{"primary", "standby"}, open, time.Second, 3)
+	require.NoError(t, err)
+	assert.Equal(t, "primary", current(t, p))
+
+	dbs["primary"].healthy.Store(false)
+	p, err = New(context.Background(), []string{"primary", "standby"}, open, time.Second, 3)
+	require.NoError(t, err)
+	assert.Equal(t, "standby", current(t, p))
+
+	dbs["standby"].healthy.Store(false)
+	_, err = New(context.Background(), []string{"primary", "standby"}, open, time.Second, 3)
+	assert.ErrorContains(t, err, "database 1 of 2")
+	assert.ErrorContains(t, err, "database 2 of 2")
+}
+
+func TestRun_FailsOverOnSustainedFailure(t *testing.T) {
+	dbs, open := databases("primary", "standby")
+	p, err := New(context.Background(), []string{"primary", "standby"}, open, 5*time.Millisecond, 3)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go p.Run(ctx)
+
+	dbs["primary"].healthy.Store(false)
+	require.Eventually(t, func() bool { return current(t, p) == "standby" }, 5*time.Second, 5*time.Millisecond)
+	require.Eventually(t, dbs["primary"].closed.Load, 5*time.Second, 5*time.Millisecond, "the old pool is closed")
+
+	p.Reset()
+	assert.Equal(t, int32(1), dbs["standby"].resets.Load(), "Reset reaches the current pool")
+
+	// The list wraps around once the standby fails too
+	dbs["primary"].healthy.Store(true)
+	dbs["standby"].healthy.Store(false)
+	require.Eventually(t, func() bool { return current(t, p) == "primary" }, 5*time.Second, 5*time.Millisecond)
+}
+
+func TestRun_StaysWithoutHealthyStandby(t *testing.T) {
+	dbs, open := databases("primary", "standby")
+	p, err := New(context.Background(), []string{"primary", "standby"}, open, 5*time.Millisecond, 2)
+	require.NoError(t, err)
+
+	dbs["primary"].healthy.Store(false)
+	dbs["standby"].healthy.Store(false)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	p.Run(ctx)
+
+	assert.Equal(t, "primary", current(t, p))
+	assert.False(t, dbs["primary"].closed.Load())
+}
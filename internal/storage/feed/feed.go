@@ -0,0 +1,249 @@
+// Package feed tells subscribers of every number stored or deleted, by any instance.
+// It follows the notifications the triggers of the numbers table send on every change,
+// so all replicas see the same numbers whichever stored them; storage without a
+// database is followed by wrapping its queries instead.
+package feed
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"golang-test-task/internal/storage"
+	"golang-test-task/internal/storage/index"
+	"golang-test-task/internal/storage/sqlc"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// subscriberBuffer is how many events a subscriber may fall behind before it is
+// dropped. The triggers of the numbers table send a statement of more rows than this
+// as one empty payload, so that no single change drops every subscriber; keep the two
+// equal.
+const subscriberBuffer = 256
+
+// maxRetry bounds the wait between attempts to listen again
+const maxRetry = 30 * time.Second
+
+// Event is a change of the stored numbers
+type Event struct {
+	// Row is the number stored, or deleted if Deleted is set, unless Reset is set
+	Row sqlc.Number
+	// Deleted tells that Row was deleted rather than stored
+	Deleted bool
+	// Reset tells that the numbers changed in a way not sent row by row: a delete of
+	// every number, a restore, an insert or delete of over subscriberBuffer rows at
+	// once, or notifications missed while the feed was reconnecting. Subscribers that
+	// keep the numbers read them again.
+	Reset bool
+}
+
+// Feed fans the changes of the stored numbers out to subscribers. It is safe for
+// concurrent use.
+type Feed struct {
+	mu          sync.Mutex
+	subscribers map[chan Event]struct{}
+	closed      bool
+
+	retry time.Duration
+}
+
+// New returns a feed without subscribers, which hears of no change until Run
+// follows the table or Wrap the queries numbers are stored through
+func New() *Feed {
+	return &Feed{subscribers: make(map[chan Event]struct{}), retry: time.Second}
+}
+
+// Subscribe returns the changes from now on. The channel is closed once ctx is done,
+// once the subscriber falls subscriberBuffer events behind, or once the feed is
+// closed.
+func (f *Feed) Subscribe(ctx context.Context) <-chan Event {
+	ch := make(chan Event, subscriberBuffer)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		close(ch)
+		return ch
+	}
+	f.subscribers[ch] = struct{}{}
+
+	go func() {
+		<-ctx.Done()
+		f.unsubscribe(ch)
+	}()
+
+	return ch
+}
+
+func (f *Feed) unsubscribe(ch chan Event) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.subscribers[ch]; ok {
+		delete(f.subscribers, ch)
+		close(ch)
+	}
+}
+
+// Close ends every subscription, and those made later at once, so that long-lived
+// responses end when the server shuts down
+func (f *Feed) Close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.closed = true
+	for ch := range f.subscribers {
+		delete(f.subscribers, ch)
+		close(ch)
+	}
+}
+
+// publish sends events to every subscriber
+func (f *Feed) publish(events ...Event) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+subscribers:
+	for ch := range f.subscribers {
+		for _, event := range events {
+			select {
+			case ch <- event:
+			default:
+				// Closing tells a subscriber that is too slow it missed changes, rather
+				// than skipping them silently or holding up the others
+				delete(f.subscribers, ch)
+				close(ch)
+				continue subscribers
+			}
+		}
+	}
+}
+
+// Run follows the numbers table until ctx is done, listening on a connection of
+// connect. When the connection fails it listens again on a new one, and subscribers
+// are sent a Reset for what they may have missed meanwhile.
+func (f *Feed) Run(ctx context.Context, connect index.Connect) {
+	retry, reconnect := f.retry, false
+	for {
+		err := f.follow(ctx, connect, func() {
+			retry = f.retry
+			if reconnect {
+				f.publish(Event{Reset: true})
+			}
+		})
+		if ctx.Err() != nil {
+			return
+		}
+
+		reconnect = true
+		slog.Warn("Feed of stored numbers lost its connection", "retry_in", retry, "error", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retry):
+		}
+		retry = min(2*retry, maxRetry)
+	}
+}
+
+// follow listens on a new connection and publishes every change notified until it
+// fails. listening is called once the connection listens.
+func (f *Feed) follow(ctx context.Context, connect index.Connect, listening func()) error {
+	conn, release, err := connect(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer release()
+
+	if _, err := conn.Exec(ctx, "listen "+index.Channel); err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	listening()
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to wait for notifications: %w", err)
+		}
+		if notification.Payload == "" {
+			f.publish(Event{Reset: true})
+			continue
+		}
+
+		notice, err := index.ParseNotice(notification.Payload)
+		if err != nil {
+			// One bad payload says nothing of the others
+			slog.Error("Failed to decode notification", "error", err)
+			f.publish(Event{Reset: true})
+			continue
+		}
+		f.publish(Event{Row: notice.Number, Deleted: notice.Deleted})
+	}
+}
+
+// Wrap returns queries that publish every number stored through them, for storage
+// whose changes are not notified, such as memory storage
+func (f *Feed) Wrap(queries sqlc.Querier) sqlc.Querier {
+	return &publisher{Decorator: storage.Decorator{Querier: queries}, feed: f}
+}
+
+// publisher is a sqlc.Querier that publishes the numbers stored through it
+type publisher struct {
+	storage.Decorator
+	feed *Feed
+}
+
+// InsertNumber inserts number and, once it is stored, publishes it
+func (p *publisher) InsertNumber(ctx context.Context, number int64) (sqlc.Number, error) {
+	row, err := p.Querier.InsertNumber(ctx, number)
+	if err != nil {
+		return row, err
+	}
+	p.feed.publish(Event{Row: row})
+
+	return row, nil
+}
+
+// InsertNumbers inserts numbers and, once they are all stored, publishes each. A batch
+// longer than subscriberBuffer would fill the buffer of every subscriber on its own,
+// so it is published as a reset instead.
+func (p *publisher) InsertNumbers(ctx context.Context, numbers []int64) ([]sqlc.Number, error) {
+	rows, err := p.Querier.InsertNumbers(ctx, numbers)
+	if err != nil {
+		return rows, err
+	}
+	if len(rows) > subscriberBuffer {
+		p.feed.publish(Event{Reset: true})
+		return rows, nil
+	}
+	events := make([]Event, len(rows))
+	for i, row := range rows {
+		events[i] = Event{Row: row}
+	}
+	p.feed.publish(events...)
+
+	return rows, nil
+}
+
+// DeleteNumber deletes the row with id and, once it is gone, publishes it as deleted
+func (p *publisher) DeleteNumber(ctx context.Context, id pgtype.UUID) (sqlc.Number, error) {
+	row, err := p.Querier.DeleteNumber(ctx, id)
+	if err != nil {
+		return row, err
+	}
+	p.feed.publish(Event{Row: row, Deleted: true})
+
+	return row, nil
+}
+
+// DeleteAllNumbers deletes every row and publishes a reset
+func (p *publisher) DeleteAllNumbers(ctx context.Context) error {
+	if err := p.Querier.DeleteAllNumbers(ctx); err != nil {
+		return err
+	}
+	p.feed.publish(Event{Reset: true})
+
+	return nil
+}
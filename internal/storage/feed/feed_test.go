@@ -0,0 +1,186 @@
+package feed
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"golang-test-task/internal/storage/index"
+	"golang-test-task/internal/storage/memstore"
+	"golang-test-task/internal/storage/sqlc"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// conn delivers the payloads sent to it as notifications
+type conn struct {
+	notifications chan string
+	listened      chan string
+}
+
+func newConn() *conn {
+	return &conn{notifications: make(chan string, 16), listened: make(chan string, 1)}
+}
+
+func (c *conn) Exec(_ context.Context, sql string, _ ...any) (pgconn.CommandTag, error) {
+	c.listened <- sql
+	return pgconn.CommandTag{}, nil
+}
+
+func (c *conn) WaitForNotification(ctx context.Context) (*pgconn.Notification, error) {
+	select {
+	case payload, ok := <-c.notifications:
+		if !ok {
+			return nil, errors.New("connection closed")
+		}
+		return &pgconn.Notification{Channel: index.Channel, Payload: payload}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// connects hands out the connections of conns in turn
+func connects(conns ...*conn) index.Connect {
+	var mu sync.Mutex
+	return func(context.Context) (index.Conn, func(), error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if len(conns) == 0 {
+			return nil, nil, errors.New("connection refused")
+		}
+		c := conns[0]
+		conns = conns[1:]
+		return c, func() {}, nil
+	}
+}
+
+// notify sends row as the insert trigger does
+func notify(t *testing.T, c *conn, row sqlc.Number) {
+	t.Helper()
+	payload, err := json.Marshal(row)
+	require.NoError(t, err)
+	c.notifications <- string(payload)
+}
+
+// next returns the next event of events, failing the test when none comes
+func next(t *testing.T, events <-chan Event) Event {
+	t.Helper()
+	select {
+	case event, ok := <-events:
+		require.True(t, ok, "the subscription ended")
+		return event
+	case <-time.After(time.Second):
+		require.FailNow(t, "no event")
+		return Event{}
+	}
+}
+
+// TestFeed_Run tests that notifications reach every subscriber as rows or resets, and
+// that a reconnect sends a reset for what was missed meanwhile
+func TestFeed_Run(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	f := New()
+	f.retry = time.Millisecond
+	first, second := newConn(), newConn()
+	go f.Run(ctx, connects(first, second))
+	assert.Equal(t, "listen numbers_changed", <-first.listened)
+
+	a, b := f.Subscribe(ctx), f.Subscribe(ctx)
+	row := sqlc.Number{Number: 7}
+	row.ID.Valid = true
+	row.ID.Bytes[0] = 1
+	notify(t, first, row)
+	assert.Equal(t, Event{Row: row}, next(t, a))
+	assert.Equal(t, Event{Row: row}, next(t, b))
+
+	payload, err := json.Marshal(index.Notice{Number: row, Deleted: true})
+	require.NoError(t, err)
+	first.notifications <- string(payload)
+	assert.Equal(t, Event{Row: row, Deleted: true}, next(t, a))
+
+	first.notifications <- ""
+	assert.Equal(t, Event{Reset: true}, next(t, a), "a change not sent row by row resets")
+	first.notifications <- "{"
+	assert.Equal(t, Event{Reset: true}, next(t, a), "a payload that cannot be read resets")
+
+	close(first.notifications)
+	<-second.listened
+	assert.Equal(t, Event{Reset: true}, next(t, a), "a reconnect resets")
+	notify(t, second, row)
+	assert.Equal(t, Event{Row: row}, next(t, a))
+}
+
+// TestFeed_Subscribe tests that subscriptions end with their context, when they fall
+// too far behind and when the feed is closed
+func TestFeed_Subscribe(t *testing.T) {
+	f := New()
+	ctx, cancel := context.WithCancel(context.Background())
+	ended := f.Subscribe(ctx)
+	cancel()
+	require.Eventually(t, func() bool {
+		_, ok := <-ended
+		return !ok
+	}, time.Second, time.Millisecond)
+
+	slow, kept := f.Subscribe(context.Background()), f.Subscribe(context.Background())
+	for range subscriberBuffer {
+		f.publish(Event{Reset: true})
+		<-kept
+	}
+	f.publish(Event{Reset: true})
+	assert.Len(t, slow, subscriberBuffer)
+	for range slow {
+	}
+	assert.Equal(t, Event{Reset: true}, <-kept, "a slow subscriber does not hold up the others")
+
+	f.Close()
+	_, ok := <-kept
+	assert.False(t, ok, "closing ends every subscription")
+	_, ok = <-f.Subscribe(context.Background())
+	assert.False(t, ok, "a closed feed ends new subscriptions at once")
+}
+
+// TestFeed_Wrap tests that numbers stored through the wrapped queries are published
+// once stored, batches longer than the buffer as a reset, deletes of a number as that
+// row and of every number as a reset, and that reads still reach the wrapped storage
+func TestFeed_Wrap(t *testing.T) {
+	ctx := context.Background()
+	f := New()
+	queries := f.Wrap(memstore.New())
+	events := f.Subscribe(ctx)
+
+	row, err := queries.InsertNumber(ctx, 3)
+	require.NoError(t, err)
+	assert.Equal(t, Event{Row: row}, next(t, events))
+
+	rows, err := queries.InsertNumbers(ctx, []int64{2, 1})
+	require.NoError(t, err)
+	assert.Equal(t, Event{Row: rows[0]}, next(t, events))
+	assert.Equal(t, Event{Row: rows[1]}, next(t, events))
+
+	numbers, err := queries.(interface {
+		SortedNumbers(ctx context.Context) ([]int64, error)
+	}).SortedNumbers(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2, 3}, numbers)
+
+	_, err = queries.DeleteNumber(ctx, row.ID)
+	require.NoError(t, err)
+	assert.Equal(t, Event{Row: row, Deleted: true}, next(t, events))
+	_, err = queries.DeleteNumber(ctx, row.ID)
+	require.Error(t, err)
+	require.NoError(t, queries.DeleteAllNumbers(ctx))
+	assert.Equal(t, Event{Reset: true}, next(t, events), "a failed delete publishes nothing")
+
+	_, err = queries.InsertNumbers(ctx, make([]int64, subscriberBuffer+1))
+	require.NoError(t, err)
+	assert.Equal(t, Event{Reset: true}, next(t, events), "a batch longer than the buffer resets")
+	assert.Empty(t, events, "and sends no row")
+}
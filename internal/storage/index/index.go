@@ -0,0 +1,419 @@
+// Package index keeps a sorted copy of the numbers table in memory, so that lists,
+// ranks and lookups are answered without a query. Inserts write through to the
+// database, and the changes every instance makes are followed with LISTEN/NOTIFY.
+package index
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"golang-test-task/internal/storage/sqlc"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Channel is where the triggers of the numbers table notify of changes: an insert or a
+// delete with the row as a Notice, and any other change with an empty payload, which
+// reloads the index
+const Channel = "numbers_changed"
+
+// streamBatch is how many numbers a stream copies out of the index at a time, so a
+// slow reader never holds up inserts for long
+const streamBatch = 1024
+
+// maxRetry bounds the wait between attempts to follow the table again
+const maxRetry = 30 * time.Second
+
+// errReloaded ends a stream whose index was reloaded partway, since where it had got
+// to no longer exists
+var errReloaded = errors.New("the sorted index was reloaded while streaming")
+
+// Notice is a row notified on Channel, inserted unless Deleted is set
+type Notice struct {
+	sqlc.Number
+	Deleted bool `json:"deleted"`
+}
+
+// ParseNotice decodes a payload notified on Channel that is not empty
+func ParseNotice(payload string) (Notice, error) {
+	var notice Notice
+	if err := json.Unmarshal([]byte(payload), &notice); err != nil {
+		return notice, fmt.Errorf("failed to decode notification %q: %w", payload, err)
+	}
+
+	return notice, nil
+}
+
+// Source is the storage the index mirrors and writes through to; *storage.Queries in
+// production
+type Source interface {
+	sqlc.Querier
+	StreamNumbersSorted(ctx context.Context, yield func(number int64) error) error
+	SortedNumbers(ctx context.Context) ([]int64, error)
+}
+
+// Conn is a connection the index LISTENs on; *pgx.Conn in production
+type Conn interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	WaitForNotification(ctx context.Context) (*pgconn.Notification, error)
+}
+
+// Connect opens a connection to listen on and returns what closes it
+type Connect func(ctx context.Context) (Conn, func(), error)
+
+// FromPool listens on a connection taken out of the pool current returns. current is
+// called on every reconnect, so the index follows a failover.
+func FromPool(current func() *pgxpool.Pool) Connect {
+	return func(ctx context.Context) (Conn, func(), error) {
+		c, err := current().Acquire(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		// The connection stays subscribed until it is closed, so it never goes back
+		conn := c.Hijack()
+
+		return conn, func() { conn.Close(context.Background()) }, nil
+	}
+}
+
+// Index is a Source answering reads from memory. Until Run has loaded the table, and
+// whenever it lost track of it, reads go to the source. An insert made through the
+// index is read back at once; one made elsewhere once its notification arrives. It is
+// safe for concurrent use.
+type Index struct {
+	Source
+
+	mu   sync.RWMutex
+	list *skipList
+	// ids maps the id of every row in the list to its seq
+	ids map[[16]byte]uint64
+	// seq orders the rows of equal numbers; it only grows, across reloads too
+	seq uint64
+	// generation counts the loads, so a stream notices the list was replaced
+	generation uint64
+	ready      bool
+
+	retry time.Duration
+}
+
+var _ Source = (*Index)(nil)
+
+// New returns an index of source, empty until Run loads it
+func New(source Source) *Index {
+	return &Index{Source: source, list: newSkipList(), ids: map[[16]byte]uint64{}, retry: time.Second}
+}
+
+// InsertNumber stores number in the source, then in the index
+func (x *Index) InsertNumber(ctx context.Context, number int64) (sqlc.Number, error) {
+	row, err := x.Source.InsertNumber(ctx, number)
+	if err != nil {
+		return row, err
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.add(row)
+
+	return row, nil
+}
+
+// InsertNumbers stores numbers in the source, then in the index
+func (x *Index) InsertNumbers(ctx context.Context, numbers []int64) ([]sqlc.Number, error) {
+	rows, err := x.Source.InsertNumbers(ctx, numbers)
+	if err != nil {
+		return rows, err
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	for _, row := range rows {
+		x.add(row)
+	}
+
+	return rows, nil
+}
+
+// add puts row in the index unless it is there already, as a row inserted through the
+// index is notified too. x.mu must be held.
+func (x *Index) add(row sqlc.Number) {
+	if _, ok := x.ids[row.ID.Bytes]; ok {
+		return
+	}
+	x.seq++
+	x.ids[row.ID.Bytes] = x.seq
+	x.list.insert(row, x.seq)
+}
+
+// remove takes row out of the index if it is there, as a row deleted through the index
+// is notified too. x.mu must be held.
+func (x *Index) remove(row sqlc.Number) {
+	if seq, ok := x.ids[row.ID.Bytes]; ok {
+		delete(x.ids, row.ID.Bytes)
+		x.list.remove(row.Number, seq)
+	}
+}
+
+// DeleteNumber deletes the row of id from the source, then from the index. A load
+// that read the table before the delete may bring the row back, until the
+// notification of the delete removes it once more.
+func (x *Index) DeleteNumber(ctx context.Context, id pgtype.UUID) (sqlc.Number, error) {
+	row, err := x.Source.DeleteNumber(ctx, id)
+	if err != nil {
+		return row, err
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.remove(row)
+
+	return row, nil
+}
+
+// DeleteAllNumbers deletes every row from the source, then empties the index. Streams
+// of the index fail, as when it is reloaded.
+func (x *Index) DeleteAllNumbers(ctx context.Context) error {
+	if err := x.Source.DeleteAllNumbers(ctx); err != nil {
+		return err
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.list, x.ids = newSkipList(), map[[16]byte]uint64{}
+	x.generation++
+
+	return nil
+}
+
+// Ready reports whether reads are answered from memory rather than by the source
+func (x *Index) Ready() bool {
+	return x.read(func(*skipList) {})
+}
+
+// read calls f with the list and reports whether the index is loaded; when it is
+// not, f is not called and the caller asks the source
+func (x *Index) read(f func(l *skipList)) bool {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	if !x.ready {
+		return false
+	}
+	f(x.list)
+
+	return true
+}
+
+func (x *Index) GetAllNumbersSorted(ctx context.Context) ([]sqlc.Number, error) {
+	var rows []sqlc.Number
+	ok := x.read(func(l *skipList) {
+		rows = make([]sqlc.Number, 0, l.len)
+		for n := l.first(); n != nil; n = n.next[0].to {
+			rows = append(rows, n.row)
+		}
+	})
+	if !ok {
+		return x.Source.GetAllNumbersSorted(ctx)
+	}
+
+	return rows, nil
+}
+
+func (x *Index) SortedNumbers(ctx context.Context) ([]int64, error) {
+	var numbers []int64
+	ok := x.read(func(l *skipList) {
+		numbers = make([]int64, 0, l.len)
+		for n := l.first(); n != nil; n = n.next[0].to {
+			numbers = append(numbers, n.row.Number)
+		}
+	})
+	if !ok {
+		return x.Source.SortedNumbers(ctx)
+	}
+
+	return numbers, nil
+}
+
+// StreamNumbersSorted yields the numbers streamBatch at a time, taking the lock only
+// to copy each batch out. Numbers inserted meanwhile are yielded if they sort after
+// the batches already yielded.
+func (x *Index) StreamNumbersSorted(ctx context.Context, yield func(number int64) error) error {
+	batch := make([]int64, 0, streamBatch)
+	var last *node
+	var generation uint64
+	for {
+		batch = batch[:0]
+		reloaded := false
+		ok := x.read(func(l *skipList) {
+			n := l.first()
+			if last != nil {
+				if reloaded = generation != x.generation; reloaded {
+					return
+				}
+				n = l.following(last.row.Number, last.seq)
+			}
+			generation = x.generation
+			for ; n != nil && len(batch) < streamBatch; n = n.next[0].to {
+				batch = append(batch, n.row.Number)
+				last = n
+			}
+		})
+		switch {
+		case !ok && last == nil:
+			return x.Source.StreamNumbersSorted(ctx, yield)
+		case !ok || reloaded:
+			return errReloaded
+		}
+
+		for _, number := range batch {
+			if err := yield(number); err != nil {
+				return err
+			}
+		}
+		if len(batch) < streamBatch {
+			return nil
+		}
+	}
+}
+
+// CountNumbers returns how many numbers are stored
+func (x *Index) CountNumbers(ctx context.Context) (int64, error) {
+	var count int
+	if !x.read(func(l *skipList) { count = l.len }) {
+		return x.Source.CountNumbers(ctx)
+	}
+
+	return int64(count), nil
+}
+
+// RankNumber returns how many stored numbers are smaller than number
+func (x *Index) RankNumber(ctx context.Context, number int64) (int64, error) {
+	var rank int
+	if !x.read(func(l *skipList) { rank = l.rank(number) }) {
+		return x.Source.RankNumber(ctx, number)
+	}
+
+	return int64(rank), nil
+}
+
+// ContainsNumber reports whether number is stored
+func (x *Index) ContainsNumber(ctx context.Context, number int64) (bool, error) {
+	var found bool
+	if !x.read(func(l *skipList) { found = l.contains(number) }) {
+		return x.Source.ContainsNumber(ctx, number)
+	}
+
+	return found, nil
+}
+
+// Run keeps the index in step with the table until ctx is done. It LISTENs on a
+// connection of connect before loading the table, so no change made during the load is
+// missed, then applies every change notified. When the connection or a reload fails,
+// reads go to the source until the index is listening and loaded again.
+func (x *Index) Run(ctx context.Context, connect Connect) {
+	retry := x.retry
+	for {
+		err := x.follow(ctx, connect, func() { retry = x.retry })
+
+		x.mu.Lock()
+		x.ready = false
+		x.mu.Unlock()
+		if ctx.Err() != nil {
+			return
+		}
+
+		slog.Warn("Sorted index lost track of the numbers table, reading from the database", "retry_in", retry, "error", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retry):
+		}
+		retry = min(2*retry, maxRetry)
+	}
+}
+
+// follow listens on a new connection, loads the table and applies notifications until
+// either fails. loaded is called once the index is loaded.
+func (x *Index) follow(ctx context.Context, connect Connect, loaded func()) error {
+	conn, release, err := connect(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer release()
+
+	if _, err := conn.Exec(ctx, "listen "+Channel); err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	if err := x.load(ctx); err != nil {
+		return err
+	}
+	loaded()
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to wait for notifications: %w", err)
+		}
+		if err := x.apply(ctx, notification.Payload); err != nil {
+			return err
+		}
+	}
+}
+
+// apply brings the index up to date with a change notified as payload
+func (x *Index) apply(ctx context.Context, payload string) error {
+	if payload == "" {
+		return x.load(ctx)
+	}
+
+	notice, err := ParseNotice(payload)
+	if err != nil {
+		return err
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if notice.Deleted {
+		x.remove(notice.Number)
+	} else {
+		x.add(notice.Number)
+	}
+
+	return nil
+}
+
+// load replaces the index with the rows of the table. Rows inserted through the index
+// while the table is read are notified afterwards, so they come back.
+func (x *Index) load(ctx context.Context) error {
+	rows, err := x.Source.GetAllNumbersSorted(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load numbers: %w", err)
+	}
+
+	x.mu.Lock()
+	seq := x.seq
+	x.seq += uint64(len(rows))
+	x.mu.Unlock()
+
+	// The list is built outside the lock, so reads and inserts carry on meanwhile
+	list, ids := newSkipList(), make(map[[16]byte]uint64, len(rows))
+	for _, row := range rows {
+		seq++
+		ids[row.ID.Bytes] = seq
+		list.insert(row, seq)
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.list, x.ids = list, ids
+	x.generation++
+	x.ready = true
+
+	return nil
+}
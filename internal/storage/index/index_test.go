@@ -0,0 +1,373 @@
+package index
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"golang-test-task/internal/storage/memstore"
+	"golang-test-task/internal/storage/sqlc"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSkipList tests ranks, lookups and order against a sorted slice, over enough
+// numbers to use many levels
+func TestSkipList(t *testing.T) {
+	l := newSkipList()
+	var want []int64
+	for i := range 5000 {
+		number := rand.Int64N(2000) - 1000
+		l.insert(sqlc.Number{Number: number}, uint64(i+1))
+		i := sort.Search(len(want), func(i int) bool { return want[i] > number })
+		want = slices.Insert(want, i, number)
+	}
+
+	var got []int64
+	for n := l.first(); n != nil; n = n.next[0].to {
+		got = append(got, n.row.Number)
+	}
+	require.Equal(t, want, got)
+	assert.Equal(t, len(want), l.len)
+
+	for number := int64(-1001); number <= 1001; number++ {
+		rank := sort.Search(len(want), func(i int) bool { return want[i] >= number })
+		require.Equal(t, rank, l.rank(number), "rank of %d", number)
+		require.Equal(t, rank < len(want) && want[rank] == number, l.contains(number), "contains %d", number)
+	}
+
+	n := l.following(want[99], 0)
+	assert.Equal(t, want[99], n.row.Number, "a seq below every row resumes at the number's first row")
+	n = l.following(n.row.Number, n.seq)
+	assert.GreaterOrEqual(t, n.row.Number, want[99])
+	assert.Nil(t, l.following(1000, ^uint64(0)))
+}
+
+// TestSkipList_Remove tests that ranks and order stay right as rows are taken out in
+// random order, down to an empty list
+func TestSkipList_Remove(t *testing.T) {
+	l := newSkipList()
+	type entry struct {
+		number int64
+		seq    uint64
+	}
+	var entries []entry
+	for i := range 2000 {
+		number := rand.Int64N(500)
+		l.insert(sqlc.Number{Number: number}, uint64(i+1))
+		entries = append(entries, entry{number, uint64(i + 1)})
+	}
+	assert.False(t, l.remove(entries[0].number, 0), "a seq not in the list removes nothing")
+
+	rand.Shuffle(len(entries), func(i, j int) { entries[i], entries[j] = entries[j], entries[i] })
+	for i, e := range entries {
+		require.True(t, l.remove(e.number, e.seq))
+		if i%100 != 0 {
+			continue
+		}
+		want := make([]int64, 0, len(entries)-i-1)
+		for _, left := range entries[i+1:] {
+			want = append(want, left.number)
+		}
+		slices.Sort(want)
+		var got []int64
+		for n := l.first(); n != nil; n = n.next[0].to {
+			got = append(got, n.row.Number)
+		}
+		require.Equal(t, want, append([]int64{}, got...))
+		for _, number := range []int64{-1, 0, 100, 250, 499, 500} {
+			rank := sort.Search(len(want), func(i int) bool { return want[i] >= number })
+			require.Equal(t, rank, l.rank(number), "rank of %d", number)
+		}
+	}
+	assert.Zero(t, l.len)
+	assert.Nil(t, l.first())
+}
+
+// source is a Source over the in-memory store that can be made to fail
+type source struct {
+	*memstore.Store
+	mu   sync.Mutex
+	err  error
+	list int
+}
+
+func (s *source) fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+func (s *source) check() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.list++
+	return s.err
+}
+
+func (s *source) GetAllNumbersSorted(ctx context.Context) ([]sqlc.Number, error) {
+	if err := s.check(); err != nil {
+		return nil, err
+	}
+	return s.Store.GetAllNumbersSorted(ctx)
+}
+
+func (s *source) SortedNumbers(ctx context.Context) ([]int64, error) {
+	if err := s.check(); err != nil {
+		return nil, err
+	}
+	numbers := []int64{}
+	err := s.Store.StreamNumbersSorted(ctx, func(number int64) error {
+		numbers = append(numbers, number)
+		return nil
+	})
+	return numbers, err
+}
+
+func (s *source) listed() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.list
+}
+
+// conn delivers the payloads sent to it as notifications
+type conn struct {
+	notifications chan string
+	listened      chan string
+}
+
+func newConn() *conn {
+	return &conn{notifications: make(chan string, 16), listened: make(chan string, 1)}
+}
+
+func (c *conn) Exec(_ context.Context, sql string, _ ...any) (pgconn.CommandTag, error) {
+	c.listened <- sql
+	return pgconn.CommandTag{}, nil
+}
+
+func (c *conn) WaitForNotification(ctx context.Context) (*pgconn.Notification, error) {
+	select {
+	case payload, ok := <-c.notifications:
+		if !ok {
+			return nil, errors.New("connection closed")
+		}
+		return &pgconn.Notification{Channel: Channel, Payload: payload}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// connects hands out the connections of conns in turn
+func connects(conns ...*conn) Connect {
+	var mu sync.Mutex
+	return func(context.Context) (Conn, func(), error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if len(conns) == 0 {
+			return nil, nil, errors.New("connection refused")
+		}
+		c := conns[0]
+		conns = conns[1:]
+		return c, func() {}, nil
+	}
+}
+
+// notify sends row as the insert trigger does
+func notify(t *testing.T, c *conn, row sqlc.Number) {
+	t.Helper()
+	payload, err := json.Marshal(row)
+	require.NoError(t, err)
+	c.notifications <- string(payload)
+}
+
+// notifyDelete sends row as the delete trigger does
+func notifyDelete(t *testing.T, c *conn, row sqlc.Number) {
+	t.Helper()
+	payload, err := json.Marshal(Notice{Number: row, Deleted: true})
+	require.NoError(t, err)
+	c.notifications <- string(payload)
+}
+
+// values returns the numbers the index lists
+func values(t *testing.T, x *Index) []int64 {
+	t.Helper()
+	numbers, err := x.SortedNumbers(context.Background())
+	require.NoError(t, err)
+	return numbers
+}
+
+// TestIndex tests that reads go to the source until the index is loaded, and then
+// come from memory with the inserts of this instance and of others
+func TestIndex(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	src := &source{Store: memstore.New()}
+	for _, n := range []int64{5, -2} {
+		_, err := src.Store.InsertNumber(ctx, n)
+		require.NoError(t, err)
+	}
+	x := New(src)
+	assert.Equal(t, []int64{-2, 5}, values(t, x), "an index not loaded yet reads the source")
+
+	c := newConn()
+	go x.Run(ctx, connects(c))
+	assert.Equal(t, "listen numbers_changed", <-c.listened)
+	require.Eventually(t, func() bool { return x.Ready() }, time.Second, time.Millisecond)
+
+	listed := src.listed()
+	row, err := x.InsertNumber(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{-2, 1, 5}, values(t, x), "inserts through the index are read back at once")
+	notify(t, c, row)
+
+	// Another instance inserts 3
+	other, err := src.Store.InsertNumber(ctx, 3)
+	require.NoError(t, err)
+	notify(t, c, other)
+	require.Eventually(t, func() bool { return slices.Equal([]int64{-2, 1, 3, 5}, values(t, x)) }, time.Second, time.Millisecond)
+
+	rows, err := x.GetAllNumbersSorted(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, other.ID, rows[2].ID)
+	rank, err := x.RankNumber(ctx, 4)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), rank)
+	found, err := x.ContainsNumber(ctx, 3)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, listed, src.listed(), "loaded reads never reach the source")
+
+	batch, err := x.InsertNumbers(ctx, []int64{4, 0})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{-2, 0, 1, 3, 4, 5}, values(t, x), "batches through the index are read back at once")
+	notify(t, c, batch[0])
+	assert.Equal(t, []int64{-2, 0, 1, 3, 4, 5}, values(t, x), "the notification of a row read back is ignored")
+}
+
+// TestIndex_Delete tests that deletes through the index are read back at once, and
+// that the rows other instances delete are removed without reloading the index
+func TestIndex_Delete(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	src := &source{Store: memstore.New()}
+	x := New(src)
+	c := newConn()
+	go x.Run(ctx, connects(c))
+	<-c.listened
+	require.Eventually(t, func() bool { return x.Ready() }, time.Second, time.Millisecond)
+
+	rows, err := x.InsertNumbers(ctx, []int64{3, 1, 3, 2})
+	require.NoError(t, err)
+	deleted, err := x.DeleteNumber(ctx, rows[2].ID)
+	require.NoError(t, err)
+	assert.Equal(t, rows[2], deleted)
+	assert.Equal(t, []int64{1, 2, 3}, values(t, x))
+	_, err = x.DeleteNumber(ctx, rows[2].ID)
+	assert.Error(t, err, "a row deleted already is not found")
+	notifyDelete(t, c, deleted)
+
+	// Another instance deletes 1
+	other, err := src.Store.DeleteNumber(ctx, rows[1].ID)
+	require.NoError(t, err)
+	notifyDelete(t, c, other)
+	require.Eventually(t, func() bool { return slices.Equal([]int64{2, 3}, values(t, x)) }, time.Second, time.Millisecond)
+
+	require.NoError(t, x.DeleteAllNumbers(ctx))
+	assert.Empty(t, values(t, x))
+	count, err := x.CountNumbers(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+	assert.Equal(t, 1, src.listed(), "deletes never reload the index themselves")
+}
+
+// TestIndex_ReloadsAndRecovers tests that a change other than an insert reloads the
+// index, and that a lost connection makes reads go to the source until the index is
+// loaded again
+func TestIndex_ReloadsAndRecovers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	src := &source{Store: memstore.New()}
+	x := New(src)
+	x.retry = time.Millisecond
+	first, second, third := newConn(), newConn(), newConn()
+	go x.Run(ctx, connects(first, second, third))
+	<-first.listened
+	require.Eventually(t, func() bool { return x.Ready() }, time.Second, time.Millisecond)
+
+	// A restore fills the table behind the index
+	for _, n := range []int64{8, 6, 7} {
+		_, err := src.Store.InsertNumber(ctx, n)
+		require.NoError(t, err)
+	}
+	first.notifications <- ""
+	require.Eventually(t, func() bool { return slices.Equal([]int64{6, 7, 8}, values(t, x)) }, time.Second, time.Millisecond)
+
+	src.fail(errors.New("connection refused"))
+	close(first.notifications)
+	<-second.listened
+	require.Eventually(t, func() bool { return !x.Ready() }, time.Second, time.Millisecond)
+	_, err := x.SortedNumbers(ctx)
+	assert.EqualError(t, err, "connection refused", "a stale index reads the source")
+
+	_, err = src.Store.InsertNumber(ctx, 1)
+	require.NoError(t, err)
+	src.fail(nil)
+	<-third.listened
+	require.Eventually(t, func() bool { return x.Ready() }, time.Second, time.Millisecond)
+	assert.Equal(t, []int64{1, 6, 7, 8}, values(t, x), "the index is loaded again after the connection is")
+}
+
+// TestIndex_Stream tests that a stream copies the index out in batches, yielding
+// numbers inserted after its position, and fails when the index is replaced under it
+func TestIndex_Stream(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	src := &source{Store: memstore.New()}
+	var want []int64
+	for i := range 3 * streamBatch {
+		_, err := src.Store.InsertNumber(ctx, int64(2*i))
+		require.NoError(t, err)
+		want = append(want, int64(2*i))
+	}
+	x := New(src)
+	c := newConn()
+	go x.Run(ctx, connects(c))
+	<-c.listened
+	require.Eventually(t, func() bool { return x.Ready() }, time.Second, time.Millisecond)
+
+	var streamed []int64
+	err := x.StreamNumbersSorted(ctx, func(number int64) error {
+		if len(streamed) == streamBatch {
+			_, err := x.InsertNumber(ctx, 1)
+			require.NoError(t, err)
+			_, err = x.InsertNumber(ctx, int64(len(want)*2))
+			require.NoError(t, err)
+		}
+		streamed = append(streamed, number)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, append(want, int64(len(want)*2)), streamed, "only numbers after the position are seen")
+
+	reloaded := false
+	err = x.StreamNumbersSorted(ctx, func(int64) error {
+		if !reloaded {
+			require.NoError(t, x.load(ctx))
+			reloaded = true
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, errReloaded)
+}
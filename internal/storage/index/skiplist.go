@@ -0,0 +1,172 @@
+package index
+
+import (
+	"math/rand/v2"
+
+	"golang-test-task/internal/storage/sqlc"
+)
+
+// maxLevel bounds the levels of a node; with a quarter of the nodes promoted at each
+// level it suits lists far longer than fit in memory
+const maxLevel = 32
+
+// skipList holds rows sorted by number and, among equal numbers, by seq, the order
+// they entered the list. Every link counts the rows it skips, so the rank of a number
+// is found in O(log n) like the number itself. It is not safe for concurrent use.
+type skipList struct {
+	head  node
+	level int
+	len   int
+}
+
+type node struct {
+	row  sqlc.Number
+	seq  uint64
+	next []link
+}
+
+// link points at the next node of a level. width is how many rows that moves forward
+// by: the nodes between, plus the one pointed at. A link to the end of the list spans
+// the rows left.
+type link struct {
+	to    *node
+	width int
+}
+
+func newSkipList() *skipList {
+	return &skipList{head: node{next: make([]link, maxLevel)}, level: 1}
+}
+
+// before reports whether n is ordered before the row with number and seq
+func (n *node) before(number int64, seq uint64) bool {
+	return n.row.Number < number || (n.row.Number == number && n.seq < seq)
+}
+
+// after reports whether n is ordered after the row with number and seq
+func (n *node) after(number int64, seq uint64) bool {
+	return n.row.Number > number || (n.row.Number == number && n.seq > seq)
+}
+
+// insert adds row with seq, which orders it among the rows of the same number
+func (l *skipList) insert(row sqlc.Number, seq uint64) {
+	var update [maxLevel]*node
+	// rank[i] is how many rows come before update[i], including it
+	var rank [maxLevel]int
+
+	x := &l.head
+	for i := l.level - 1; i >= 0; i-- {
+		if i < l.level-1 {
+			rank[i] = rank[i+1]
+		}
+		for x.next[i].to != nil && x.next[i].to.before(row.Number, seq) {
+			rank[i] += x.next[i].width
+			x = x.next[i].to
+		}
+		update[i] = x
+	}
+
+	level := randomLevel()
+	for i := l.level; i < level; i++ {
+		update[i] = &l.head
+		l.head.next[i].width = l.len
+	}
+	l.level = max(l.level, level)
+
+	n := &node{row: row, seq: seq, next: make([]link, level)}
+	for i := range level {
+		n.next[i] = link{to: update[i].next[i].to, width: update[i].next[i].width - (rank[0] - rank[i])}
+		update[i].next[i] = link{to: n, width: rank[0] - rank[i] + 1}
+	}
+	for i := level; i < l.level; i++ {
+		update[i].next[i].width++
+	}
+	l.len++
+}
+
+// remove takes out the row with number and seq, and reports whether it was there
+func (l *skipList) remove(number int64, seq uint64) bool {
+	var update [maxLevel]*node
+	x := &l.head
+	for i := l.level - 1; i >= 0; i-- {
+		for x.next[i].to != nil && x.next[i].to.before(number, seq) {
+			x = x.next[i].to
+		}
+		update[i] = x
+	}
+	n := x.next[0].to
+	if n == nil || n.row.Number != number || n.seq != seq {
+		return false
+	}
+
+	for i := range l.level {
+		if update[i].next[i].to == n {
+			update[i].next[i] = link{to: n.next[i].to, width: update[i].next[i].width + n.next[i].width - 1}
+		} else {
+			// The link skips over n
+			update[i].next[i].width--
+		}
+	}
+	for l.level > 1 && l.head.next[l.level-1].to == nil {
+		l.level--
+	}
+	l.len--
+
+	return true
+}
+
+// rank returns how many rows have a number smaller than number
+func (l *skipList) rank(number int64) int {
+	rank, _ := l.seek(number)
+	return rank
+}
+
+// contains reports whether a row has number
+func (l *skipList) contains(number int64) bool {
+	_, x := l.seek(number)
+	next := x.next[0].to
+
+	return next != nil && next.row.Number == number
+}
+
+// seek returns the last node with a number smaller than number, or the head, and how
+// many rows come before it, itself included
+func (l *skipList) seek(number int64) (int, *node) {
+	rank, x := 0, &l.head
+	for i := l.level - 1; i >= 0; i-- {
+		for x.next[i].to != nil && x.next[i].to.row.Number < number {
+			rank += x.next[i].width
+			x = x.next[i].to
+		}
+	}
+
+	return rank, x
+}
+
+// following returns the first node ordered after the row with number and seq, or nil
+// at the end of the list. Readers resume from there when the list changed in between.
+func (l *skipList) following(number int64, seq uint64) *node {
+	x := &l.head
+	for i := l.level - 1; i >= 0; i-- {
+		for x.next[i].to != nil && !x.next[i].to.after(number, seq) {
+			x = x.next[i].to
+		}
+	}
+
+	return x.next[0].to
+}
+
+// first returns the node of the smallest number, or nil when the list is empty
+func (l *skipList) first() *node {
+	return l.head.next[0].to
+}
+
+// randomLevel returns how many levels a new node is linked on: each one more with a
+// chance of a quarter
+func randomLevel() int {
+	level := 1
+	for level < maxLevel && rand.IntN(4) == 0 {
+		level++
+	}
+
+	return level
+}
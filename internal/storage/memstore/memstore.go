@@ -0,0 +1,253 @@
+// Package memstore is an in-memory sqlc.Querier for local development without
+// PostgreSQL. Numbers are lost when the process exits.
+package memstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"math"
+	"math/big"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"golang-test-task/internal/storage/sqlc"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Store keeps numbers sorted in memory. It is safe for concurrent use.
+type Store struct {
+	mu      sync.Mutex
+	numbers []sqlc.Number
+}
+
+var _ sqlc.Querier = (*Store)(nil)
+
+// New returns an empty Store
+func New() *Store {
+	return &Store{}
+}
+
+// InsertNumber stores number under a new random UUID and the current time, like the
+// numbers table defaults
+func (s *Store) InsertNumber(_ context.Context, number int64) (sqlc.Number, error) {
+	row := newRow(number)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.insert(row)
+
+	return row, nil
+}
+
+// InsertNumbers stores every number like InsertNumber, all at once: no read sees
+// some of them without the others
+func (s *Store) InsertNumbers(_ context.Context, numbers []int64) ([]sqlc.Number, error) {
+	rows := make([]sqlc.Number, len(numbers))
+	for i, number := range numbers {
+		rows[i] = newRow(number)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, row := range rows {
+		s.insert(row)
+	}
+
+	return rows, nil
+}
+
+// newRow is number with a new random UUID and the current time
+func newRow(number int64) sqlc.Number {
+	row := sqlc.Number{
+		ID:        pgtype.UUID{Valid: true},
+		Number:    number,
+		CreatedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	}
+	rand.Read(row.ID.Bytes[:])
+	row.ID.Bytes[6] = row.ID.Bytes[6]&0x0f | 0x40 // version 4
+	row.ID.Bytes[8] = row.ID.Bytes[8]&0x3f | 0x80 // RFC 4122 variant
+
+	return row
+}
+
+// insert puts row in its place. s.mu must be held.
+func (s *Store) insert(row sqlc.Number) {
+	// Equal numbers are ordered by id, as pages of the table are
+	i := sort.Search(len(s.numbers), func(i int) bool { return after(s.numbers[i], row.Number, row.ID) })
+	s.numbers = slices.Insert(s.numbers, i, row)
+}
+
+// DeleteNumber removes the row of id and returns it, or pgx.ErrNoRows when no row has
+// it, like the query does
+func (s *Store) DeleteNumber(_ context.Context, id pgtype.UUID) (sqlc.Number, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := slices.IndexFunc(s.numbers, func(row sqlc.Number) bool { return row.ID == id })
+	if i < 0 {
+		return sqlc.Number{}, pgx.ErrNoRows
+	}
+	row := s.numbers[i]
+	s.numbers = slices.Delete(s.numbers, i, i+1)
+
+	return row, nil
+}
+
+// DeleteAllNumbers removes every row
+func (s *Store) DeleteAllNumbers(_ context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.numbers = nil
+
+	return nil
+}
+
+// GetAllNumbersSorted returns a copy of every stored number in ascending order
+func (s *Store) GetAllNumbersSorted(_ context.Context) ([]sqlc.Number, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.numbers), nil
+}
+
+// StreamNumbersSorted yields a snapshot of the stored numbers in ascending order,
+// so a slow reader never holds up inserts
+func (s *Store) StreamNumbersSorted(ctx context.Context, yield func(number int64) error) error {
+	numbers, _ := s.GetAllNumbersSorted(ctx)
+	for _, num := range numbers {
+		if err := yield(num.Number); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CountNumbers returns how many numbers are stored
+func (s *Store) CountNumbers(_ context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return int64(len(s.numbers)), nil
+}
+
+// RankNumber returns how many stored numbers are smaller than number
+func (s *Store) RankNumber(_ context.Context, number int64) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return int64(s.search(number)), nil
+}
+
+// RankRow returns how many rows come before the row of arg.Number and arg.ID in the
+// order of pages
+func (s *Store) RankRow(_ context.Context, arg sqlc.RankRowParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return int64(sort.Search(len(s.numbers), func(i int) bool { return !before(s.numbers[i], arg.Number, arg.ID) })), nil
+}
+
+// ContainsNumber reports whether number is stored
+func (s *Store) ContainsNumber(_ context.Context, number int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.search(number)
+	return i < len(s.numbers) && s.numbers[i].Number == number, nil
+}
+
+// NumberStats aggregates the stored numbers as the query does in PostgreSQL: the sum
+// and mean are computed exactly and rounded once, and percentiles interpolate
+// between the two numbers nearest to each fraction
+func (s *Store) NumberStats(_ context.Context, fractions []float64) (sqlc.NumberStatsRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := sqlc.NumberStatsRow{Count: int64(len(s.numbers)), Percentiles: []float64{}}
+	if len(s.numbers) == 0 {
+		return stats, nil
+	}
+	stats.Min, stats.Max = s.numbers[0].Number, s.numbers[len(s.numbers)-1].Number
+
+	sum := new(big.Int)
+	for _, row := range s.numbers {
+		sum.Add(sum, big.NewInt(row.Number))
+	}
+	stats.Sum, _ = new(big.Float).SetInt(sum).Float64()
+	stats.Mean, _ = new(big.Rat).SetFrac(sum, big.NewInt(stats.Count)).Float64()
+	stats.Median = s.percentile(0.5)
+	for _, fraction := range fractions {
+		stats.Percentiles = append(stats.Percentiles, s.percentile(fraction))
+	}
+
+	return stats, nil
+}
+
+// percentile is the value at fraction of the way through the stored numbers, like
+// percentile_cont. The caller holds mu and there is at least one number.
+func (s *Store) percentile(fraction float64) float64 {
+	position := fraction * float64(len(s.numbers)-1)
+	lower := int(math.Floor(position))
+	upper := int(math.Ceil(position))
+	low, high := float64(s.numbers[lower].Number), float64(s.numbers[upper].Number)
+
+	return low + (high-low)*(position-float64(lower))
+}
+
+// ListNumbersPage returns up to arg.PageLimit rows between arg.MinNumber and
+// arg.MaxNumber, after arg.AfterNumber and arg.AfterID when they are set
+func (s *Store) ListNumbersPage(_ context.Context, arg sqlc.ListNumbersPageParams) ([]sqlc.Number, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.search(arg.MinNumber)
+	if arg.AfterNumber.Valid {
+		i = max(i, sort.Search(len(s.numbers), func(i int) bool { return after(s.numbers[i], arg.AfterNumber.Int64, arg.AfterID) }))
+	}
+	page := []sqlc.Number{}
+	for ; i < len(s.numbers) && len(page) < int(arg.PageLimit) && s.numbers[i].Number <= arg.MaxNumber; i++ {
+		page = append(page, s.numbers[i])
+	}
+
+	return page, nil
+}
+
+// NumberWindow returns the row of arg.Number and arg.ID with up to arg.Size rows on
+// each side of it
+func (s *Store) NumberWindow(_ context.Context, arg sqlc.NumberWindowParams) ([]sqlc.Number, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The first row not before the one of the window
+	i := sort.Search(len(s.numbers), func(i int) bool { return !before(s.numbers[i], arg.Number, arg.ID) })
+	size := int(arg.Size)
+
+	return slices.Clone(s.numbers[max(i-size, 0):min(i+size+1, len(s.numbers))]), nil
+}
+
+// before reports whether row sorts before number and id in the order of pages
+func before(row sqlc.Number, number int64, id pgtype.UUID) bool {
+	if row.Number != number {
+		return row.Number < number
+	}
+	return bytes.Compare(row.ID.Bytes[:], id.Bytes[:]) < 0
+}
+
+// after reports whether row sorts after number and id in the order of pages
+func after(row sqlc.Number, number int64, id pgtype.UUID) bool {
+	if row.Number != number {
+		return row.Number > number
+	}
+	return bytes.Compare(row.ID.Bytes[:], id.Bytes[:]) > 0
+}
+
+// search returns the index of the first number not smaller than number
+func (s *Store) search(number int64) int {
+	return sort.Search(len(s.numbers), func(i int) bool { return s.numbers[i].Number >= number })
+}
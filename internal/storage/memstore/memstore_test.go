@@ -0,0 +1,152 @@
+package memstore
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"golang-test-task/internal/storage/sqlc"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+
+	for _, n := range []int64{3, -1, 3, 2147483647, 0} {
+		row, err := s.InsertNumber(ctx, n)
+		require.NoError(t, err)
+		assert.Equal(t, n, row.Number)
+		assert.True(t, row.ID.Valid)
+	}
+
+	rows, err := s.GetAllNumbersSorted(ctx)
+	require.NoError(t, err)
+
+	numbers := make([]int64, len(rows))
+	for i, row := range rows {
+		numbers[i] = row.Number
+	}
+	assert.Equal(t, []int64{-1, 0, 3, 3, 2147483647}, numbers)
+	assert.NotEqual(t, rows[2].ID, rows[3].ID, "duplicates get their own IDs")
+
+	var streamed []int64
+	require.NoError(t, s.StreamNumbersSorted(ctx, func(number int64) error {
+		streamed = append(streamed, number)
+		return nil
+	}))
+	assert.Equal(t, numbers, streamed)
+}
+
+func TestStore_ListNumbersPage(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	for _, number := range []int64{4, 3, 3, 3, 1, 9} {
+		_, err := s.InsertNumber(ctx, number)
+		require.NoError(t, err)
+	}
+	rows, err := s.GetAllNumbersSorted(ctx)
+	require.NoError(t, err)
+
+	page, err := s.ListNumbersPage(ctx, sqlc.ListNumbersPageParams{MinNumber: 2, MaxNumber: 4, PageLimit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, rows[1:3], page)
+	assert.Less(t, page[0].ID.String(), page[1].ID.String(), "equal numbers are ordered by id")
+
+	last := page[1]
+	page, err = s.ListNumbersPage(ctx, sqlc.ListNumbersPageParams{
+		MinNumber:   2,
+		MaxNumber:   4,
+		AfterNumber: pgtype.Int8{Int64: last.Number, Valid: true},
+		AfterID:     last.ID,
+		PageLimit:   2,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, rows[3:5], page)
+}
+
+func TestStore_NumberWindow(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	for _, number := range []int64{4, 3, 3, 3, 1, 9} {
+		_, err := s.InsertNumber(ctx, number)
+		require.NoError(t, err)
+	}
+	rows, err := s.GetAllNumbersSorted(ctx)
+	require.NoError(t, err)
+
+	for i, want := range [][]sqlc.Number{rows[0:3], rows[0:4], rows[0:5], rows[1:6], rows[2:6], rows[3:6]} {
+		window, err := s.NumberWindow(ctx, sqlc.NumberWindowParams{Number: rows[i].Number, ID: rows[i].ID, Size: 2})
+		require.NoError(t, err)
+		assert.Equal(t, want, window, "window of row %d", i)
+	}
+
+	window, err := s.NumberWindow(ctx, sqlc.NumberWindowParams{Number: 3, ID: rows[2].ID, Size: 0})
+	require.NoError(t, err)
+	assert.Equal(t, rows[2:3], window, "a window without neighbours is the row alone")
+}
+
+func TestStore_InsertNumbers(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	_, err := s.InsertNumber(ctx, 2)
+	require.NoError(t, err)
+
+	inserted, err := s.InsertNumbers(ctx, []int64{5, -1, 2})
+	require.NoError(t, err)
+	require.Len(t, inserted, 3)
+	assert.Equal(t, int64(5), inserted[0].Number, "rows come back in the order of the numbers")
+	assert.True(t, inserted[0].ID.Valid)
+
+	rows, err := s.GetAllNumbersSorted(ctx)
+	require.NoError(t, err)
+	numbers := make([]int64, len(rows))
+	for i, row := range rows {
+		numbers[i] = row.Number
+	}
+	assert.Equal(t, []int64{-1, 2, 2, 5}, numbers)
+}
+
+func TestStore_Delete(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+	rows, err := s.InsertNumbers(ctx, []int64{2, 7, 2})
+	require.NoError(t, err)
+
+	deleted, err := s.DeleteNumber(ctx, rows[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, rows[0], deleted)
+	count, err := s.CountNumbers(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	_, err = s.DeleteNumber(ctx, rows[0].ID)
+	assert.ErrorIs(t, err, pgx.ErrNoRows, "a missing row is reported as the query reports it")
+
+	require.NoError(t, s.DeleteAllNumbers(ctx))
+	left, err := s.GetAllNumbersSorted(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, left)
+}
+
+func TestStore_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	s := New()
+
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.InsertNumber(ctx, int64(i))
+		}()
+	}
+	wg.Wait()
+
+	rows, err := s.GetAllNumbersSorted(ctx)
+	require.NoError(t, err)
+	assert.Len(t, rows, 50)
+}
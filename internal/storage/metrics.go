@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// poolCollector exports the statistics of the pool in use for Prometheus. They are
+// read at every scrape, so after a failover they are those of the new pool and the
+// counters start over, which Prometheus takes as a reset.
+type poolCollector struct {
+	current func() *pgxpool.Pool
+
+	acquired, idle, constructing, total, max      *prometheus.Desc
+	acquires, acquireSeconds, emptyAcquires       *prometheus.Desc
+	canceledAcquires, newConns, lifetimeDestroyed *prometheus.Desc
+	idleDestroyed                                 *prometheus.Desc
+}
+
+// NewPoolCollector returns a collector of the connection pool statistics of c, or
+// nil when c has no pool, such as with memory storage
+func NewPoolCollector(c Closer) prometheus.Collector {
+	pool, ok := c.(interface{ Current() *pgxpool.Pool })
+	if !ok {
+		return nil
+	}
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc("pgxpool_"+name, help, nil, nil)
+	}
+
+	return &poolCollector{
+		current:           pool.Current,
+		acquired:          desc("acquired_conns", "Connections currently acquired from the pool."),
+		idle:              desc("idle_conns", "Idle connections in the pool."),
+		constructing:      desc("constructing_conns", "Connections being opened."),
+		total:             desc("total_conns", "Connections in the pool: acquired, idle and being opened."),
+		max:               desc("max_conns", "Most connections the pool may hold."),
+		acquires:          desc("acquires_total", "Connections acquired from the pool."),
+		acquireSeconds:    desc("acquire_duration_seconds_total", "Time spent acquiring connections from the pool."),
+		emptyAcquires:     desc("empty_acquires_total", "Acquires that had to wait for a connection because none was idle."),
+		canceledAcquires:  desc("canceled_acquires_total", "Acquires cancelled by their context."),
+		newConns:          desc("new_conns_total", "Connections opened."),
+		lifetimeDestroyed: desc("max_lifetime_destroyed_total", "Connections closed for exceeding their maximum lifetime."),
+		idleDestroyed:     desc("max_idle_destroyed_total", "Connections closed for being idle too long."),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.current().Stat()
+	gauge := func(desc *prometheus.Desc, value float64) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value)
+	}
+	counter := func(desc *prometheus.Desc, value float64) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, value)
+	}
+
+	gauge(c.acquired, float64(stat.AcquiredConns()))
+	gauge(c.idle, float64(stat.IdleConns()))
+	gauge(c.constructing, float64(stat.ConstructingConns()))
+	gauge(c.total, float64(stat.TotalConns()))
+	gauge(c.max, float64(stat.MaxConns()))
+	counter(c.acquires, float64(stat.AcquireCount()))
+	counter(c.acquireSeconds, stat.AcquireDuration().Seconds())
+	counter(c.emptyAcquires, float64(stat.EmptyAcquireCount()))
+	counter(c.canceledAcquires, float64(stat.CanceledAcquireCount()))
+	counter(c.newConns, float64(stat.NewConnsCount()))
+	counter(c.lifetimeDestroyed, float64(stat.MaxLifetimeDestroyCount()))
+	counter(c.idleDestroyed, float64(stat.MaxIdleDestroyCount()))
+}
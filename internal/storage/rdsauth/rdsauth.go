@@ -0,0 +1,91 @@
+// Package rdsauth authenticates PostgreSQL connections to Amazon RDS with IAM
+// auth tokens instead of a static password.
+package rdsauth
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/rds/auth"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// tokenLifetime is how long RDS accepts a token after it is generated
+	tokenLifetime = 15 * time.Minute
+	// refreshMargin is how long before expiry a cached token is replaced
+	refreshMargin = 5 * time.Minute
+)
+
+// buildFunc generates a token for endpoint (host:port) and user; auth.BuildAuthToken in production
+type buildFunc func(ctx context.Context, endpoint, region, user string, creds aws.CredentialsProvider, optFns ...func(*auth.BuildAuthTokenOptions)) (string, error)
+
+// TokenSource generates IAM auth tokens and reuses each one until shortly before it expires
+type TokenSource struct {
+	region string
+	creds  aws.CredentialsProvider
+	build  buildFunc
+	now    func() time.Time
+
+	mu      sync.Mutex
+	key     string
+	token   string
+	expires time.Time
+}
+
+// New returns a TokenSource for an RDS instance in region. AWS credentials are resolved
+// the standard way: environment variables, shared config files, web identity (IRSA)
+// or the instance and task metadata endpoints.
+func New(ctx context.Context, region string) (*TokenSource, error) {
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(region))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS configuration: %w", err)
+	}
+
+	return &TokenSource{
+		region: region,
+		creds:  awsCfg.Credentials,
+		build:  auth.BuildAuthToken,
+		now:    time.Now,
+	}, nil
+}
+
+// Token returns a valid token for user at host:port
+func (s *TokenSource) Token(ctx context.Context, host string, port uint16, user string) (string, error) {
+	endpoint := net.JoinHostPort(host, strconv.Itoa(int(port)))
+	key := user + "@" + endpoint
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if s.key == key && now.Before(s.expires.Add(-refreshMargin)) {
+		return s.token, nil
+	}
+
+	token, err := s.build(ctx, endpoint, s.region, user, s.creds)
+	if err != nil {
+		return "", fmt.Errorf("failed to build RDS IAM auth token: %w", err)
+	}
+
+	s.key, s.token, s.expires = key, token, now.Add(tokenLifetime)
+
+	return token, nil
+}
+
+// BeforeConnect is a pgxpool.Config.BeforeConnect hook that uses a fresh token as the password
+func (s *TokenSource) BeforeConnect(ctx context.Context, cfg *pgx.ConnConfig) error {
+	token, err := s.Token(ctx, cfg.Host, cfg.Port, cfg.User)
+	if err != nil {
+		return err
+	}
+	cfg.Password = token
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package rdsauth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/feature/rds/auth"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSource counts generated tokens and lets the test move the clock
+func fakeSource(now *time.Time) (*TokenSource, *int) {
+	builds := 0
+	return &TokenSource{
+		region: "eu-west-1",
+		build: func(ctx context.Context, endpoint, region, user string, creds aws.CredentialsProvider, optFns ...func(*auth.BuildAuthTokenOptions)) (string, error) {
+			builds++
+			return fmt.Sprintf("%s/%s/%s/%d", endpoint, region, user, builds), nil
+		},
+		now: func() time.Time { return *now },
+	}, &builds
+}
+
+func TestTokenSource_CachesUntilNearExpiry(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	s, builds := fakeSource(&now)
+	ctx := context.Background()
+
+	token, err := s.Token(ctx, "db.example.com", 5432, "app")
+	require.NoError(t, err)
+	assert.Equal(t, "db.example.com:5432/eu-west-1/app/1", token)
+
+	now = now.Add(9 * time.Minute)
+	token, err = s.Token(ctx, "db.example.com", 5432, "app")
+	require.NoError(t, err)
+	assert.Equal(t, 1, *builds, "token reused while it has plenty of time left")
+
+	now = now.Add(2 * time.Minute)
+	token, err = s.Token(ctx, "db.example.com", 5432, "app")
+	require.NoError(t, err)
+	assert.Equal(t, 2, *builds, "token refreshed before it expires")
+	assert.True(t, strings.HasSuffix(token, "/2"))
+
+	_, err = s.Token(ctx, "db.example.com", 5432, "other")
+	require.NoError(t, err)
+	assert.Equal(t, 3, *builds, "a different user gets its own token")
+}
+
+func TestTokenSource_BeforeConnect(t *testing.T) {
+	now := time.Now()
+	s, _ := fakeSource(&now)
+
+	cfg := &pgx.ConnConfig{}
+	cfg.Host, cfg.Port, cfg.User = "db.example.com", 5432, "app"
+
+	require.NoError(t, s.BeforeConnect(context.Background(), cfg))
+	assert.Equal(t, "db.example.com:5432/eu-west-1/app/1", cfg.Password)
+}
+
+func TestTokenSource_RealSigner(t *testing.T) {
+	now := time.Now()
+	s := &TokenSource{
+		region: "eu-west-1",
+		creds:  credentials.NewStaticCredentialsProvider("AKIDEXAMPLE", "secret", ""),
+		build:  auth.BuildAuthToken,
+		now:    func() time.Time { return now },
+	}
+
+	token, err := s.Token(context.Background(), "db.example.com", 5432, "app")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(token, "db.example.com:5432?Action=connect&DBUser=app"), token)
+	assert.Contains(t, token, "X-Amz-Signature=")
+}
@@ -9,6 +9,7 @@ import (
 )
 
 type Number struct {
-	ID     pgtype.UUID `json:"id"`
-	Number int32       `json:"number"`
+	ID        pgtype.UUID        `json:"id"`
+	Number    int64              `json:"number"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
 }
@@ -0,0 +1,48 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+
+package sqlc
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type Querier interface {
+	// One probe of the index on number
+	ContainsNumber(ctx context.Context, number int64) (bool, error)
+	// count(*) reads every row, or every entry of the index on number when the visibility
+	// map lets it, so it takes as long as the table is large
+	CountNumbers(ctx context.Context) (int64, error)
+	// TRUNCATE rather than DELETE hands the space of the table back at once and leaves no
+	// dead rows for vacuum, at the cost of an exclusive lock for as long as it takes
+	DeleteAllNumbers(ctx context.Context) error
+	DeleteNumber(ctx context.Context, id pgtype.UUID) (Number, error)
+	GetAllNumbersSorted(ctx context.Context) ([]Number, error)
+	InsertNumber(ctx context.Context, number int64) (Number, error)
+	// The numbers are stored by one statement, so either all of them are or none is
+	InsertNumbers(ctx context.Context, numbers []int64) ([]Number, error)
+	// Rows are ordered by number, then id, so that a page can start right after the
+	// last row of the one before even among equal numbers
+	ListNumbersPage(ctx context.Context, arg ListNumbersPageParams) ([]Number, error)
+	// Every aggregate is computed by the database in one scan of the table, so no row
+	// leaves it. An empty table has no min, max or percentiles, so they are coalesced
+	// and count tells them apart. The sum of bigints is summed exactly as a numeric and
+	// only rounded when it is cast, so it is exact up to 2^53.
+	NumberStats(ctx context.Context, fractions []float64) (NumberStatsRow, error)
+	// The row of number and id with up to size rows on each side of it, in the order of
+	// ListNumbersPage. Each side is one range scan of the (number, id) index, however
+	// large the table is.
+	NumberWindow(ctx context.Context, arg NumberWindowParams) ([]Number, error)
+	// How many numbers are smaller than number, which is where it stands in the sorted
+	// list. A range scan of the index on number up to it, so it grows with the rank.
+	RankNumber(ctx context.Context, number int64) (int64, error)
+	// How many rows come before the row of number and id in the order of
+	// ListNumbersPage, which is where it stands among equal numbers too. A range scan of
+	// the (number, id) index up to it, so it grows with the rank.
+	RankRow(ctx context.Context, arg RankRowParams) (int64, error)
+}
+
+var _ Querier = (*Queries)(nil)
@@ -0,0 +1,293 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: queries.sql
+
+package sqlc
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const containsNumber = `-- name: ContainsNumber :one
+SELECT EXISTS (SELECT 1 FROM numbers WHERE number = $1::bigint)
+`
+
+// One probe of the index on number
+func (q *Queries) ContainsNumber(ctx context.Context, number int64) (bool, error) {
+	row := q.db.QueryRow(ctx, containsNumber, number)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const countNumbers = `-- name: CountNumbers :one
+SELECT count(*) FROM numbers
+`
+
+// count(*) reads every row, or every entry of the index on number when the visibility
+// map lets it, so it takes as long as the table is large
+func (q *Queries) CountNumbers(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, countNumbers)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const deleteAllNumbers = `-- name: DeleteAllNumbers :exec
+TRUNCATE numbers
+`
+
+// TRUNCATE rather than DELETE hands the space of the table back at once and leaves no
+// dead rows for vacuum, at the cost of an exclusive lock for as long as it takes
+func (q *Queries) DeleteAllNumbers(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, deleteAllNumbers)
+	return err
+}
+
+const deleteNumber = `-- name: DeleteNumber :one
+DELETE FROM numbers
+WHERE id = $1
+RETURNING id, number, created_at
+`
+
+func (q *Queries) DeleteNumber(ctx context.Context, id pgtype.UUID) (Number, error) {
+	row := q.db.QueryRow(ctx, deleteNumber, id)
+	var i Number
+	err := row.Scan(&i.ID, &i.Number, &i.CreatedAt)
+	return i, err
+}
+
+const getAllNumbersSorted = `-- name: GetAllNumbersSorted :many
+SELECT id, number, created_at
+FROM numbers
+ORDER BY number ASC
+`
+
+func (q *Queries) GetAllNumbersSorted(ctx context.Context) ([]Number, error) {
+	rows, err := q.db.Query(ctx, getAllNumbersSorted)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Number{}
+	for rows.Next() {
+		var i Number
+		if err := rows.Scan(&i.ID, &i.Number, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertNumber = `-- name: InsertNumber :one
+INSERT INTO numbers (number)
+VALUES ($1)
+RETURNING id, number, created_at
+`
+
+func (q *Queries) InsertNumber(ctx context.Context, number int64) (Number, error) {
+	row := q.db.QueryRow(ctx, insertNumber, number)
+	var i Number
+	err := row.Scan(&i.ID, &i.Number, &i.CreatedAt)
+	return i, err
+}
+
+const insertNumbers = `-- name: InsertNumbers :many
+INSERT INTO numbers (number)
+SELECT unnest($1::bigint[])
+RETURNING id, number, created_at
+`
+
+// The numbers are stored by one statement, so either all of them are or none is
+func (q *Queries) InsertNumbers(ctx context.Context, numbers []int64) ([]Number, error) {
+	rows, err := q.db.Query(ctx, insertNumbers, numbers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Number{}
+	for rows.Next() {
+		var i Number
+		if err := rows.Scan(&i.ID, &i.Number, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listNumbersPage = `-- name: ListNumbersPage :many
+SELECT id, number, created_at
+FROM numbers
+WHERE number BETWEEN $1::bigint AND $2::bigint
+  AND ($3::bigint IS NULL OR (number, id) > ($3::bigint, $4::uuid))
+ORDER BY number ASC, id ASC
+LIMIT $5
+`
+
+type ListNumbersPageParams struct {
+	MinNumber   int64       `json:"min_number"`
+	MaxNumber   int64       `json:"max_number"`
+	AfterNumber pgtype.Int8 `json:"after_number"`
+	AfterID     pgtype.UUID `json:"after_id"`
+	PageLimit   int32       `json:"page_limit"`
+}
+
+// Rows are ordered by number, then id, so that a page can start right after the
+// last row of the one before even among equal numbers
+func (q *Queries) ListNumbersPage(ctx context.Context, arg ListNumbersPageParams) ([]Number, error) {
+	rows, err := q.db.Query(ctx, listNumbersPage,
+		arg.MinNumber,
+		arg.MaxNumber,
+		arg.AfterNumber,
+		arg.AfterID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Number{}
+	for rows.Next() {
+		var i Number
+		if err := rows.Scan(&i.ID, &i.Number, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const numberStats = `-- name: NumberStats :one
+SELECT count(*) AS count,
+       coalesce(min(number), 0)::bigint AS min,
+       coalesce(max(number), 0)::bigint AS max,
+       coalesce(sum(number), 0)::float8 AS sum,
+       coalesce(avg(number), 0)::float8 AS mean,
+       coalesce(percentile_cont(0.5) WITHIN GROUP (ORDER BY number), 0)::float8 AS median,
+       coalesce(percentile_cont($1::float8[]) WITHIN GROUP (ORDER BY number), '{}')::float8[] AS percentiles
+FROM numbers
+`
+
+type NumberStatsRow struct {
+	Count       int64     `json:"count"`
+	Min         int64     `json:"min"`
+	Max         int64     `json:"max"`
+	Sum         float64   `json:"sum"`
+	Mean        float64   `json:"mean"`
+	Median      float64   `json:"median"`
+	Percentiles []float64 `json:"percentiles"`
+}
+
+// Every aggregate is computed by the database in one scan of the table, so no row
+// leaves it. An empty table has no min, max or percentiles, so they are coalesced
+// and count tells them apart. The sum of bigints is summed exactly as a numeric and
+// only rounded when it is cast, so it is exact up to 2^53.
+func (q *Queries) NumberStats(ctx context.Context, fractions []float64) (NumberStatsRow, error) {
+	row := q.db.QueryRow(ctx, numberStats, fractions)
+	var i NumberStatsRow
+	err := row.Scan(
+		&i.Count,
+		&i.Min,
+		&i.Max,
+		&i.Sum,
+		&i.Mean,
+		&i.Median,
+		&i.Percentiles,
+	)
+	return i, err
+}
+
+const numberWindow = `-- name: NumberWindow :many
+SELECT id, number, created_at
+FROM (
+    (SELECT id, number, created_at
+     FROM numbers
+     WHERE (number, id) < ($1::bigint, $2::uuid)
+     ORDER BY number DESC, id DESC
+     LIMIT $3::int)
+    UNION ALL
+    (SELECT id, number, created_at
+     FROM numbers
+     WHERE (number, id) >= ($1::bigint, $2::uuid)
+     ORDER BY number ASC, id ASC
+     LIMIT $3::int + 1)
+) AS window_rows
+ORDER BY number ASC, id ASC
+`
+
+type NumberWindowParams struct {
+	Number int64       `json:"number"`
+	ID     pgtype.UUID `json:"id"`
+	Size   int32       `json:"size"`
+}
+
+// The row of number and id with up to size rows on each side of it, in the order of
+// ListNumbersPage. Each side is one range scan of the (number, id) index, however
+// large the table is.
+func (q *Queries) NumberWindow(ctx context.Context, arg NumberWindowParams) ([]Number, error) {
+	rows, err := q.db.Query(ctx, numberWindow, arg.Number, arg.ID, arg.Size)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Number{}
+	for rows.Next() {
+		var i Number
+		if err := rows.Scan(&i.ID, &i.Number, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const rankNumber = `-- name: RankNumber :one
+SELECT count(*) FROM numbers
+WHERE number < $1::bigint
+`
+
+// How many numbers are smaller than number, which is where it stands in the sorted
+// list. A range scan of the index on number up to it, so it grows with the rank.
+func (q *Queries) RankNumber(ctx context.Context, number int64) (int64, error) {
+	row := q.db.QueryRow(ctx, rankNumber, number)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const rankRow = `-- name: RankRow :one
+SELECT count(*) FROM numbers
+WHERE (number, id) < ($1::bigint, $2::uuid)
+`
+
+type RankRowParams struct {
+	Number int64       `json:"number"`
+	ID     pgtype.UUID `json:"id"`
+}
+
+// How many rows come before the row of number and id in the order of
+// ListNumbersPage, which is where it stands among equal numbers too. A range scan of
+// the (number, id) index up to it, so it grows with the rank.
+func (q *Queries) RankRow(ctx context.Context, arg RankRowParams) (int64, error) {
+	row := q.db.QueryRow(ctx, rankRow, arg.Number, arg.ID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
@@ -0,0 +1,139 @@
+// Package storage opens the configured storage: PostgreSQL, through a failover pool
+// with credentials from Vault, RDS IAM tokens or the configuration itself, or the
+// in-memory store of local development. The transports only see the sqlc.Querier
+// it returns.
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"golang-test-task/internal/config"
+	"golang-test-task/internal/storage/failover"
+	"golang-test-task/internal/storage/index"
+	"golang-test-task/internal/storage/memstore"
+	"golang-test-task/internal/storage/rdsauth"
+	"golang-test-task/internal/storage/sqlc"
+	"golang-test-task/internal/storage/vault"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Closer closes the connections of an open storage
+type Closer interface {
+	Close()
+}
+
+// Open returns the queries of cfg.Storage and what closes its connections. Vault
+// credentials are kept renewed, databases health-checked and, with postgres.index,
+// the index kept in step with the table until ctx is done.
+func Open(ctx context.Context, cfg config.Config) (sqlc.Querier, Closer, error) {
+	if cfg.Storage == "memory" {
+		slog.Warn("Using in-memory storage; numbers are lost when the server exits")
+		return memstore.New(), nopCloser{}, nil
+	}
+
+	db, err := ConnectPostgres(ctx, cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	slog.Info("Successfully connected to database")
+
+	queries := NewQueries(db)
+	if cfg.Postgres.Index {
+		idx := index.New(queries)
+		go idx.Run(ctx, index.FromPool(db.Current))
+		return idx, db, nil
+	}
+
+	return queries, db, nil
+}
+
+// nopCloser stands in for the pool when there is no database
+type nopCloser struct{}
+
+func (nopCloser) Close() {}
+
+// Ping checks that the database of c answers. Storage without a database, such as
+// memory storage, always does.
+func Ping(ctx context.Context, c Closer) error {
+	if p, ok := c.(interface {
+		Ping(ctx context.Context) error
+	}); ok {
+		return p.Ping(ctx)
+	}
+
+	return nil
+}
+
+// ConnectPostgres opens a pool to the first healthy database of the configured list,
+// with credentials from Vault, RDS IAM tokens or the configuration itself. Vault
+// credentials are kept renewed and, with several DSNs, the database health-checked
+// for failover until ctx is done.
+func ConnectPostgres(ctx context.Context, cfg config.Config) (*failover.Pool[*pgxpool.Pool], error) {
+	var beforeConnect func(context.Context, *pgx.ConnConfig) error
+	var rotator *vault.Rotator
+	if cfg.Vault.Enabled() {
+		var err error
+		rotator, err = vault.NewRotator(ctx, vault.NewClient(cfg.Vault.Addr, cfg.Vault.Token), cfg.Vault.DBMount, cfg.Vault.DBRole)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get database credentials from Vault: %w", err)
+		}
+		beforeConnect = rotator.BeforeConnect
+	}
+	if cfg.Postgres.IAMAuthRegion != "" {
+		tokens, err := rdsauth.New(ctx, cfg.Postgres.IAMAuthRegion)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set up RDS IAM authentication: %w", err)
+		}
+		beforeConnect = tokens.BeforeConnect
+	}
+
+	open := func(ctx context.Context, dsn string) (*pgxpool.Pool, error) {
+		return NewPostgresDB(dsn, cfg.Postgres, beforeConnect)
+	}
+	pool, err := failover.New(ctx, cfg.Postgres.ConnStrings(), open,
+		cfg.Postgres.FailoverCheckInterval, int(cfg.Postgres.FailoverThreshold))
+	if err != nil {
+		return nil, err
+	}
+	go pool.Run(ctx)
+
+	if rotator != nil {
+		// Connections made with rotated-out credentials are replaced as they are released
+		rotator.OnRotate(pool.Reset)
+		go rotator.Run(ctx)
+	}
+
+	return pool, nil
+}
+
+// NewPostgresDB opens a pool to the database of connString and pings it, with the
+// pool settings of cfg and credentials from beforeConnect when set
+func NewPostgresDB(connString string, cfg config.PostgresConfig, beforeConnect func(context.Context, *pgx.ConnConfig) error) (*pgxpool.Pool, error) {
+	poolConfig, err := pgxpool.ParseConfig(connString)
+	if err != nil {
+		return nil, err
+	}
+
+	poolConfig.MaxConns = cfg.MaxConns
+	poolConfig.MinConns = cfg.MinConns
+	poolConfig.MaxConnLifetime = cfg.MaxConnLifetime
+	poolConfig.MaxConnIdleTime = cfg.MaxConnIdleTime
+	poolConfig.HealthCheckPeriod = cfg.HealthCheckPeriod
+	poolConfig.BeforeConnect = beforeConnect
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = pool.Ping(context.Background()); err != nil {
+		pool.Close()
+		return nil, err
+	}
+
+	return pool, nil
+}
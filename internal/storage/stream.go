@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"context"
+
+	"golang-test-task/internal/storage/sqlc"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Streamer is a sqlc.Querier that can hand out the stored numbers one at a time,
+// so listing them takes memory independent of how many there are
+type Streamer interface {
+	sqlc.Querier
+	// StreamNumbersSorted calls yield with every stored number in ascending order,
+	// stopping at the first error yield returns and returning it
+	StreamNumbersSorted(ctx context.Context, yield func(number int64) error) error
+}
+
+// StreamNumbersSorted streams the numbers of queries when it is a Streamer, and
+// otherwise yields them from GetAllNumbersSorted
+func StreamNumbersSorted(ctx context.Context, queries sqlc.Querier, yield func(number int64) error) error {
+	if s, ok := queries.(Streamer); ok {
+		return s.StreamNumbersSorted(ctx, yield)
+	}
+
+	numbers, err := queries.GetAllNumbersSorted(ctx)
+	if err != nil {
+		return err
+	}
+	for _, num := range numbers {
+		if err := yield(num.Number); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SortedNumbers returns the numbers of queries in ascending order without their ids
+// and creation times, scanned straight into the slice where queries can, so lists
+// that need only the values never allocate a row for each
+func SortedNumbers(ctx context.Context, queries sqlc.Querier) ([]int64, error) {
+	if l, ok := queries.(interface {
+		SortedNumbers(ctx context.Context) ([]int64, error)
+	}); ok {
+		return l.SortedNumbers(ctx)
+	}
+
+	numbers := []int64{}
+	err := StreamNumbersSorted(ctx, queries, func(number int64) error {
+		numbers = append(numbers, number)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return numbers, nil
+}
+
+// numbersSorted reads only the numbers, in the order of GetAllNumbersSorted
+const numbersSorted = `SELECT number FROM numbers ORDER BY number ASC`
+
+// Queries are the generated queries of a PostgreSQL database, streaming lists
+// straight from its rows
+type Queries struct {
+	*sqlc.Queries
+	db sqlc.DBTX
+}
+
+var _ Streamer = (*Queries)(nil)
+
+func NewQueries(db sqlc.DBTX) *Queries {
+	return &Queries{Queries: sqlc.New(db), db: db}
+}
+
+func (q *Queries) StreamNumbersSorted(ctx context.Context, yield func(number int64) error) error {
+	rows, err := q.db.Query(ctx, numbersSorted)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var number int64
+	for rows.Next() {
+		if err := rows.Scan(&number); err != nil {
+			return err
+		}
+		if err := yield(number); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func (q *Queries) SortedNumbers(ctx context.Context) ([]int64, error) {
+	rows, err := q.db.Query(ctx, numbersSorted)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowTo[int64])
+}
@@ -0,0 +1,131 @@
+// Package vault fetches short-lived PostgreSQL credentials from the HashiCorp Vault
+// database secrets engine and keeps them fresh for a connection pool.
+// It talks to the Vault HTTP API directly and needs no Vault SDK.
+package vault
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Client is a minimal Vault HTTP API client authenticated with a token
+type Client struct {
+	addr  string
+	token string
+	http  *http.Client
+}
+
+// NewClient returns a client for the Vault server at addr, e.g. https://vault:8200
+func NewClient(addr, token string) *Client {
+	return &Client{
+		addr:  strings.TrimSuffix(addr, "/"),
+		token: token,
+		http:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Credentials are database credentials leased from Vault
+type Credentials struct {
+	Username string
+	Password string
+	LeaseID  string
+	// LeaseDuration is how long the credentials stay valid unless renewed
+	LeaseDuration time.Duration
+	Renewable     bool
+}
+
+// DatabaseCredentials generates new credentials for role from the database secrets engine at mount
+func (c *Client) DatabaseCredentials(ctx context.Context, mount, role string) (Credentials, error) {
+	var resp struct {
+		LeaseID       string `json:"lease_id"`
+		LeaseDuration int64  `json:"lease_duration"`
+		Renewable     bool   `json:"renewable"`
+		Data          struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+		} `json:"data"`
+	}
+
+	path := "/v1/" + strings.Trim(mount, "/") + "/creds/" + url.PathEscape(role)
+	if err := c.do(ctx, http.MethodGet, path, nil, &resp); err != nil {
+		return Credentials{}, fmt.Errorf("failed to read database credentials: %w", err)
+	}
+	if resp.Data.Username == "" {
+		return Credentials{}, fmt.Errorf("failed to read database credentials: response has no username")
+	}
+
+	return Credentials{
+		Username:      resp.Data.Username,
+		Password:      resp.Data.Password,
+		LeaseID:       resp.LeaseID,
+		LeaseDuration: time.Duration(resp.LeaseDuration) * time.Second,
+		Renewable:     resp.Renewable,
+	}, nil
+}
+
+// RenewLease extends the lease by increment and returns the duration Vault granted,
+// which is shorter than increment once the lease approaches its maximum TTL
+func (c *Client) RenewLease(ctx context.Context, leaseID string, increment time.Duration) (time.Duration, error) {
+	req := struct {
+		LeaseID   string `json:"lease_id"`
+		Increment int64  `json:"increment"`
+	}{leaseID, int64(increment / time.Second)}
+
+	var resp struct {
+		LeaseDuration int64 `json:"lease_duration"`
+	}
+
+	if err := c.do(ctx, http.MethodPut, "/v1/sys/leases/renew", req, &resp); err != nil {
+		return 0, fmt.Errorf("failed to renew lease: %w", err)
+	}
+
+	return time.Duration(resp.LeaseDuration) * time.Second, nil
+}
+
+// do sends a request and decodes the JSON response into out, turning Vault's
+// {"errors": [...]} bodies into errors
+func (c *Client) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.addr+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", c.token)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		var vaultErr struct {
+			Errors []string `json:"errors"`
+		}
+		json.NewDecoder(resp.Body).Decode(&vaultErr)
+		if len(vaultErr.Errors) > 0 {
+			return fmt.Errorf("vault returned %s: %s", resp.Status, strings.Join(vaultErr.Errors, "; "))
+		}
+		return fmt.Errorf("vault returned %s", resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
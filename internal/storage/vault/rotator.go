@@ -0,0 +1,135 @@
+package vault
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// retryDelay is how long the Rotator waits after a failed Vault request
+const retryDelay = 5 * time.Second
+
+// Rotator holds the current database credentials for a Vault role. It renews the
+// lease before it expires and, once the lease can no longer be extended, fetches
+// new credentials and notifies OnRotate so the pool can replace its connections.
+type Rotator struct {
+	client *Client
+	mount  string
+	role   string
+
+	mu       sync.Mutex
+	current  Credentials
+	onRotate func()
+}
+
+// NewRotator fetches the first credentials for role so a pool can connect immediately
+func NewRotator(ctx context.Context, client *Client, mount, role string) (*Rotator, error) {
+	creds, err := client.DatabaseCredentials(ctx, mount, role)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Rotator{
+		client:  client,
+		mount:   mount,
+		role:    role,
+		current: creds,
+	}, nil
+}
+
+// Credentials returns the current credentials
+func (r *Rotator) Credentials() Credentials {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.current
+}
+
+// OnRotate registers f to run after new credentials replace the old ones, typically pool.Reset
+func (r *Rotator) OnRotate(f func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.onRotate = f
+}
+
+// BeforeConnect is a pgxpool.Config.BeforeConnect hook that makes every new
+// connection log in with the current credentials
+func (r *Rotator) BeforeConnect(ctx context.Context, cfg *pgx.ConnConfig) error {
+	creds := r.Credentials()
+	cfg.User = creds.Username
+	cfg.Password = creds.Password
+
+	return nil
+}
+
+// Run renews or rotates the credentials until ctx is done
+func (r *Rotator) Run(ctx context.Context) {
+	creds := r.Credentials()
+	if creds.LeaseDuration <= 0 {
+		// The lease never expires
+		return
+	}
+
+	lease := creds.LeaseDuration
+	wait := renewalWait(lease)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+
+		creds = r.Credentials()
+		if creds.Renewable {
+			granted, err := r.client.RenewLease(ctx, creds.LeaseID, creds.LeaseDuration)
+			switch {
+			case err != nil:
+				slog.Warn("Failed to renew database credentials lease, rotating", "error", err)
+			case granted >= creds.LeaseDuration/2:
+				lease = granted
+				wait = renewalWait(lease)
+				slog.Debug("Renewed database credentials lease", "duration", granted)
+				continue
+			default:
+				slog.Info("Database credentials lease is reaching its maximum TTL, rotating", "granted", granted)
+			}
+		}
+
+		if err := r.rotate(ctx); err != nil {
+			slog.Error("Failed to rotate database credentials", "error", err)
+			wait = min(retryDelay, lease/4)
+			continue
+		}
+		lease = r.Credentials().LeaseDuration
+		wait = renewalWait(lease)
+	}
+}
+
+// rotate replaces the current credentials with new ones and notifies OnRotate
+func (r *Rotator) rotate(ctx context.Context) error {
+	creds, err := r.client.DatabaseCredentials(ctx, r.mount, r.role)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.current = creds
+	onRotate := r.onRotate
+	r.mu.Unlock()
+
+	slog.Info("Rotated database credentials", "username", creds.Username, "lease_duration", creds.LeaseDuration)
+	if onRotate != nil {
+		onRotate()
+	}
+
+	return nil
+}
+
+// renewalWait leaves a third of the lease as headroom for renewing it
+func renewalWait(lease time.Duration) time.Duration {
+	return lease * 2 / 3
+}
@@ -0,0 +1,120 @@
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeVault serves the database secrets engine and lease renewal endpoints
+type fakeVault struct {
+	*httptest.Server
+
+	issued   atomic.Int32
+	renewals atomic.Int32
+	// renewGrants are the lease durations returned by successive renewals, in seconds
+	mu          sync.Mutex
+	renewGrants []int64
+}
+
+func newFakeVault(t *testing.T, renewGrants ...int64) *fakeVault {
+	t.Helper()
+
+	v := &fakeVault{renewGrants: renewGrants}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/database/creds/app", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]any{"errors": []string{"permission denied"}})
+			return
+		}
+		n := v.issued.Add(1)
+		json.NewEncoder(w).Encode(map[string]any{
+			"lease_id":       fmt.Sprintf("database/creds/app/%d", n),
+			"lease_duration": 1,
+			"renewable":      true,
+			"data":           map[string]string{"username": fmt.Sprintf("user-%d", n), "password": "secret"},
+		})
+	})
+	mux.HandleFunc("PUT /v1/sys/leases/renew", func(w http.ResponseWriter, r *http.Request) {
+		v.renewals.Add(1)
+
+		v.mu.Lock()
+		grant := int64(0)
+		if len(v.renewGrants) > 0 {
+			grant, v.renewGrants = v.renewGrants[0], v.renewGrants[1:]
+		}
+		v.mu.Unlock()
+
+		json.NewEncoder(w).Encode(map[string]any{"lease_duration": grant})
+	})
+	v.Server = httptest.NewServer(mux)
+	t.Cleanup(v.Close)
+
+	return v
+}
+
+func TestClient_DatabaseCredentials(t *testing.T) {
+	v := newFakeVault(t)
+
+	creds, err := NewClient(v.URL+"/", "root").DatabaseCredentials(context.Background(), "database", "app")
+	require.NoError(t, err)
+	assert.Equal(t, Credentials{
+		Username:      "user-1",
+		Password:      "secret",
+		LeaseID:       "database/creds/app/1",
+		LeaseDuration: time.Second,
+		Renewable:     true,
+	}, creds)
+}
+
+func TestClient_Errors(t *testing.T) {
+	v := newFakeVault(t)
+
+	_, err := NewClient(v.URL, "wrong").DatabaseCredentials(context.Background(), "database", "app")
+	assert.ErrorContains(t, err, "permission denied")
+
+	_, err = NewClient(v.URL, "root").DatabaseCredentials(context.Background(), "database", "missing")
+	assert.ErrorContains(t, err, "404")
+}
+
+func TestRotator_BeforeConnect(t *testing.T) {
+	v := newFakeVault(t)
+
+	r, err := NewRotator(context.Background(), NewClient(v.URL, "root"), "database", "app")
+	require.NoError(t, err)
+
+	cfg := &pgx.ConnConfig{}
+	require.NoError(t, r.BeforeConnect(context.Background(), cfg))
+	assert.Equal(t, "user-1", cfg.User)
+	assert.Equal(t, "secret", cfg.Password)
+}
+
+func TestRotator_RenewsThenRotates(t *testing.T) {
+	// The first renewal extends the lease, the second hits the maximum TTL
+	v := newFakeVault(t, 1, 0)
+
+	r, err := NewRotator(context.Background(), NewClient(v.URL, "root"), "database", "app")
+	require.NoError(t, err)
+
+	var rotations atomic.Int32
+	r.OnRotate(func() { rotations.Add(1) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.Run(ctx)
+
+	require.Eventually(t, func() bool { return rotations.Load() == 1 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, int32(2), v.renewals.Load())
+	assert.Equal(t, "user-2", r.Credentials().Username)
+}
@@ -0,0 +1,50 @@
+package gqlapi
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"golang-test-task/internal/service"
+)
+
+// codedError is an error GraphQL clients are told of, with its code in the
+// extensions of the error, as GraphQL servers commonly do
+type codedError struct {
+	message string
+	code    string
+}
+
+func (e *codedError) Error() string {
+	return e.message
+}
+
+func (e *codedError) Extensions() map[string]any {
+	return map[string]any{"code": e.code}
+}
+
+// serviceErrors are the domain errors of the service and their codes
+var serviceErrors = []struct {
+	err  error
+	code string
+}{
+	{service.ErrNotFound, "NOT_FOUND"},
+	{service.ErrDuplicate, "ALREADY_EXISTS"},
+	{service.ErrStorageUnavailable, "UNAVAILABLE"},
+	{context.Canceled, "CANCELED"},
+}
+
+// serviceError logs err, an error of the service, and returns what clients are told
+// of it, as the other APIs do: the domain error it wraps, never the storage failure
+// behind it
+func serviceError(ctx context.Context, err error) error {
+	for _, domain := range serviceErrors {
+		if errors.Is(err, domain.err) {
+			slog.WarnContext(ctx, "GraphQL request failed", "error", err)
+			return &codedError{message: domain.err.Error(), code: domain.code}
+		}
+	}
+	slog.ErrorContext(ctx, "GraphQL request failed", "error", err)
+
+	return &codedError{message: "internal error", code: "INTERNAL"}
+}
@@ -0,0 +1,85 @@
+package gqlapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+// request is a GraphQL request as sent over HTTP
+type request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// NewHandler serves schema at POST requests with a JSON body. A request accepting
+// text/event-stream gets its results as server-sent events, one next event per
+// result and a complete event at the end, following the distinct connections mode
+// of the GraphQL over SSE protocol; that is how subscriptions are served, so no
+// WebSocket is needed.
+func NewHandler(schema *graphql.Schema) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "GraphQL requests must be POSTed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]any{"errors": []map[string]string{{"message": "invalid request body: " + err.Error()}}})
+			return
+		}
+
+		if strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+			serveEvents(w, r, schema, req)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, schema.Exec(r.Context(), req.Query, req.OperationName, req.Variables))
+	})
+}
+
+// serveEvents streams the results of req until they end or the client goes away
+func serveEvents(w http.ResponseWriter, r *http.Request, schema *graphql.Schema, req request) {
+	results, err := schema.Subscribe(r.Context(), req.Query, req.OperationName, req.Variables)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"errors": []map[string]string{{"message": err.Error()}}})
+		return
+	}
+
+	// A subscription outlives any write timeout the server has
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	rc.Flush()
+
+	for result := range results {
+		data, err := json.Marshal(result)
+		if err != nil {
+			break
+		}
+		if _, err := fmt.Fprintf(w, "event: next\ndata: %s\n\n", data); err != nil {
+			return
+		}
+		rc.Flush()
+	}
+
+	fmt.Fprint(w, "event: complete\ndata:\n\n")
+	rc.Flush()
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	json.NewEncoder(w).Encode(v)
+}
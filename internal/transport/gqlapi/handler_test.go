@@ -0,0 +1,124 @@
+package gqlapi
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang-test-task/internal/storage/memstore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_Query(t *testing.T) {
+	srv := httptest.NewServer(NewHandler(NewSchema(NewNotifier(memstore.New()))))
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL, "application/json", strings.NewReader(`{"query": "mutation { addNumber(number: 7) { number } }"}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+	resp, err = http.Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+	resp, err = http.Post(srv.URL, "application/json", strings.NewReader(`{`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+// TestHandler_Subscription tests that a subscriber gets numbers inserted by anyone
+// through the notifier as server-sent events
+func TestHandler_Subscription(t *testing.T) {
+	notifier := NewNotifier(memstore.New())
+	srv := httptest.NewServer(NewHandler(NewSchema(notifier)))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, srv.URL, strings.NewReader(`{"query": "subscription { numberAdded { number } }"}`))
+	require.NoError(t, err)
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	// The subscription is registered once the handler answered, but retry in case
+	// the resolver has not run yet
+	events := bufio.NewScanner(resp.Body)
+	go func() {
+		for ctx.Err() == nil {
+			notifier.InsertNumber(ctx, 42)
+			time.Sleep(20 * time.Millisecond)
+		}
+	}()
+
+	require.True(t, events.Scan())
+	assert.Equal(t, "event: next", events.Text())
+	require.True(t, events.Scan())
+	assert.Equal(t, `data: {"data":{"numberAdded":{"number":42}}}`, events.Text())
+}
+
+func TestNotifier_DropsSlowSubscribers(t *testing.T) {
+	notifier := NewNotifier(memstore.New())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	numbers := notifier.Subscribe(ctx)
+	for i := range subscriberBuffer + 1 {
+		_, err := notifier.InsertNumber(ctx, int64(i))
+		require.NoError(t, err)
+	}
+
+	received := 0
+	for range numbers {
+		received++
+	}
+	assert.Equal(t, subscriberBuffer, received, "the channel is closed once the subscriber falls behind")
+}
+
+func TestNotifier_Batch(t *testing.T) {
+	notifier := NewNotifier(memstore.New())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	inserts := notifier.Subscribe(ctx)
+	_, err := notifier.InsertNumbers(ctx, []int64{3, 1})
+	require.NoError(t, err)
+	rows := <-inserts
+	require.Len(t, rows, 2)
+	assert.Equal(t, int64(3), rows[0].Number)
+	assert.Equal(t, int64(1), rows[1].Number)
+
+	batch := make([]int64, 10*subscriberBuffer)
+	_, err = notifier.InsertNumbers(ctx, batch)
+	require.NoError(t, err)
+	assert.Len(t, <-inserts, len(batch), "a batch longer than the buffer is sent whole")
+	_, err = notifier.InsertNumber(ctx, 7)
+	require.NoError(t, err)
+	rows, open := <-inserts
+	require.True(t, open, "the subscriber is kept")
+	assert.Equal(t, int64(7), rows[0].Number)
+}
+
+func TestNotifier_UnsubscribesOnCancel(t *testing.T) {
+	notifier := NewNotifier(memstore.New())
+	ctx, cancel := context.WithCancel(context.Background())
+
+	numbers := notifier.Subscribe(ctx)
+	cancel()
+
+	_, open := <-numbers
+	assert.False(t, open)
+}
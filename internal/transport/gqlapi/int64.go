@@ -0,0 +1,44 @@
+package gqlapi
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// int64Scalar is the Int64 scalar of the schema, for numbers beyond the 32 bits of
+// Int. It is sent as a JSON number and read from an integer literal, an integral
+// JSON number variable or a string of decimal digits; JavaScript clients need the
+// string beyond 2^53, which their numbers cannot hold exactly.
+type int64Scalar int64
+
+func (int64Scalar) ImplementsGraphQLType(name string) bool {
+	return name == "Int64"
+}
+
+func (n *int64Scalar) UnmarshalGraphQL(input any) error {
+	switch input := input.(type) {
+	case int32:
+		*n = int64Scalar(input)
+	case int:
+		*n = int64Scalar(input)
+	case int64:
+		*n = int64Scalar(input)
+	case float64:
+		// -2^63 is exact as a float64 and 2^63 is the first value past the range
+		if input != math.Trunc(input) || input < math.MinInt64 || input >= -math.MinInt64 {
+			return fmt.Errorf("%v is not a 64-bit integer", input)
+		}
+		*n = int64Scalar(input)
+	case string:
+		v, err := strconv.ParseInt(input, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a 64-bit integer", input)
+		}
+		*n = int64Scalar(v)
+	default:
+		return fmt.Errorf("%T is not an Int64", input)
+	}
+
+	return nil
+}
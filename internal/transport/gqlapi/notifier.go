@@ -0,0 +1,91 @@
+package gqlapi
+
+import (
+	"context"
+	"sync"
+
+	"golang-test-task/internal/storage"
+	"golang-test-task/internal/storage/sqlc"
+)
+
+// subscriberBuffer is how many inserts a subscriber may fall behind before it is
+// dropped. A batch is one insert, however many numbers it holds.
+const subscriberBuffer = 64
+
+// Notifier is a sqlc.Querier that tells subscribers about every number inserted
+// through it. Only inserts through this instance are seen, so the server wraps the
+// queries every API shares in it.
+type Notifier struct {
+	storage.Decorator
+
+	mu          sync.Mutex
+	subscribers map[chan []sqlc.Number]struct{}
+}
+
+func NewNotifier(queries sqlc.Querier) *Notifier {
+	return &Notifier{Decorator: storage.Decorator{Querier: queries}, subscribers: make(map[chan []sqlc.Number]struct{})}
+}
+
+// InsertNumber inserts number and, once it is stored, sends it to every subscriber
+func (n *Notifier) InsertNumber(ctx context.Context, number int64) (sqlc.Number, error) {
+	row, err := n.Querier.InsertNumber(ctx, number)
+	if err != nil {
+		return row, err
+	}
+	n.notify(row)
+
+	return row, nil
+}
+
+// InsertNumbers inserts numbers and, once they are all stored, sends each to every
+// subscriber
+func (n *Notifier) InsertNumbers(ctx context.Context, numbers []int64) ([]sqlc.Number, error) {
+	rows, err := n.Querier.InsertNumbers(ctx, numbers)
+	if err != nil {
+		return rows, err
+	}
+	n.notify(rows...)
+
+	return rows, nil
+}
+
+// notify sends the rows of one insert to every subscriber together, so that a batch
+// takes a single place in their buffers
+func (n *Notifier) notify(rows ...sqlc.Number) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for ch := range n.subscribers {
+		select {
+		case ch <- rows:
+		default:
+			// Closing tells a subscriber that is too slow it missed numbers, rather
+			// than skipping them silently or holding up the insert
+			delete(n.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Subscribe returns the rows of every insert from now on, a batch at a time. The
+// channel is closed once ctx is done, or once the subscriber falls subscriberBuffer
+// inserts behind.
+func (n *Notifier) Subscribe(ctx context.Context) <-chan []sqlc.Number {
+	ch := make(chan []sqlc.Number, subscriberBuffer)
+
+	n.mu.Lock()
+	n.subscribers[ch] = struct{}{}
+	n.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		if _, ok := n.subscribers[ch]; ok {
+			delete(n.subscribers, ch)
+			close(ch)
+		}
+	}()
+
+	return ch
+}
@@ -0,0 +1,224 @@
+// Package gqlapi serves the numbers over GraphQL, for frontend teams standardized
+// on it: a paginated sorted list, statistics, an addNumber mutation and a
+// subscription to new numbers, all over the same storage as the HTTP API.
+package gqlapi
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+
+	"golang-test-task/internal/service"
+	"golang-test-task/internal/storage/sqlc"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+//go:embed schema.graphql
+var schemaSource string
+
+// maxPageSize bounds the first argument of numbers
+const maxPageSize = 1000
+
+// NewSchema parses the schema with resolvers reading and writing through
+// notifier, which the numberAdded subscription listens to. They go through the
+// service like the other APIs, so they fail with the same domain errors.
+func NewSchema(notifier *Notifier) *graphql.Schema {
+	return graphql.MustParseSchema(schemaSource, &resolver{numbers: service.New(notifier), notifier: notifier}, graphql.UseStringDescriptions())
+}
+
+type resolver struct {
+	numbers  *service.Numbers
+	notifier *Notifier
+}
+
+// Numbers lists a page of the numbers ordered by value, then by id, with the keyset
+// query and the cursors of GET /numbers, so a page costs the same however deep it is
+func (r *resolver) Numbers(ctx context.Context, args struct {
+	First int32
+	After *string
+}) (*connectionResolver, error) {
+	if args.First < 0 || args.First > maxPageSize {
+		return nil, fmt.Errorf("first must be within [0, %d], got %d", maxPageSize, args.First)
+	}
+
+	// One row more than the page holds tells whether there is a next page
+	params := sqlc.ListNumbersPageParams{MinNumber: math.MinInt64, MaxNumber: math.MaxInt64, PageLimit: args.First + 1}
+	if args.After != nil {
+		number, id, ok := service.DecodeCursor(*args.After)
+		if !ok {
+			return nil, errInvalidCursor
+		}
+		params.AfterNumber = pgtype.Int8{Int64: number, Valid: true}
+		params.AfterID = pgtype.UUID{Bytes: id, Valid: true}
+	}
+	page, err := r.numbers.Page(ctx, params)
+	if err != nil {
+		return nil, serviceError(ctx, err)
+	}
+
+	hasNext := len(page) > int(args.First)
+	if hasNext {
+		page = page[:args.First]
+	}
+
+	return &connectionResolver{numbers: r.numbers, page: page, hasNext: hasNext}, nil
+}
+
+func (r *resolver) Stats(ctx context.Context) (*statsResolver, error) {
+	stats, err := r.numbers.Stats(ctx, nil)
+	if err != nil {
+		return nil, serviceError(ctx, err)
+	}
+
+	return &statsResolver{stats}, nil
+}
+
+func (r *resolver) AddNumber(ctx context.Context, args struct{ Number int64Scalar }) (*numberResolver, error) {
+	row, err := r.numbers.InsertRow(ctx, int64(args.Number))
+	if err != nil {
+		return nil, serviceError(ctx, err)
+	}
+
+	return &numberResolver{row}, nil
+}
+
+// NumberAdded sends the numbers inserted through the notifier until the client
+// unsubscribes, or falls so far behind that it is dropped
+func (r *resolver) NumberAdded(ctx context.Context) <-chan *numberResolver {
+	inserts := r.notifier.Subscribe(ctx)
+
+	numbers := make(chan *numberResolver)
+	go func() {
+		defer close(numbers)
+		for rows := range inserts {
+			for _, row := range rows {
+				select {
+				case numbers <- &numberResolver{row}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return numbers
+}
+
+type numberResolver struct {
+	row sqlc.Number
+}
+
+func (n *numberResolver) ID() graphql.ID {
+	return graphql.ID(n.row.ID.String())
+}
+
+func (n *numberResolver) Number() int64Scalar {
+	return int64Scalar(n.row.Number)
+}
+
+func (n *numberResolver) CreatedAt() *graphql.Time {
+	if !n.row.CreatedAt.Valid {
+		return nil
+	}
+	return &graphql.Time{Time: n.row.CreatedAt.Time}
+}
+
+type connectionResolver struct {
+	numbers *service.Numbers
+	page    []sqlc.Number
+	hasNext bool
+}
+
+func (c *connectionResolver) Nodes() []*numberResolver {
+	nodes := make([]*numberResolver, len(c.page))
+	for i, row := range c.page {
+		nodes[i] = &numberResolver{row}
+	}
+
+	return nodes
+}
+
+func (c *connectionResolver) PageInfo() *pageInfoResolver {
+	info := &pageInfoResolver{hasNext: c.hasNext}
+	if len(c.page) > 0 {
+		end := service.EncodeCursor(c.page[len(c.page)-1])
+		info.endCursor = &end
+	}
+
+	return info
+}
+
+// TotalCount counts the table only when the query asks for it, as the count reads
+// every row
+func (c *connectionResolver) TotalCount(ctx context.Context) (int32, error) {
+	count, err := c.numbers.Count(ctx)
+	if err != nil {
+		return 0, serviceError(ctx, err)
+	}
+
+	return clampInt32(count), nil
+}
+
+type pageInfoResolver struct {
+	endCursor *string
+	hasNext   bool
+}
+
+func (p *pageInfoResolver) EndCursor() *string {
+	return p.endCursor
+}
+
+func (p *pageInfoResolver) HasNextPage() bool {
+	return p.hasNext
+}
+
+// statsResolver is aggregated by the storage, so that stats does not read the list
+type statsResolver struct {
+	stats sqlc.NumberStatsRow
+}
+
+func (s *statsResolver) Count() int32 {
+	return clampInt32(s.stats.Count)
+}
+
+func (s *statsResolver) Min() *int64Scalar {
+	if s.stats.Count == 0 {
+		return nil
+	}
+	min := int64Scalar(s.stats.Min)
+	return &min
+}
+
+func (s *statsResolver) Max() *int64Scalar {
+	if s.stats.Count == 0 {
+		return nil
+	}
+	max := int64Scalar(s.stats.Max)
+	return &max
+}
+
+func (s *statsResolver) Mean() *float64 {
+	if s.stats.Count == 0 {
+		return nil
+	}
+	return &s.stats.Mean
+}
+
+func (s *statsResolver) Median() *float64 {
+	if s.stats.Count == 0 {
+		return nil
+	}
+	return &s.stats.Median
+}
+
+var errInvalidCursor = errors.New("after is not a cursor returned by numbers")
+
+// clampInt32 is n, or the nearest value an Int can hold: GraphQL has no larger
+// integer for counts
+func clampInt32(n int64) int32 {
+	return int32(min(max(n, math.MinInt32), math.MaxInt32))
+}
@@ -0,0 +1,210 @@
+package gqlapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net"
+	"syscall"
+	"testing"
+
+	"golang-test-task/internal/storage/memstore"
+	"golang-test-task/internal/storage/sqlc"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// exec runs query against a schema over notifier and decodes its data into v
+func exec(t *testing.T, notifier *Notifier, query string, variables map[string]any, v any) {
+	t.Helper()
+
+	resp := NewSchema(notifier).Exec(context.Background(), query, "", variables)
+	require.Empty(t, resp.Errors)
+	require.NoError(t, json.Unmarshal(resp.Data, v))
+}
+
+func TestResolver_AddNumberAndStats(t *testing.T) {
+	notifier := NewNotifier(memstore.New())
+
+	for _, n := range []int{5, -3, 2, 8} {
+		var data struct {
+			AddNumber struct{ ID, CreatedAt string }
+		}
+		exec(t, notifier, `mutation($n: Int64!) { addNumber(number: $n) { id createdAt } }`, map[string]any{"n": n}, &data)
+		assert.NotEmpty(t, data.AddNumber.ID)
+		assert.NotEmpty(t, data.AddNumber.CreatedAt)
+	}
+
+	var data struct {
+		Stats struct {
+			Count, Min, Max int
+			Mean, Median    float64
+		}
+	}
+	exec(t, notifier, `{ stats { count min max mean median } }`, nil, &data)
+	assert.Equal(t, 4, data.Stats.Count)
+	assert.Equal(t, -3, data.Stats.Min)
+	assert.Equal(t, 8, data.Stats.Max)
+	assert.Equal(t, 3.0, data.Stats.Mean)
+	assert.Equal(t, 3.5, data.Stats.Median)
+}
+
+func TestResolver_EmptyStats(t *testing.T) {
+	var data struct {
+		Stats struct {
+			Count int
+			Min   *int
+			Mean  *float64
+		}
+	}
+	exec(t, NewNotifier(memstore.New()), `{ stats { count min mean } }`, nil, &data)
+	assert.Zero(t, data.Stats.Count)
+	assert.Nil(t, data.Stats.Min)
+	assert.Nil(t, data.Stats.Mean)
+}
+
+func TestResolver_Pagination(t *testing.T) {
+	store := memstore.New()
+	for _, n := range []int64{4, 1, 3, 1, 2} {
+		_, err := store.InsertNumber(context.Background(), n)
+		require.NoError(t, err)
+	}
+	notifier := NewNotifier(store)
+
+	type page struct {
+		Numbers struct {
+			Nodes    []struct{ Number int }
+			PageInfo struct {
+				EndCursor   *string
+				HasNextPage bool
+			}
+			TotalCount int
+		}
+	}
+	const query = `query($after: String) { numbers(first: 2, after: $after) { nodes { number } pageInfo { endCursor hasNextPage } totalCount } }`
+
+	var got []int
+	variables := map[string]any{}
+	for range 3 {
+		var p page
+		exec(t, notifier, query, variables, &p)
+		assert.Equal(t, 5, p.Numbers.TotalCount)
+		for _, node := range p.Numbers.Nodes {
+			got = append(got, node.Number)
+		}
+		require.NotNil(t, p.Numbers.PageInfo.EndCursor)
+		variables["after"] = *p.Numbers.PageInfo.EndCursor
+		if !p.Numbers.PageInfo.HasNextPage {
+			break
+		}
+	}
+	assert.Equal(t, []int{1, 1, 2, 3, 4}, got, "duplicates are neither skipped nor repeated across pages")
+
+	var last page
+	exec(t, notifier, query, variables, &last)
+	assert.Empty(t, last.Numbers.Nodes)
+	assert.Nil(t, last.Numbers.PageInfo.EndCursor)
+}
+
+// TestResolver_Int64 tests that numbers beyond 32 bits go in as literals, JSON
+// numbers or strings and come out as JSON numbers
+func TestResolver_Int64(t *testing.T) {
+	notifier := NewNotifier(memstore.New())
+
+	var added struct{ AddNumber struct{ Number json.Number } }
+	exec(t, notifier, `mutation { addNumber(number: "-9223372036854775808") { number } }`, nil, &added)
+	assert.Equal(t, json.Number("-9223372036854775808"), added.AddNumber.Number)
+	exec(t, notifier, `mutation($n: Int64!) { addNumber(number: $n) { number } }`, map[string]any{"n": float64(1 << 40)}, &added)
+	assert.Equal(t, json.Number("1099511627776"), added.AddNumber.Number)
+	exec(t, notifier, `mutation($n: Int64!) { addNumber(number: $n) { number } }`, map[string]any{"n": "9223372036854775807"}, &added)
+	assert.Equal(t, json.Number("9223372036854775807"), added.AddNumber.Number)
+
+	var data struct {
+		Stats struct{ Min, Max json.Number }
+	}
+	exec(t, notifier, `{ stats { min max } }`, nil, &data)
+	assert.Equal(t, json.Number("-9223372036854775808"), data.Stats.Min)
+	assert.Equal(t, json.Number("9223372036854775807"), data.Stats.Max)
+
+	schema := NewSchema(notifier)
+	for _, n := range []any{1.5, float64(1 << 63), "1e3", "9223372036854775808"} {
+		resp := schema.Exec(context.Background(), `mutation($n: Int64!) { addNumber(number: $n) { id } }`, "", map[string]any{"n": n})
+		assert.NotEmpty(t, resp.Errors, "%v", n)
+	}
+}
+
+func TestResolver_InvalidArguments(t *testing.T) {
+	schema := NewSchema(NewNotifier(memstore.New()))
+
+	for _, query := range []string{
+		`{ numbers(first: 1001) { totalCount } }`,
+		`{ numbers(first: -1) { totalCount } }`,
+		`{ numbers(after: "bm90IGEgY3Vyc29y") { totalCount } }`,
+		`{ numbers(after: "%%%") { totalCount } }`,
+	} {
+		resp := schema.Exec(context.Background(), query, "", nil)
+		assert.NotEmpty(t, resp.Errors, query)
+	}
+}
+
+// countingQueries counts the table as count rows, however many it holds, and how
+// often it is counted
+type countingQueries struct {
+	*memstore.Store
+	count int64
+	calls int
+}
+
+func (q *countingQueries) CountNumbers(context.Context) (int64, error) {
+	q.calls++
+	return q.count, nil
+}
+
+// TestResolver_TotalCount tests that the table is counted only when totalCount is
+// asked for, and that a count beyond an Int is clamped rather than wrapped
+func TestResolver_TotalCount(t *testing.T) {
+	queries := &countingQueries{Store: memstore.New(), count: 1 << 40}
+	notifier := NewNotifier(queries)
+
+	var page struct {
+		Numbers struct{ TotalCount int }
+	}
+	exec(t, notifier, `{ numbers { nodes { number } } }`, nil, &page)
+	assert.Zero(t, queries.calls, "a page alone does not count the table")
+
+	exec(t, notifier, `{ numbers { totalCount } }`, nil, &page)
+	assert.Equal(t, 1, queries.calls)
+	assert.Equal(t, math.MaxInt32, page.Numbers.TotalCount)
+}
+
+// failingQueries fails to aggregate because the database is unreachable and to
+// insert for a reason of its own
+type failingQueries struct {
+	*memstore.Store
+}
+
+func (failingQueries) NumberStats(context.Context, []float64) (sqlc.NumberStatsRow, error) {
+	return sqlc.NumberStatsRow{}, &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+}
+
+func (failingQueries) InsertNumber(context.Context, int64) (sqlc.Number, error) {
+	return sqlc.Number{}, errors.New("relation numbers does not exist")
+}
+
+// TestResolver_Errors tests that failures are reported as the domain errors of the
+// service, with their code, never with the storage failure behind them
+func TestResolver_Errors(t *testing.T) {
+	schema := NewSchema(NewNotifier(failingQueries{memstore.New()}))
+
+	resp := schema.Exec(context.Background(), `{ stats { count } }`, "", nil)
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, "storage unavailable", resp.Errors[0].Message)
+	assert.Equal(t, map[string]any{"code": "UNAVAILABLE"}, resp.Errors[0].Extensions)
+
+	resp = schema.Exec(context.Background(), `mutation { addNumber(number: 1) { id } }`, "", nil)
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, "internal error", resp.Errors[0].Message)
+	assert.Equal(t, map[string]any{"code": "INTERNAL"}, resp.Errors[0].Extensions)
+}
@@ -0,0 +1,169 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"golang-test-task/internal/storage/memstore"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAMQP is a session whose deliveries the test sends, recording how each is settled
+type fakeAMQP struct {
+	deliveries chan amqp.Delivery
+	prefetch   int
+
+	mu      sync.Mutex
+	settled map[uint64]string
+	closed  bool
+}
+
+func newFakeAMQP() *fakeAMQP {
+	return &fakeAMQP{deliveries: make(chan amqp.Delivery), settled: map[uint64]string{}}
+}
+
+func (f *fakeAMQP) Qos(prefetchCount, _ int, _ bool) error {
+	f.prefetch = prefetchCount
+	return nil
+}
+
+func (f *fakeAMQP) Consume(_, _ string, _, _, _, _ bool, _ amqp.Table) (<-chan amqp.Delivery, error) {
+	return f.deliveries, nil
+}
+
+func (f *fakeAMQP) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+
+	return nil
+}
+
+func (f *fakeAMQP) Ack(tag uint64, _ bool) error {
+	return f.settle(tag, "ack")
+}
+
+func (f *fakeAMQP) Nack(tag uint64, _ bool, requeue bool) error {
+	if requeue {
+		return f.settle(tag, "requeue")
+	}
+	return f.settle(tag, "reject")
+}
+
+func (f *fakeAMQP) Reject(tag uint64, requeue bool) error {
+	return f.Nack(tag, false, requeue)
+}
+
+func (f *fakeAMQP) settle(tag uint64, how string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.settled[tag] = how
+
+	return nil
+}
+
+// deliver sends a message with body and tag and waits until it is settled
+func (f *fakeAMQP) deliver(t *testing.T, tag uint64, body string) string {
+	t.Helper()
+
+	f.deliveries <- amqp.Delivery{Acknowledger: f, DeliveryTag: tag, Body: []byte(body)}
+
+	var how string
+	require.Eventually(t, func() bool {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		how = f.settled[tag]
+		return how != ""
+	}, 5*time.Second, time.Millisecond)
+
+	return how
+}
+
+// startAMQP runs c against sessions, one per connection attempt, until the test ends
+func startAMQP(t *testing.T, c *AMQPConsumer, sessions ...*fakeAMQP) (cancel func()) {
+	t.Helper()
+
+	var mu sync.Mutex
+	c.dial = func() (amqpSession, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if len(sessions) == 0 {
+			return nil, errors.New("connection refused")
+		}
+		session := sessions[0]
+		sessions = sessions[1:]
+		return session, nil
+	}
+	c.backoff = time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		c.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	return cancel
+}
+
+func TestAMQPConsumer_SettlesMessages(t *testing.T) {
+	store := &flakyStore{Store: memstore.New()}
+	session := newFakeAMQP()
+	startAMQP(t, NewAMQPConsumer(store, AMQPOptions{Queue: "numbers", Prefetch: 50, MaxAttempts: 3}), session)
+
+	assert.Equal(t, "ack", session.deliver(t, 1, "12"))
+	assert.Equal(t, "reject", session.deliver(t, 2, "twelve"), "a message without a number is dead-lettered")
+
+	store.mu.Lock()
+	store.failures = 2
+	store.mu.Unlock()
+	assert.Equal(t, "ack", session.deliver(t, 3, "13"), "storage is retried")
+
+	store.mu.Lock()
+	store.failures = 3
+	store.mu.Unlock()
+	assert.Equal(t, "reject", session.deliver(t, 4, "14"), "the message is rejected after MaxAttempts")
+
+	assert.Equal(t, []int64{12, 13}, storedNumbers(t, store))
+	assert.Equal(t, 50, session.prefetch)
+}
+
+func TestAMQPConsumer_RequeuesOnShutdown(t *testing.T) {
+	store := &flakyStore{Store: memstore.New(), failures: 1 << 30}
+	session := newFakeAMQP()
+	cancel := startAMQP(t, NewAMQPConsumer(store, AMQPOptions{Queue: "numbers", Prefetch: 1}), session)
+
+	session.deliveries <- amqp.Delivery{Acknowledger: session, DeliveryTag: 1, Body: []byte("1")}
+	cancel()
+
+	require.Eventually(t, func() bool {
+		session.mu.Lock()
+		defer session.mu.Unlock()
+		return session.settled[1] == "requeue" && session.closed
+	}, 5*time.Second, time.Millisecond)
+}
+
+func TestAMQPConsumer_Reconnects(t *testing.T) {
+	store := memstore.New()
+	first, second := newFakeAMQP(), newFakeAMQP()
+	startAMQP(t, NewAMQPConsumer(store, AMQPOptions{Queue: "numbers", Prefetch: 1}), first, second)
+
+	assert.Equal(t, "ack", first.deliver(t, 1, "1"))
+	close(first.deliveries)
+	assert.Equal(t, "ack", second.deliver(t, 1, "2"))
+
+	assert.Equal(t, []int64{1, 2}, storedNumbers(t, store))
+	first.mu.Lock()
+	defer first.mu.Unlock()
+	assert.True(t, first.closed)
+}
@@ -0,0 +1,58 @@
+// Package ingest connects the storage to message brokers: it stores numbers received
+// from them, for producers that do not speak HTTP, and publishes the numbers stored,
+// for event-driven consumers. A received message carries one number as decimal text,
+// such as "42".
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseNumber reads the number a message body carries
+func ParseNumber(body []byte) (int64, error) {
+	text := strings.TrimSpace(string(body))
+	n, err := strconv.ParseInt(text, 10, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		return 0, fmt.Errorf("number %s is out of range [%d, %d]", text, math.MinInt64, math.MaxInt64)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a decimal integer", text)
+	}
+
+	return n, nil
+}
+
+const (
+	// defaultBackoff is the wait after the first failed attempt of retry
+	defaultBackoff = 100 * time.Millisecond
+	// maxBackoff caps the wait between attempts
+	maxBackoff = 10 * time.Second
+)
+
+// retry calls fn until it succeeds, doubling the wait between attempts from backoff
+// up to maxBackoff, so that an outage delays messages instead of losing them. It
+// gives up when ctx is done, returning ctx's error, or after attempts calls of fn
+// unless attempts is 0, returning fn's last error.
+func retry(ctx context.Context, backoff time.Duration, attempts int, what string, fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt == attempts {
+			return err
+		}
+		slog.Warn("Failed to "+what+", retrying", "error", err, "delay", backoff)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, maxBackoff)
+	}
+}
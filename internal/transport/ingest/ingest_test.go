@@ -0,0 +1,116 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"golang-test-task/internal/storage/memstore"
+	"golang-test-task/internal/storage/sqlc"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNumber(t *testing.T) {
+	for body, want := range map[string]int64{
+		"42":                   42,
+		" -7\n":                -7,
+		"9223372036854775807":  math.MaxInt64,
+		"-9223372036854775808": math.MinInt64,
+		"+3":                   3,
+		"0000000000001":        1,
+	} {
+		n, err := ParseNumber([]byte(body))
+		require.NoError(t, err, body)
+		assert.Equal(t, want, n, body)
+	}
+
+	for _, body := range []string{"", "abc", "4.2", `{"number": 1}`} {
+		_, err := ParseNumber([]byte(body))
+		assert.ErrorContains(t, err, "is not a decimal integer", body)
+	}
+
+	_, err := ParseNumber([]byte("9223372036854775808"))
+	assert.EqualError(t, err, "number 9223372036854775808 is out of range [-9223372036854775808, 9223372036854775807]")
+}
+
+func TestRetry(t *testing.T) {
+	calls := 0
+	err := retry(context.Background(), time.Millisecond, 0, "do it", func() error {
+		if calls++; calls < 3 {
+			return errors.New("not yet")
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	calls = 0
+	err = retry(context.Background(), time.Millisecond, 2, "do it", func() error {
+		calls++
+		return errors.New("never")
+	})
+	assert.EqualError(t, err, "never")
+	assert.Equal(t, 2, calls)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = retry(ctx, time.Hour, 0, "do it", func() error { return errors.New("never") })
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// flakyStore fails the first failures inserts, then stores numbers in memory. It
+// counts the single and batch inserts asked of it.
+type flakyStore struct {
+	*memstore.Store
+
+	mu       sync.Mutex
+	failures int
+	singles  int
+	batches  int
+}
+
+func (s *flakyStore) InsertNumber(ctx context.Context, number int64) (sqlc.Number, error) {
+	s.mu.Lock()
+	s.singles++
+	if s.failures > 0 {
+		s.failures--
+		s.mu.Unlock()
+		return sqlc.Number{}, errors.New("database is down")
+	}
+	s.mu.Unlock()
+
+	return s.Store.InsertNumber(ctx, number)
+}
+
+// InsertNumbers fails as a whole, taking one failure, like a single insert
+func (s *flakyStore) InsertNumbers(ctx context.Context, numbers []int64) ([]sqlc.Number, error) {
+	s.mu.Lock()
+	s.batches++
+	if s.failures > 0 {
+		s.failures--
+		s.mu.Unlock()
+		return nil, errors.New("database is down")
+	}
+	s.mu.Unlock()
+
+	return s.Store.InsertNumbers(ctx, numbers)
+}
+
+// storedNumbers lists the numbers in store in ascending order
+func storedNumbers(t *testing.T, store sqlc.Querier) []int64 {
+	t.Helper()
+
+	rows, err := store.GetAllNumbersSorted(context.Background())
+	require.NoError(t, err)
+	numbers := make([]int64, len(rows))
+	for i, row := range rows {
+		numbers[i] = row.Number
+	}
+
+	return numbers
+}
@@ -0,0 +1,182 @@
+package ingest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"time"
+
+	"golang-test-task/internal/storage/sqlc"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+const (
+	// mqttPending is how many received messages may wait to be stored before the
+	// client stops reading from the broker
+	mqttPending = 1024
+	// subscriptionRefused is the SUBACK return code of a refused subscription
+	subscriptionRefused = 0x80
+	// disconnectQuiesce is how long Disconnect lets outstanding acknowledgements go out, in milliseconds
+	disconnectQuiesce = 250
+)
+
+// MQTTOptions configure an MQTTConsumer
+type MQTTOptions struct {
+	// Broker is the broker's URL, such as tcp://host:1883 or ssl://host:8883
+	Broker string
+	// Topic is the topic filter subscribed to; it may contain wildcards
+	Topic string
+	QoS   byte
+	// ClientID identifies a persistent session, which the broker keeps across
+	// disconnects; when empty a random ID is used with a clean session
+	ClientID string
+	Username string
+	Password string
+}
+
+// mqttSubscriber is the part of mqtt.Client an MQTTConsumer subscribes with
+type mqttSubscriber interface {
+	Subscribe(topic string, qos byte, callback mqtt.MessageHandler) mqtt.Token
+}
+
+// mqttClient is the part of mqtt.Client an MQTTConsumer uses
+type mqttClient interface {
+	mqttSubscriber
+	Connect() mqtt.Token
+	Unsubscribe(topics ...string) mqtt.Token
+	Disconnect(quiesce uint)
+}
+
+// MQTTConsumer stores the numbers published to an MQTT topic, such as the readings
+// of a fleet of sensors. QoS 1 and 2 messages are acknowledged once their number is
+// stored; with a persistent session the broker redelivers those that were not after
+// a reconnect or restart.
+type MQTTConsumer struct {
+	queries sqlc.Querier
+	opts    MQTTOptions
+	// newClient creates a client that calls onConnect on every connection, so the
+	// subscription is renewed when a clean session reconnects
+	newClient func(onConnect func(mqttSubscriber)) mqttClient
+	// backoff is the first wait when storing a number or connecting fails
+	backoff time.Duration
+}
+
+// NewMQTTConsumer returns a consumer inserting through queries. It connects once Run
+// is called.
+func NewMQTTConsumer(queries sqlc.Querier, opts MQTTOptions) *MQTTConsumer {
+	clientID := opts.ClientID
+	if clientID == "" {
+		suffix := make([]byte, 8)
+		rand.Read(suffix)
+		clientID = "numbers-" + hex.EncodeToString(suffix)
+	}
+
+	return &MQTTConsumer{
+		queries: queries,
+		opts:    opts,
+		newClient: func(onConnect func(mqttSubscriber)) mqttClient {
+			return mqtt.NewClient(mqtt.NewClientOptions().
+				AddBroker(opts.Broker).
+				SetClientID(clientID).
+				SetCleanSession(opts.ClientID == "").
+				SetUsername(opts.Username).
+				SetPassword(opts.Password).
+				// Messages are acknowledged once stored, and the handler may then
+				// block without stalling the connection
+				SetAutoAckDisabled(true).
+				SetOrderMatters(false).
+				SetOnConnectHandler(func(client mqtt.Client) { onConnect(client) }).
+				SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+					slog.Warn("Lost connection to MQTT broker, reconnecting", "error", err)
+				}))
+		},
+		backoff: defaultBackoff,
+	}
+}
+
+// Run stores numbers until ctx is done. It then unsubscribes and stores the
+// messages already received, for up to drainTimeout, before disconnecting.
+func (c *MQTTConsumer) Run(ctx context.Context) {
+	msgs := make(chan mqtt.Message, mqttPending)
+	client := c.newClient(func(client mqttSubscriber) {
+		token := client.Subscribe(c.opts.Topic, c.opts.QoS, func(_ mqtt.Client, msg mqtt.Message) {
+			select {
+			case msgs <- msg:
+			case <-ctx.Done():
+			}
+		})
+		if err := wait(ctx, token); err != nil {
+			slog.Error("Failed to subscribe to MQTT topic", "topic", c.opts.Topic, "error", err)
+			return
+		}
+		if st, ok := token.(*mqtt.SubscribeToken); ok && st.Result()[c.opts.Topic] == subscriptionRefused {
+			slog.Error("MQTT broker refused the subscription", "topic", c.opts.Topic)
+			return
+		}
+		slog.Info("Consuming numbers from MQTT", "topic", c.opts.Topic, "qos", c.opts.QoS)
+	})
+
+	err := retry(ctx, c.backoff, 0, "connect to MQTT broker", func() error {
+		return wait(ctx, client.Connect())
+	})
+	if err != nil {
+		return
+	}
+	defer client.Disconnect(disconnectQuiesce)
+
+	for ctx.Err() == nil {
+		select {
+		case msg := <-msgs:
+			c.store(ctx, msg)
+		case <-ctx.Done():
+		}
+	}
+
+	drainCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), drainTimeout)
+	defer cancel()
+	if err := wait(drainCtx, client.Unsubscribe(c.opts.Topic)); err != nil {
+		slog.Warn("Failed to unsubscribe from MQTT topic", "topic", c.opts.Topic, "error", err)
+	}
+	for {
+		select {
+		case msg := <-msgs:
+			c.store(drainCtx, msg)
+		default:
+			return
+		}
+	}
+}
+
+// store inserts the number msg carries, retrying storage failures until ctx is done,
+// and acknowledges msg once it is stored. Messages without a number are logged and
+// acknowledged, since redelivering them cannot help.
+func (c *MQTTConsumer) store(ctx context.Context, msg mqtt.Message) {
+	n, err := ParseNumber(msg.Payload())
+	if err != nil {
+		slog.Warn("Skipping MQTT message without a number", "topic", msg.Topic(), "error", err)
+		msg.Ack()
+		return
+	}
+
+	err = retry(ctx, c.backoff, 0, "store number from MQTT", func() error {
+		_, err := c.queries.InsertNumber(ctx, n)
+		return err
+	})
+	if err != nil {
+		slog.Error("Failed to store number from MQTT, leaving it unacknowledged", "number", n, "error", err)
+		return
+	}
+	msg.Ack()
+}
+
+// wait waits for token to complete, or for ctx to be done
+func wait(ctx context.Context, token mqtt.Token) error {
+	select {
+	case <-token.Done():
+		return token.Error()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,181 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"golang-test-task/internal/storage/memstore"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/stretchr/testify/assert"
+)
+
+// doneToken is a completed mqtt.Token
+type doneToken struct {
+	err error
+}
+
+func (t doneToken) Wait() bool                     { return true }
+func (t doneToken) WaitTimeout(time.Duration) bool { return true }
+func (t doneToken) Error() error                   { return t.err }
+
+func (t doneToken) Done() <-chan struct{} {
+	done := make(chan struct{})
+	close(done)
+	return done
+}
+
+// fakeMQTT fails the first connectErrs connection attempts, then calls onConnect
+// and hands the subscription's handler to the test
+type fakeMQTT struct {
+	onConnect   func(mqttSubscriber)
+	connectErrs int
+	handler     chan mqtt.MessageHandler
+
+	mu           sync.Mutex
+	unsubscribed bool
+	disconnected bool
+}
+
+func (f *fakeMQTT) Connect() mqtt.Token {
+	if f.connectErrs > 0 {
+		f.connectErrs--
+		return doneToken{err: errors.New("connection refused")}
+	}
+	f.onConnect(f)
+	return doneToken{}
+}
+
+func (f *fakeMQTT) Subscribe(_ string, _ byte, callback mqtt.MessageHandler) mqtt.Token {
+	f.handler <- callback
+	return doneToken{}
+}
+
+func (f *fakeMQTT) Unsubscribe(...string) mqtt.Token {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.unsubscribed = true
+
+	return doneToken{}
+}
+
+func (f *fakeMQTT) Disconnect(uint) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.disconnected = true
+}
+
+// fakeMessage is a received message that records its acknowledgement
+type fakeMessage struct {
+	payload string
+	acked   chan struct{}
+}
+
+func newFakeMessage(payload string) *fakeMessage {
+	return &fakeMessage{payload: payload, acked: make(chan struct{})}
+}
+
+func (m *fakeMessage) Duplicate() bool   { return false }
+func (m *fakeMessage) Qos() byte         { return 1 }
+func (m *fakeMessage) Retained() bool    { return false }
+func (m *fakeMessage) Topic() string     { return "sensors/1/reading" }
+func (m *fakeMessage) MessageID() uint16 { return 1 }
+func (m *fakeMessage) Payload() []byte   { return []byte(m.payload) }
+func (m *fakeMessage) Ack()              { close(m.acked) }
+
+func (m *fakeMessage) isAcked() bool {
+	select {
+	case <-m.acked:
+		return true
+	default:
+		return false
+	}
+}
+
+// startMQTT runs c against client and returns the subscription's handler
+func startMQTT(t *testing.T, c *MQTTConsumer, client *fakeMQTT) (mqtt.MessageHandler, context.CancelFunc, <-chan struct{}) {
+	t.Helper()
+
+	client.handler = make(chan mqtt.MessageHandler, 1)
+	c.newClient = func(onConnect func(mqttSubscriber)) mqttClient {
+		client.onConnect = onConnect
+		return client
+	}
+	c.backoff = time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		c.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	select {
+	case handler := <-client.handler:
+		return handler, cancel, done
+	case <-time.After(5 * time.Second):
+		t.Fatal("the consumer did not subscribe")
+		return nil, nil, nil
+	}
+}
+
+func TestMQTTConsumer_StoresAndAcknowledges(t *testing.T) {
+	store := &flakyStore{Store: memstore.New(), failures: 2}
+	client := &fakeMQTT{connectErrs: 2}
+	handler, _, _ := startMQTT(t, NewMQTTConsumer(store, MQTTOptions{Topic: "sensors/+/reading", QoS: 1}), client)
+
+	stored, invalid := newFakeMessage("21"), newFakeMessage("21.5")
+	handler(nil, stored)
+	handler(nil, invalid)
+
+	for _, msg := range []*fakeMessage{stored, invalid} {
+		select {
+		case <-msg.acked:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("message %q was not acknowledged", msg.payload)
+		}
+	}
+	assert.Equal(t, []int64{21}, storedNumbers(t, store))
+}
+
+func TestMQTTConsumer_LeavesUnstoredUnacknowledged(t *testing.T) {
+	store := &flakyStore{Store: memstore.New(), failures: 1 << 30}
+	client := &fakeMQTT{}
+	handler, cancel, done := startMQTT(t, NewMQTTConsumer(store, MQTTOptions{Topic: "sensors/+/reading", QoS: 1}), client)
+
+	msg := newFakeMessage("21")
+	handler(nil, msg)
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	<-done
+
+	assert.False(t, msg.isAcked(), "the broker redelivers an unacknowledged message")
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	assert.True(t, client.unsubscribed)
+	assert.True(t, client.disconnected)
+}
+
+func TestMQTTConsumer_StoresReceivedOnShutdown(t *testing.T) {
+	store := memstore.New()
+	handler, cancel, done := startMQTT(t, NewMQTTConsumer(store, MQTTOptions{Topic: "sensors/+/reading", QoS: 1}), &fakeMQTT{})
+
+	msgs := []*fakeMessage{newFakeMessage("1"), newFakeMessage("2"), newFakeMessage("3")}
+	for _, msg := range msgs {
+		handler(nil, msg)
+	}
+	cancel()
+	<-done
+
+	assert.ElementsMatch(t, []int64{1, 2, 3}, storedNumbers(t, store))
+	for _, msg := range msgs {
+		assert.True(t, msg.isAcked())
+	}
+}
@@ -0,0 +1,155 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"golang-test-task/internal/storage"
+	"golang-test-task/internal/storage/sqlc"
+
+	"github.com/nats-io/nats.go"
+)
+
+const (
+	// natsPending is how many received messages may wait to be stored; beyond that
+	// the client drops messages and reports the subscriber as a slow consumer
+	natsPending = 1024
+	// drainTimeout bounds storing the messages already received once shutdown begins
+	drainTimeout = 5 * time.Second
+)
+
+// natsSubscriber is the part of *nats.Conn a NATSSubscriber uses
+type natsSubscriber interface {
+	ChanQueueSubscribe(subj, queue string, ch chan *nats.Msg) (*nats.Subscription, error)
+}
+
+// NATSSubscriber stores the numbers published to a NATS subject. Core NATS delivers
+// at most once: messages sent while no instance is subscribed, or dropped because
+// storage fell behind, are lost.
+type NATSSubscriber struct {
+	queries sqlc.Querier
+	conn    natsSubscriber
+	subject string
+	// queue is the queue group, so that each message is stored by one instance only
+	queue string
+	// backoff is the first wait when storing a number fails
+	backoff time.Duration
+}
+
+// NewNATSSubscriber returns a subscriber to subject in the queue group queue,
+// inserting through queries. It subscribes once Run is called.
+func NewNATSSubscriber(queries sqlc.Querier, conn *nats.Conn, subject, queue string) *NATSSubscriber {
+	return &NATSSubscriber{queries: queries, conn: conn, subject: subject, queue: queue, backoff: defaultBackoff}
+}
+
+// Run stores numbers until ctx is done. It then unsubscribes and stores the
+// messages already received, for up to drainTimeout, before returning.
+func (s *NATSSubscriber) Run(ctx context.Context) {
+	msgs := make(chan *nats.Msg, natsPending)
+	sub, err := s.conn.ChanQueueSubscribe(s.subject, s.queue, msgs)
+	if err != nil {
+		slog.Error("Failed to subscribe to NATS", "subject", s.subject, "error", err)
+		return
+	}
+	slog.Info("Consuming numbers from NATS", "subject", s.subject, "queue", s.queue)
+
+	for ctx.Err() == nil {
+		select {
+		case msg := <-msgs:
+			s.store(ctx, msg)
+		case <-ctx.Done():
+		}
+	}
+
+	if err := sub.Unsubscribe(); err != nil && !errors.Is(err, nats.ErrConnectionClosed) {
+		slog.Warn("Failed to unsubscribe from NATS", "subject", s.subject, "error", err)
+	}
+	drainCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), drainTimeout)
+	defer cancel()
+	for {
+		select {
+		case msg := <-msgs:
+			s.store(drainCtx, msg)
+		default:
+			return
+		}
+	}
+}
+
+// store inserts the number msg carries, retrying storage failures until ctx is done.
+// Messages without a number are logged and skipped.
+func (s *NATSSubscriber) store(ctx context.Context, msg *nats.Msg) {
+	n, err := ParseNumber(msg.Data)
+	if err != nil {
+		slog.Warn("Skipping NATS message without a number", "subject", msg.Subject, "error", err)
+		return
+	}
+
+	err = retry(ctx, s.backoff, 0, "store number from NATS", func() error {
+		_, err := s.queries.InsertNumber(ctx, n)
+		return err
+	})
+	if err != nil {
+		slog.Error("Dropping number from NATS", "number", n, "error", err)
+	}
+}
+
+// Event is what a Publisher sends for every stored number
+type Event struct {
+	ID        string    `json:"id"`
+	Number    int64     `json:"number"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// natsPublisher is the part of *nats.Conn a Publisher uses
+type natsPublisher interface {
+	Publish(subj string, data []byte) error
+}
+
+// Publisher is a sqlc.Querier that publishes an Event to a NATS subject for every
+// number inserted through it, whichever API it came from. Publishing is best effort:
+// a number is stored even if its event cannot be sent.
+type Publisher struct {
+	storage.Decorator
+	conn    natsPublisher
+	subject string
+}
+
+// NewPublisher wraps queries, publishing to subject on conn
+func NewPublisher(queries sqlc.Querier, conn *nats.Conn, subject string) *Publisher {
+	return &Publisher{Decorator: storage.Decorator{Querier: queries}, conn: conn, subject: subject}
+}
+
+func (p *Publisher) InsertNumber(ctx context.Context, number int64) (sqlc.Number, error) {
+	row, err := p.Querier.InsertNumber(ctx, number)
+	if err != nil {
+		return row, err
+	}
+	p.publish(row)
+
+	return row, nil
+}
+
+// InsertNumbers publishes an Event for every number once all of them are stored
+func (p *Publisher) InsertNumbers(ctx context.Context, numbers []int64) ([]sqlc.Number, error) {
+	rows, err := p.Querier.InsertNumbers(ctx, numbers)
+	if err != nil {
+		return rows, err
+	}
+	for _, row := range rows {
+		p.publish(row)
+	}
+
+	return rows, nil
+}
+
+// publish sends the Event of row
+func (p *Publisher) publish(row sqlc.Number) {
+	event, _ := json.Marshal(Event{ID: row.ID.String(), Number: row.Number, CreatedAt: row.CreatedAt.Time})
+	if err := p.conn.Publish(p.subject, event); err != nil {
+		slog.Warn("Failed to publish number to NATS", "subject", p.subject, "number", row.Number, "error", err)
+	}
+}
@@ -0,0 +1,162 @@
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	api "golang-test-task/api"
+	"golang-test-task/internal/storage"
+	"golang-test-task/internal/storage/memstore"
+
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNATS hands the channel of the one subscription to the test, after delivering
+// pending to it, and records what is published
+type fakeNATS struct {
+	subscribed chan chan *nats.Msg
+	pending    []string
+
+	mu        sync.Mutex
+	published []*nats.Msg
+	// publishErr fails every Publish
+	publishErr error
+}
+
+func newFakeNATS() *fakeNATS {
+	return &fakeNATS{subscribed: make(chan chan *nats.Msg, 1)}
+}
+
+func (f *fakeNATS) ChanQueueSubscribe(subj, _ string, ch chan *nats.Msg) (*nats.Subscription, error) {
+	for _, body := range f.pending {
+		ch <- &nats.Msg{Subject: subj, Data: []byte(body)}
+	}
+	f.subscribed <- ch
+	return &nats.Subscription{}, nil
+}
+
+func (f *fakeNATS) Publish(subj string, data []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.publishErr != nil {
+		return f.publishErr
+	}
+	f.published = append(f.published, &nats.Msg{Subject: subj, Data: data})
+
+	return nil
+}
+
+func TestNATSSubscriber_StoresNumbers(t *testing.T) {
+	store := &flakyStore{Store: memstore.New(), failures: 2}
+	conn := newFakeNATS()
+	s := &NATSSubscriber{queries: store, conn: conn, subject: "numbers", queue: "numbers", backoff: time.Millisecond}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+
+	msgs := <-conn.subscribed
+	for _, body := range []string{"3", "three", "1"} {
+		msgs <- &nats.Msg{Subject: "numbers", Data: []byte(body)}
+	}
+	require.Eventually(t, func() bool { return len(storedNumbers(t, store)) == 2 }, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+
+	assert.Equal(t, []int64{1, 3}, storedNumbers(t, store))
+}
+
+func TestNATSSubscriber_StoresReceivedOnShutdown(t *testing.T) {
+	store := memstore.New()
+	conn := newFakeNATS()
+	conn.pending = []string{"4", "2"}
+	s := NewNATSSubscriber(store, nil, "numbers", "numbers")
+	s.conn = conn
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.Run(ctx)
+
+	assert.Equal(t, []int64{2, 4}, storedNumbers(t, store), "messages received before shutdown are stored")
+}
+
+func TestPublisher(t *testing.T) {
+	conn := newFakeNATS()
+	p := &Publisher{Decorator: storage.Decorator{Querier: memstore.New()}, conn: conn, subject: "numbers.added"}
+
+	row, err := p.InsertNumber(context.Background(), 42)
+	require.NoError(t, err)
+
+	require.Len(t, conn.published, 1)
+	assert.Equal(t, "numbers.added", conn.published[0].Subject)
+	var event Event
+	require.NoError(t, json.Unmarshal(conn.published[0].Data, &event))
+	assert.Equal(t, row.ID.String(), event.ID)
+	assert.Equal(t, int64(42), event.Number)
+	assert.True(t, row.CreatedAt.Time.Equal(event.CreatedAt))
+}
+
+// TestPublisher_EventMatchesSpec tests that events decode as the NumberEvent the
+// OpenAPI spec documents, field for field
+func TestPublisher_EventMatchesSpec(t *testing.T) {
+	conn := newFakeNATS()
+	p := &Publisher{Decorator: storage.Decorator{Querier: memstore.New()}, conn: conn, subject: "numbers.added"}
+
+	row, err := p.InsertNumber(context.Background(), -3)
+	require.NoError(t, err)
+
+	require.Len(t, conn.published, 1)
+	dec := json.NewDecoder(bytes.NewReader(conn.published[0].Data))
+	dec.DisallowUnknownFields()
+	var event api.NumberEvent
+	require.NoError(t, dec.Decode(&event))
+	assert.Equal(t, row.ID.String(), event.Id.String())
+	assert.Equal(t, int64(-3), event.Number)
+	assert.True(t, row.CreatedAt.Time.Equal(event.CreatedAt))
+}
+
+func TestPublisher_Batch(t *testing.T) {
+	conn := newFakeNATS()
+	p := &Publisher{Decorator: storage.Decorator{Querier: memstore.New()}, conn: conn, subject: "numbers.added"}
+
+	rows, err := p.InsertNumbers(context.Background(), []int64{5, -1})
+	require.NoError(t, err)
+
+	require.Len(t, conn.published, 2, "every number of a batch has its event")
+	for i, row := range rows {
+		var event Event
+		require.NoError(t, json.Unmarshal(conn.published[i].Data, &event))
+		assert.Equal(t, row.ID.String(), event.ID)
+		assert.Equal(t, row.Number, event.Number)
+	}
+}
+
+func TestPublisher_StoresWhenPublishingFails(t *testing.T) {
+	store := memstore.New()
+	conn := newFakeNATS()
+	conn.publishErr = errors.New("nats: connection closed")
+	p := &Publisher{Decorator: storage.Decorator{Querier: store}, conn: conn, subject: "numbers.added"}
+
+	_, err := p.InsertNumber(context.Background(), 7)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{7}, storedNumbers(t, store))
+}
+
+func TestPublisher_SkipsFailedInserts(t *testing.T) {
+	conn := newFakeNATS()
+	p := &Publisher{Decorator: storage.Decorator{Querier: &flakyStore{Store: memstore.New(), failures: 1}}, conn: conn, subject: "numbers.added"}
+
+	_, err := p.InsertNumber(context.Background(), 7)
+	assert.Error(t, err)
+	assert.Empty(t, conn.published)
+}
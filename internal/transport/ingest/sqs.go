@@ -0,0 +1,212 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang-test-task/internal/storage/sqlc"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+const (
+	// sqsBatchSize is the most messages one receive returns, and one batch deletes
+	sqsBatchSize = 10
+	// sqsWaitTime is how long a receive waits for messages, the longest long poll
+	sqsWaitTime = 20
+)
+
+// SQSOptions configure an SQSConsumer
+type SQSOptions struct {
+	// QueueURL is the queue, as https://sqs.<region>.amazonaws.com/<account>/<name>;
+	// any other host, such as LocalStack's, is used as the endpoint
+	QueueURL string
+	// VisibilityTimeout hides received messages from other consumers while their
+	// numbers are stored; a message that could not be stored in time reappears
+	VisibilityTimeout time.Duration
+}
+
+// sqsAPI is the part of *sqs.Client an SQSConsumer uses
+type sqsAPI interface {
+	ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error)
+	DeleteMessageBatch(ctx context.Context, params *sqs.DeleteMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageBatchOutput, error)
+}
+
+// SQSConsumer stores the numbers sent to an AWS SQS queue, such as by Lambda
+// functions. Messages are received in batches, their numbers stored in one insert
+// and the messages deleted in one call. One whose number could not be stored within the visibility
+// timeout is left to SQS, which delivers it again and, with a redrive policy,
+// moves it to the dead letter queue after maxReceiveCount receives. Messages
+// without a number are deleted.
+type SQSConsumer struct {
+	queries sqlc.Querier
+	opts    SQSOptions
+	client  sqsAPI
+	// backoff is the first wait when storing a number or receiving fails
+	backoff time.Duration
+}
+
+// NewSQSConsumer returns a consumer inserting through queries. AWS credentials are
+// resolved the standard way, and the region is taken from the queue URL when it has
+// one. It receives once Run is called.
+func NewSQSConsumer(ctx context.Context, queries sqlc.Querier, opts SQSOptions) (*SQSConsumer, error) {
+	u, err := url.Parse(opts.QueueURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SQS queue URL: %w", err)
+	}
+
+	var loadOpts []func(*awsconfig.LoadOptions) error
+	if region, ok := sqsRegion(u.Host); ok {
+		loadOpts = append(loadOpts, awsconfig.WithRegion(region))
+	}
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, loadOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS configuration: %w", err)
+	}
+
+	client := sqs.NewFromConfig(awsCfg, func(o *sqs.Options) {
+		if !strings.HasSuffix(u.Host, ".amazonaws.com") {
+			o.BaseEndpoint = aws.String(u.Scheme + "://" + u.Host)
+		}
+	})
+
+	return &SQSConsumer{queries: queries, opts: opts, client: client, backoff: defaultBackoff}, nil
+}
+
+// sqsRegion returns the region of an SQS endpoint host such as sqs.eu-west-1.amazonaws.com
+func sqsRegion(host string) (string, bool) {
+	rest, ok := strings.CutPrefix(host, "sqs.")
+	if !ok {
+		return "", false
+	}
+	region, _, ok := strings.Cut(rest, ".")
+
+	return region, ok && strings.HasSuffix(host, ".amazonaws.com")
+}
+
+// Run receives and stores batches until ctx is done. Messages of a batch not yet
+// stored when shutdown begins are left to be redelivered.
+func (c *SQSConsumer) Run(ctx context.Context) {
+	slog.Info("Consuming numbers from SQS", "queue", c.opts.QueueURL)
+
+	for ctx.Err() == nil {
+		var msgs []types.Message
+		err := retry(ctx, c.backoff, 0, "receive from SQS", func() error {
+			out, err := c.client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+				QueueUrl:            aws.String(c.opts.QueueURL),
+				MaxNumberOfMessages: sqsBatchSize,
+				WaitTimeSeconds:     sqsWaitTime,
+				VisibilityTimeout:   int32(c.opts.VisibilityTimeout / time.Second),
+			})
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return err
+			}
+			msgs = out.Messages
+			return nil
+		})
+		if err != nil {
+			return
+		}
+
+		c.store(ctx, msgs)
+	}
+}
+
+// store inserts the numbers msgs carry and deletes the messages that are done with:
+// those stored and those without a number
+func (c *SQSConsumer) store(ctx context.Context, msgs []types.Message) {
+	if len(msgs) == 0 {
+		return
+	}
+
+	var numbers []int64
+	var pending []int
+	for i, msg := range msgs {
+		n, err := ParseNumber([]byte(aws.ToString(msg.Body)))
+		if err != nil {
+			slog.Warn("Deleting SQS message without a number", "queue", c.opts.QueueURL, "error", err)
+			continue
+		}
+		numbers, pending = append(numbers, n), append(pending, i)
+	}
+	stored := c.insert(ctx, numbers)
+
+	var done []types.DeleteMessageBatchRequestEntry
+	for i, msg := range msgs {
+		if len(pending) > 0 && pending[0] == i {
+			ok := stored[0]
+			pending, stored = pending[1:], stored[1:]
+			if !ok {
+				continue
+			}
+		}
+		done = append(done, types.DeleteMessageBatchRequestEntry{Id: aws.String(strconv.Itoa(i)), ReceiptHandle: msg.ReceiptHandle})
+	}
+	if len(done) == 0 {
+		return
+	}
+
+	// Deleting stored messages must not be cut short by shutdown
+	deleteCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), drainTimeout)
+	defer cancel()
+	out, err := c.client.DeleteMessageBatch(deleteCtx, &sqs.DeleteMessageBatchInput{
+		QueueUrl: aws.String(c.opts.QueueURL),
+		Entries:  done,
+	})
+	if err != nil {
+		slog.Warn("Failed to delete SQS messages; they will be redelivered", "count", len(done), "error", err)
+		return
+	}
+	for _, failed := range out.Failed {
+		slog.Warn("Failed to delete SQS message; it will be redelivered", "code", aws.ToString(failed.Code), "error", aws.ToString(failed.Message))
+	}
+}
+
+// insert stores numbers in one batch and reports which are stored. When the batch
+// fails, which stores none of them, each is inserted on its own instead, retrying
+// storage failures until the visibility timeout or ctx is done, so that one number
+// does not hold up the others.
+func (c *SQSConsumer) insert(ctx context.Context, numbers []int64) []bool {
+	stored := make([]bool, len(numbers))
+	if len(numbers) == 0 {
+		return stored
+	}
+	storeCtx, cancel := context.WithTimeout(ctx, c.opts.VisibilityTimeout)
+	defer cancel()
+
+	_, err := c.queries.InsertNumbers(storeCtx, numbers)
+	if err == nil {
+		for i := range stored {
+			stored[i] = true
+		}
+		return stored
+	}
+	slog.Warn("Failed to store SQS batch, storing its numbers one at a time", "count", len(numbers), "error", err)
+
+	for i, n := range numbers {
+		err := retry(storeCtx, c.backoff, 0, "store number from SQS", func() error {
+			_, err := c.queries.InsertNumber(storeCtx, n)
+			return err
+		})
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Error("Failed to store number from SQS, leaving it to be redelivered", "number", n, "error", err)
+			}
+			continue
+		}
+		stored[i] = true
+	}
+
+	return stored
+}
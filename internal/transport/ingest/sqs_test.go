@@ -0,0 +1,152 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"golang-test-task/internal/storage/memstore"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSQS returns the batches the test queues, one per receive, and records the
+// receipt handles deleted with each batch call
+type fakeSQS struct {
+	batches     chan []types.Message
+	receiveErrs int
+
+	mu      sync.Mutex
+	deleted [][]string
+}
+
+func newFakeSQS() *fakeSQS {
+	return &fakeSQS{batches: make(chan []types.Message, 10)}
+}
+
+func (f *fakeSQS) ReceiveMessage(ctx context.Context, in *sqs.ReceiveMessageInput, _ ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+	f.mu.Lock()
+	if f.receiveErrs > 0 {
+		f.receiveErrs--
+		f.mu.Unlock()
+		return nil, errors.New("service unavailable")
+	}
+	f.mu.Unlock()
+
+	select {
+	case msgs := <-f.batches:
+		return &sqs.ReceiveMessageOutput{Messages: msgs}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (f *fakeSQS) DeleteMessageBatch(_ context.Context, in *sqs.DeleteMessageBatchInput, _ ...func(*sqs.Options)) (*sqs.DeleteMessageBatchOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var handles []string
+	for _, e := range in.Entries {
+		handles = append(handles, aws.ToString(e.ReceiptHandle))
+	}
+	f.deleted = append(f.deleted, handles)
+
+	return &sqs.DeleteMessageBatchOutput{}, nil
+}
+
+func (f *fakeSQS) deletedBatches() [][]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.deleted
+}
+
+// sqsMessages makes a message per body, with receipt handles r0, r1...
+func sqsMessages(bodies ...string) []types.Message {
+	msgs := make([]types.Message, len(bodies))
+	for i, body := range bodies {
+		msgs[i] = types.Message{Body: aws.String(body), ReceiptHandle: aws.String("r" + strconv.Itoa(i))}
+	}
+
+	return msgs
+}
+
+// runSQS runs c until fake has handed out every queued batch and deleted wantDeletes batches
+func runSQS(t *testing.T, c *SQSConsumer, fake *fakeSQS, wantDeletes int) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.Run(ctx)
+	}()
+
+	require.Eventually(t, func() bool {
+		return len(fake.batches) == 0 && len(fake.deletedBatches()) == wantDeletes
+	}, 5*time.Second, time.Millisecond)
+	cancel()
+	<-done
+}
+
+func TestSQSConsumer_StoresAndDeletesBatch(t *testing.T) {
+	store := &flakyStore{Store: memstore.New()}
+	fake := newFakeSQS()
+	fake.receiveErrs = 1
+	fake.batches <- sqsMessages("3", "not a number", "-1")
+	c := &SQSConsumer{queries: store, opts: SQSOptions{QueueURL: "q", VisibilityTimeout: time.Second}, client: fake, backoff: time.Millisecond}
+
+	runSQS(t, c, fake, 1)
+
+	assert.Equal(t, []int64{-1, 3}, storedNumbers(t, store))
+	assert.Equal(t, 1, store.batches, "the numbers are stored in one insert")
+	assert.Zero(t, store.singles)
+	assert.Equal(t, [][]string{{"r0", "r1", "r2"}}, fake.deletedBatches(), "one call deletes the stored and the invalid messages")
+}
+
+// TestSQSConsumer_FallsBackToSingleInserts tests that the numbers of a batch that
+// failed are stored one at a time, and that only their messages are deleted
+func TestSQSConsumer_FallsBackToSingleInserts(t *testing.T) {
+	// The batch and the first single insert fail, and that one is retried
+	store := &flakyStore{Store: memstore.New(), failures: 2}
+	fake := newFakeSQS()
+	fake.batches <- sqsMessages("4", "x", "2")
+	c := &SQSConsumer{queries: store, opts: SQSOptions{QueueURL: "q", VisibilityTimeout: time.Second}, client: fake, backoff: time.Millisecond}
+
+	runSQS(t, c, fake, 1)
+
+	assert.Equal(t, []int64{2, 4}, storedNumbers(t, store))
+	assert.Equal(t, 1, store.batches)
+	assert.Equal(t, 3, store.singles)
+	assert.Equal(t, [][]string{{"r0", "r1", "r2"}}, fake.deletedBatches())
+}
+
+func TestSQSConsumer_LeavesUnstoredMessages(t *testing.T) {
+	store := &flakyStore{Store: memstore.New(), failures: 1 << 30}
+	fake := newFakeSQS()
+	fake.batches <- sqsMessages("5", "x")
+	c := &SQSConsumer{queries: store, opts: SQSOptions{QueueURL: "q", VisibilityTimeout: 20 * time.Millisecond}, client: fake, backoff: time.Millisecond}
+
+	runSQS(t, c, fake, 1)
+
+	assert.Empty(t, storedNumbers(t, store))
+	assert.Equal(t, [][]string{{"r1"}}, fake.deletedBatches(), "the message whose number was not stored is redelivered by SQS")
+}
+
+func TestSQSRegion(t *testing.T) {
+	region, ok := sqsRegion("sqs.eu-west-1.amazonaws.com")
+	assert.True(t, ok)
+	assert.Equal(t, "eu-west-1", region)
+
+	for _, host := range []string{"localhost:4566", "sqs.example.com", "queue.amazonaws.com"} {
+		_, ok := sqsRegion(host)
+		assert.False(t, ok, host)
+	}
+}
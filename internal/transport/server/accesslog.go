@@ -0,0 +1,129 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// requestIDHeader carries the id of a request, from the client or else LogRequests
+const requestIDHeader = "X-Request-Id"
+
+type requestInfoKey struct{}
+
+// requestInfo is what the handlers learn of a request for the middleware outside
+// them, which cannot see into the context the handlers run with
+type requestInfo struct {
+	// operationID is the operation in openapi.yaml the request was routed to, empty
+	// for other routes
+	operationID string
+}
+
+// withRequestInfo returns r with a requestInfo in its context that withOperationID
+// fills in, and the requestInfo. A request that already has one keeps it, so
+// middleware nested in each other share it.
+func withRequestInfo(r *http.Request) (*http.Request, *requestInfo) {
+	if info, ok := r.Context().Value(requestInfoKey{}).(*requestInfo); ok {
+		return r, info
+	}
+	info := &requestInfo{}
+
+	return r.WithContext(context.WithValue(r.Context(), requestInfoKey{}, info)), info
+}
+
+// recordOperation tells the middleware outside the handlers which operation the
+// request of ctx was routed to
+func recordOperation(ctx context.Context, operationID string) {
+	if info, ok := ctx.Value(requestInfoKey{}).(*requestInfo); ok {
+		info.operationID = operationID
+	}
+}
+
+// newRequestID returns a random id for a request the client sent none for
+func newRequestID() string {
+	id := make([]byte, 8)
+	rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
+
+// LogRequests logs every request to logger once it is answered, with its status,
+// size and duration. Requests without an X-Request-Id header are given a random
+// one, which the handlers see as if the client had sent it; either way it is sent
+// back in the response and logged, so a client can quote it when reporting a
+// failure.
+func LogRequests(next http.Handler, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		requestID := r.Header.Get(requestIDHeader)
+		if requestID == "" {
+			requestID = newRequestID()
+			r.Header.Set(requestIDHeader, requestID)
+		}
+		w.Header().Set(requestIDHeader, requestID)
+
+		r, info := withRequestInfo(r)
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+
+		attrs := []slog.Attr{
+			slog.String("request_id", requestID),
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", rec.statusCode()),
+			slog.Int64("bytes", rec.bytes),
+			slog.Duration("duration", time.Since(start)),
+			slog.String("remote", r.RemoteAddr),
+		}
+		if info.operationID != "" {
+			attrs = append(attrs, slog.String("operation", info.operationID))
+		}
+		logger.LogAttrs(r.Context(), slog.LevelInfo, "Request", attrs...)
+	})
+}
+
+// statusRecorder records the status and size of the response written through it.
+// It unwraps for http.ResponseController and flushes, so streamed responses are
+// still sent as they are written.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(p []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(p)
+	r.bytes += int64(n)
+
+	return n, err
+}
+
+func (r *statusRecorder) Flush() {
+	http.NewResponseController(r.ResponseWriter).Flush()
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// statusCode is the status sent, 200 when the handler wrote nothing
+func (r *statusRecorder) statusCode() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+
+	return r.status
+}
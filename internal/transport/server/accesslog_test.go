@@ -0,0 +1,62 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	api "golang-test-task/api"
+	"golang-test-task/internal/service"
+	"golang-test-task/internal/storage/memstore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLogRequests tests that every request is logged with its operation and an id,
+// the client's or else a random one that the response and its meta carry too
+func TestLogRequests(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, nil))
+	handler := LogRequests(NewHandler(NewServer(service.New(memstore.New()))), logger)
+	serve := func(req *http.Request) (*httptest.ResponseRecorder, map[string]any) {
+		logs.Reset()
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal(logs.Bytes(), &entry))
+		return rec, entry
+	}
+
+	rec, entry := serve(httptest.NewRequest(http.MethodPost, "/numbers?number=3&envelope=true", nil))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	requestID := rec.Header().Get("X-Request-Id")
+	assert.Len(t, requestID, 16)
+	size := rec.Body.Len()
+	var body api.CreateNumberResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	require.NotNil(t, body.Meta)
+	assert.Equal(t, requestID, body.Meta.RequestId, "the handler sees the id it is logged with")
+	assert.Equal(t, "Request", entry["msg"])
+	assert.Equal(t, requestID, entry["request_id"])
+	assert.Equal(t, "AddNumber", entry["operation"])
+	assert.Equal(t, "POST", entry["method"])
+	assert.Equal(t, "/numbers", entry["path"])
+	assert.Equal(t, float64(http.StatusOK), entry["status"])
+	assert.Equal(t, float64(size), entry["bytes"])
+
+	req := httptest.NewRequest(http.MethodGet, "/numbers?limit=0", nil)
+	req.Header.Set("X-Request-Id", "req-1")
+	rec, entry = serve(req)
+	assert.Equal(t, "req-1", rec.Header().Get("X-Request-Id"))
+	assert.Equal(t, "req-1", entry["request_id"])
+	assert.Equal(t, "ListNumbers", entry["operation"])
+	assert.Equal(t, float64(http.StatusBadRequest), entry["status"])
+
+	_, entry = serve(httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.NotContains(t, entry, "operation", "routes outside openapi.yaml have no operation")
+	assert.Equal(t, float64(http.StatusOK), entry["status"])
+}
@@ -0,0 +1,188 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"golang-test-task/numberspb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// APIKey is a key clients call the API with
+type APIKey struct {
+	Key string
+	// ReadOnly keys may only call GET operations
+	ReadOnly bool
+	// Admin keys may also delete every number, with WithClear(Admin)
+	Admin bool
+}
+
+type apiKeyKey struct{}
+
+// Caller returns the API key the request of ctx was authenticated with, or false
+// when the request needed none
+func Caller(ctx context.Context) (APIKey, bool) {
+	key, ok := ctx.Value(apiKeyKey{}).(APIKey)
+	return key, ok
+}
+
+// Admin tells whether the request of ctx was authenticated with an admin key
+func Admin(ctx context.Context) bool {
+	key, ok := Caller(ctx)
+	return ok && key.Admin
+}
+
+// APIKeys authenticates requests with one of keys, for Authenticate. Clients send a
+// key as the X-API-Key header or as a bearer token in Authorization. A read-only key
+// calling anything but a GET operation is forbidden, and with publicReads GET
+// operations need no key at all. Keys are compared in constant time.
+func APIKeys(keys []APIKey, publicReads bool) func(ctx context.Context, r *http.Request, operationID string) (context.Context, error) {
+	ring := newKeyring(keys, publicReads)
+
+	return func(ctx context.Context, r *http.Request, operationID string) (context.Context, error) {
+		sent, err := sentKey(r.Header.Get("X-API-Key"), r.Header.Get("Authorization"))
+		if err != nil {
+			return nil, err
+		}
+
+		return ring.authenticate(ctx, sent, r.Method == http.MethodGet || r.Method == http.MethodHead, operationID)
+	}
+}
+
+// GRPCAPIKeys returns the interceptors that authenticate calls of NumbersService as
+// APIKeys does requests, for NewGRPC. Clients send a key as x-api-key or bearer
+// authorization metadata. ListNumbers and StreamNumbers read, and AddNumber writes.
+// A missing or unknown key is Unauthenticated and a read-only key writing is
+// PermissionDenied. The health and reflection services need no key, like the probes
+// of the HTTP port.
+func GRPCAPIKeys(keys []APIKey, publicReads bool) []grpc.ServerOption {
+	ring := newKeyring(keys, publicReads)
+	authenticate := func(ctx context.Context, fullMethod string) (context.Context, error) {
+		if !strings.HasPrefix(fullMethod, "/"+numberspb.NumbersService_ServiceDesc.ServiceName+"/") {
+			return ctx, nil
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		sent, err := sentKey(firstValue(md, "x-api-key"), firstValue(md, "authorization"))
+		if err == nil {
+			read := fullMethod == numberspb.NumbersService_ListNumbers_FullMethodName || fullMethod == numberspb.NumbersService_StreamNumbers_FullMethodName
+			ctx, err = ring.authenticate(ctx, sent, read, path.Base(fullMethod))
+		}
+		switch {
+		case errors.Is(err, ErrForbidden):
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		case err != nil:
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+
+		return ctx, nil
+	}
+
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			ctx, err := authenticate(ctx, info.FullMethod)
+			if err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			ctx, err := authenticate(ss.Context(), info.FullMethod)
+			if err != nil {
+				return err
+			}
+			return handler(srv, authenticatedStream{ss, ctx})
+		}),
+	}
+}
+
+// authenticatedStream is a stream whose context carries its caller
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// firstValue is the first value of key in md, empty when it has none
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// keyring is the keys a server knows, by hash
+type keyring struct {
+	keys        []APIKey
+	hashes      [][sha256.Size]byte
+	publicReads bool
+}
+
+func newKeyring(keys []APIKey, publicReads bool) *keyring {
+	// Comparing hashes keeps the time taken independent of the length of the keys too
+	hashes := make([][sha256.Size]byte, len(keys))
+	for i, key := range keys {
+		hashes[i] = sha256.Sum256([]byte(key.Key))
+	}
+
+	return &keyring{keys: keys, hashes: hashes, publicReads: publicReads}
+}
+
+// authenticate returns ctx carrying the key sent, empty when none was, for an
+// operation that reads or writes
+func (k *keyring) authenticate(ctx context.Context, sent string, read bool, operation string) (context.Context, error) {
+	if sent == "" {
+		if read && k.publicReads {
+			return ctx, nil
+		}
+		return nil, errors.New("an API key is required, as X-API-Key or a bearer token")
+	}
+
+	hash := sha256.Sum256([]byte(sent))
+	match := -1
+	for i := range k.hashes {
+		if subtle.ConstantTimeCompare(hash[:], k.hashes[i][:]) == 1 {
+			match = i
+		}
+	}
+	if match < 0 {
+		return nil, errors.New("the API key is not valid")
+	}
+	if k.keys[match].ReadOnly && !read {
+		return nil, fmt.Errorf("%w: the API key is read-only and %s writes", ErrForbidden, operation)
+	}
+
+	return context.WithValue(ctx, apiKeyKey{}, k.keys[match]), nil
+}
+
+// sentKey returns the key of the X-API-Key header or the bearer token of the
+// Authorization header, empty when neither is sent
+func sentKey(header, authorization string) (string, error) {
+	if authorization == "" {
+		return header, nil
+	}
+
+	scheme, token, _ := strings.Cut(authorization, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return "", fmt.Errorf("authorization scheme must be Bearer, got %q", scheme)
+	}
+	token = strings.TrimSpace(token)
+	if header != "" && header != token {
+		return "", errors.New("X-API-Key and the bearer token differ")
+	}
+
+	return token, nil
+}
@@ -0,0 +1,159 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	api "golang-test-task/api"
+	"golang-test-task/internal/service"
+	"golang-test-task/internal/storage/memstore"
+	"golang-test-task/numberspb"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const (
+	writerKey = "writer-0123456789abcdef0123456789abcdef"
+	readerKey = "reader-0123456789abcdef0123456789abcdef"
+)
+
+// TestAPIKeys tests that operations need a known key, sent either way, that
+// read-only keys are forbidden to write and that reads can be left public
+func TestAPIKeys(t *testing.T) {
+	keys := []APIKey{{Key: writerKey}, {Key: readerKey, ReadOnly: true}}
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		header      string
+		value       string
+		publicReads bool
+		wantStatus  int
+		wantError   string
+	}{
+		{name: "header", method: http.MethodPost, target: "/numbers?number=1", header: "X-API-Key", value: writerKey, wantStatus: http.StatusOK},
+		{name: "bearer", method: http.MethodPost, target: "/numbers?number=1", header: "Authorization", value: "Bearer " + writerKey, wantStatus: http.StatusOK},
+		{name: "bearer in lower case", method: http.MethodPost, target: "/numbers?number=1", header: "Authorization", value: "bearer " + writerKey, wantStatus: http.StatusOK},
+		{name: "missing", method: http.MethodPost, target: "/numbers?number=1", wantStatus: http.StatusUnauthorized, wantError: "an API key is required, as X-API-Key or a bearer token"},
+		{name: "unknown", method: http.MethodPost, target: "/numbers?number=1", header: "X-API-Key", value: writerKey + "x", wantStatus: http.StatusUnauthorized, wantError: "the API key is not valid"},
+		{name: "basic", method: http.MethodPost, target: "/numbers?number=1", header: "Authorization", value: "Basic d3JpdGVyOg==", wantStatus: http.StatusUnauthorized, wantError: `authorization scheme must be Bearer, got "Basic"`},
+		{name: "read-only write", method: http.MethodPost, target: "/numbers?number=1", header: "X-API-Key", value: readerKey, wantStatus: http.StatusForbidden, wantError: "forbidden: the API key is read-only and AddNumber writes"},
+		{name: "read-only read", method: http.MethodGet, target: "/numbers", header: "X-API-Key", value: readerKey, wantStatus: http.StatusOK},
+		{name: "private read", method: http.MethodGet, target: "/numbers", wantStatus: http.StatusUnauthorized, wantError: "an API key is required, as X-API-Key or a bearer token"},
+		{name: "public read", method: http.MethodGet, target: "/numbers", publicReads: true, wantStatus: http.StatusOK},
+		{name: "public reads still check keys", method: http.MethodGet, target: "/numbers", header: "X-API-Key", value: "guess", publicReads: true, wantStatus: http.StatusUnauthorized, wantError: "the API key is not valid"},
+		{name: "public reads protect writes", method: http.MethodPost, target: "/numbers?number=1", publicReads: true, wantStatus: http.StatusUnauthorized, wantError: "an API key is required, as X-API-Key or a bearer token"},
+		{name: "probes", method: http.MethodGet, target: "/healthz", wantStatus: http.StatusOK},
+	}
+
+	routers := map[string]func(api.StrictServerInterface, ...api.StrictMiddlewareFunc) http.Handler{
+		"stdlib": NewHandler,
+		"chi": func(s api.StrictServerInterface, middlewares ...api.StrictMiddlewareFunc) http.Handler {
+			return NewChiHandler(s, chi.NewRouter(), middlewares...)
+		},
+	}
+
+	for router, newHandler := range routers {
+		for _, tt := range tests {
+			t.Run(router+"/"+tt.name, func(t *testing.T) {
+				handler := newHandler(NewServer(service.New(memstore.New())), Authenticate(APIKeys(keys, tt.publicReads)))
+				req := httptest.NewRequest(tt.method, tt.target, nil)
+				if tt.header != "" {
+					req.Header.Set(tt.header, tt.value)
+				}
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, req)
+
+				require.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+				if tt.wantError != "" {
+					var body api.ErrorResponse
+					require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+					assert.Equal(t, tt.wantError, body.Error)
+				}
+			})
+		}
+	}
+}
+
+// TestAPIKeys_Caller tests that handlers can tell which key called them
+func TestAPIKeys_Caller(t *testing.T) {
+	authenticate := APIKeys([]APIKey{{Key: readerKey, ReadOnly: true}}, true)
+
+	req := httptest.NewRequest(http.MethodGet, "/numbers", nil)
+	req.Header.Set("X-API-Key", readerKey)
+	req.Header.Set("Authorization", "Bearer "+readerKey)
+	ctx, err := authenticate(context.Background(), req, "ListNumbers")
+	require.NoError(t, err)
+	caller, ok := Caller(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, APIKey{Key: readerKey, ReadOnly: true}, caller)
+
+	ctx, err = authenticate(context.Background(), httptest.NewRequest(http.MethodGet, "/numbers", nil), "ListNumbers")
+	require.NoError(t, err)
+	_, ok = Caller(ctx)
+	assert.False(t, ok, "a public read has no caller")
+
+	req.Header.Set("Authorization", "Bearer "+writerKey)
+	_, err = authenticate(context.Background(), req, "ListNumbers")
+	assert.EqualError(t, err, "X-API-Key and the bearer token differ")
+}
+
+// TestGRPCAPIKeys tests that NumbersService calls need a key sent as metadata, with
+// the same scopes as HTTP, and that health checks need none
+func TestGRPCAPIKeys(t *testing.T) {
+	ln := bufconn.Listen(1 << 20)
+	srv, _ := NewGRPC(NewGRPCServer(service.New(memstore.New())), GRPCAPIKeys([]APIKey{{Key: writerKey}, {Key: readerKey, ReadOnly: true}}, false)...)
+	go srv.Serve(ln)
+	t.Cleanup(srv.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return ln.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	client := numberspb.NewNumbersServiceClient(conn)
+	withKey := func(key, value string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), key, value)
+	}
+
+	_, err = client.AddNumber(context.Background(), &numberspb.AddNumberRequest{Number: 1})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Equal(t, "an API key is required, as X-API-Key or a bearer token", status.Convert(err).Message())
+	_, err = client.AddNumber(withKey("x-api-key", writerKey+"x"), &numberspb.AddNumberRequest{Number: 1})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = client.AddNumber(withKey("x-api-key", readerKey), &numberspb.AddNumberRequest{Number: 1})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Equal(t, "forbidden: the API key is read-only and AddNumber writes", status.Convert(err).Message())
+
+	_, err = client.AddNumber(withKey("authorization", "Bearer "+writerKey), &numberspb.AddNumberRequest{Number: 1})
+	require.NoError(t, err)
+	list, err := client.ListNumbers(withKey("x-api-key", readerKey), &numberspb.ListNumbersRequest{})
+	require.NoError(t, err)
+	assert.Len(t, list.GetNumbers(), 1)
+
+	stream, err := client.StreamNumbers(context.Background(), &numberspb.StreamNumbersRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "streams are checked too")
+	stream, err = client.StreamNumbers(withKey("x-api-key", readerKey), &numberspb.StreamNumbersRequest{})
+	require.NoError(t, err)
+	number, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), number.GetNumber())
+
+	health, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, health.GetStatus())
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	api "golang-test-task/api"
+	"golang-test-task/internal/service"
+	"golang-test-task/internal/storage/sqlc"
+)
+
+// maxBatch is the most numbers one batch can add, which keeps the transaction and the
+// locks it holds short
+const maxBatch = 10_000
+
+// maxBody is the largest request body read, which a list of maxBatch numbers of any
+// length fits in
+const maxBody = 1 << 20
+
+// limitBodies stops reading request bodies past maxBody, so a client cannot make the
+// server decode a list of any length only to reject it
+func limitBodies(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxBody)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// AddNumberBatch stores every number of the list in one statement, so that either
+// all of them are stored or none is, and answers with how many there were and the
+// first page of the stored numbers they span
+func (s *Server) AddNumberBatch(ctx context.Context, request api.AddNumberBatchRequestObject) (api.AddNumberBatchResponseObject, error) {
+	numbers := *request.Body
+	if len(numbers) == 0 || len(numbers) > maxBatch {
+		return api.AddNumberBatch400JSONResponse{Error: fmt.Sprintf("a batch must hold between 1 and %d numbers, got %d", maxBatch, len(numbers))}, nil
+	}
+	for i, number := range numbers {
+		for _, validate := range s.validators {
+			if err := validate(number); err != nil {
+				return api.AddNumberBatch400JSONResponse{Error: fmt.Sprintf("numbers[%d]: %v", i, err)}, nil
+			}
+		}
+	}
+
+	err := s.hookedAll(ctx, numbers, func() error { return s.numbers.InsertAll(ctx, numbers) })
+	if err != nil {
+		return s.batchError(ctx, err), nil
+	}
+
+	// The window is read after the batch is committed, so it may hold numbers added
+	// since. Failing to read it fails the request although the batch is stored.
+	low, high := slices.Min(numbers), slices.Max(numbers)
+	window, err := s.page(ctx, sqlc.ListNumbersPageParams{MinNumber: low, MaxNumber: high, PageLimit: defaultPageLimit + 1})
+	if err != nil {
+		return s.batchError(ctx, err), nil
+	}
+
+	if responseTypeOf(ctx) == jsonAPIType {
+		return jsonAPIBatch{window: window, inserted: int64(len(numbers)), min: low, max: high}, nil
+	}
+
+	return api.AddNumberBatch200JSONResponse{Inserted: int64(len(numbers)), Window: window.model()}, nil
+}
+
+// batchError is the response to a failure of the service to add a batch
+func (s *Server) batchError(ctx context.Context, err error) api.AddNumberBatchResponseObject {
+	body := api.ErrorResponse{Error: s.errorMessage(ctx, err)}
+	if errors.Is(err, service.ErrStorageUnavailable) {
+		return api.AddNumberBatch503JSONResponse(body)
+	}
+
+	return api.AddNumberBatch500JSONResponse(body)
+}
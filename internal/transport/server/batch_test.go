@@ -0,0 +1,183 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	api "golang-test-task/api"
+	"golang-test-task/internal/service"
+	"golang-test-task/internal/storage/memstore"
+	"golang-test-task/numberspb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+// postBatch posts body to POST /numbers/batch as JSON, accepting accept
+func postBatch(handler http.Handler, body, accept string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/numbers/batch", strings.NewReader(body))
+	req.Header.Set("Content-Type", jsonType)
+	req.Header.Set("Accept", accept)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+// TestServer_AddNumberBatch tests that a batch stores every number and answers with
+// the window they span, on every router
+func TestServer_AddNumberBatch(t *testing.T) {
+	for router, newHandler := range handlerRouters {
+		t.Run(router, func(t *testing.T) {
+			store := memstore.New()
+			for _, number := range []int64{1, 5, 100} {
+				_, err := store.InsertNumber(t.Context(), number)
+				require.NoError(t, err)
+			}
+
+			rec := postBatch(newHandler(NewServer(service.New(store))), "[7, 3, 7, 50]", jsonType)
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+			var result api.BatchResult
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&result))
+			assert.Equal(t, api.BatchResult{Inserted: 4, Window: api.NumbersPage{Numbers: api.Numbers{3, 5, 7, 7, 50}}}, result)
+
+			rows, err := store.GetAllNumbersSorted(t.Context())
+			require.NoError(t, err)
+			assert.Len(t, rows, 7)
+		})
+	}
+}
+
+// TestServer_AddNumberBatch_Window tests that a window longer than a page continues
+// with GET /numbers over the range of the batch
+func TestServer_AddNumberBatch_Window(t *testing.T) {
+	handler := NewHandler(NewServer(service.New(memstore.New())))
+	numbers := make([]string, 150)
+	for i := range numbers {
+		numbers[i] = "-3"
+	}
+
+	rec := postBatch(handler, "["+strings.Join(numbers, ",")+"]", jsonType)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var result api.BatchResult
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&result))
+	assert.Equal(t, int64(150), result.Inserted)
+	assert.Len(t, result.Window.Numbers, defaultPageLimit)
+	require.NotNil(t, result.Window.NextCursor)
+
+	rest := listPage(t, handler, url.Values{"min": {"-3"}, "max": {"-3"}, "after": {*result.Window.NextCursor}})
+	assert.Len(t, rest.Numbers, 50)
+	assert.Nil(t, rest.NextCursor)
+}
+
+// TestServer_AddNumberBatch_BadRequests tests that a batch with any invalid number is
+// rejected whole
+func TestServer_AddNumberBatch_BadRequests(t *testing.T) {
+	positive := WithValidator(func(number int64) error {
+		if number <= 0 {
+			return errors.New("number must be positive")
+		}
+		return nil
+	})
+	tests := []struct {
+		name      string
+		body      string
+		wantError string
+	}{
+		{name: "empty", body: "[]", wantError: "a batch must hold between 1 and 10000 numbers, got 0"},
+		{name: "too long", body: "[" + strings.Repeat("1,", maxBatch) + "1]", wantError: "a batch must hold between 1 and 10000 numbers, got 10001"},
+		{name: "invalid number", body: "[3, -1, 4]", wantError: "numbers[1]: number must be positive"},
+		{name: "not a list", body: `{"numbers": [1]}`, wantError: "can't decode JSON body: json: cannot unmarshal object into Go value of type []int64"},
+		{name: "too large", body: "[" + strings.Repeat(" ", maxBody) + "1]", wantError: "can't decode JSON body: http: request body too large"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := memstore.New()
+			rec := postBatch(NewHandler(NewServer(service.New(store), positive)), tt.body, jsonType)
+
+			require.Equal(t, http.StatusBadRequest, rec.Code)
+			var body api.ErrorResponse
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+			assert.Equal(t, tt.wantError, body.Error)
+			count, err := store.CountNumbers(t.Context())
+			require.NoError(t, err)
+			assert.Zero(t, count, "nothing of a rejected batch is stored")
+		})
+	}
+}
+
+// TestServer_AddNumberBatch_StorageErrors tests that a failed batch is reported like
+// a failed AddNumber, with the hooks told of every number
+func TestServer_AddNumberBatch_StorageErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "unavailable", err: service.ErrStorageUnavailable, wantStatus: http.StatusServiceUnavailable},
+		{name: "unexpected", err: errors.New("boom"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var failed []int64
+			queries := &fakeQuerier{insertErr: tt.err}
+			server := NewServer(service.New(queries), WithHooks(Hooks{
+				AfterAdd: func(_ context.Context, number int64, _ time.Duration, err error) {
+					if err != nil {
+						failed = append(failed, number)
+					}
+				},
+			}))
+
+			rec := postBatch(NewHandler(server), "[1, 2]", jsonType)
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, []int64{1, 2}, failed)
+			assert.Empty(t, queries.numbers)
+		})
+	}
+}
+
+// TestServer_AddNumberBatch_Encodings tests that batches are answered in every encoding
+// and may be sent as MessagePack
+func TestServer_AddNumberBatch_Encodings(t *testing.T) {
+	handler := NewHandler(NewServer(service.New(memstore.New())))
+
+	rec := postBatch(handler, "[2, 9]", protobufType)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var result numberspb.BatchResult
+	require.NoError(t, proto.Unmarshal(rec.Body.Bytes(), &result))
+	assert.Equal(t, int64(2), result.Inserted)
+	assert.Equal(t, []int64{2, 9}, result.Window.Numbers)
+
+	rec = postBatch(handler, "[3]", jsonAPIType)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var doc struct {
+		Data  []struct{ Attributes struct{ Number int64 } }
+		Meta  struct{ Inserted int64 }
+		Links struct{ Self, Next string }
+	}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&doc))
+	assert.Equal(t, int64(1), doc.Meta.Inserted)
+	require.Len(t, doc.Data, 1)
+	assert.Equal(t, "/numbers?max=3&min=3", doc.Links.Self, "the window links to where GET /numbers lists it")
+	assert.Empty(t, doc.Links.Next)
+
+	body, err := msgpack.Marshal([]int64{-5})
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/numbers/batch", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", msgpackType)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"inserted": 1, "window": {"numbers": [-5]}}`, rec.Body.String())
+}
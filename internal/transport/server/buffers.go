@@ -0,0 +1,31 @@
+package server
+
+import (
+	"bytes"
+	"sync"
+)
+
+// maxPooledBuffer is the largest buffer kept for reuse. A buffer grown past it by an
+// unusually long list goes to the garbage collector, so one such response does not
+// keep its memory for good.
+const maxPooledBuffer = 1 << 20
+
+// buffers recycles the buffers responses are encoded into, so that a server answering
+// at a high rate does not allocate one per response
+var buffers = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// getBuffer returns an empty buffer, to be handed back with putBuffer once its bytes
+// are written out
+func getBuffer() *bytes.Buffer {
+	return buffers.Get().(*bytes.Buffer)
+}
+
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBuffer {
+		return
+	}
+	buf.Reset()
+	buffers.Put(buf)
+}
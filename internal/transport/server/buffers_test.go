@@ -0,0 +1,95 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang-test-task/internal/service"
+	"golang-test-task/internal/storage/sqlc"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// benchQuerier serves a fixed list without allocating, so that benchmarks measure the
+// encoding rather than the storage
+type benchQuerier struct {
+	sqlc.Querier
+	rows    []sqlc.Number
+	numbers []int64
+}
+
+func newBenchQuerier(n int) benchQuerier {
+	q := benchQuerier{rows: make([]sqlc.Number, n), numbers: make([]int64, n)}
+	for i := range n {
+		number := int64(i*7919 - n*3000)
+		q.rows[i] = sqlc.Number{Number: number}
+		q.numbers[i] = number
+	}
+	return q
+}
+
+func (q benchQuerier) InsertNumber(_ context.Context, number int64) (sqlc.Number, error) {
+	return sqlc.Number{Number: number}, nil
+}
+
+func (q benchQuerier) GetAllNumbersSorted(context.Context) ([]sqlc.Number, error) {
+	return q.rows, nil
+}
+
+func (q benchQuerier) SortedNumbers(context.Context) ([]int64, error) {
+	return q.numbers, nil
+}
+
+func (q benchQuerier) StreamNumbersSorted(_ context.Context, yield func(number int64) error) error {
+	for _, number := range q.numbers {
+		if err := yield(number); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// discardResponse is a ResponseWriter that keeps nothing of the body
+type discardResponse struct {
+	header http.Header
+}
+
+func (d *discardResponse) Header() http.Header         { return d.header }
+func (d *discardResponse) Write(p []byte) (int, error) { return len(p), nil }
+func (d *discardResponse) WriteHeader(int)             {}
+
+// TestBuffers tests that buffers come back empty and that outgrown ones are dropped
+func TestBuffers(t *testing.T) {
+	buf := getBuffer()
+	buf.WriteString("numbers")
+	putBuffer(buf)
+	assert.Zero(t, buf.Len())
+
+	large := getBuffer()
+	large.Grow(maxPooledBuffer + 1)
+	large.WriteString("numbers")
+	putBuffer(large)
+	assert.Equal(t, "numbers", large.String(), "an outgrown buffer is left as it was")
+}
+
+// BenchmarkResponses measures answering AddNumber with a list of 10,000 numbers in
+// every encoding
+func BenchmarkResponses(b *testing.B) {
+	handler := NewHandler(NewServer(service.New(newBenchQuerier(10000))))
+
+	for _, contentType := range responseTypes {
+		b.Run(contentType, func(b *testing.B) {
+			req := httptest.NewRequest(http.MethodPost, "/numbers?number=5", nil)
+			req.Header.Set("Accept", contentType)
+			w := &discardResponse{header: http.Header{}}
+
+			b.ReportAllocs()
+			for b.Loop() {
+				clear(w.header)
+				handler.ServeHTTP(w, req)
+			}
+		})
+	}
+}
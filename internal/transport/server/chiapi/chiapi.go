@@ -0,0 +1,18 @@
+// Package chiapi routes the API on a chi router. The routing code is generated from
+// the same spec as package api; the aliases below give its ServerInterface the exact
+// signature of api.ServerInterface, so the strict handler plugs into either router.
+// An operation with parameters added to the spec needs its params type aliased here,
+// and a security scheme its scopes key.
+package chiapi
+
+import api "golang-test-task/api"
+
+type AddNumberParams = api.AddNumberParams
+type ListNumbersParams = api.ListNumbersParams
+type GetNumberStatsParams = api.GetNumberStatsParams
+type StreamNumbersParams = api.StreamNumbersParams
+
+const (
+	ApiKeyScopes     = api.ApiKeyScopes
+	BearerAuthScopes = api.BearerAuthScopes
+)
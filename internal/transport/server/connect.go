@@ -0,0 +1,75 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"golang-test-task/internal/service"
+	"golang-test-task/numberspb"
+	"golang-test-task/numberspb/numberspbconnect"
+
+	"connectrpc.com/connect"
+)
+
+// ConnectServer serves numbers.v1.NumbersService with connect-go, which speaks the
+// Connect, gRPC and gRPC-Web protocols, chosen by the request's content type. It
+// shares its message conversion with GRPCServer.
+type ConnectServer struct {
+	numbers numbersService
+}
+
+func NewConnectServer(numbers *service.Numbers) *ConnectServer {
+	return &ConnectServer{numbers: numbersService{numbers}}
+}
+
+// WithConnect mounts s under /numbers.v1.NumbersService/ beside next, which serves
+// every other path. gRPC needs HTTP/2, so a plain-text server must allow unencrypted
+// HTTP/2 for gRPC clients to connect.
+func WithConnect(next http.Handler, s *ConnectServer) http.Handler {
+	path, handler := numberspbconnect.NewNumbersServiceHandler(s)
+
+	mux := http.NewServeMux()
+	mux.Handle(path, handler)
+	mux.Handle("/", next)
+
+	return mux
+}
+
+func (s *ConnectServer) AddNumber(ctx context.Context, req *numberspb.AddNumberRequest) (*numberspb.AddNumberResponse, error) {
+	resp, err := s.numbers.add(ctx, req)
+	if err != nil {
+		return nil, connectError(ctx, err)
+	}
+
+	return resp, nil
+}
+
+func (s *ConnectServer) ListNumbers(ctx context.Context, _ *numberspb.ListNumbersRequest) (*numberspb.ListNumbersResponse, error) {
+	resp, err := s.numbers.list(ctx)
+	if err != nil {
+		return nil, connectError(ctx, err)
+	}
+
+	return resp, nil
+}
+
+func (s *ConnectServer) StreamNumbers(ctx context.Context, _ *numberspb.StreamNumbersRequest, stream *connect.ServerStream[numberspb.Number]) error {
+	err := s.numbers.stream(ctx, stream.Send)
+	var sendErr sendError
+	if errors.As(err, &sendErr) {
+		return sendErr.error
+	}
+	if err != nil {
+		return connectError(ctx, err)
+	}
+
+	return nil
+}
+
+// connectError is the Connect error of err, an error of the service. Connect codes
+// have the values of their gRPC counterparts.
+func connectError(ctx context.Context, err error) *connect.Error {
+	return connect.NewError(connect.Code(errorCode(err)), errors.New(errorMessage(ctx, slog.Default(), err)))
+}
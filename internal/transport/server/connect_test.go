@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang-test-task/internal/service"
+	"golang-test-task/numberspb"
+	"golang-test-task/numberspb/numberspbconnect"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// newConnectServer serves the Connect handler beside the HTTP API, speaking
+// unencrypted HTTP/2 as well as HTTP/1.1 like the server does
+func newConnectServer(t *testing.T, queries *fakeQuerier) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewUnstartedServer(WithConnect(NewHandler(NewServer(service.New(queries))), NewConnectServer(service.New(queries))))
+	srv.Config.Protocols = new(http.Protocols)
+	srv.Config.Protocols.SetHTTP1(true)
+	srv.Config.Protocols.SetUnencryptedHTTP2(true)
+	srv.Start()
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+// TestWithConnect_Protocols tests that Connect, gRPC and plain JSON clients reach the
+// same storage through one port, and that the HTTP API is still served beside them
+func TestWithConnect_Protocols(t *testing.T) {
+	ctx := context.Background()
+	srv := newConnectServer(t, &fakeQuerier{})
+
+	connectClient := numberspbconnect.NewNumbersServiceClient(srv.Client(), srv.URL)
+	_, err := connectClient.AddNumber(ctx, &numberspb.AddNumberRequest{Number: 3})
+	require.NoError(t, err)
+
+	conn, err := grpc.NewClient(strings.TrimPrefix(srv.URL, "http://"), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	added, err := numberspb.NewNumbersServiceClient(conn).AddNumber(ctx, &numberspb.AddNumberRequest{Number: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 3}, added.GetNumbers())
+
+	// The Connect protocol is plain JSON over HTTP/1.1, as a browser sends it
+	resp, err := http.Post(srv.URL+numberspbconnect.NumbersServiceAddNumberProcedure, "application/json", strings.NewReader(`{"number": 2}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	stream, err := connectClient.StreamNumbers(ctx, &numberspb.StreamNumbersRequest{})
+	require.NoError(t, err)
+	var streamed []int64
+	for stream.Receive() {
+		streamed = append(streamed, stream.Msg().GetNumber())
+	}
+	require.NoError(t, stream.Err())
+	assert.Equal(t, []int64{1, 2, 3}, streamed)
+
+	resp, err = http.Post(srv.URL+"/numbers?number=4", "", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+// TestWithConnect_StorageErrors tests that storage failures surface with the code of
+// their domain error in every protocol
+func TestWithConnect_StorageErrors(t *testing.T) {
+	ctx := context.Background()
+	srv := newConnectServer(t, &fakeQuerier{insertErr: errRefused, listErr: errors.New("timeout")})
+
+	client := numberspbconnect.NewNumbersServiceClient(srv.Client(), srv.URL)
+	_, err := client.AddNumber(ctx, &numberspb.AddNumberRequest{Number: 1})
+	assert.Equal(t, connect.CodeUnavailable, connect.CodeOf(err))
+	assert.EqualError(t, err, "unavailable: storage unavailable")
+
+	conn, err := grpc.NewClient(strings.TrimPrefix(srv.URL, "http://"), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = numberspb.NewNumbersServiceClient(conn).ListNumbers(ctx, &numberspb.ListNumbersRequest{})
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
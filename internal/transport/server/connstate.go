@@ -0,0 +1,88 @@
+package server
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"sync"
+)
+
+// ConnMetrics counts the connections of an http.Server by state. Set Track as the
+// server's ConnState hook. It is an expvar.Var, so it can be published with the
+// runtime's variables. It is safe for concurrent use.
+type ConnMetrics struct {
+	mu    sync.Mutex
+	conns map[net.Conn]http.ConnState
+	stats ConnStats
+}
+
+// ConnStats are the connections an http.Server holds, by state, and how many it has
+// accepted and let go of since it started
+type ConnStats struct {
+	// Open is every connection not yet closed or hijacked: New, Active and Idle
+	Open int64 `json:"open"`
+	// New connections have not sent a whole request yet
+	New int64 `json:"new"`
+	// Active connections are reading a request or writing its response
+	Active int64 `json:"active"`
+	// Idle connections are kept alive between requests
+	Idle int64 `json:"idle"`
+
+	Accepted uint64 `json:"accepted"`
+	Closed   uint64 `json:"closed"`
+	// Hijacked connections were taken over by a handler, such as for a WebSocket
+	Hijacked uint64 `json:"hijacked"`
+}
+
+// Track records that conn has moved to state
+func (m *ConnMetrics) Track(conn net.Conn, state http.ConnState) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.conns == nil {
+		m.conns = map[net.Conn]http.ConnState{}
+	}
+	if previous, ok := m.conns[conn]; ok {
+		*m.gauge(previous)--
+		delete(m.conns, conn)
+	} else if state == http.StateNew {
+		m.stats.Accepted++
+	}
+
+	switch state {
+	case http.StateClosed:
+		m.stats.Closed++
+	case http.StateHijacked:
+		m.stats.Hijacked++
+	default:
+		m.conns[conn] = state
+		*m.gauge(state)++
+	}
+	m.stats.Open = m.stats.New + m.stats.Active + m.stats.Idle
+}
+
+// gauge is the count of connections in state, which is New, Active or Idle
+func (m *ConnMetrics) gauge(state http.ConnState) *int64 {
+	switch state {
+	case http.StateNew:
+		return &m.stats.New
+	case http.StateActive:
+		return &m.stats.Active
+	default:
+		return &m.stats.Idle
+	}
+}
+
+// Stats returns the counts as they are now
+func (m *ConnMetrics) Stats() ConnStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.stats
+}
+
+// String returns the counts as a JSON object, for expvar
+func (m *ConnMetrics) String() string {
+	data, _ := json.Marshal(m.Stats())
+	return string(data)
+}
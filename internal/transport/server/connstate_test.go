@@ -0,0 +1,65 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestConnMetrics tests that connections are counted as they move between states
+func TestConnMetrics(t *testing.T) {
+	var m ConnMetrics
+	a, b := net.Pipe()
+	defer a.Close()
+	defer b.Close()
+
+	m.Track(a, http.StateNew)
+	m.Track(b, http.StateNew)
+	m.Track(a, http.StateActive)
+	assert.Equal(t, ConnStats{Open: 2, New: 1, Active: 1, Accepted: 2}, m.Stats())
+
+	m.Track(a, http.StateIdle)
+	m.Track(b, http.StateActive)
+	m.Track(b, http.StateHijacked)
+	assert.Equal(t, ConnStats{Open: 1, Idle: 1, Accepted: 2, Hijacked: 1}, m.Stats())
+
+	m.Track(a, http.StateActive)
+	m.Track(a, http.StateClosed)
+	assert.Equal(t, ConnStats{Accepted: 2, Closed: 1, Hijacked: 1}, m.Stats())
+
+	var published map[string]int64
+	require.NoError(t, json.Unmarshal([]byte(m.String()), &published))
+	assert.Equal(t, int64(2), published["accepted"])
+}
+
+// TestConnMetrics_Server tests the hook on a real server: a kept-alive connection is
+// idle between requests and closed once the client lets it go
+func TestConnMetrics_Server(t *testing.T) {
+	var m ConnMetrics
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	}))
+	srv.Config.ConnState = m.Track
+	srv.Start()
+	defer srv.Close()
+
+	client := srv.Client()
+	for range 2 {
+		resp, err := client.Get(srv.URL)
+		require.NoError(t, err)
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+	require.Eventually(t, func() bool { return m.Stats().Idle == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, uint64(1), m.Stats().Accepted, "the connection is kept alive")
+
+	client.CloseIdleConnections()
+	require.Eventually(t, func() bool { return m.Stats() == ConnStats{Accepted: 1, Closed: 1} }, time.Second, time.Millisecond)
+}
@@ -0,0 +1,155 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang-test-task/internal/service"
+	"golang-test-task/internal/storage/sqlc"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+	"github.com/stretchr/testify/require"
+)
+
+// loadSpecRouter loads openapi.yaml, the source the api package is generated from
+func loadSpecRouter(t *testing.T) routers.Router {
+	t.Helper()
+
+	spec, err := openapi3.NewLoader().LoadFromFile("../../../openapi.yaml")
+	require.NoError(t, err)
+	// kin-openapi does not model the OpenAPI 3.1 webhooks, which only document NATS events
+	allowWebhooks := openapi3.AllowExtraSiblingFields("webhooks")
+	require.NoError(t, spec.Validate(context.Background(), allowWebhooks))
+
+	router, err := legacy.NewRouter(spec, allowWebhooks)
+	require.NoError(t, err)
+
+	return router
+}
+
+// validateContract checks the recorded response against the spec operation matching req
+func validateContract(t *testing.T, router routers.Router, req *http.Request, rec *httptest.ResponseRecorder) {
+	t.Helper()
+
+	route, pathParams, err := router.FindRoute(req)
+	require.NoError(t, err, "request does not match any operation in the spec")
+
+	err = openapi3filter.ValidateResponse(context.Background(), &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: &openapi3filter.RequestValidationInput{
+			Request:    req,
+			PathParams: pathParams,
+			Route:      route,
+		},
+		Status: rec.Code,
+		Header: rec.Header(),
+		Body:   io.NopCloser(bytes.NewReader(rec.Body.Bytes())),
+		Options: &openapi3filter.Options{
+			IncludeResponseStatus: true,
+		},
+	})
+	require.NoError(t, err, "status %d body %q violates the spec", rec.Code, rec.Body.String())
+}
+
+// TestContract_AddNumber runs every kind of AddNumber response through spec validation
+func TestContract_AddNumber(t *testing.T) {
+	router := loadSpecRouter(t)
+
+	tests := []struct {
+		name       string
+		query      string
+		queries    sqlc.Querier
+		wantStatus int
+	}{
+		{name: "success", query: "?number=5", queries: &fakeQuerier{numbers: []int64{7, -1}}, wantStatus: http.StatusOK},
+		{name: "envelope", query: "?number=5&envelope=true", queries: &fakeQuerier{numbers: []int64{7, -1}}, wantStatus: http.StatusOK},
+		{name: "full", query: "?number=5&full=true", queries: &fakeQuerier{numbers: []int64{7, -1}}, wantStatus: http.StatusOK},
+		{name: "full conflicts", query: "?number=5&full=true&response=none", queries: &fakeQuerier{numbers: []int64{7, -1}}, wantStatus: http.StatusBadRequest},
+		{name: "count", query: "?number=5&response=count", queries: &fakeQuerier{numbers: []int64{7, -1}}, wantStatus: http.StatusOK},
+		{name: "no content", query: "?number=5&response=none", queries: &fakeQuerier{numbers: []int64{7, -1}}, wantStatus: http.StatusNoContent},
+		{name: "empty table", query: "?number=0", queries: &fakeQuerier{}, wantStatus: http.StatusOK},
+		{name: "missing parameter", query: "", queries: &fakeQuerier{}, wantStatus: http.StatusBadRequest},
+		{name: "malformed parameter", query: "?number=abc", queries: &fakeQuerier{}, wantStatus: http.StatusBadRequest},
+		{name: "out of range", query: "?number=9223372036854775808", queries: &fakeQuerier{}, wantStatus: http.StatusBadRequest},
+		{name: "insert error", query: "?number=1", queries: &fakeQuerier{insertErr: errors.New("boom")}, wantStatus: http.StatusInternalServerError},
+		{name: "list error", query: "?number=1", queries: &fakeQuerier{listErr: errors.New("boom")}, wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/numbers"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			NewHandler(NewServer(service.New(tt.queries))).ServeHTTP(rec, req)
+
+			require.Equal(t, tt.wantStatus, rec.Code)
+			validateContract(t, router, req, rec)
+		})
+	}
+}
+
+// TestContract_ListNumbers runs every kind of ListNumbers response through spec validation
+func TestContract_ListNumbers(t *testing.T) {
+	router := loadSpecRouter(t)
+
+	tests := []struct {
+		name       string
+		query      string
+		queries    sqlc.Querier
+		wantStatus int
+	}{
+		{name: "first page", query: "?limit=1", queries: &fakeQuerier{numbers: []int64{7, -1}}, wantStatus: http.StatusOK},
+		{name: "last page", query: "?min=0&max=10", queries: &fakeQuerier{numbers: []int64{7, -1}}, wantStatus: http.StatusOK},
+		{name: "empty table", query: "", queries: &fakeQuerier{}, wantStatus: http.StatusOK},
+		{name: "limit out of range", query: "?limit=0", queries: &fakeQuerier{}, wantStatus: http.StatusBadRequest},
+		{name: "bad cursor", query: "?after=abc", queries: &fakeQuerier{}, wantStatus: http.StatusBadRequest},
+		{name: "list error", query: "", queries: &fakeQuerier{listErr: errors.New("boom")}, wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/numbers"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			NewHandler(NewServer(service.New(tt.queries))).ServeHTTP(rec, req)
+
+			require.Equal(t, tt.wantStatus, rec.Code)
+			validateContract(t, router, req, rec)
+		})
+	}
+}
+
+// TestContract_AddNumberBatch runs every kind of AddNumberBatch response through spec
+// validation
+func TestContract_AddNumberBatch(t *testing.T) {
+	router := loadSpecRouter(t)
+
+	tests := []struct {
+		name       string
+		body       string
+		queries    sqlc.Querier
+		wantStatus int
+	}{
+		{name: "success", body: "[5, -1]", queries: &fakeQuerier{numbers: []int64{7}}, wantStatus: http.StatusOK},
+		{name: "malformed body", body: "[5,", queries: &fakeQuerier{}, wantStatus: http.StatusBadRequest},
+		{name: "insert error", body: "[1]", queries: &fakeQuerier{insertErr: errors.New("boom")}, wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/numbers/batch", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			NewHandler(NewServer(service.New(tt.queries))).ServeHTTP(rec, req)
+
+			require.Equal(t, tt.wantStatus, rec.Code)
+			validateContract(t, router, req, rec)
+		})
+	}
+}
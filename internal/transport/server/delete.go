@@ -0,0 +1,46 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	api "golang-test-task/api"
+	"golang-test-task/internal/service"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// DeleteNumber deletes the row whose id an insert answered with
+func (s *Server) DeleteNumber(ctx context.Context, request api.DeleteNumberRequestObject) (api.DeleteNumberResponseObject, error) {
+	// The id is decoded as a UUID, so a malformed one never gets here
+	_, err := s.numbers.Delete(ctx, pgtype.UUID{Bytes: request.Id, Valid: true})
+	if err != nil {
+		body := api.ErrorResponse{Error: s.errorMessage(ctx, err)}
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			return api.DeleteNumber404JSONResponse(body), nil
+		case errors.Is(err, service.ErrStorageUnavailable):
+			return api.DeleteNumber503JSONResponse(body), nil
+		}
+		return api.DeleteNumber500JSONResponse(body), nil
+	}
+
+	return api.DeleteNumber204Response{}, nil
+}
+
+// ClearNumbers deletes every stored number, when the caller may
+func (s *Server) ClearNumbers(ctx context.Context, _ api.ClearNumbersRequestObject) (api.ClearNumbersResponseObject, error) {
+	if s.clearAllowed == nil || !s.clearAllowed(ctx) {
+		return api.ClearNumbers403JSONResponse{Error: "clearing the numbers needs an admin API key"}, nil
+	}
+
+	if err := s.numbers.Clear(ctx); err != nil {
+		body := api.ErrorResponse{Error: s.errorMessage(ctx, err)}
+		if errors.Is(err, service.ErrStorageUnavailable) {
+			return api.ClearNumbers503JSONResponse(body), nil
+		}
+		return api.ClearNumbers500JSONResponse(body), nil
+	}
+
+	return api.ClearNumbers204Response{}, nil
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang-test-task/internal/service"
+	"golang-test-task/internal/storage/memstore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const adminKey = "admin-0123456789abcdef0123456789abcdef"
+
+// TestServer_DeleteNumber tests that the id an add answers with deletes its row once,
+// and that other ids and failures get their own status
+func TestServer_DeleteNumber(t *testing.T) {
+	for router, newHandler := range handlerRouters {
+		t.Run(router, func(t *testing.T) {
+			store := memstore.New()
+			handler := newHandler(NewServer(service.New(store)))
+			serve := func(method, target string) *httptest.ResponseRecorder {
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+				return rec
+			}
+
+			var added struct{ ID string }
+			require.NoError(t, json.NewDecoder(serve(http.MethodPost, "/numbers?number=5").Body).Decode(&added))
+			require.Len(t, added.ID, 36)
+			_, err := store.InsertNumber(context.Background(), 7)
+			require.NoError(t, err)
+
+			rec := serve(http.MethodDelete, "/numbers/"+added.ID)
+			assert.Equal(t, http.StatusNoContent, rec.Code)
+			assert.Empty(t, rec.Body.String())
+			count, err := store.CountNumbers(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), count)
+
+			rec = serve(http.MethodDelete, "/numbers/"+added.ID)
+			assert.Equal(t, http.StatusNotFound, rec.Code)
+			assert.JSONEq(t, `{"error":"not found"}`, rec.Body.String())
+
+			rec = serve(http.MethodDelete, "/numbers/five")
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		})
+	}
+
+	for _, tt := range []struct {
+		err        error
+		wantStatus int
+	}{
+		{err: errRefused, wantStatus: http.StatusServiceUnavailable},
+		{err: errors.New("boom"), wantStatus: http.StatusInternalServerError},
+	} {
+		rec := httptest.NewRecorder()
+		NewHandler(NewServer(service.New(&fakeQuerier{deleteErr: tt.err}))).
+			ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/numbers/5d41402a-bc4b-4a76-b971-9d911017c592", nil))
+		assert.Equal(t, tt.wantStatus, rec.Code, tt.err)
+	}
+}
+
+// TestServer_ClearNumbers tests that only the callers WithClear allows delete every
+// number, which with Admin are those of admin keys
+func TestServer_ClearNumbers(t *testing.T) {
+	queries := &fakeQuerier{numbers: []int64{1, 2}}
+	rec := httptest.NewRecorder()
+	NewHandler(NewServer(service.New(queries))).ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/numbers", nil))
+	assert.Equal(t, http.StatusForbidden, rec.Code, "no one may without WithClear")
+	assert.JSONEq(t, `{"error":"clearing the numbers needs an admin API key"}`, rec.Body.String())
+
+	handler := NewHandler(NewServer(service.New(queries), WithClear(Admin)),
+		Authenticate(APIKeys([]APIKey{{Key: writerKey}, {Key: adminKey, Admin: true}}, false)))
+	clear := func(key string) int {
+		req := httptest.NewRequest(http.MethodDelete, "/numbers", nil)
+		req.Header.Set("X-API-Key", key)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	assert.Equal(t, http.StatusForbidden, clear(writerKey))
+	assert.Equal(t, []int64{1, 2}, queries.numbers)
+	assert.Equal(t, http.StatusNoContent, clear(adminKey))
+	assert.Empty(t, queries.numbers)
+
+	queries.deleteErr = errRefused
+	assert.Equal(t, http.StatusServiceUnavailable, clear(adminKey))
+}
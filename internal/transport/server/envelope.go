@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	api "golang-test-task/api"
+)
+
+// envelopePreference is the preference of the Prefer header asking for the envelope
+const envelopePreference = "envelope"
+
+type envelopeKey struct{}
+
+// envelopeRequest is what the meta of a response takes from the request headers
+type envelopeRequest struct {
+	// preferred is whether the Prefer header asks for the envelope
+	preferred bool
+	// requestID is the X-Request-Id header, empty when the client sent none
+	requestID string
+}
+
+// envelopes records in the context whether the Prefer header asks for the envelope
+// and the id of the request, which the handlers cannot see otherwise
+func envelopes(f api.StrictHandlerFunc, operationID string) api.StrictHandlerFunc {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request, request any) (any, error) {
+		e := envelopeRequest{preferred: prefersEnvelope(r), requestID: r.Header.Get("X-Request-Id")}
+		return f(context.WithValue(ctx, envelopeKey{}, e), w, r, request)
+	}
+}
+
+// prefersEnvelope reports whether one of the Prefer headers of r, a list of RFC 7240
+// preferences, holds the envelope preference
+func prefersEnvelope(r *http.Request) bool {
+	for _, header := range r.Header.Values("Prefer") {
+		for _, preference := range strings.Split(header, ",") {
+			token, _, _ := strings.Cut(preference, ";")
+			token, _, _ = strings.Cut(token, "=")
+			if strings.EqualFold(strings.TrimSpace(token), envelopePreference) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// enveloped reports whether the response to the request of ctx carries meta. The
+// envelope query parameter, when sent, wins over the Prefer header.
+func enveloped(ctx context.Context, params api.AddNumberParams) bool {
+	if params.Envelope != nil {
+		return *params.Envelope
+	}
+	e, _ := ctx.Value(envelopeKey{}).(envelopeRequest)
+
+	return e.preferred
+}
+
+// meta describes a response holding returned of the total numbers stored, to the
+// request of ctx that began at start
+func (s *Server) meta(ctx context.Context, total, returned int64, start time.Time) *api.ResponseMeta {
+	e, _ := ctx.Value(envelopeKey{}).(envelopeRequest)
+	requestID := e.requestID
+	if requestID == "" {
+		requestID = newRequestID()
+	}
+
+	// The list is never split, so no page follows
+	return &api.ResponseMeta{
+		Total:     total,
+		Returned:  returned,
+		ElapsedMs: s.now().Sub(start).Milliseconds(),
+		RequestId: requestID,
+	}
+}
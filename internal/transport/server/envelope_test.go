@@ -0,0 +1,99 @@
+package server
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	api "golang-test-task/api"
+	"golang-test-task/internal/service"
+	"golang-test-task/numberspb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// TestEnvelope tests that the list carries meta when the query flag or the Prefer
+// header asks for it, the flag winning, and never otherwise
+func TestEnvelope(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		prefer   string
+		wantMeta bool
+	}{
+		{name: "plain", query: "?number=5"},
+		{name: "query flag", query: "?number=5&envelope=true", wantMeta: true},
+		{name: "prefer", query: "?number=5", prefer: "respond-async, Envelope; strict", wantMeta: true},
+		{name: "other preference", query: "?number=5", prefer: "return=minimal"},
+		{name: "flag turns prefer off", query: "?number=5&envelope=false", prefer: "envelope"},
+	}
+
+	for router, newHandler := range handlerRouters {
+		for _, tt := range tests {
+			t.Run(router+"/"+tt.name, func(t *testing.T) {
+				now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+				clock := func() time.Time {
+					now = now.Add(3 * time.Millisecond)
+					return now
+				}
+
+				req := httptest.NewRequest(http.MethodPost, "/numbers"+tt.query, nil)
+				req.Header.Set("X-Request-Id", "req-1")
+				if tt.prefer != "" {
+					req.Header.Set("Prefer", tt.prefer)
+				}
+				rec := httptest.NewRecorder()
+				newHandler(NewServer(service.New(&fakeQuerier{numbers: []int64{7, -1}}), WithClock(clock), WithWriteResponse(WriteFull))).ServeHTTP(rec, req)
+
+				require.Equal(t, http.StatusOK, rec.Code)
+				if !tt.wantMeta {
+					assert.JSONEq(t, `{"numbers":[-1,5,7]}`, rec.Body.String())
+					return
+				}
+				assert.JSONEq(t, `{"numbers":[-1,5,7],"meta":{"total":3,"returned":3,"elapsed_ms":9,"request_id":"req-1"}}`, rec.Body.String())
+			})
+		}
+	}
+}
+
+// TestEnvelope_Encodings tests that meta is sent in every encoding but JSON:API,
+// whose documents have their own, with an id made up when the client sent none
+func TestEnvelope_Encodings(t *testing.T) {
+	serve := func(accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/numbers?number=5&envelope=true", nil)
+		req.Header.Set("Accept", accept)
+		rec := httptest.NewRecorder()
+		NewHandler(NewServer(service.New(&fakeQuerier{numbers: []int64{7}}), WithWriteResponse(WriteFull))).ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+		return rec
+	}
+
+	var body api.CreateNumberResponse
+	require.NoError(t, json.Unmarshal(serve(jsonType).Body.Bytes(), &body))
+	require.NotNil(t, body.Meta)
+	assert.Len(t, body.Meta.RequestId, 16)
+	other := serve(jsonType)
+	assert.NotContains(t, other.Body.String(), body.Meta.RequestId, "every request gets its own id")
+
+	var xmlBody api.CreateNumberResponse
+	require.NoError(t, xml.Unmarshal(serve(xmlType).Body.Bytes(), &xmlBody))
+	require.NotNil(t, xmlBody.Meta)
+	assert.Equal(t, int64(2), xmlBody.Meta.Total)
+	assert.Equal(t, int64(2), xmlBody.Meta.Returned)
+
+	var pbBody numberspb.AddNumberResponse
+	require.NoError(t, proto.Unmarshal(serve(protobufType).Body.Bytes(), &pbBody))
+	assert.Equal(t, int64(2), pbBody.GetMeta().GetTotal())
+	assert.NotEmpty(t, pbBody.GetMeta().GetRequestId())
+
+	assert.Contains(t, serve(msgpackType).Body.String(), "request_id")
+
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(serve(jsonAPIType).Body.Bytes(), &doc))
+	assert.Equal(t, map[string]any{"total": 2.0}, doc["meta"])
+}
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"golang-test-task/internal/service"
+
+	"google.golang.org/grpc/codes"
+)
+
+// internalError is what clients are told of a failure that is none of the
+// service's domain errors
+const internalError = "internal error"
+
+// errorCode maps an error of the service to the gRPC status code clients get
+func errorCode(err error) codes.Code {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		return codes.NotFound
+	case errors.Is(err, service.ErrDuplicate):
+		return codes.AlreadyExists
+	case errors.Is(err, service.ErrStorageUnavailable):
+		return codes.Unavailable
+	case errors.Is(err, context.Canceled):
+		return codes.Canceled
+	}
+
+	return codes.Internal
+}
+
+// errorMessage logs err, an error of the service, to logger and returns what clients
+// are told of it: the domain error it wraps, never the storage failure behind it
+func errorMessage(ctx context.Context, logger *slog.Logger, err error) string {
+	for _, domainErr := range []error{service.ErrNotFound, service.ErrDuplicate, service.ErrStorageUnavailable, context.Canceled} {
+		if errors.Is(err, domainErr) {
+			logger.WarnContext(ctx, "Request failed", "error", err)
+			return domainErr.Error()
+		}
+	}
+	logger.ErrorContext(ctx, "Request failed", "error", err)
+
+	return internalError
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// etags gives successful GET responses an ETag, the hash of their body, and answers
+// 304 Not Modified with no body to a request whose If-None-Match holds it, so
+// clients polling a page or the statistics only download what changed. The response
+// is still read from the database and encoded to be hashed; what is saved is the
+// transfer. Streams of events are sent as they are written, without one.
+func etags(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ew := &etagWriter{ResponseWriter: w, buf: getBuffer()}
+		defer putBuffer(ew.buf)
+		next.ServeHTTP(ew, r)
+		ew.finish(r.Header.Get("If-None-Match"))
+	})
+}
+
+// etagWriter holds back a 200 response until it is whole, and passes any other
+// through as it is written
+type etagWriter struct {
+	http.ResponseWriter
+	buf *bytes.Buffer
+	// status is the status written, 0 before it is
+	status      int
+	passthrough bool
+}
+
+func (w *etagWriter) WriteHeader(status int) {
+	if w.status != 0 {
+		return
+	}
+	w.status = status
+	if status != http.StatusOK || w.Header().Get("Content-Type") == eventStreamType {
+		w.passthrough = true
+		w.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (w *etagWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.passthrough {
+		return w.ResponseWriter.Write(p)
+	}
+
+	return w.buf.Write(p)
+}
+
+// Unwrap lets http.ResponseController reach the connection, for streams to flush
+// and lift their write deadline
+func (w *etagWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// finish sends the response held back, or 304 when ifNoneMatch names its ETag
+func (w *etagWriter) finish(ifNoneMatch string) {
+	if w.passthrough || w.status == 0 {
+		return
+	}
+
+	sum := sha256.Sum256(w.buf.Bytes())
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	w.Header().Set("ETag", etag)
+	if etagMatches(ifNoneMatch, etag) {
+		w.Header().Del("Content-Length")
+		w.Header().Del("Content-Type")
+		w.ResponseWriter.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.ResponseWriter.WriteHeader(http.StatusOK)
+	w.ResponseWriter.Write(w.buf.Bytes())
+}
+
+// etagMatches reports whether the If-None-Match header ifNoneMatch names etag,
+// comparing weakly as RFC 9110 asks of it
+func etagMatches(ifNoneMatch, etag string) bool {
+	for candidate := range strings.SplitSeq(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+
+	return false
+}
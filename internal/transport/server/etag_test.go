@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	api "golang-test-task/api"
+	"golang-test-task/internal/service"
+	"golang-test-task/internal/storage/memstore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestServer_ETags tests that GET responses carry an ETag that changes with their
+// body and is answered 304 while it does not, and that other methods carry none
+func TestServer_ETags(t *testing.T) {
+	handler := NewHandler(NewServer(service.New(memstore.New())))
+	get := func(target, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := get("/numbers/stats", "")
+	require.Equal(t, http.StatusOK, first.Code)
+	etag := first.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	rec := get("/numbers/stats", `"other", W/`+etag)
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.String())
+	assert.Equal(t, etag, rec.Header().Get("ETag"))
+	assert.Equal(t, http.StatusOK, get("/numbers/stats", `"other"`).Code)
+	assert.NotEqual(t, etag, get("/numbers", "").Header().Get("ETag"), "the ETag is the body's")
+
+	add := httptest.NewRecorder()
+	handler.ServeHTTP(add, httptest.NewRequest(http.MethodPost, "/numbers?number=5", nil))
+	require.Equal(t, http.StatusOK, add.Code)
+	assert.Empty(t, add.Header().Get("ETag"), "only GET responses have one")
+
+	rec = get("/numbers/stats", etag)
+	assert.Equal(t, http.StatusOK, rec.Code, "the statistics changed")
+	assert.NotEqual(t, etag, rec.Header().Get("ETag"))
+	assert.Empty(t, get("/numbers/stats?percentiles=101", "").Header().Get("ETag"), "errors have none")
+}
+
+// TestServer_ETags_CachingDoer tests that a client behind api.CachingDoer gets the
+// cached page when the server answers 304
+func TestServer_ETags_CachingDoer(t *testing.T) {
+	store := memstore.New()
+	_, err := store.InsertNumbers(context.Background(), []int64{3, 1})
+	require.NoError(t, err)
+	var notModified int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := httptest.NewRecorder()
+		NewHandler(NewServer(service.New(store))).ServeHTTP(rec, r)
+		if rec.Code == http.StatusNotModified {
+			notModified++
+		}
+		for k, v := range rec.Header() {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(rec.Code)
+		w.Write(rec.Body.Bytes())
+	}))
+	defer srv.Close()
+
+	client, err := api.NewClientWithResponses(srv.URL, api.WithHTTPClient(api.NewCachingDoer(srv.Client())))
+	require.NoError(t, err)
+	for range 2 {
+		resp, err := client.ListNumbersWithResponse(context.Background(), &api.ListNumbersParams{})
+		require.NoError(t, err)
+		require.NotNil(t, resp.JSON200)
+		assert.Equal(t, []int64{1, 3}, resp.JSON200.Numbers)
+	}
+	assert.Equal(t, 1, notModified)
+}
@@ -0,0 +1,176 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	api "golang-test-task/api"
+	"golang-test-task/internal/service"
+	"golang-test-task/internal/storage/feed"
+)
+
+const eventStreamType = "text/event-stream"
+
+// keepaliveInterval is how often an idle stream is sent a comment, so that proxies
+// and clients do not take it for a dead connection
+const keepaliveInterval = 15 * time.Second
+
+// StreamNumbers answers with a stream of server-sent events that lasts until the
+// client goes or the feed ends, telling of every number stored from now on
+func (s *Server) StreamNumbers(ctx context.Context, request api.StreamNumbersRequestObject) (api.StreamNumbersResponseObject, error) {
+	if s.feed == nil {
+		return api.StreamNumbers503JSONResponse{Error: "the server does not follow the stored numbers"}, nil
+	}
+
+	// Subscribing before the snapshot is read means no number stored after it is
+	// missed, though one stored meanwhile may be both in it and sent
+	ctx, cancel := context.WithCancel(ctx)
+	stream := numberEvents{ctx: ctx, cancel: cancel, server: s, events: s.feed.Subscribe(ctx)}
+	if request.Params.Snapshot != nil && *request.Params.Snapshot {
+		stream.snapshot = true
+		numbers, err := s.numbers.Values(ctx)
+		if err != nil {
+			cancel()
+			return s.streamError(ctx, err), nil
+		}
+		stream.first = numbers
+	}
+
+	return stream, nil
+}
+
+// streamError is the response to a failure of the service before a stream starts
+func (s *Server) streamError(ctx context.Context, err error) api.StreamNumbersResponseObject {
+	body := api.ErrorResponse{Error: s.errorMessage(ctx, err)}
+	if errors.Is(err, service.ErrStorageUnavailable) {
+		return api.StreamNumbers503JSONResponse(body)
+	}
+
+	return api.StreamNumbers500JSONResponse(body)
+}
+
+// numberEvents is the stream of events a subscription to the feed is sent as
+type numberEvents struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	server *Server
+	events <-chan feed.Event
+	// snapshot is whether the stored numbers are sent after every change, and first
+	// the ones sent before any
+	snapshot bool
+	first    []int64
+}
+
+func (e numberEvents) VisitStreamNumbersResponse(w http.ResponseWriter) error {
+	defer e.cancel()
+
+	// The stream lasts as long as the client follows it, past the server's write
+	// timeout
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Time{})
+	w.Header().Set("Content-Type", eventStreamType)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	var buf bytes.Buffer
+	if e.snapshot {
+		writeSnapshot(&buf, e.first)
+	}
+	keepalive := time.NewTicker(keepaliveInterval)
+	defer keepalive.Stop()
+
+	for {
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			// The client is gone
+			return nil
+		}
+		if err := rc.Flush(); err != nil {
+			return nil
+		}
+		buf.Reset()
+
+		select {
+		case <-e.ctx.Done():
+			return nil
+		case <-keepalive.C:
+			buf.WriteString(":\n\n")
+			continue
+		case event, ok := <-e.events:
+			if !ok {
+				// The feed ended, or dropped the client for falling behind; clients
+				// that reconnect start afresh
+				return nil
+			}
+			writeChange(&buf, event)
+		}
+
+		// Changes that came together are sent together, with one snapshot after them
+	pending:
+		for {
+			select {
+			case event, ok := <-e.events:
+				if !ok {
+					break pending
+				}
+				writeChange(&buf, event)
+			default:
+				break pending
+			}
+		}
+		if !e.snapshot {
+			continue
+		}
+		numbers, err := e.server.numbers.Values(e.ctx)
+		if err != nil {
+			// The status is sent, so the stream can only end. The changes before it
+			// are still sent, and a client that reconnects gets a snapshot afresh.
+			e.server.errorMessage(e.ctx, err)
+			w.Write(buf.Bytes())
+			return nil
+		}
+		writeSnapshot(&buf, numbers)
+	}
+}
+
+// writeChange writes event to buf as a number, delete or reset event
+func writeChange(buf *bytes.Buffer, event feed.Event) {
+	if event.Reset {
+		writeEvent(buf, "reset", "", nil)
+		return
+	}
+
+	name := "number"
+	if event.Deleted {
+		name = "delete"
+	}
+	row := event.Row
+	writeEvent(buf, name, row.ID.String(), api.NumberEvent{Id: row.ID.Bytes, Number: row.Number, CreatedAt: row.CreatedAt.Time})
+}
+
+// writeSnapshot writes numbers to buf as a snapshot event
+func writeSnapshot(buf *bytes.Buffer, numbers []int64) {
+	if numbers == nil {
+		numbers = []int64{}
+	}
+	writeEvent(buf, "snapshot", "", numbers)
+}
+
+// writeEvent writes an event named name to buf, with data encoded as JSON and id
+// unless it is empty. The data line is written even when data is nil, as clients
+// ignore events without one.
+func writeEvent(buf *bytes.Buffer, name, id string, data any) {
+	if id != "" {
+		buf.WriteString("id: " + id + "\n")
+	}
+	buf.WriteString("event: " + name + "\ndata:")
+	if data != nil {
+		encoded, _ := json.Marshal(data)
+		buf.WriteByte(' ')
+		buf.Write(encoded)
+	}
+	buf.WriteString("\n\n")
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang-test-task/internal/service"
+	"golang-test-task/internal/storage/feed"
+	"golang-test-task/internal/storage/memstore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readEvent reads the next event of a stream, skipping comments, as its lines
+func readEvent(t *testing.T, r *bufio.Reader) []string {
+	t.Helper()
+	var lines []string
+	for {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "" && len(lines) > 0:
+			return lines
+		case line == "" || strings.HasPrefix(line, ":"):
+		default:
+			lines = append(lines, line)
+		}
+	}
+}
+
+// TestServer_StreamNumbers tests that a stream sends the snapshot, then every number
+// stored or deleted with the snapshot after it, and ends when the feed does
+func TestServer_StreamNumbers(t *testing.T) {
+	ctx := context.Background()
+	f := feed.New()
+	queries := f.Wrap(memstore.New())
+	_, err := queries.InsertNumber(ctx, 5)
+	require.NoError(t, err)
+	srv := httptest.NewServer(NewHandler(NewServer(service.New(queries), WithFeed(f))))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/numbers/stream?snapshot=true")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, eventStreamType, resp.Header.Get("Content-Type"))
+	events := bufio.NewReader(resp.Body)
+	assert.Equal(t, []string{"event: snapshot", "data: [5]"}, readEvent(t, events))
+
+	row, err := queries.InsertNumber(ctx, 3)
+	require.NoError(t, err)
+	number := readEvent(t, events)
+	require.Len(t, number, 3)
+	assert.Equal(t, []string{"id: " + row.ID.String(), "event: number"}, number[:2])
+	assert.JSONEq(t, `{"id":"`+row.ID.String()+`","number":3,"created_at":"`+row.CreatedAt.Time.Format(time.RFC3339Nano)+`"}`,
+		strings.TrimPrefix(number[2], "data: "))
+	assert.Equal(t, []string{"event: snapshot", "data: [3,5]"}, readEvent(t, events))
+
+	_, err = queries.DeleteNumber(ctx, row.ID)
+	require.NoError(t, err)
+	deleted := readEvent(t, events)
+	require.Len(t, deleted, 3)
+	assert.Equal(t, []string{"id: " + row.ID.String(), "event: delete"}, deleted[:2])
+	assert.Equal(t, number[2], deleted[2], "a delete carries the row deleted")
+	assert.Equal(t, []string{"event: snapshot", "data: [5]"}, readEvent(t, events))
+
+	f.Close()
+	_, err = events.ReadString('\n')
+	assert.Error(t, err, "closing the feed ends the stream")
+}
+
+// TestServer_StreamNumbers_Unavailable tests that a server without a feed, or whose
+// storage is down for the first snapshot, answers 503
+func TestServer_StreamNumbers_Unavailable(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewHandler(NewServer(service.New(&fakeQuerier{}))).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/numbers/stream", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.JSONEq(t, `{"error":"the server does not follow the stored numbers"}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	server := NewServer(service.New(&fakeQuerier{listErr: errRefused}), WithFeed(feed.New()))
+	NewHandler(server).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/numbers/stream?snapshot=true", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.JSONEq(t, `{"error":"storage unavailable"}`, rec.Body.String())
+}
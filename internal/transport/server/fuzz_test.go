@@ -0,0 +1,90 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	api "golang-test-task/api"
+	"golang-test-task/internal/service"
+)
+
+// FuzzAddNumber feeds arbitrary query strings through parameter decoding and
+// checks that every request gets either a valid list or a JSON error, never a panic
+func FuzzAddNumber(f *testing.F) {
+	for _, seed := range []string{
+		"number=1",
+		"number=-1",
+		"number=0",
+		"number=2147483647",
+		"number=-2147483648",
+		"number=2147483648",
+		"number=-2147483649",
+		"number=9223372036854775807",
+		"number=9223372036854775808",
+		"number=99999999999999999999999",
+		"number=",
+		"",
+		"number=abc",
+		"number=1.5",
+		"number=1e3",
+		"number=0x10",
+		"number=+5",
+		"number=1&number=2",
+		"number=%zz",
+		"number=%00",
+		"number[]=1",
+		"number=" + strings.Repeat("9", 4096),
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, rawQuery string) {
+		handler := NewHandler(NewServer(service.New(&fakeQuerier{})))
+
+		req := httptest.NewRequest(http.MethodPost, "/numbers", nil)
+		req.URL.RawQuery = rawQuery
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+			t.Fatalf("query %q: unexpected content type %q", rawQuery, ct)
+		}
+
+		switch rec.Code {
+		case http.StatusOK:
+			var body api.CreateNumberResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("query %q: invalid success body %q: %v", rawQuery, rec.Body.String(), err)
+			}
+			// The window is the default, and full=true may ask for the list instead
+			numbers := body.Window
+			if numbers == nil {
+				numbers = body.Numbers
+			}
+			if numbers == nil || len(*numbers) != 1 {
+				t.Fatalf("query %q: expected exactly one number, got %q", rawQuery, rec.Body.String())
+			}
+
+			number, err := strconv.ParseInt(req.URL.Query().Get("number"), 10, 64)
+			if err != nil || !slices.Contains(*numbers, number) {
+				t.Fatalf("query %q: response %v does not contain the requested number", rawQuery, *numbers)
+			}
+		case http.StatusBadRequest:
+			var body api.ErrorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("query %q: invalid error body %q: %v", rawQuery, rec.Body.String(), err)
+			}
+			if body.Error == "" {
+				t.Fatalf("query %q: empty error message", rawQuery)
+			}
+		default:
+			t.Fatalf("query %q: unexpected status %d", rawQuery, rec.Code)
+		}
+	})
+}
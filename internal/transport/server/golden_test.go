@@ -0,0 +1,78 @@
+package server
+
+import (
+	"errors"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"golang-test-task/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files with the current responses")
+
+// assertGolden compares body with testdata/<name>.golden, rewriting the file when -update is set
+func assertGolden(t *testing.T, name string, body []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, body, 0o644))
+	}
+
+	want, err := os.ReadFile(path)
+	require.NoError(t, err, "missing golden file, run go test ./server/ -run Golden -update")
+	assert.Equal(t, string(want), string(body), "response differs from %s, rerun with -update if the change is intended", path)
+}
+
+// handlerRouters build the handler on each supported router; responses must not depend on it
+var handlerRouters = map[string]func(s *Server) http.Handler{
+	"std": func(s *Server) http.Handler { return NewHandler(s) },
+	"chi": func(s *Server) http.Handler { return NewChiHandler(s, chi.NewRouter()) },
+}
+
+// TestGolden_AddNumber pins the exact bodies of every AddNumber success and error
+// response, on every router
+func TestGolden_AddNumber(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		queries    *fakeQuerier
+		wantStatus int
+	}{
+		{name: "add_number_ok", query: "?number=5", queries: &fakeQuerier{numbers: []int64{7, -1, 5}}, wantStatus: http.StatusOK},
+		{name: "add_number_full", query: "?number=5&full=true", queries: &fakeQuerier{numbers: []int64{7, -1, 5}}, wantStatus: http.StatusOK},
+		{name: "add_number_missing_param", query: "", queries: &fakeQuerier{}, wantStatus: http.StatusBadRequest},
+		{name: "add_number_malformed_param", query: "?number=abc", queries: &fakeQuerier{}, wantStatus: http.StatusBadRequest},
+		{name: "add_number_out_of_range", query: "?number=9223372036854775808", queries: &fakeQuerier{}, wantStatus: http.StatusBadRequest},
+		{name: "add_number_insert_error", query: "?number=1", queries: &fakeQuerier{insertErr: errors.New("disk full")}, wantStatus: http.StatusInternalServerError},
+		{name: "add_number_storage_unavailable", query: "?number=1", queries: &fakeQuerier{insertErr: errRefused}, wantStatus: http.StatusServiceUnavailable},
+		{name: "add_number_list_error", query: "?number=1", queries: &fakeQuerier{listErr: errors.New("timeout")}, wantStatus: http.StatusInternalServerError},
+	}
+
+	for router, newHandler := range handlerRouters {
+		for _, tt := range tests {
+			t.Run(router+"/"+tt.name, func(t *testing.T) {
+				// Every router starts from the same rows
+				queries := *tt.queries
+				queries.numbers = slices.Clone(queries.numbers)
+
+				rec := httptest.NewRecorder()
+				newHandler(NewServer(service.New(&queries))).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/numbers"+tt.query, nil))
+
+				require.Equal(t, tt.wantStatus, rec.Code)
+				assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+				assertGolden(t, tt.name, rec.Body.Bytes())
+			})
+		}
+	}
+}
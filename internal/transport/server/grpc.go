@@ -0,0 +1,147 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"golang-test-task/internal/service"
+	"golang-test-task/internal/storage/sqlc"
+	"golang-test-task/numberspb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// GRPCServer serves numbers.v1.NumbersService through the same service as Server,
+// for internal consumers that prefer gRPC. Failures are returned with the status code
+// of the domain error they wrap: Unavailable when the storage is, otherwise Internal.
+type GRPCServer struct {
+	numberspb.UnimplementedNumbersServiceServer
+	numbers numbersService
+}
+
+func NewGRPCServer(numbers *service.Numbers) *GRPCServer {
+	return &GRPCServer{numbers: numbersService{numbers}}
+}
+
+// NewGRPC returns a gRPC server with s registered, the standard grpc.health.v1
+// service for load balancers, reporting SERVING for the server as a whole ("") and
+// for NumbersService until it is shut down, and server reflection so grpcurl works
+// without the proto files. opts are passed to grpc.NewServer, such as the
+// interceptors of GRPCAPIKeys.
+func NewGRPC(s *GRPCServer, opts ...grpc.ServerOption) (*grpc.Server, *health.Server) {
+	srv := grpc.NewServer(opts...)
+	numberspb.RegisterNumbersServiceServer(srv, s)
+
+	h := health.NewServer()
+	h.SetServingStatus(numberspb.NumbersService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(srv, h)
+	reflection.Register(srv)
+
+	return srv, h
+}
+
+func (s *GRPCServer) AddNumber(ctx context.Context, req *numberspb.AddNumberRequest) (*numberspb.AddNumberResponse, error) {
+	resp, err := s.numbers.add(ctx, req)
+	if err != nil {
+		return nil, status.Error(errorCode(err), errorMessage(ctx, slog.Default(), err))
+	}
+
+	return resp, nil
+}
+
+func (s *GRPCServer) ListNumbers(ctx context.Context, _ *numberspb.ListNumbersRequest) (*numberspb.ListNumbersResponse, error) {
+	resp, err := s.numbers.list(ctx)
+	if err != nil {
+		return nil, status.Error(errorCode(err), errorMessage(ctx, slog.Default(), err))
+	}
+
+	return resp, nil
+}
+
+// StreamNumbers sends the numbers one message each, as they are read from the storage,
+// so a consumer can process a large list as it arrives instead of holding a single
+// huge response, and the server never holds the whole list either
+func (s *GRPCServer) StreamNumbers(_ *numberspb.StreamNumbersRequest, stream grpc.ServerStreamingServer[numberspb.Number]) error {
+	ctx := stream.Context()
+	err := s.numbers.stream(ctx, stream.Send)
+	var sendErr sendError
+	if errors.As(err, &sendErr) {
+		return sendErr.error
+	}
+	if err != nil {
+		return status.Error(errorCode(err), errorMessage(ctx, slog.Default(), err))
+	}
+
+	return nil
+}
+
+// numbersService converts between NumbersService messages and the service, for the
+// gRPC and Connect handlers
+type numbersService struct {
+	*service.Numbers
+}
+
+func (s numbersService) add(ctx context.Context, req *numberspb.AddNumberRequest) (*numberspb.AddNumberResponse, error) {
+	row, err := s.InsertRow(ctx, req.GetNumber())
+	if err != nil {
+		return nil, err
+	}
+
+	numbers, err := s.Values(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &numberspb.AddNumberResponse{Numbers: numbers}
+	if row.ID.Valid {
+		resp.Id = row.ID.String()
+	}
+
+	return resp, nil
+}
+
+// sendError is a failure to send a message of a stream, rather than of the storage
+type sendError struct{ error }
+
+// stream calls send with every number as it is read, returning its failures as
+// sendError
+func (s numbersService) stream(ctx context.Context, send func(*numberspb.Number) error) error {
+	return s.StreamRows(ctx, func(row sqlc.Number) error {
+		if err := send(numberMessage(row)); err != nil {
+			return sendError{err}
+		}
+		return nil
+	})
+}
+
+func (s numbersService) list(ctx context.Context) (*numberspb.ListNumbersResponse, error) {
+	numbers, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &numberspb.ListNumbersResponse{Numbers: make([]*numberspb.Number, len(numbers))}
+	for i, num := range numbers {
+		resp.Numbers[i] = numberMessage(num)
+	}
+
+	return resp, nil
+}
+
+func numberMessage(num sqlc.Number) *numberspb.Number {
+	msg := &numberspb.Number{Number: num.Number}
+	if num.ID.Valid {
+		msg.Id = num.ID.String()
+	}
+	if num.CreatedAt.Valid {
+		msg.CreatedAt = timestamppb.New(num.CreatedAt.Time)
+	}
+
+	return msg
+}
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"golang-test-task/internal/service"
+	"golang-test-task/internal/storage/sqlc"
+	"golang-test-task/numberspb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newGRPCClient serves a GRPCServer over queries in memory and returns a client of it
+func newGRPCClient(t *testing.T, queries sqlc.Querier) numberspb.NumbersServiceClient {
+	t.Helper()
+
+	ln := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	numberspb.RegisterNumbersServiceServer(srv, NewGRPCServer(service.New(queries)))
+	go srv.Serve(ln)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return ln.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return numberspb.NewNumbersServiceClient(conn)
+}
+
+// TestGRPCServer tests that the gRPC methods read and write the same storage
+func TestGRPCServer(t *testing.T) {
+	ctx := context.Background()
+	client := newGRPCClient(t, &fakeQuerier{})
+
+	for _, n := range []int64{3, 1} {
+		_, err := client.AddNumber(ctx, &numberspb.AddNumberRequest{Number: n})
+		require.NoError(t, err)
+	}
+	added, err := client.AddNumber(ctx, &numberspb.AddNumberRequest{Number: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2, 3}, added.GetNumbers())
+
+	list, err := client.ListNumbers(ctx, &numberspb.ListNumbersRequest{})
+	require.NoError(t, err)
+	require.Len(t, list.GetNumbers(), 3)
+	assert.Equal(t, int64(1), list.GetNumbers()[0].GetNumber())
+
+	stream, err := client.StreamNumbers(ctx, &numberspb.StreamNumbersRequest{})
+	require.NoError(t, err)
+	var streamed []int64
+	for {
+		num, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		streamed = append(streamed, num.GetNumber())
+	}
+	assert.Equal(t, []int64{1, 2, 3}, streamed)
+}
+
+// TestGRPCServer_StorageErrors tests that an unreachable storage surfaces as
+// Unavailable and other storage failures as Internal, without their details
+func TestGRPCServer_StorageErrors(t *testing.T) {
+	ctx := context.Background()
+	client := newGRPCClient(t, &fakeQuerier{insertErr: errRefused, listErr: errors.New("timeout")})
+
+	_, err := client.AddNumber(ctx, &numberspb.AddNumberRequest{Number: 1})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, "storage unavailable", status.Convert(err).Message())
+
+	_, err = client.ListNumbers(ctx, &numberspb.ListNumbersRequest{})
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Equal(t, "internal error", status.Convert(err).Message())
+
+	stream, err := client.StreamNumbers(ctx, &numberspb.StreamNumbersRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+// rowStreamer streams its rows and then fails with err, as a connection lost partway
+// through an export does
+type rowStreamer struct {
+	*fakeQuerier
+	err error
+}
+
+func (r rowStreamer) StreamRowsSorted(_ context.Context, yield func(row sqlc.Number) error) error {
+	for _, number := range r.numbers {
+		if err := yield(sqlc.Number{Number: number}); err != nil {
+			return err
+		}
+	}
+	return r.err
+}
+
+// TestGRPCServer_StreamsAsRead tests that StreamNumbers sends rows as the storage
+// yields them, so a failure partway ends a stream that has already sent some
+func TestGRPCServer_StreamsAsRead(t *testing.T) {
+	client := newGRPCClient(t, rowStreamer{fakeQuerier: &fakeQuerier{numbers: []int64{1, 2}}, err: errRefused})
+
+	stream, err := client.StreamNumbers(context.Background(), &numberspb.StreamNumbersRequest{})
+	require.NoError(t, err)
+	var streamed []int64
+	for {
+		num, err := stream.Recv()
+		if err != nil {
+			assert.Equal(t, codes.Unavailable, status.Code(err))
+			break
+		}
+		streamed = append(streamed, num.GetNumber())
+	}
+	assert.Equal(t, []int64{1, 2}, streamed)
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+
+	api "golang-test-task/api"
+	"golang-test-task/internal/transport/server/chiapi"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// NewHandler wraps the strict server into an http.Handler whose parameter
+// decoding and response errors are reported as ErrorResponse bodies instead of
+// the generated plain-text defaults. Responses are JSON, MessagePack, protobuf, XML
+// or JSON:API as the Accept header prefers, and request bodies may be MessagePack.
+// It also serves GET /healthz.
+//
+// middlewares wrap every operation at the level of typed requests and responses, the
+// first innermost, such as ValidateRequests, Authenticate and ObserveOperations. They
+// see the JSON response objects whatever the encoding, and OperationID in their
+// context.
+func NewHandler(s api.StrictServerInterface, middlewares ...api.StrictMiddlewareFunc) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", healthz)
+
+	return api.HandlerWithOptions(strictHandler(s, middlewares), api.StdHTTPServerOptions{
+		BaseRouter:       mux,
+		Middlewares:      []api.MiddlewareFunc{msgpackBodies, limitBodies, etags},
+		ErrorHandlerFunc: errorHandler(http.StatusBadRequest),
+	})
+}
+
+// NewChiHandler is NewHandler on a chi router, so chi middleware and route groups
+// can be used: the API routes and GET /healthz are added to r, which is returned.
+func NewChiHandler(s api.StrictServerInterface, r chi.Router, middlewares ...api.StrictMiddlewareFunc) http.Handler {
+	r.Get("/healthz", healthz)
+
+	return chiapi.HandlerWithOptions(strictHandler(s, middlewares), chiapi.ChiServerOptions{
+		BaseRouter:       r,
+		Middlewares:      []chiapi.MiddlewareFunc{msgpackBodies, limitBodies, etags},
+		ErrorHandlerFunc: errorHandler(http.StatusBadRequest),
+	})
+}
+
+// strictHandler adapts s to the generated router interface, which both routers share.
+// The generated handler makes the last middleware the outermost, so responses are
+// encoded after every middleware has run.
+func strictHandler(s api.StrictServerInterface, middlewares []api.StrictMiddlewareFunc) api.ServerInterface {
+	pipeline := append(slices.Clone(middlewares), envelopes, withOperationID, encodeResponses)
+
+	return api.NewStrictHandlerWithOptions(s, pipeline, api.StrictHTTPServerOptions{
+		RequestErrorHandlerFunc:  errorHandler(http.StatusBadRequest),
+		ResponseErrorHandlerFunc: responseErrorHandler,
+	})
+}
+
+func errorHandler(status int) func(w http.ResponseWriter, r *http.Request, err error) {
+	return func(w http.ResponseWriter, r *http.Request, err error) {
+		writeError(w, r, status, paramErrorMessage(err))
+	}
+}
+
+// paramErrorMessage is what clients are told of a request that failed to decode. A
+// number that does not fit in int64 is reported with the range it must be in,
+// rather than as the parser's error.
+func paramErrorMessage(err error) string {
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) && errors.Is(numErr, strconv.ErrRange) {
+		return fmt.Sprintf("number %s is out of range [%d, %d]", numErr.Num, math.MinInt64, math.MaxInt64)
+	}
+
+	return err.Error()
+}
+
+// writeError reports message as an ErrorResponse in the encoding r accepts
+func writeError(w http.ResponseWriter, r *http.Request, status int, message string) {
+	writeBody(w, responseType(r), status, api.ErrorResponse{Error: message})
+}
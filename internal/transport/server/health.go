@@ -0,0 +1,54 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+)
+
+// healthz reports that the process is up and serving requests
+func healthz(w http.ResponseWriter, r *http.Request) {
+	writeStatus(w, http.StatusOK, "ok")
+}
+
+// Readiness reports whether the instance should receive new traffic.
+// It is ready until Drain is called at the start of shutdown, as long as Check
+// passes.
+type Readiness struct {
+	// Check, when set, is called on every request, such as to ping the database; an
+	// error makes the instance unready until it passes again
+	Check func(ctx context.Context) error
+
+	draining atomic.Bool
+}
+
+// Drain makes readiness checks fail so load balancers stop routing to this instance
+func (r *Readiness) Drain() {
+	r.draining.Store(true)
+}
+
+// ServeHTTP answers 200 while ready, and 503 once draining or while Check fails
+func (r *Readiness) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if r.draining.Load() {
+		writeStatus(w, http.StatusServiceUnavailable, "draining")
+		return
+	}
+	if r.Check != nil {
+		if err := r.Check(req.Context()); err != nil {
+			slog.Warn("Readiness check failed", "error", err)
+			writeStatus(w, http.StatusServiceUnavailable, "unavailable")
+			return
+		}
+	}
+
+	writeStatus(w, http.StatusOK, "ready")
+}
+
+func writeStatus(w http.ResponseWriter, status int, value string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	json.NewEncoder(w).Encode(map[string]string{"status": value})
+}
@@ -0,0 +1,41 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestReadiness tests that readiness follows its check until draining, which no
+// check can undo
+func TestReadiness(t *testing.T) {
+	var checkErr error
+	readiness := &Readiness{Check: func(context.Context) error { return checkErr }}
+	ready := func() (int, string) {
+		rec := httptest.NewRecorder()
+		readiness.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		return rec.Code, rec.Body.String()
+	}
+
+	code, body := ready()
+	assert.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `{"status":"ready"}`, body)
+
+	checkErr = errors.New("database is down")
+	code, body = ready()
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.JSONEq(t, `{"status":"unavailable"}`, body)
+
+	checkErr = nil
+	code, _ = ready()
+	assert.Equal(t, http.StatusOK, code, "readiness recovers with the database")
+
+	readiness.Drain()
+	code, body = ready()
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.JSONEq(t, `{"status":"draining"}`, body)
+}
@@ -0,0 +1,311 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	api "golang-test-task/api"
+	"golang-test-task/internal/storage/sqlc"
+
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// jsonAPIVersion is the version of the JSON:API specification documents follow
+const jsonAPIVersion = "1.1"
+
+// jsonAPINumbers is the list of numbers as a JSON:API document, with every number a
+// resource of type numbers identified by its id
+type jsonAPINumbers struct {
+	rows []sqlc.Number
+	// added is the id of the number added, nil when the storage keeps no ids
+	added *openapi_types.UUID
+	// self is the URL the document was requested at
+	self string
+}
+
+// jsonAPIPage is a page of ListNumbers as a JSON:API document, linking to the next
+// page when there is one
+type jsonAPIPage struct {
+	rows []sqlc.Number
+	// next is the cursor of the next page, nil on the last
+	next *string
+	// self and nextLink are the URLs of the page and the next one
+	self, nextLink string
+}
+
+// jsonAPIBatch is the answer to a batch as a JSON:API document: the window of the
+// numbers it spans, with how many it added as meta
+type jsonAPIBatch struct {
+	window   jsonAPIPage
+	inserted int64
+	// min and max are the range of the window, which its links list
+	min, max int64
+}
+
+// jsonAPIWindow is the window AddNumber answers with as a JSON:API document, with the
+// position of the number added as meta
+type jsonAPIWindow struct {
+	rows     []sqlc.Number
+	position int64
+	added    *openapi_types.UUID
+	// self is the URL the document was requested at
+	self string
+}
+
+type jsonAPIDocument struct {
+	JSONAPI jsonAPIObject     `json:"jsonapi"`
+	Data    []jsonAPIResource `json:"data"`
+	Meta    jsonAPIMeta       `json:"meta"`
+	Links   jsonAPILinks      `json:"links"`
+}
+
+// jsonAPIPageDocument is a page of the list, which has no total: counting every
+// number would cost a page what the cursor saves
+type jsonAPIPageDocument struct {
+	JSONAPI jsonAPIObject     `json:"jsonapi"`
+	Data    []jsonAPIResource `json:"data"`
+	Links   jsonAPILinks      `json:"links"`
+}
+
+// jsonAPIBatchDocument answers a batch, linking to the window as GET /numbers lists it
+type jsonAPIBatchDocument struct {
+	JSONAPI jsonAPIObject     `json:"jsonapi"`
+	Data    []jsonAPIResource `json:"data"`
+	Meta    jsonAPIBatchMeta  `json:"meta"`
+	Links   jsonAPILinks      `json:"links"`
+}
+
+type jsonAPIBatchMeta struct {
+	Inserted int64 `json:"inserted"`
+}
+
+// jsonAPIWindowDocument answers an add made with the window response, which lists only
+// the numbers around the one added and so has no total
+type jsonAPIWindowDocument struct {
+	JSONAPI jsonAPIObject     `json:"jsonapi"`
+	Data    []jsonAPIResource `json:"data"`
+	Meta    jsonAPIWindowMeta `json:"meta"`
+	Links   jsonAPILinks      `json:"links"`
+}
+
+type jsonAPIWindowMeta struct {
+	Position int64               `json:"position"`
+	ID       *openapi_types.UUID `json:"id,omitempty"`
+}
+
+// jsonAPICountDocument answers an add made with the count response, which lists no
+// resources
+type jsonAPICountDocument struct {
+	JSONAPI jsonAPIObject    `json:"jsonapi"`
+	Meta    jsonAPICountMeta `json:"meta"`
+}
+
+// jsonAPIStatsDocument answers GET /numbers/stats, whose statistics describe no
+// resource and so are meta
+type jsonAPIStatsDocument struct {
+	JSONAPI jsonAPIObject   `json:"jsonapi"`
+	Meta    api.NumberStats `json:"meta"`
+}
+
+type jsonAPIErrorDocument struct {
+	JSONAPI jsonAPIObject  `json:"jsonapi"`
+	Errors  []jsonAPIError `json:"errors"`
+}
+
+type jsonAPIObject struct {
+	Version string `json:"version"`
+}
+
+type jsonAPIResource struct {
+	Type       string            `json:"type"`
+	ID         string            `json:"id"`
+	Attributes jsonAPIAttributes `json:"attributes"`
+}
+
+type jsonAPIAttributes struct {
+	Number    int64      `json:"number"`
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+}
+
+// jsonAPIMeta describes the page: the list is never split, so it holds every number.
+// ID is the number added, as among the resources.
+type jsonAPIMeta struct {
+	Total int                 `json:"total"`
+	ID    *openapi_types.UUID `json:"id,omitempty"`
+}
+
+// jsonAPICountMeta holds how many numbers are stored and where the one added stands
+type jsonAPICountMeta struct {
+	Total    int64               `json:"total"`
+	Position int64               `json:"position"`
+	ID       *openapi_types.UUID `json:"id,omitempty"`
+}
+
+type jsonAPILinks struct {
+	Self string `json:"self"`
+	Next string `json:"next,omitempty"`
+}
+
+type jsonAPIError struct {
+	Status string `json:"status"`
+	Title  string `json:"title"`
+	Detail string `json:"detail"`
+}
+
+// maxPooledResources is the longest resource slice kept for reuse, like maxPooledBuffer
+const maxPooledResources = 1 << 14
+
+// resourceSlices recycles the slices documents lay their resources out in before they
+// are encoded
+var resourceSlices = sync.Pool{
+	New: func() any { return new([]jsonAPIResource) },
+}
+
+func (n jsonAPINumbers) VisitAddNumberResponse(w http.ResponseWriter) error {
+	data := resourceSlices.Get().(*[]jsonAPIResource)
+	*data = slices.Grow((*data)[:0], len(n.rows))[:len(n.rows)]
+	defer func() {
+		if cap(*data) <= maxPooledResources {
+			// The creation times point into the rows, which are not kept alive
+			clear(*data)
+			resourceSlices.Put(data)
+		}
+	}()
+
+	doc := jsonAPIDocument{
+		JSONAPI: jsonAPIObject{Version: jsonAPIVersion},
+		Data:    *data,
+		Meta:    jsonAPIMeta{Total: len(n.rows), ID: n.added},
+		Links:   jsonAPILinks{Self: n.self},
+	}
+	for i, row := range n.rows {
+		doc.Data[i] = jsonAPIResourceOf(row)
+	}
+
+	return writeJSONAPIDocument(w, http.StatusOK, doc)
+}
+
+func (n jsonAPIWindow) VisitAddNumberResponse(w http.ResponseWriter) error {
+	doc := jsonAPIWindowDocument{
+		JSONAPI: jsonAPIObject{Version: jsonAPIVersion},
+		Data:    make([]jsonAPIResource, len(n.rows)),
+		Meta:    jsonAPIWindowMeta{Position: n.position, ID: n.added},
+		Links:   jsonAPILinks{Self: n.self},
+	}
+	for i, row := range n.rows {
+		doc.Data[i] = jsonAPIResourceOf(row)
+	}
+
+	return writeJSONAPIDocument(w, http.StatusOK, doc)
+}
+
+func (p jsonAPIPage) VisitListNumbersResponse(w http.ResponseWriter) error {
+	doc := jsonAPIPageDocument{
+		JSONAPI: jsonAPIObject{Version: jsonAPIVersion},
+		Data:    make([]jsonAPIResource, len(p.rows)),
+		Links:   jsonAPILinks{Self: p.self, Next: p.nextLink},
+	}
+	for i, row := range p.rows {
+		doc.Data[i] = jsonAPIResourceOf(row)
+	}
+
+	return writeJSONAPIDocument(w, http.StatusOK, doc)
+}
+
+func (b jsonAPIBatch) VisitAddNumberBatchResponse(w http.ResponseWriter) error {
+	doc := jsonAPIBatchDocument{
+		JSONAPI: jsonAPIObject{Version: jsonAPIVersion},
+		Data:    make([]jsonAPIResource, len(b.window.rows)),
+		Meta:    jsonAPIBatchMeta{Inserted: b.inserted},
+		Links:   jsonAPILinks{Self: b.window.self, Next: b.window.nextLink},
+	}
+	for i, row := range b.window.rows {
+		doc.Data[i] = jsonAPIResourceOf(row)
+	}
+
+	return writeJSONAPIDocument(w, http.StatusOK, doc)
+}
+
+// withRequestURL links the window from u, the URL the batch was posted to, as the
+// pages of GET /numbers with the range of the batch
+func (b jsonAPIBatch) withRequestURL(u *url.URL) jsonAPIBatch {
+	list := url.URL{
+		Path:     strings.TrimSuffix(u.Path, "/batch"),
+		RawQuery: url.Values{"min": {strconv.FormatInt(b.min, 10)}, "max": {strconv.FormatInt(b.max, 10)}}.Encode(),
+	}
+	b.window = b.window.withRequestURL(&list)
+
+	return b
+}
+
+// model is the page as the API model, without ids
+func (p jsonAPIPage) model() api.NumbersPage {
+	page := api.NumbersPage{Numbers: make(api.Numbers, len(p.rows)), NextCursor: p.next}
+	for i, row := range p.rows {
+		page.Numbers[i] = row.Number
+	}
+
+	return page
+}
+
+// withRequestURL links the page from u, the URL it was requested at
+func (p jsonAPIPage) withRequestURL(u *url.URL) jsonAPIPage {
+	p.self = u.RequestURI()
+	if p.next != nil {
+		next := *u
+		query := next.Query()
+		query.Set("after", *p.next)
+		next.RawQuery = query.Encode()
+		p.nextLink = next.RequestURI()
+	}
+
+	return p
+}
+
+// jsonAPIResourceOf is row as a resource of type numbers
+func jsonAPIResourceOf(row sqlc.Number) jsonAPIResource {
+	resource := jsonAPIResource{Type: "numbers", ID: row.ID.String(), Attributes: jsonAPIAttributes{Number: row.Number}}
+	if row.CreatedAt.Valid {
+		resource.Attributes.CreatedAt = &row.CreatedAt.Time
+	}
+
+	return resource
+}
+
+// writeJSONAPI encodes body, an API error, a count or statistics, as a JSON:API
+// document. Lists are only JSON:API documents when the handler returns them as
+// jsonAPINumbers or jsonAPIWindow, since the API models lack the ids resources need.
+func writeJSONAPI(w http.ResponseWriter, status int, body any) error {
+	switch resp := body.(type) {
+	case api.ErrorResponse:
+		return writeJSONAPIDocument(w, status, jsonAPIErrorDocument{
+			JSONAPI: jsonAPIObject{Version: jsonAPIVersion},
+			Errors:  []jsonAPIError{{Status: strconv.Itoa(status), Title: http.StatusText(status), Detail: resp.Error}},
+		})
+	case api.NumberStats:
+		return writeJSONAPIDocument(w, status, jsonAPIStatsDocument{JSONAPI: jsonAPIObject{Version: jsonAPIVersion}, Meta: resp})
+	case api.CreateNumberResponse:
+		if resp.Numbers == nil && resp.Count != nil && resp.Position != nil {
+			return writeJSONAPIDocument(w, status, jsonAPICountDocument{
+				JSONAPI: jsonAPIObject{Version: jsonAPIVersion},
+				Meta:    jsonAPICountMeta{Total: *resp.Count, Position: *resp.Position, ID: resp.Id},
+			})
+		}
+	}
+
+	return fmt.Errorf("no JSON:API document for %T", body)
+}
+
+func writeJSONAPIDocument(w http.ResponseWriter, status int, doc any) error {
+	w.Header().Set("Content-Type", jsonAPIType)
+	w.WriteHeader(status)
+
+	return json.NewEncoder(w).Encode(doc)
+}
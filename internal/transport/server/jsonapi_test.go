@@ -0,0 +1,79 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang-test-task/internal/service"
+	"golang-test-task/internal/storage/memstore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestJSONAPI_Numbers tests that the list is a JSON:API document of number resources
+// identified by their ids, on every router
+func TestJSONAPI_Numbers(t *testing.T) {
+	for router, newHandler := range handlerRouters {
+		t.Run(router, func(t *testing.T) {
+			store := memstore.New()
+			_, err := store.InsertNumber(t.Context(), 7)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "/numbers?number=-1&full=true", nil)
+			req.Header.Set("Accept", "application/vnd.api+json")
+			rec := httptest.NewRecorder()
+			newHandler(NewServer(service.New(store))).ServeHTTP(rec, req)
+
+			require.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, "application/vnd.api+json", rec.Header().Get("Content-Type"))
+
+			var doc struct {
+				JSONAPI struct{ Version string }
+				Data    []struct {
+					Type       string
+					ID         string
+					Attributes map[string]any
+				}
+				Meta  struct{ Total int }
+				Links struct{ Self string }
+			}
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&doc))
+			assert.Equal(t, "1.1", doc.JSONAPI.Version)
+			assert.Equal(t, 2, doc.Meta.Total)
+			assert.Equal(t, "/numbers?number=-1&full=true", doc.Links.Self)
+			require.Len(t, doc.Data, 2)
+			for i, want := range []float64{-1, 7} {
+				assert.Equal(t, "numbers", doc.Data[i].Type)
+				assert.Len(t, doc.Data[i].ID, 36)
+				assert.Equal(t, want, doc.Data[i].Attributes["number"])
+				assert.NotEmpty(t, doc.Data[i].Attributes["createdAt"])
+			}
+			assert.NotEqual(t, doc.Data[0].ID, doc.Data[1].ID)
+		})
+	}
+}
+
+// TestJSONAPI_Errors tests that errors are JSON:API error objects
+func TestJSONAPI_Errors(t *testing.T) {
+	for _, query := range []string{"", "?number=9223372036854775808"} {
+		req := httptest.NewRequest(http.MethodPost, "/numbers"+query, nil)
+		req.Header.Set("Accept", "application/vnd.api+json")
+		rec := httptest.NewRecorder()
+		NewHandler(NewServer(service.New(&fakeQuerier{}))).ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code, query)
+		assert.Equal(t, "application/vnd.api+json", rec.Header().Get("Content-Type"), query)
+
+		var doc struct {
+			Errors []struct{ Status, Title, Detail string }
+		}
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&doc), query)
+		require.Len(t, doc.Errors, 1, query)
+		assert.Equal(t, "400", doc.Errors[0].Status, query)
+		assert.Equal(t, "Bad Request", doc.Errors[0].Title, query)
+		assert.NotEmpty(t, doc.Errors[0].Detail, query)
+	}
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	api "golang-test-task/api"
+	"golang-test-task/internal/service"
+	"golang-test-task/internal/storage/sqlc"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	// defaultPageLimit is how many numbers a page holds unless the request says
+	defaultPageLimit = 100
+	// maxPageLimit is the most numbers a request can ask a page to hold
+	maxPageLimit = 1000
+)
+
+// ListNumbers answers with a page of the stored numbers in order. Equal numbers are
+// ordered by id, so a page's cursor is the last row it holds and the next page is
+// every row after it, however many numbers are added in between.
+func (s *Server) ListNumbers(ctx context.Context, request api.ListNumbersRequestObject) (api.ListNumbersResponseObject, error) {
+	params := sqlc.ListNumbersPageParams{MinNumber: math.MinInt64, MaxNumber: math.MaxInt64}
+	if request.Params.Min != nil {
+		params.MinNumber = *request.Params.Min
+	}
+	if request.Params.Max != nil {
+		params.MaxNumber = *request.Params.Max
+	}
+	if params.MinNumber > params.MaxNumber {
+		return api.ListNumbers400JSONResponse{Error: fmt.Sprintf("min must not be greater than max, got %d and %d", params.MinNumber, params.MaxNumber)}, nil
+	}
+
+	// The generated handlers do not check bounds either
+	limit := int32(defaultPageLimit)
+	if request.Params.Limit != nil {
+		limit = *request.Params.Limit
+	}
+	if limit < 1 || limit > maxPageLimit {
+		return api.ListNumbers400JSONResponse{Error: fmt.Sprintf("limit must be between 1 and %d, got %d", maxPageLimit, limit)}, nil
+	}
+	// One row more than the page holds tells whether there is a next page
+	params.PageLimit = limit + 1
+
+	if request.Params.After != nil {
+		number, id, ok := service.DecodeCursor(*request.Params.After)
+		if !ok {
+			return api.ListNumbers400JSONResponse{Error: fmt.Sprintf("after must be the next_cursor of a page, got %q", *request.Params.After)}, nil
+		}
+		params.AfterNumber = pgtype.Int8{Int64: number, Valid: true}
+		params.AfterID = pgtype.UUID{Bytes: id, Valid: true}
+	}
+
+	page, err := s.page(ctx, params)
+	if err != nil {
+		return s.listError(ctx, err), nil
+	}
+
+	if responseTypeOf(ctx) == jsonAPIType {
+		// Resources need the ids of the numbers, as they do for AddNumber
+		return page, nil
+	}
+
+	return api.ListNumbers200JSONResponse(page.model()), nil
+}
+
+// page lists the page params selects. params.PageLimit is one more than the page
+// holds, which tells whether there is a next page.
+func (s *Server) page(ctx context.Context, params sqlc.ListNumbersPageParams) (jsonAPIPage, error) {
+	rows, err := s.numbers.Page(ctx, params)
+	if err != nil {
+		return jsonAPIPage{}, err
+	}
+
+	page := jsonAPIPage{rows: rows}
+	if limit := int(params.PageLimit) - 1; len(rows) > limit {
+		page.rows = rows[:limit]
+		cursor := service.EncodeCursor(page.rows[limit-1])
+		page.next = &cursor
+	}
+
+	return page, nil
+}
+
+// listError is the response to a failure of the service to list numbers
+func (s *Server) listError(ctx context.Context, err error) api.ListNumbersResponseObject {
+	body := api.ErrorResponse{Error: s.errorMessage(ctx, err)}
+	if errors.Is(err, service.ErrStorageUnavailable) {
+		return api.ListNumbers503JSONResponse(body)
+	}
+
+	return api.ListNumbers500JSONResponse(body)
+}
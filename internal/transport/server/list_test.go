@@ -0,0 +1,184 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	api "golang-test-task/api"
+	"golang-test-task/internal/service"
+	"golang-test-task/internal/storage/memstore"
+	"golang-test-task/numberspb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// listPage gets a page over HTTP and decodes it as JSON
+func listPage(t *testing.T, handler http.Handler, query url.Values) api.NumbersPage {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/numbers?"+query.Encode(), nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var page api.NumbersPage
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&page))
+	return page
+}
+
+// TestServer_ListNumbers_Pages tests that following the cursors lists every number in
+// the range once, duplicates and numbers added between pages included, on every router
+func TestServer_ListNumbers_Pages(t *testing.T) {
+	for router, newHandler := range handlerRouters {
+		t.Run(router, func(t *testing.T) {
+			store := memstore.New()
+			for _, number := range []int64{5, 3, 3, 3, 9, -2, 3, 100, 7} {
+				_, err := store.InsertNumber(t.Context(), number)
+				require.NoError(t, err)
+			}
+			handler := newHandler(NewServer(service.New(store)))
+
+			query := url.Values{"limit": {"2"}, "min": {"0"}, "max": {"9"}}
+			var listed []int64
+			for pages := 0; ; pages++ {
+				require.Less(t, pages, 10, "the cursors never reach the last page")
+				page := listPage(t, handler, query)
+				assert.LessOrEqual(t, len(page.Numbers), 2)
+				listed = append(listed, page.Numbers...)
+				if page.NextCursor == nil {
+					break
+				}
+				if pages == 0 {
+					// Neither a number before the cursor nor one after it is missed
+					_, err := store.InsertNumber(t.Context(), 1)
+					require.NoError(t, err)
+					_, err = store.InsertNumber(t.Context(), 8)
+					require.NoError(t, err)
+				}
+				query.Set("after", *page.NextCursor)
+			}
+
+			assert.Equal(t, []int64{3, 3, 3, 3, 5, 7, 8, 9}, listed)
+		})
+	}
+}
+
+// TestServer_ListNumbers_Defaults tests that a request without parameters lists the
+// first hundred numbers of the whole range
+func TestServer_ListNumbers_Defaults(t *testing.T) {
+	store := memstore.New()
+	for i := range 150 {
+		_, err := store.InsertNumber(t.Context(), int64(149-i))
+		require.NoError(t, err)
+	}
+
+	page := listPage(t, NewHandler(NewServer(service.New(store))), nil)
+	require.Len(t, page.Numbers, defaultPageLimit)
+	assert.Equal(t, int64(0), page.Numbers[0])
+	assert.Equal(t, int64(99), page.Numbers[99])
+	assert.NotNil(t, page.NextCursor)
+
+	empty := listPage(t, NewHandler(NewServer(service.New(memstore.New()))), nil)
+	assert.Equal(t, api.NumbersPage{Numbers: api.Numbers{}}, empty, "an empty table is an empty list, not null")
+}
+
+// TestServer_ListNumbers_BadRequests tests that parameters the spec allows but the
+// service cannot serve are rejected with messages naming them
+func TestServer_ListNumbers_BadRequests(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		wantError string
+	}{
+		{name: "limit too small", query: "?limit=0", wantError: "limit must be between 1 and 1000, got 0"},
+		{name: "limit too large", query: "?limit=1001", wantError: "limit must be between 1 and 1000, got 1001"},
+		{name: "empty range", query: "?min=5&max=4", wantError: "min must not be greater than max, got 5 and 4"},
+		{name: "cursor not base64", query: "?after=%21%21", wantError: `after must be the next_cursor of a page, got "!!"`},
+		{name: "cursor too short", query: "?after=AAAA", wantError: `after must be the next_cursor of a page, got "AAAA"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/numbers"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			NewHandler(NewServer(service.New(memstore.New()))).ServeHTTP(rec, req)
+
+			require.Equal(t, http.StatusBadRequest, rec.Code)
+			var body api.ErrorResponse
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+			assert.Equal(t, tt.wantError, body.Error)
+		})
+	}
+}
+
+// TestServer_ListNumbers_StorageErrors tests that storage failures are reported like
+// those of AddNumber
+func TestServer_ListNumbers_StorageErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "unavailable", err: service.ErrStorageUnavailable, wantStatus: http.StatusServiceUnavailable},
+		{name: "unexpected", err: errors.New("boom"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/numbers", nil)
+			rec := httptest.NewRecorder()
+			NewHandler(NewServer(service.New(&fakeQuerier{listErr: tt.err}))).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
+
+// TestServer_ListNumbers_Encodings tests that pages are negotiated like the list AddNumber
+// answers with, and that JSON:API pages link to the next one
+func TestServer_ListNumbers_Encodings(t *testing.T) {
+	store := memstore.New()
+	for _, number := range []int64{2, 1, 3} {
+		_, err := store.InsertNumber(t.Context(), number)
+		require.NoError(t, err)
+	}
+	handler := NewHandler(NewServer(service.New(store)))
+	get := func(accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/numbers?limit=2&min=1", nil)
+		req.Header.Set("Accept", accept)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		return rec
+	}
+
+	var page numberspb.NumbersPage
+	require.NoError(t, proto.Unmarshal(get(protobufType).Body.Bytes(), &page))
+	assert.Equal(t, []int64{1, 2}, page.Numbers)
+	require.NotEmpty(t, page.NextCursor)
+
+	assert.Contains(t, get(xmlType).Body.String(), "<numbers><number>1</number><number>2</number></numbers>")
+
+	var doc struct {
+		Data []struct {
+			ID         string
+			Attributes struct{ Number int64 }
+		}
+		Links struct{ Self, Next string }
+	}
+	require.NoError(t, json.NewDecoder(get(jsonAPIType).Body).Decode(&doc))
+	require.Len(t, doc.Data, 2)
+	assert.Equal(t, int64(2), doc.Data[1].Attributes.Number)
+	assert.Equal(t, "/numbers?limit=2&min=1", doc.Links.Self)
+	assert.Equal(t, "/numbers?after="+page.NextCursor+"&limit=2&min=1", doc.Links.Next)
+
+	rows, err := store.GetAllNumbersSorted(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, service.EncodeCursor(rows[1]), page.NextCursor, "every encoding has the same cursor")
+}
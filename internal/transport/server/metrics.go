@@ -0,0 +1,65 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// HTTPMetrics counts and times the requests of a handler for Prometheus, by method,
+// operation and status. The operation is the id in openapi.yaml of the one the
+// request was routed to, or other for the routes outside the OpenAPI handler, which
+// keeps the number of series bounded whatever paths clients ask for.
+type HTTPMetrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+// NewHTTPMetrics registers the metrics with reg
+func NewHTTPMetrics(reg prometheus.Registerer) *HTTPMetrics {
+	m := &HTTPMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "HTTP requests answered, by method, operation and status code.",
+		}, []string{"method", "operation", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Time taken to answer HTTP requests, by method and operation.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "operation"}),
+	}
+	reg.MustRegister(m.requests, m.duration)
+
+	return m
+}
+
+// Wrap returns next observed by the metrics
+func (m *HTTPMetrics) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		r, info := withRequestInfo(r)
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+
+		method, operation := metricMethod(r.Method), info.operationID
+		if operation == "" {
+			operation = "other"
+		}
+		m.requests.WithLabelValues(method, operation, strconv.Itoa(rec.statusCode())).Inc()
+		m.duration.WithLabelValues(method, operation).Observe(time.Since(start).Seconds())
+	})
+}
+
+// metricMethod is the method label of a request: the method itself when it is one of
+// the standard ones, so that clients cannot add series by making methods up
+func metricMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	}
+
+	return "OTHER"
+}
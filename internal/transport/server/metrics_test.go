@@ -0,0 +1,37 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang-test-task/internal/service"
+	"golang-test-task/internal/storage/memstore"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestHTTPMetrics tests that requests are counted and timed by operation, with the
+// routes outside openapi.yaml and unknown methods folded into one series each
+func TestHTTPMetrics(t *testing.T) {
+	metrics := NewHTTPMetrics(prometheus.NewRegistry())
+	handler := metrics.Wrap(NewHandler(NewServer(service.New(memstore.New()))))
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodPost, "/numbers?number=1", nil),
+		httptest.NewRequest(http.MethodPost, "/numbers?number=2", nil),
+		httptest.NewRequest(http.MethodPost, "/numbers?number=x", nil),
+		httptest.NewRequest(http.MethodGet, "/healthz", nil),
+		httptest.NewRequest("BREW", "/coffee", nil),
+	} {
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.requests.WithLabelValues("POST", "AddNumber", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.requests.WithLabelValues("POST", "other", "400")),
+		"a request that fails to decode never reaches the operation")
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.requests.WithLabelValues("GET", "other", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.requests.WithLabelValues("OTHER", "other", "404")))
+	assert.Equal(t, 4, testutil.CollectAndCount(metrics.duration))
+}
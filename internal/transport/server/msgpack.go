@@ -0,0 +1,56 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// writeMsgpack encodes v with the field names of its JSON encoding and integers in
+// as few bytes as they fit
+func writeMsgpack(w http.ResponseWriter, status int, v any) error {
+	w.Header().Set("Content-Type", msgpackType)
+	w.WriteHeader(status)
+
+	enc := msgpack.GetEncoder()
+	defer msgpack.PutEncoder(enc)
+	enc.Reset(w)
+	enc.SetCustomStructTag("json")
+	enc.UseCompactInts(true)
+
+	return enc.Encode(v)
+}
+
+// msgpackBodies lets clients send request bodies as MessagePack: next gets the
+// equivalent JSON, which the generated handlers decode
+func msgpackBodies(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if mediaType != msgpackType {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var body any
+		var data []byte
+		err := msgpack.NewDecoder(r.Body).Decode(&body)
+		if err == nil {
+			data, err = json.Marshal(body)
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			writeError(w, r, http.StatusBadRequest, "invalid MessagePack body: "+err.Error())
+			return
+		}
+
+		r = r.Clone(r.Context())
+		r.Header.Set("Content-Type", jsonType)
+		r.Body = io.NopCloser(bytes.NewReader(data))
+		r.ContentLength = int64(len(data))
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	api "golang-test-task/api"
+	"golang-test-task/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// decodeMsgpack decodes a response body the way a client keyed by the JSON field names would
+func decodeMsgpack(t *testing.T, body io.Reader, v any) {
+	t.Helper()
+
+	dec := msgpack.NewDecoder(body)
+	dec.SetCustomStructTag("json")
+	require.NoError(t, dec.Decode(v))
+}
+
+// TestMsgpack_Responses tests that successes and errors are MessagePack encoded
+// when the client prefers it, on every router
+func TestMsgpack_Responses(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		queries    *fakeQuerier
+		wantStatus int
+		wantBody   []int64
+	}{
+		{name: "ok", query: "?number=5&full=true", queries: &fakeQuerier{numbers: []int64{300, -70000}}, wantStatus: http.StatusOK, wantBody: []int64{-70000, 5, 300}},
+		{name: "out of range", query: "?number=9223372036854775808", queries: &fakeQuerier{}, wantStatus: http.StatusBadRequest},
+		{name: "missing param", query: "", queries: &fakeQuerier{}, wantStatus: http.StatusBadRequest},
+		{name: "insert error", query: "?number=1", queries: &fakeQuerier{insertErr: errors.New("connection refused")}, wantStatus: http.StatusInternalServerError},
+	}
+
+	for router, newHandler := range handlerRouters {
+		for _, tt := range tests {
+			t.Run(router+"/"+tt.name, func(t *testing.T) {
+				queries := *tt.queries
+				req := httptest.NewRequest(http.MethodPost, "/numbers"+tt.query, nil)
+				req.Header.Set("Accept", "application/msgpack, application/json;q=0.5")
+
+				rec := httptest.NewRecorder()
+				newHandler(NewServer(service.New(&queries))).ServeHTTP(rec, req)
+
+				require.Equal(t, tt.wantStatus, rec.Code)
+				assert.Equal(t, "application/msgpack", rec.Header().Get("Content-Type"))
+				if tt.wantBody == nil {
+					var body api.ErrorResponse
+					decodeMsgpack(t, rec.Body, &body)
+					assert.NotEmpty(t, body.Error)
+					return
+				}
+
+				assert.Contains(t, rec.Header().Values("Vary"), "Accept")
+				var body api.CreateNumberResponse
+				decodeMsgpack(t, rec.Body, &body)
+				require.NotNil(t, body.Numbers)
+				assert.Equal(t, tt.wantBody, *body.Numbers)
+			})
+		}
+	}
+}
+
+// TestMsgpack_JSONPreferred tests that JSON stays the default
+func TestMsgpack_JSONPreferred(t *testing.T) {
+	for _, accept := range []string{"", "*/*", "application/json", "application/json, application/msgpack;q=0.9", "application/msgpack;q=0"} {
+		req := httptest.NewRequest(http.MethodPost, "/numbers?number=5&full=true", nil)
+		req.Header.Set("Accept", accept)
+
+		rec := httptest.NewRecorder()
+		NewHandler(NewServer(service.New(&fakeQuerier{}))).ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code, accept)
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"), accept)
+		assert.JSONEq(t, `{"numbers":[5]}`, rec.Body.String(), accept)
+	}
+}
+
+// TestMsgpack_Bodies tests that MessagePack request bodies reach the handlers as JSON
+func TestMsgpack_Bodies(t *testing.T) {
+	var gotType, gotBody string
+	handler := msgpackBodies(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotType, gotBody = r.Header.Get("Content-Type"), string(body)
+	}))
+
+	body, err := msgpack.Marshal(map[string]any{"numbers": []int{1, -2, 300}})
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/numbers", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/msgpack")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	assert.Equal(t, "application/json", gotType)
+	assert.JSONEq(t, `{"numbers":[1,-2,300]}`, gotBody)
+
+	req = httptest.NewRequest(http.MethodPost, "/numbers", bytes.NewReader([]byte{0xc1}))
+	req.Header.Set("Content-Type", "application/msgpack")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "invalid MessagePack body")
+}
@@ -0,0 +1,224 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	api "golang-test-task/api"
+)
+
+const (
+	jsonType     = "application/json"
+	msgpackType  = "application/msgpack"
+	protobufType = "application/x-protobuf"
+	xmlType      = "application/xml"
+	jsonAPIType  = "application/vnd.api+json"
+)
+
+// responseTypes are the encodings responses can be negotiated into, JSON first as the default
+var responseTypes = []string{jsonType, msgpackType, protobufType, xmlType, jsonAPIType}
+
+// responseType is the encoding of the responses to r
+func responseType(r *http.Request) string {
+	return negotiate(r.Header.Get("Accept"), responseTypes...)
+}
+
+type responseTypeKey struct{}
+
+// responseTypeOf is the encoding negotiated for the response to the request of ctx,
+// for handlers that return a different response object for it
+func responseTypeOf(ctx context.Context) string {
+	contentType, _ := ctx.Value(responseTypeKey{}).(string)
+	return contentType
+}
+
+// encodeResponses answers requests whose Accept header prefers another encoding to
+// JSON with the same bodies in that encoding. The response objects are encoded
+// directly, so large number lists are never rendered as JSON first.
+func encodeResponses(f api.StrictHandlerFunc, operationID string) api.StrictHandlerFunc {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request, request any) (any, error) {
+		w.Header().Add("Vary", "Accept")
+
+		contentType := responseType(r)
+		response, err := f(context.WithValue(ctx, responseTypeKey{}, contentType), w, r, request)
+		if err != nil || contentType == jsonType {
+			return response, err
+		}
+
+		switch resp := response.(type) {
+		case jsonAPINumbers:
+			resp.self = r.URL.RequestURI()
+			return resp, nil
+		case jsonAPIWindow:
+			resp.self = r.URL.RequestURI()
+			return resp, nil
+		case api.AddNumber200JSONResponse:
+			return encodedResponse{contentType, http.StatusOK, api.CreateNumberResponse(resp)}, nil
+		case api.AddNumber400JSONResponse:
+			return encodedResponse{contentType, http.StatusBadRequest, api.ErrorResponse(resp)}, nil
+		case api.AddNumber500JSONResponse:
+			return encodedResponse{contentType, http.StatusInternalServerError, api.ErrorResponse(resp)}, nil
+		case api.AddNumber503JSONResponse:
+			return encodedResponse{contentType, http.StatusServiceUnavailable, api.ErrorResponse(resp)}, nil
+		case jsonAPIBatch:
+			return resp.withRequestURL(r.URL), nil
+		case api.AddNumberBatch200JSONResponse:
+			return encodedResponse{contentType, http.StatusOK, api.BatchResult(resp)}, nil
+		case api.AddNumberBatch400JSONResponse:
+			return encodedResponse{contentType, http.StatusBadRequest, api.ErrorResponse(resp)}, nil
+		case api.AddNumberBatch500JSONResponse:
+			return encodedResponse{contentType, http.StatusInternalServerError, api.ErrorResponse(resp)}, nil
+		case api.AddNumberBatch503JSONResponse:
+			return encodedResponse{contentType, http.StatusServiceUnavailable, api.ErrorResponse(resp)}, nil
+		case jsonAPIPage:
+			return resp.withRequestURL(r.URL), nil
+		case api.ListNumbers200JSONResponse:
+			return encodedResponse{contentType, http.StatusOK, api.NumbersPage(resp)}, nil
+		case api.ListNumbers400JSONResponse:
+			return encodedResponse{contentType, http.StatusBadRequest, api.ErrorResponse(resp)}, nil
+		case api.ListNumbers500JSONResponse:
+			return encodedResponse{contentType, http.StatusInternalServerError, api.ErrorResponse(resp)}, nil
+		case api.ListNumbers503JSONResponse:
+			return encodedResponse{contentType, http.StatusServiceUnavailable, api.ErrorResponse(resp)}, nil
+		case api.GetNumberStats200JSONResponse:
+			return encodedResponse{contentType, http.StatusOK, api.NumberStats(resp)}, nil
+		case api.GetNumberStats400JSONResponse:
+			return encodedResponse{contentType, http.StatusBadRequest, api.ErrorResponse(resp)}, nil
+		case api.GetNumberStats500JSONResponse:
+			return encodedResponse{contentType, http.StatusInternalServerError, api.ErrorResponse(resp)}, nil
+		case api.GetNumberStats503JSONResponse:
+			return encodedResponse{contentType, http.StatusServiceUnavailable, api.ErrorResponse(resp)}, nil
+		case api.StreamNumbers500JSONResponse:
+			return encodedResponse{contentType, http.StatusInternalServerError, api.ErrorResponse(resp)}, nil
+		case api.StreamNumbers503JSONResponse:
+			return encodedResponse{contentType, http.StatusServiceUnavailable, api.ErrorResponse(resp)}, nil
+		case api.DeleteNumber404JSONResponse:
+			return encodedResponse{contentType, http.StatusNotFound, api.ErrorResponse(resp)}, nil
+		case api.DeleteNumber500JSONResponse:
+			return encodedResponse{contentType, http.StatusInternalServerError, api.ErrorResponse(resp)}, nil
+		case api.DeleteNumber503JSONResponse:
+			return encodedResponse{contentType, http.StatusServiceUnavailable, api.ErrorResponse(resp)}, nil
+		case api.ClearNumbers403JSONResponse:
+			return encodedResponse{contentType, http.StatusForbidden, api.ErrorResponse(resp)}, nil
+		case api.ClearNumbers500JSONResponse:
+			return encodedResponse{contentType, http.StatusInternalServerError, api.ErrorResponse(resp)}, nil
+		case api.ClearNumbers503JSONResponse:
+			return encodedResponse{contentType, http.StatusServiceUnavailable, api.ErrorResponse(resp)}, nil
+		}
+
+		return response, nil
+	}
+}
+
+// encodedResponse is a response body to be sent as contentType
+type encodedResponse struct {
+	contentType string
+	status      int
+	body        any
+}
+
+func (e encodedResponse) VisitAddNumberResponse(w http.ResponseWriter) error {
+	return writeBody(w, e.contentType, e.status, e.body)
+}
+
+func (e encodedResponse) VisitListNumbersResponse(w http.ResponseWriter) error {
+	return writeBody(w, e.contentType, e.status, e.body)
+}
+
+func (e encodedResponse) VisitAddNumberBatchResponse(w http.ResponseWriter) error {
+	return writeBody(w, e.contentType, e.status, e.body)
+}
+
+func (e encodedResponse) VisitGetNumberStatsResponse(w http.ResponseWriter) error {
+	return writeBody(w, e.contentType, e.status, e.body)
+}
+
+func (e encodedResponse) VisitStreamNumbersResponse(w http.ResponseWriter) error {
+	return writeBody(w, e.contentType, e.status, e.body)
+}
+
+func (e encodedResponse) VisitDeleteNumberResponse(w http.ResponseWriter) error {
+	return writeBody(w, e.contentType, e.status, e.body)
+}
+
+func (e encodedResponse) VisitClearNumbersResponse(w http.ResponseWriter) error {
+	return writeBody(w, e.contentType, e.status, e.body)
+}
+
+// writeBody encodes body, an API model, as contentType
+func writeBody(w http.ResponseWriter, contentType string, status int, body any) error {
+	switch contentType {
+	case msgpackType:
+		return writeMsgpack(w, status, body)
+	case protobufType:
+		return writeProtobuf(w, status, body)
+	case xmlType:
+		return writeXML(w, status, body)
+	case jsonAPIType:
+		return writeJSONAPI(w, status, body)
+	}
+
+	w.Header().Set("Content-Type", jsonType)
+	w.WriteHeader(status)
+
+	return json.NewEncoder(w).Encode(body)
+}
+
+// negotiate returns the one of offers the Accept header prefers: the highest
+// quality wins, then the most specific media range, then the earliest offer. With
+// no Accept header, or one accepting none of offers, it returns the first offer.
+func negotiate(accept string, offers ...string) string {
+	best, bestQ, bestSpecificity := offers[0], 0.0, -1
+	for _, offer := range offers {
+		q, specificity := quality(accept, offer)
+		if q > bestQ || (q == bestQ && q > 0 && specificity > bestSpecificity) {
+			best, bestQ, bestSpecificity = offer, q, specificity
+		}
+	}
+
+	return best
+}
+
+// quality returns the q value accept gives offer and how specific the media range
+// it comes from is: 2 for an exact type, 1 for type/*, 0 for */*
+func quality(accept, offer string) (float64, int) {
+	if strings.TrimSpace(accept) == "" {
+		return 1, 0
+	}
+	offerType, _, _ := strings.Cut(offer, "/")
+
+	q, specificity := 0.0, -1
+	for _, part := range strings.Split(accept, ",") {
+		mediaRange, params, err := mime.ParseMediaType(part)
+		if err != nil {
+			continue
+		}
+
+		s := -1
+		switch {
+		case mediaRange == offer:
+			s = 2
+		case mediaRange == offerType+"/*":
+			s = 1
+		case mediaRange == "*/*":
+			s = 0
+		}
+		if s <= specificity {
+			continue
+		}
+
+		rangeQ := 1.0
+		if v, ok := params["q"]; ok {
+			if rangeQ, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		q, specificity = rangeQ, s
+	}
+
+	return q, specificity
+}
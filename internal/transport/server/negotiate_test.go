@@ -0,0 +1,36 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{accept: "", want: jsonType},
+		{accept: "*/*", want: jsonType},
+		{accept: "application/msgpack", want: msgpackType},
+		{accept: "application/msgpack, */*", want: msgpackType},
+		{accept: "application/json, application/msgpack", want: jsonType},
+		{accept: "application/json;q=0.5, application/msgpack", want: msgpackType},
+		{accept: "application/*;q=0.2, application/msgpack;q=0.1", want: jsonType},
+		{accept: "text/html", want: jsonType},
+		{accept: "application/msgpack;q=0", want: jsonType},
+		{accept: "application/msgpack;q=bad, application/json", want: jsonType},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, negotiate(tt.accept, jsonType, msgpackType), tt.accept)
+	}
+}
+
+func TestNegotiate_Protobuf(t *testing.T) {
+	assert.Equal(t, protobufType, negotiate("application/x-protobuf", responseTypes...))
+	assert.Equal(t, protobufType, negotiate("application/x-protobuf, application/msgpack;q=0.8", responseTypes...))
+	assert.Equal(t, msgpackType, negotiate("application/msgpack, application/x-protobuf", responseTypes...))
+	assert.Equal(t, jsonType, negotiate("application/*", responseTypes...))
+}
@@ -0,0 +1,132 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	api "golang-test-task/api"
+	"golang-test-task/internal/storage/feed"
+)
+
+// Option customizes a Server
+type Option func(*Server)
+
+// ResponseMode sets how much of a failure a Server tells its clients
+type ResponseMode int
+
+const (
+	// ResponseSafe tells clients only the domain error a failure amounts to, or
+	// "internal error". It is the default.
+	ResponseSafe ResponseMode = iota
+	// ResponseVerbose tells clients the whole error, storage details included. It is
+	// meant for development and tests, never for a server others can reach.
+	ResponseVerbose
+)
+
+// WriteResponse is what AddNumber answers with once the number is stored. Requests can
+// choose with the response query parameter.
+type WriteResponse string
+
+const (
+	// WriteWindow answers with where the number stands and the stored numbers next to
+	// it, reading no more of the list than that. It is the default.
+	WriteWindow WriteResponse = WriteResponse(api.AddNumberParamsResponseWindow)
+	// WriteFull answers with every stored number, which takes as long as the list is
+	// long
+	WriteFull WriteResponse = WriteResponse(api.AddNumberParamsResponseFull)
+	// WriteCount answers with how many numbers are stored and how many are smaller than
+	// the one added, without reading the list
+	WriteCount WriteResponse = WriteResponse(api.AddNumberParamsResponseCount)
+	// WriteNone answers 204 with no body
+	WriteNone WriteResponse = WriteResponse(api.AddNumberParamsResponseNone)
+)
+
+// Validator checks a number to be added. Its error is returned to the client as a
+// 400.
+type Validator func(number int64) error
+
+// Hooks are called around every number added, for metrics or auditing. Either may be
+// nil.
+type Hooks struct {
+	// BeforeAdd is called with a number that passed validation, before it is stored
+	BeforeAdd func(ctx context.Context, number int64)
+	// AfterAdd is called once number is stored, or failed to be, with how long that
+	// took by the server's clock
+	AfterAdd func(ctx context.Context, number int64, elapsed time.Duration, err error)
+}
+
+// Feed tells of the changes of the stored numbers, such as a *feed.Feed. Subscribe
+// closes the channel it returns once ctx is done.
+type Feed interface {
+	Subscribe(ctx context.Context) <-chan feed.Event
+}
+
+// WithLogger logs failed requests to logger instead of slog.Default()
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *Server) {
+		s.logger = logger
+	}
+}
+
+// WithClock times the hooks by now instead of time.Now
+func WithClock(now func() time.Time) Option {
+	return func(s *Server) {
+		s.now = now
+	}
+}
+
+// WithValidator rejects the numbers validate returns an error for. Later validators
+// run after earlier ones.
+func WithValidator(validate Validator) Option {
+	return func(s *Server) {
+		s.validators = append(s.validators, validate)
+	}
+}
+
+// WithResponseMode sets how much of a failure clients are told
+func WithResponseMode(mode ResponseMode) Option {
+	return func(s *Server) {
+		s.mode = mode
+	}
+}
+
+// WithWriteResponse answers requests that do not choose with write rather than a
+// window
+func WithWriteResponse(write WriteResponse) Option {
+	return func(s *Server) {
+		s.writeResponse = write
+	}
+}
+
+// WithWindowSize sets how many stored numbers a window holds on each side of the one
+// added, 5 unless set
+func WithWindowSize(size int32) Option {
+	return func(s *Server) {
+		s.windowSize = size
+	}
+}
+
+// WithHooks calls hooks around every number added, after those of earlier WithHooks
+func WithHooks(hooks Hooks) Option {
+	return func(s *Server) {
+		s.hooks = append(s.hooks, hooks)
+	}
+}
+
+// WithFeed streams the changes f tells of from GET /numbers/stream, which answers 503
+// without a feed
+func WithFeed(f Feed) Option {
+	return func(s *Server) {
+		s.feed = f
+	}
+}
+
+// WithClear lets the callers allowed returns true for delete every number through
+// DELETE /numbers, which answers 403 to everyone without it. Admin allows the callers
+// of admin API keys.
+func WithClear(allowed func(ctx context.Context) bool) Option {
+	return func(s *Server) {
+		s.clearAllowed = allowed
+	}
+}
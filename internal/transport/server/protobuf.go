@@ -0,0 +1,76 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	api "golang-test-task/api"
+	"golang-test-task/numberspb"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// writeProtobuf encodes body as its counterpart in numberspb, the messages the gRPC
+// service uses
+func writeProtobuf(w http.ResponseWriter, status int, body any) error {
+	var msg proto.Message
+	switch body := body.(type) {
+	case api.CreateNumberResponse:
+		resp := &numberspb.AddNumberResponse{}
+		if body.Numbers != nil {
+			resp.Numbers = *body.Numbers
+		}
+		if body.Window != nil {
+			resp.Window = *body.Window
+		}
+		resp.Count, resp.Position = body.Count, body.Position
+		if body.Id != nil {
+			resp.Id = body.Id.String()
+		}
+		if m := body.Meta; m != nil {
+			resp.Meta = &numberspb.ResponseMeta{Total: m.Total, Returned: m.Returned, ElapsedMs: m.ElapsedMs, RequestId: m.RequestId}
+			if m.NextCursor != nil {
+				resp.Meta.NextCursor = *m.NextCursor
+			}
+		}
+		msg = resp
+	case api.NumbersPage:
+		msg = numbersPage(body)
+	case api.BatchResult:
+		msg = &numberspb.BatchResult{Inserted: body.Inserted, Window: numbersPage(body.Window)}
+	case api.NumberStats:
+		stats := &numberspb.NumberStats{Count: body.Count, Min: body.Min, Max: body.Max, Sum: body.Sum, Mean: body.Mean, Median: body.Median}
+		for _, p := range body.Percentiles {
+			stats.Percentiles = append(stats.Percentiles, &numberspb.Percentile{Percentile: p.Percentile, Value: p.Value})
+		}
+		msg = stats
+	case api.ErrorResponse:
+		msg = &numberspb.ErrorResponse{Error: body.Error}
+	default:
+		return fmt.Errorf("no protobuf message for %T", body)
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+	data, err := proto.MarshalOptions{}.MarshalAppend(buf.AvailableBuffer(), msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode protobuf response: %w", err)
+	}
+	// The buffer keeps the capacity data grew to, for the next response
+	buf.Write(data)
+
+	w.Header().Set("Content-Type", protobufType)
+	w.WriteHeader(status)
+	_, err = w.Write(buf.Bytes())
+
+	return err
+}
+
+func numbersPage(page api.NumbersPage) *numberspb.NumbersPage {
+	msg := &numberspb.NumbersPage{Numbers: page.Numbers}
+	if page.NextCursor != nil {
+		msg.NextCursor = *page.NextCursor
+	}
+
+	return msg
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang-test-task/internal/service"
+	"golang-test-task/numberspb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// TestProtobuf_Responses tests that successes and errors are sent as numberspb
+// messages when the client asks for protobuf, on every router
+func TestProtobuf_Responses(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		queries    *fakeQuerier
+		wantStatus int
+		wantBody   []int64
+	}{
+		{name: "ok", query: "?number=5&full=true", queries: &fakeQuerier{numbers: []int64{300, -70000}}, wantStatus: http.StatusOK, wantBody: []int64{-70000, 5, 300}},
+		{name: "out of range", query: "?number=9223372036854775808", queries: &fakeQuerier{}, wantStatus: http.StatusBadRequest},
+		{name: "missing param", query: "", queries: &fakeQuerier{}, wantStatus: http.StatusBadRequest},
+		{name: "list error", query: "?number=1", queries: &fakeQuerier{listErr: errors.New("timeout")}, wantStatus: http.StatusInternalServerError},
+	}
+
+	for router, newHandler := range handlerRouters {
+		for _, tt := range tests {
+			t.Run(router+"/"+tt.name, func(t *testing.T) {
+				queries := *tt.queries
+				req := httptest.NewRequest(http.MethodPost, "/numbers"+tt.query, nil)
+				req.Header.Set("Accept", "application/x-protobuf")
+
+				rec := httptest.NewRecorder()
+				newHandler(NewServer(service.New(&queries))).ServeHTTP(rec, req)
+
+				require.Equal(t, tt.wantStatus, rec.Code)
+				assert.Equal(t, "application/x-protobuf", rec.Header().Get("Content-Type"))
+				if tt.wantBody == nil {
+					var body numberspb.ErrorResponse
+					require.NoError(t, proto.Unmarshal(rec.Body.Bytes(), &body))
+					assert.NotEmpty(t, body.GetError())
+					return
+				}
+
+				var body numberspb.AddNumberResponse
+				require.NoError(t, proto.Unmarshal(rec.Body.Bytes(), &body))
+				assert.Equal(t, tt.wantBody, body.GetNumbers())
+			})
+		}
+	}
+}
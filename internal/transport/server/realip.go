@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+type clientIPKey struct{}
+
+// ClientIP returns the client address resolved by RealIP, or false outside it
+func ClientIP(ctx context.Context) (netip.Addr, bool) {
+	ip, ok := ctx.Value(clientIPKey{}).(netip.Addr)
+	return ip, ok
+}
+
+// RealIP resolves the address of the client behind trusted reverse proxies and load
+// balancers. Forwarded (RFC 7239) or X-Forwarded-For is honoured only when the peer is
+// trusted, and is read from the right, skipping trusted hops, so clients cannot spoof
+// their address by sending the header themselves. The result is stored for ClientIP
+// and replaces r.RemoteAddr, with port 0 when it came from a header.
+func RealIP(next http.Handler, trusted []netip.Prefix) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		peer, err := netip.ParseAddrPort(r.RemoteAddr)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ip := clientIP(peer.Addr().Unmap(), forwardedFor(r.Header), trusted)
+		r = r.WithContext(context.WithValue(r.Context(), clientIPKey{}, ip))
+		if ip != peer.Addr().Unmap() {
+			r.RemoteAddr = netip.AddrPortFrom(ip, 0).String()
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// clientIP walks the hops from the peer towards the client and returns the first one
+// not in trusted. An unparsable hop stops the walk at the last trusted one.
+func clientIP(peer netip.Addr, hops []string, trusted []netip.Prefix) netip.Addr {
+	ip := peer
+	for i := len(hops) - 1; i >= 0 && isTrusted(ip, trusted); i-- {
+		hop, err := parseHop(hops[i])
+		if err != nil {
+			break
+		}
+		ip = hop
+	}
+
+	return ip
+}
+
+func isTrusted(ip netip.Addr, trusted []netip.Prefix) bool {
+	for _, prefix := range trusted {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// forwardedFor lists the client addresses from Forwarded or, without it, X-Forwarded-For,
+// leftmost (closest to the client) first
+func forwardedFor(h http.Header) []string {
+	var hops []string
+	if values := h.Values("Forwarded"); len(values) > 0 {
+		for _, element := range strings.Split(strings.Join(values, ","), ",") {
+			for _, pair := range strings.Split(element, ";") {
+				key, value, _ := strings.Cut(strings.TrimSpace(pair), "=")
+				if strings.EqualFold(key, "for") {
+					hops = append(hops, strings.Trim(value, `"`))
+				}
+			}
+		}
+		return hops
+	}
+
+	for _, value := range h.Values("X-Forwarded-For") {
+		for _, hop := range strings.Split(value, ",") {
+			hops = append(hops, strings.TrimSpace(hop))
+		}
+	}
+
+	return hops
+}
+
+// parseHop accepts an address with or without a port, IPv6 optionally in brackets
+func parseHop(hop string) (netip.Addr, error) {
+	if host, _, err := net.SplitHostPort(hop); err == nil {
+		hop = host
+	}
+	ip, err := netip.ParseAddr(strings.Trim(hop, "[]"))
+
+	return ip.Unmap(), err
+}
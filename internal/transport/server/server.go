@@ -0,0 +1,234 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	api "golang-test-task/api"
+	"golang-test-task/internal/service"
+	"golang-test-task/internal/storage/sqlc"
+
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+type Server struct {
+	numbers    *service.Numbers
+	logger     *slog.Logger
+	now        func() time.Time
+	validators []Validator
+	mode       ResponseMode
+	hooks      []Hooks
+	// writeResponse is what AddNumber answers with unless the request says
+	writeResponse WriteResponse
+	// windowSize is how many numbers a window holds on each side of the one added
+	windowSize int32
+	// feed is what StreamNumbers follows, nil when the numbers are not followed
+	feed Feed
+	// clearAllowed tells whether the caller of ctx may delete every number, nil when
+	// no one may
+	clearAllowed func(ctx context.Context) bool
+}
+
+// NewServer serves numbers over the HTTP API. Without options it logs to
+// slog.Default(), accepts every int64, answers with a window of five numbers on each
+// side of the one added and tells clients only domain errors.
+func NewServer(numbers *service.Numbers, opts ...Option) *Server {
+	s := &Server{
+		numbers:       numbers,
+		logger:        slog.Default(),
+		now:           time.Now,
+		writeResponse: WriteWindow,
+		windowSize:    5,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+func (s *Server) AddNumber(ctx context.Context, request api.AddNumberRequestObject) (api.AddNumberResponseObject, error) {
+	start := s.now()
+	// The parameter is decoded as an int64, so a number out of range never gets here
+	number := request.Params.Number
+	for _, validate := range s.validators {
+		if err := validate(number); err != nil {
+			return api.AddNumber400JSONResponse{Error: err.Error()}, nil
+		}
+	}
+
+	mode := s.writeResponse
+	if request.Params.Response != nil {
+		// The generated handlers do not check enums, so any string gets here
+		mode = WriteResponse(*request.Params.Response)
+		if mode != WriteWindow && mode != WriteFull && mode != WriteCount && mode != WriteNone {
+			return api.AddNumber400JSONResponse{Error: fmt.Sprintf("response must be window, full, count or none, got %q", mode)}, nil
+		}
+	}
+	if request.Params.Full != nil && *request.Params.Full {
+		if request.Params.Response != nil && mode != WriteFull {
+			return api.AddNumber400JSONResponse{Error: fmt.Sprintf("full=true conflicts with response=%s", mode)}, nil
+		}
+		mode = WriteFull
+	}
+	withMeta := enveloped(ctx, request.Params)
+	var row sqlc.Number
+	insert := func() (err error) {
+		row, err = s.numbers.InsertRow(ctx, number)
+		return err
+	}
+
+	if mode == WriteNone {
+		if err := s.hooked(ctx, number, insert); err != nil {
+			return s.addError(ctx, err), nil
+		}
+		return api.AddNumber204Response{}, nil
+	}
+
+	if mode == WriteCount {
+		return s.addCounted(ctx, number, start, withMeta), nil
+	}
+
+	if mode == WriteWindow {
+		return s.addWindowed(ctx, number, start, withMeta), nil
+	}
+
+	// Meta counts the list, so an enveloped list is read whole rather than streamed
+	if responseTypeOf(ctx) == jsonType && !withMeta {
+		// The list is encoded as the storage yields it rather than collected first
+		if err := s.hooked(ctx, number, insert); err != nil {
+			return s.addError(ctx, err), nil
+		}
+		return streamedNumbers{ctx: ctx, server: s, id: rowID(row)}, nil
+	}
+
+	if responseTypeOf(ctx) == jsonAPIType {
+		// Resources need the ids of the numbers, so only JSON:API gets whole rows. Its
+		// documents have meta of their own, so they are never enveloped.
+		if err := s.hooked(ctx, number, insert); err != nil {
+			return s.addError(ctx, err), nil
+		}
+		rows, err := s.numbers.List(ctx)
+		if err != nil {
+			return s.addError(ctx, err), nil
+		}
+		return jsonAPINumbers{rows: rows, added: rowID(row)}, nil
+	}
+
+	if err := s.hooked(ctx, number, insert); err != nil {
+		return s.addError(ctx, err), nil
+	}
+	numbers, err := s.numbers.Values(ctx)
+	if err != nil {
+		return s.addError(ctx, err), nil
+	}
+
+	resp := api.AddNumber200JSONResponse{
+		Id:      rowID(row),
+		Numbers: &numbers,
+	}
+	if withMeta {
+		// The list is never split, so it is all there is
+		resp.Meta = s.meta(ctx, int64(len(numbers)), int64(len(numbers)), start)
+	}
+
+	return resp, nil
+}
+
+// addCounted stores number and answers with how many numbers are stored and where it
+// stands among them. Both are counted by the database, so each read grows with the
+// table unless postgres.index answers them from memory.
+func (s *Server) addCounted(ctx context.Context, number int64, start time.Time, withMeta bool) api.AddNumberResponseObject {
+	var row sqlc.Number
+	err := s.hooked(ctx, number, func() (err error) {
+		row, err = s.numbers.InsertRow(ctx, number)
+		return err
+	})
+	if err != nil {
+		return s.addError(ctx, err)
+	}
+	count, err := s.numbers.Count(ctx)
+	if err != nil {
+		return s.addError(ctx, err)
+	}
+	position, err := s.numbers.Rank(ctx, number)
+	if err != nil {
+		return s.addError(ctx, err)
+	}
+
+	resp := api.AddNumber200JSONResponse{
+		Id:       rowID(row),
+		Count:    &count,
+		Position: &position,
+	}
+	if withMeta {
+		resp.Meta = s.meta(ctx, count, 0, start)
+	}
+
+	return resp
+}
+
+// addError is the response to a failure of the service to add a number
+func (s *Server) addError(ctx context.Context, err error) api.AddNumberResponseObject {
+	// Adding never finds nothing or a duplicate, so only unavailability has its own status
+	body := api.ErrorResponse{Error: s.errorMessage(ctx, err)}
+	if errors.Is(err, service.ErrStorageUnavailable) {
+		return api.AddNumber503JSONResponse(body)
+	}
+
+	return api.AddNumber500JSONResponse(body)
+}
+
+// rowID is the id of row as the API sends it, nil when the storage keeps no ids
+func rowID(row sqlc.Number) *openapi_types.UUID {
+	if !row.ID.Valid {
+		return nil
+	}
+	id := openapi_types.UUID(row.ID.Bytes)
+
+	return &id
+}
+
+// hooked calls add, which stores number, with the hooks around it
+func (s *Server) hooked(ctx context.Context, number int64, add func() error) error {
+	return s.hookedAll(ctx, []int64{number}, add)
+}
+
+// hookedAll calls add, which stores numbers at once, with the hooks around it for
+// each of them. Every number is reported with how long storing them all took.
+func (s *Server) hookedAll(ctx context.Context, numbers []int64, add func() error) error {
+	for _, h := range s.hooks {
+		if h.BeforeAdd != nil {
+			for _, number := range numbers {
+				h.BeforeAdd(ctx, number)
+			}
+		}
+	}
+
+	start := s.now()
+	err := add()
+	elapsed := s.now().Sub(start)
+
+	for _, h := range s.hooks {
+		if h.AfterAdd != nil {
+			for _, number := range numbers {
+				h.AfterAdd(ctx, number, elapsed, err)
+			}
+		}
+	}
+
+	return err
+}
+
+// errorMessage logs err and returns what the client is told of it
+func (s *Server) errorMessage(ctx context.Context, err error) string {
+	message := errorMessage(ctx, s.logger, err)
+	if s.mode == ResponseVerbose {
+		return err.Error()
+	}
+
+	return message
+}
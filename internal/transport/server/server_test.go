@@ -0,0 +1,608 @@
+package server
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	api "golang-test-task/api"
+	"golang-test-task/api/apitest"
+	"golang-test-task/internal/service"
+	"golang-test-task/internal/storage/memstore"
+	"golang-test-task/internal/storage/sqlc"
+	"golang-test-task/numberspb"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// errRefused is the error of a storage that cannot be reached
+var errRefused = &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+
+// fakeQuerier is an in-memory sqlc.Querier with injectable failures
+type fakeQuerier struct {
+	numbers   []int64
+	insertErr error
+	listErr   error
+	deleteErr error
+}
+
+func (f *fakeQuerier) InsertNumber(_ context.Context, number int64) (sqlc.Number, error) {
+	if f.insertErr != nil {
+		return sqlc.Number{}, f.insertErr
+	}
+	f.numbers = append(f.numbers, number)
+	return sqlc.Number{Number: number}, nil
+}
+
+func (f *fakeQuerier) InsertNumbers(_ context.Context, numbers []int64) ([]sqlc.Number, error) {
+	if f.insertErr != nil {
+		return nil, f.insertErr
+	}
+	rows := make([]sqlc.Number, len(numbers))
+	for i, number := range numbers {
+		rows[i] = sqlc.Number{Number: number}
+	}
+	f.numbers = append(f.numbers, numbers...)
+	return rows, nil
+}
+
+// DeleteNumber finds no row, since the fake keeps no ids
+func (f *fakeQuerier) DeleteNumber(context.Context, pgtype.UUID) (sqlc.Number, error) {
+	if f.deleteErr != nil {
+		return sqlc.Number{}, f.deleteErr
+	}
+	return sqlc.Number{}, pgx.ErrNoRows
+}
+
+func (f *fakeQuerier) DeleteAllNumbers(context.Context) error {
+	if f.deleteErr != nil {
+		return f.deleteErr
+	}
+	f.numbers = nil
+	return nil
+}
+
+// NumberStats aggregates the numbers as memstore does
+func (f *fakeQuerier) NumberStats(ctx context.Context, fractions []float64) (sqlc.NumberStatsRow, error) {
+	if f.listErr != nil {
+		return sqlc.NumberStatsRow{}, f.listErr
+	}
+	store := memstore.New()
+	if _, err := store.InsertNumbers(ctx, f.numbers); err != nil {
+		return sqlc.NumberStatsRow{}, err
+	}
+	return store.NumberStats(ctx, fractions)
+}
+
+func (f *fakeQuerier) GetAllNumbersSorted(_ context.Context) ([]sqlc.Number, error) {
+	if f.listErr != nil {
+		return nil, f.listErr
+	}
+	sorted := slices.Clone(f.numbers)
+	slices.Sort(sorted)
+
+	result := make([]sqlc.Number, len(sorted))
+	for i, num := range sorted {
+		result[i] = sqlc.Number{Number: num}
+	}
+	return result, nil
+}
+
+func (f *fakeQuerier) ListNumbersPage(ctx context.Context, arg sqlc.ListNumbersPageParams) ([]sqlc.Number, error) {
+	rows, err := f.GetAllNumbersSorted(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// The rows have no ids, so a page starts after every copy of the last number
+	rows = slices.DeleteFunc(rows, func(row sqlc.Number) bool {
+		return row.Number < arg.MinNumber || row.Number > arg.MaxNumber || arg.AfterNumber.Valid && row.Number <= arg.AfterNumber.Int64
+	})
+	return rows[:min(len(rows), int(arg.PageLimit))], nil
+}
+
+func (f *fakeQuerier) NumberWindow(ctx context.Context, arg sqlc.NumberWindowParams) ([]sqlc.Number, error) {
+	rows, err := f.GetAllNumbersSorted(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// The rows have no ids, so the window is around the first copy of the number
+	i, _ := slices.BinarySearchFunc(rows, arg.Number, func(row sqlc.Number, number int64) int {
+		return cmp.Compare(row.Number, number)
+	})
+	size := int(arg.Size)
+	return rows[max(i-size, 0):min(i+size+1, len(rows))], nil
+}
+
+func (f *fakeQuerier) CountNumbers(context.Context) (int64, error) {
+	if f.listErr != nil {
+		return 0, f.listErr
+	}
+	return int64(len(f.numbers)), nil
+}
+
+func (f *fakeQuerier) RankNumber(_ context.Context, number int64) (int64, error) {
+	if f.listErr != nil {
+		return 0, f.listErr
+	}
+	var rank int64
+	for _, n := range f.numbers {
+		if n < number {
+			rank++
+		}
+	}
+	return rank, nil
+}
+
+// RankRow ranks by number, since the fake keeps no ids
+func (f *fakeQuerier) RankRow(ctx context.Context, arg sqlc.RankRowParams) (int64, error) {
+	return f.RankNumber(ctx, arg.Number)
+}
+
+func (f *fakeQuerier) ContainsNumber(_ context.Context, number int64) (bool, error) {
+	if f.listErr != nil {
+		return false, f.listErr
+	}
+	return slices.Contains(f.numbers, number), nil
+}
+
+// addNumber calls the handler directly, bypassing HTTP
+func addNumber(t *testing.T, server *Server, number int64) api.AddNumberResponseObject {
+	t.Helper()
+	resp, err := server.AddNumber(context.Background(), api.AddNumberRequestObject{
+		Params: api.AddNumberParams{Number: number},
+	})
+	require.NoError(t, err)
+	return resp
+}
+
+// TestServer_AddNumber_ReturnsSortedNumbers tests the success path of the full
+// response
+func TestServer_AddNumber_ReturnsSortedNumbers(t *testing.T) {
+	queries := &fakeQuerier{}
+	server := NewServer(service.New(queries), WithWriteResponse(WriteFull))
+
+	for _, num := range []int64{3, 1, 2} {
+		addNumber(t, server, num)
+	}
+
+	resp := addNumber(t, server, 0)
+	require.IsType(t, api.AddNumber200JSONResponse{}, resp)
+	assert.Equal(t, []int64{0, 1, 2, 3}, *resp.(api.AddNumber200JSONResponse).Numbers)
+	assert.Equal(t, []int64{3, 1, 2, 0}, queries.numbers)
+}
+
+// TestServer_AddNumber_InsertError tests that insert failures surface as 500 without
+// their details
+func TestServer_AddNumber_InsertError(t *testing.T) {
+	server := NewServer(service.New(&fakeQuerier{insertErr: errors.New("relation \"numbers\" does not exist")}))
+
+	resp := addNumber(t, server, 1)
+	require.IsType(t, api.AddNumber500JSONResponse{}, resp)
+	assert.Equal(t, "internal error", resp.(api.AddNumber500JSONResponse).Error)
+}
+
+// TestServer_AddNumber_StorageUnavailable tests that an unreachable storage surfaces
+// as 503, so clients know to retry
+func TestServer_AddNumber_StorageUnavailable(t *testing.T) {
+	server := NewServer(service.New(&fakeQuerier{insertErr: errRefused}))
+
+	resp := addNumber(t, server, 1)
+	require.IsType(t, api.AddNumber503JSONResponse{}, resp)
+	assert.Equal(t, "storage unavailable", resp.(api.AddNumber503JSONResponse).Error)
+}
+
+// TestServer_AddNumber_ListError tests that read failures after insert surface as 500
+func TestServer_AddNumber_ListError(t *testing.T) {
+	queries := &fakeQuerier{listErr: errors.New("timeout")}
+	server := NewServer(service.New(queries))
+
+	resp := addNumber(t, server, 1)
+	require.IsType(t, api.AddNumber500JSONResponse{}, resp)
+	assert.Equal(t, "internal error", resp.(api.AddNumber500JSONResponse).Error)
+	assert.Equal(t, []int64{1}, queries.numbers)
+}
+
+// TestServer_AddNumber_Int64Range tests that the whole int64 range is stored, the
+// edges included
+func TestServer_AddNumber_Int64Range(t *testing.T) {
+	queries := &fakeQuerier{}
+	server := NewServer(service.New(queries), WithWriteResponse(WriteFull))
+
+	addNumber(t, server, math.MaxInt64)
+	resp := addNumber(t, server, math.MinInt64)
+	require.IsType(t, api.AddNumber200JSONResponse{}, resp)
+	assert.Equal(t, []int64{math.MinInt64, math.MaxInt64}, *resp.(api.AddNumber200JSONResponse).Numbers)
+}
+
+// TestServer_Options_Validator tests that numbers a validator rejects are 400 with
+// its message and never stored
+func TestServer_Options_Validator(t *testing.T) {
+	queries := &fakeQuerier{}
+	server := NewServer(service.New(queries), WithValidator(func(number int64) error {
+		if number < 0 {
+			return errors.New("number must not be negative")
+		}
+		return nil
+	}))
+
+	resp := addNumber(t, server, -1)
+	require.IsType(t, api.AddNumber400JSONResponse{}, resp)
+	assert.Equal(t, "number must not be negative", resp.(api.AddNumber400JSONResponse).Error)
+	assert.Empty(t, queries.numbers)
+
+	require.IsType(t, api.AddNumber200JSONResponse{}, addNumber(t, server, 1))
+}
+
+// TestServer_Options_HooksAndClock tests that hooks see every number added and the
+// time storing it took by the server's clock
+func TestServer_Options_HooksAndClock(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time {
+		now = now.Add(5 * time.Millisecond)
+		return now
+	}
+
+	var before []int64
+	var elapsed []time.Duration
+	var errs []error
+	positive := WithValidator(func(number int64) error {
+		if number <= 0 {
+			return errors.New("number must be positive")
+		}
+		return nil
+	})
+	server := NewServer(service.New(&fakeQuerier{}), positive, WithClock(clock), WithHooks(Hooks{
+		BeforeAdd: func(_ context.Context, number int64) { before = append(before, number) },
+		AfterAdd: func(_ context.Context, _ int64, took time.Duration, err error) {
+			elapsed = append(elapsed, took)
+			errs = append(errs, err)
+		},
+	}))
+
+	addNumber(t, server, 4)
+	addNumber(t, server, -4)
+
+	assert.Equal(t, []int64{4}, before, "rejected numbers are never stored")
+	assert.Equal(t, []time.Duration{5 * time.Millisecond}, elapsed)
+	assert.Equal(t, []error{nil}, errs)
+}
+
+// TestServer_Options_LoggerAndResponseMode tests that failures are logged to the
+// server's logger, and only told to clients in full in verbose mode
+func TestServer_Options_LoggerAndResponseMode(t *testing.T) {
+	tests := []struct {
+		mode    ResponseMode
+		message string
+	}{
+		{ResponseSafe, "internal error"},
+		{ResponseVerbose, "failed to insert number: disk full"},
+	}
+
+	for _, tt := range tests {
+		var logs strings.Builder
+		logger := slog.New(slog.NewTextHandler(&logs, nil))
+		server := NewServer(service.New(&fakeQuerier{insertErr: errors.New("disk full")}), WithLogger(logger), WithResponseMode(tt.mode))
+
+		resp := addNumber(t, server, 1)
+		require.IsType(t, api.AddNumber500JSONResponse{}, resp)
+		assert.Equal(t, tt.message, resp.(api.AddNumber500JSONResponse).Error)
+		assert.Contains(t, logs.String(), "disk full")
+	}
+}
+
+// TestServer_WriteResponse tests that AddNumber answers in the mode the request asks
+// for, or the server's when it asks for none, and that only the full list is read
+func TestServer_WriteResponse(t *testing.T) {
+	tests := []struct {
+		name       string
+		write      WriteResponse
+		query      string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "default", query: "?number=5", wantStatus: http.StatusOK, wantBody: `{"position":1,"window":[-1,5,7]}`},
+		{name: "window enveloped", query: "?number=8&envelope=true", wantStatus: http.StatusOK,
+			wantBody: `{"position":2,"window":[-1,7,8],"meta":{"total":3,"returned":3,"elapsed_ms":0,"request_id":"req-1"}}`},
+		{name: "full", query: "?number=5&full=true", wantStatus: http.StatusOK, wantBody: `{"numbers":[-1,5,7]}`},
+		{name: "server full", write: WriteFull, query: "?number=5", wantStatus: http.StatusOK, wantBody: `{"numbers":[-1,5,7]}`},
+		{name: "full not asked", write: WriteFull, query: "?number=5&full=false", wantStatus: http.StatusOK, wantBody: `{"numbers":[-1,5,7]}`},
+		{name: "full and response", query: "?number=5&full=true&response=full", wantStatus: http.StatusOK, wantBody: `{"numbers":[-1,5,7]}`},
+		{name: "count", query: "?number=5&response=count", wantStatus: http.StatusOK, wantBody: `{"count":3,"position":1}`},
+		{name: "count enveloped", query: "?number=8&response=count&envelope=true", wantStatus: http.StatusOK,
+			wantBody: `{"count":3,"position":2,"meta":{"total":3,"returned":0,"elapsed_ms":0,"request_id":"req-1"}}`},
+		{name: "none", query: "?number=5&response=none", wantStatus: http.StatusNoContent},
+		{name: "server none", write: WriteNone, query: "?number=5", wantStatus: http.StatusNoContent},
+		{name: "request wins", write: WriteNone, query: "?number=5&response=full", wantStatus: http.StatusOK, wantBody: `{"numbers":[-1,5,7]}`},
+		{name: "unknown", query: "?number=5&response=some", wantStatus: http.StatusBadRequest, wantBody: `{"error":"response must be window, full, count or none, got \"some\""}`},
+		{name: "full conflicts", query: "?number=5&full=true&response=count", wantStatus: http.StatusBadRequest, wantBody: `{"error":"full=true conflicts with response=count"}`},
+	}
+
+	for router, newHandler := range handlerRouters {
+		for _, tt := range tests {
+			t.Run(router+"/"+tt.name, func(t *testing.T) {
+				options := []Option{WithClock(func() time.Time { return time.Time{} })}
+				if tt.write != "" {
+					options = append(options, WithWriteResponse(tt.write))
+				}
+				queries := &fakeQuerier{numbers: []int64{7, -1}}
+				handler := newHandler(NewServer(service.New(queries), options...))
+
+				req := httptest.NewRequest(http.MethodPost, "/numbers"+tt.query, nil)
+				req.Header.Set("X-Request-Id", "req-1")
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, req)
+
+				require.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+				if tt.wantBody == "" {
+					assert.Empty(t, rec.Body.String())
+					assert.Len(t, queries.numbers, 3, "the number is stored")
+					return
+				}
+				assert.JSONEq(t, tt.wantBody, rec.Body.String())
+			})
+		}
+	}
+}
+
+// TestServer_WriteResponse_Encodings tests that a count is sent in every encoding,
+// JSON:API holding it in its meta
+func TestServer_WriteResponse_Encodings(t *testing.T) {
+	serve := func(accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/numbers?number=5", nil)
+		req.Header.Set("Accept", accept)
+		rec := httptest.NewRecorder()
+		NewHandler(NewServer(service.New(&fakeQuerier{numbers: []int64{7}}), WithWriteResponse(WriteCount))).ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+		return rec
+	}
+
+	assert.JSONEq(t, `{"jsonapi":{"version":"1.1"},"meta":{"total":2,"position":0}}`, serve(jsonAPIType).Body.String())
+	assert.Contains(t, serve(xmlType).Body.String(), "<CreateNumberResponse><count>2</count><position>0</position></CreateNumberResponse>")
+
+	var msg numberspb.AddNumberResponse
+	require.NoError(t, proto.Unmarshal(serve(protobufType).Body.Bytes(), &msg))
+	assert.Equal(t, int64(2), msg.GetCount())
+	assert.Equal(t, int64(0), msg.GetPosition())
+	assert.NotNil(t, msg.Position, "a zero position is still sent")
+	assert.Empty(t, msg.Numbers)
+}
+
+// TestServer_AddNumber_HTTP tests parameter decoding through the generated handler
+func TestServer_AddNumber_HTTP(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantBody   []int64
+	}{
+		{name: "valid", query: "?number=5", wantStatus: http.StatusOK, wantBody: []int64{5}},
+		{name: "negative", query: "?number=-5", wantStatus: http.StatusOK, wantBody: []int64{-5}},
+		{name: "missing", query: "", wantStatus: http.StatusBadRequest},
+		{name: "empty", query: "?number=", wantStatus: http.StatusBadRequest},
+		{name: "not a number", query: "?number=abc", wantStatus: http.StatusBadRequest},
+		{name: "float", query: "?number=1.5", wantStatus: http.StatusBadRequest},
+		{name: "out of range", query: "?number=9223372036854775808", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewHandler(NewServer(service.New(&fakeQuerier{})))
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/numbers"+tt.query, nil))
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+			if tt.wantStatus != http.StatusOK {
+				var body api.ErrorResponse
+				require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+				assert.NotEmpty(t, body.Error)
+			}
+			if tt.wantBody != nil {
+				var body api.CreateNumberResponse
+				require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+				require.NotNil(t, body.Window)
+				assert.Equal(t, tt.wantBody, *body.Window)
+			}
+		})
+	}
+}
+
+// TestHandler_Healthz tests that the liveness endpoint answers without touching the database
+func TestHandler_Healthz(t *testing.T) {
+	for router, newHandler := range handlerRouters {
+		handler := newHandler(NewServer(service.New(&fakeQuerier{listErr: errors.New("database is down")})))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code, router)
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"), router)
+		assert.JSONEq(t, `{"status":"ok"}`, rec.Body.String(), router)
+	}
+}
+
+// TestServer_MatchesFake tests that apitest.Server, which client consumers test against,
+// answers exactly like the real server but for the ids of the rows, which are random.
+// Equal numbers stand in the order of those ids, so each number is added once.
+func TestServer_MatchesFake(t *testing.T) {
+	realHandler := NewHandler(NewServer(service.New(memstore.New())))
+	fakeHandler := apitest.NewServer().Handler()
+	ids := regexp.MustCompile(`"id":"[0-9a-f-]{36}"`)
+
+	for _, query := range []string{"?number=5", "?number=-5", "?number=7", "?number=0", "?number=1&full=true", "?number=9223372036854775808", "?number=abc", ""} {
+		realRec := httptest.NewRecorder()
+		realHandler.ServeHTTP(realRec, httptest.NewRequest(http.MethodPost, "/numbers"+query, nil))
+
+		fakeRec := httptest.NewRecorder()
+		fakeHandler.ServeHTTP(fakeRec, httptest.NewRequest(http.MethodPost, "/numbers"+query, nil))
+
+		assert.Equal(t, realRec.Code, fakeRec.Code, "status for %q", query)
+		assert.Equal(t, realRec.Header().Get("Content-Type"), fakeRec.Header().Get("Content-Type"), "content type for %q", query)
+		assert.Equal(t, ids.ReplaceAllString(realRec.Body.String(), `"id":"…"`), ids.ReplaceAllString(fakeRec.Body.String(), `"id":"…"`), "body for %q", query)
+	}
+}
+
+func TestCORS(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusTeapot) })
+	handler := CORS(next, []string{"http://localhost:3000"})
+
+	request := func(method, origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/numbers", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		if method == http.MethodOptions {
+			req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := request(http.MethodPost, "http://localhost:3000")
+	assert.Equal(t, http.StatusTeapot, rec.Code)
+	assert.Equal(t, "http://localhost:3000", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, rec.Header().Get("Access-Control-Expose-Headers"), "Grpc-Status")
+
+	rec = request(http.MethodOptions, "http://localhost:3000")
+	assert.Equal(t, http.StatusNoContent, rec.Code, "preflight answered without calling next")
+	assert.Contains(t, rec.Header().Get("Access-Control-Allow-Methods"), "POST")
+
+	rec = request(http.MethodPost, "https://evil.example.com")
+	assert.Equal(t, http.StatusTeapot, rec.Code)
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+
+	rec = request(http.MethodPost, "")
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+
+	handler = CORS(next, []string{"*"})
+	rec = request(http.MethodPost, "https://anything.example.com")
+	assert.Equal(t, "*", rec.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestRealIP(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("fd00::/8")}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		header     http.Header
+		want       string
+	}{
+		{name: "no proxy", remoteAddr: "203.0.113.9:5000", want: "203.0.113.9"},
+		{name: "untrusted peer cannot spoof", remoteAddr: "203.0.113.9:5000", header: http.Header{"X-Forwarded-For": {"1.2.3.4"}}, want: "203.0.113.9"},
+		{name: "trusted peer", remoteAddr: "10.0.0.2:5000", header: http.Header{"X-Forwarded-For": {"198.51.100.7"}}, want: "198.51.100.7"},
+		{name: "client-supplied entries are skipped", remoteAddr: "10.0.0.2:5000", header: http.Header{"X-Forwarded-For": {"1.2.3.4, 198.51.100.7, 10.0.0.3"}}, want: "198.51.100.7"},
+		{name: "repeated headers", remoteAddr: "10.0.0.2:5000", header: http.Header{"X-Forwarded-For": {"198.51.100.7", "10.0.0.3"}}, want: "198.51.100.7"},
+		{name: "all hops trusted", remoteAddr: "10.0.0.2:5000", header: http.Header{"X-Forwarded-For": {"10.0.0.4, 10.0.0.3"}}, want: "10.0.0.4"},
+		{name: "garbage stops at last trusted hop", remoteAddr: "10.0.0.2:5000", header: http.Header{"X-Forwarded-For": {"198.51.100.7, nonsense"}}, want: "10.0.0.2"},
+		{name: "forwarded", remoteAddr: "10.0.0.2:5000", header: http.Header{"Forwarded": {`for=198.51.100.7;proto=https, for="[fd00::5]:4711"`}}, want: "198.51.100.7"},
+		{name: "forwarded wins over x-forwarded-for", remoteAddr: "10.0.0.2:5000", header: http.Header{"Forwarded": {"for=198.51.100.7"}, "X-Forwarded-For": {"192.0.2.1"}}, want: "198.51.100.7"},
+		{name: "ipv6 client", remoteAddr: "[fd00::1]:5000", header: http.Header{"X-Forwarded-For": {"2001:db8::7"}}, want: "2001:db8::7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got netip.Addr
+			var remoteAddr string
+			handler := RealIP(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ip, ok := ClientIP(r.Context())
+				require.True(t, ok)
+				got, remoteAddr = ip, r.RemoteAddr
+			}), trusted)
+
+			req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for key, values := range tt.header {
+				req.Header[key] = values
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tt.want, got.String())
+			host, _, err := net.SplitHostPort(remoteAddr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, host, "RemoteAddr carries the client address")
+		})
+	}
+}
+
+func TestVerifySignatures(t *testing.T) {
+	oldSecret, newSecret := []byte("old-secret-0123456789abcdef"), []byte("new-secret-0123456789abcdef")
+	handler := VerifySignatures(NewHandler(NewServer(service.New(&fakeQuerier{}))), [][]byte{newSecret, oldSecret}, time.Minute)
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	for _, secret := range [][]byte{oldSecret, newSecret} {
+		client, err := api.NewClientWithResponses(srv.URL, api.WithHTTPClient(api.NewSigningDoer(http.DefaultClient, secret)))
+		require.NoError(t, err)
+		resp, err := client.AddNumberWithResponse(context.Background(), &api.AddNumberParams{Number: 1})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode(), "either secret is accepted during a rotation")
+	}
+
+	// signed returns a request to add 7 signed with secret at the given time
+	signed := func(secret []byte, at time.Time, nonce string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/numbers?number=7", nil)
+		timestamp := strconv.FormatInt(at.Unix(), 10)
+		req.Header.Set(api.SignatureTimestampHeader, timestamp)
+		req.Header.Set(api.SignatureNonceHeader, nonce)
+		req.Header.Set(api.SignatureHeader, api.Signature(secret, http.MethodPost, "/numbers?number=7", timestamp, nonce, nil))
+		return req
+	}
+	status := func(req *http.Request) int {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, status(signed(newSecret, time.Now(), "n1")))
+	assert.Equal(t, http.StatusUnauthorized, status(signed(newSecret, time.Now(), "n1")), "a replay is rejected")
+	assert.Equal(t, http.StatusUnauthorized, status(signed([]byte("guessed"), time.Now(), "n2")))
+	assert.Equal(t, http.StatusUnauthorized, status(signed(newSecret, time.Now().Add(-2*time.Minute), "n3")), "a stale timestamp is rejected")
+	assert.Equal(t, http.StatusUnauthorized, status(httptest.NewRequest(http.MethodPost, "/numbers?number=7", nil)))
+
+	tampered := signed(newSecret, time.Now(), "n4")
+	tampered.URL.RawQuery = "number=8"
+	assert.Equal(t, http.StatusUnauthorized, status(tampered))
+
+	assert.Equal(t, http.StatusOK, status(httptest.NewRequest(http.MethodGet, "/healthz", nil)), "probes need no signature")
+}
+
+func TestVerifier_ForgetsExpiredNonces(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	v := &verifier{secrets: [][]byte{[]byte("secret")}, maxSkew: time.Minute, nonces: make(map[string]time.Time), now: func() time.Time { return now }}
+
+	request := func(nonce string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/numbers?number=1", nil)
+		timestamp := strconv.FormatInt(now.Unix(), 10)
+		req.Header.Set(api.SignatureTimestampHeader, timestamp)
+		req.Header.Set(api.SignatureNonceHeader, nonce)
+		req.Header.Set(api.SignatureHeader, api.Signature([]byte("secret"), http.MethodPost, "/numbers?number=1", timestamp, nonce, nil))
+		return req
+	}
+
+	require.NoError(t, v.verify(request("a"), nil))
+	now = now.Add(3 * time.Minute)
+	require.NoError(t, v.verify(request("b"), nil))
+	assert.NotContains(t, v.nonces, "a")
+	assert.Contains(t, v.nonces, "b")
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	api "golang-test-task/api"
+)
+
+// maxSignedBody bounds the body read into memory to check its signature
+const maxSignedBody = 1 << 20
+
+// VerifySignatures rejects with 401 every request that is not signed by
+// api.SigningDoer with one of secrets, listed so that a new secret can be rolled out
+// before the old one is retired. The timestamp must be within maxSkew of the
+// server's clock and a nonce is accepted once, so a captured request cannot be
+// replayed; nonces are remembered per instance, for as long as their timestamp is
+// acceptable. GET /healthz stays unsigned for probes.
+func VerifySignatures(next http.Handler, secrets [][]byte, maxSkew time.Duration) http.Handler {
+	v := &verifier{secrets: secrets, maxSkew: maxSkew, nonces: make(map[string]time.Time), now: time.Now}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && r.URL.Path == "/healthz" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxSignedBody))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, r, http.StatusRequestEntityTooLarge, "request body is too large to verify")
+			return
+		}
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, "failed to read request body")
+			return
+		}
+
+		if err := v.verify(r, body); err != nil {
+			writeError(w, r, http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		next.ServeHTTP(w, r)
+	})
+}
+
+type verifier struct {
+	secrets [][]byte
+	maxSkew time.Duration
+	now     func() time.Time
+
+	mu sync.Mutex
+	// nonces maps the nonces seen to when they can be forgotten
+	nonces    map[string]time.Time
+	lastSweep time.Time
+}
+
+// verify checks the signature of r, then records its nonce
+func (v *verifier) verify(r *http.Request, body []byte) error {
+	timestamp, nonce, signature := r.Header.Get(api.SignatureTimestampHeader), r.Header.Get(api.SignatureNonceHeader), r.Header.Get(api.SignatureHeader)
+	if timestamp == "" || nonce == "" || signature == "" {
+		return errors.New("request is not signed")
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.New("malformed signature timestamp")
+	}
+	now := v.now()
+	signedAt := time.Unix(unix, 0)
+	if signedAt.Before(now.Add(-v.maxSkew)) || signedAt.After(now.Add(v.maxSkew)) {
+		return errors.New("signature timestamp is too far from the server time")
+	}
+
+	valid := false
+	for _, secret := range v.secrets {
+		want := api.Signature(secret, r.Method, r.URL.RequestURI(), timestamp, nonce, body)
+		valid = valid || hmac.Equal([]byte(want), []byte(signature))
+	}
+	if !valid {
+		return errors.New("invalid request signature")
+	}
+
+	// Recorded only once the signature holds, so unsigned traffic cannot fill the map
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.sweep(now)
+	if _, seen := v.nonces[nonce]; seen {
+		return errors.New("request was already received")
+	}
+	v.nonces[nonce] = signedAt.Add(v.maxSkew)
+
+	return nil
+}
+
+// sweep forgets the nonces whose timestamp would now be rejected anyway, at most
+// once per maxSkew
+func (v *verifier) sweep(now time.Time) {
+	if now.Sub(v.lastSweep) < v.maxSkew {
+		return
+	}
+
+	for nonce, expires := range v.nonces {
+		if now.After(expires) {
+			delete(v.nonces, nonce)
+		}
+	}
+	v.lastSweep = now
+}
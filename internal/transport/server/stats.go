@@ -0,0 +1,61 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	api "golang-test-task/api"
+	"golang-test-task/internal/service"
+)
+
+// maxPercentiles bounds the percentiles a request may ask for
+const maxPercentiles = 20
+
+// defaultPercentiles are computed when the request asks for none
+var defaultPercentiles = []float64{90, 95, 99}
+
+// GetNumberStats answers with the statistics of the stored numbers, which the storage
+// computes without sending them
+func (s *Server) GetNumberStats(ctx context.Context, request api.GetNumberStatsRequestObject) (api.GetNumberStatsResponseObject, error) {
+	// The generated handlers do not check the bounds of the spec
+	percentiles := defaultPercentiles
+	if request.Params.Percentiles != nil {
+		percentiles = *request.Params.Percentiles
+	}
+	if len(percentiles) > maxPercentiles {
+		return api.GetNumberStats400JSONResponse{Error: fmt.Sprintf("at most %d percentiles may be asked for, got %d", maxPercentiles, len(percentiles))}, nil
+	}
+	fractions := make([]float64, len(percentiles))
+	for i, p := range percentiles {
+		if math.IsNaN(p) || p < 0 || p > 100 {
+			return api.GetNumberStats400JSONResponse{Error: fmt.Sprintf("percentiles must be between 0 and 100, got %v", p)}, nil
+		}
+		fractions[i] = p / 100
+	}
+
+	row, err := s.numbers.Stats(ctx, fractions)
+	if err != nil {
+		body := api.ErrorResponse{Error: s.errorMessage(ctx, err)}
+		if errors.Is(err, service.ErrStorageUnavailable) {
+			return api.GetNumberStats503JSONResponse(body), nil
+		}
+		return api.GetNumberStats500JSONResponse(body), nil
+	}
+
+	stats := api.NumberStats{Count: row.Count, Sum: row.Sum, Percentiles: make([]api.Percentile, len(percentiles))}
+	for i, p := range percentiles {
+		stats.Percentiles[i].Percentile = p
+	}
+	// Without numbers the storage has nothing to aggregate but the count and sum
+	if row.Count > 0 {
+		stats.Min, stats.Max = &row.Min, &row.Max
+		stats.Mean, stats.Median = &row.Mean, &row.Median
+		for i := range stats.Percentiles {
+			stats.Percentiles[i].Value = &row.Percentiles[i]
+		}
+	}
+
+	return api.GetNumberStats200JSONResponse(stats), nil
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	api "golang-test-task/api"
+	"golang-test-task/api/apitest"
+	"golang-test-task/internal/service"
+	"golang-test-task/internal/storage/memstore"
+	"golang-test-task/numberspb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// TestServer_GetNumberStats tests that the statistics are the storage's, with the
+// percentiles asked for or the default ones, in every encoding
+func TestServer_GetNumberStats(t *testing.T) {
+	store := memstore.New()
+	_, err := store.InsertNumbers(context.Background(), []int64{10, 1, 4, 3})
+	require.NoError(t, err)
+	handler := NewHandler(NewServer(service.New(store)))
+	serve := func(target, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("Accept", accept)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		return rec
+	}
+
+	assert.JSONEq(t, `{"count":4,"min":1,"max":10,"sum":18,"mean":4.5,"median":3.5,"percentiles":[
+		{"percentile":0,"value":1},{"percentile":50,"value":3.5},{"percentile":100,"value":10}]}`,
+		serve("/numbers/stats?percentiles=0&percentiles=50&percentiles=100", jsonType).Body.String())
+
+	var stats api.NumberStats
+	require.NoError(t, json.NewDecoder(serve("/numbers/stats", jsonType).Body).Decode(&stats))
+	require.Len(t, stats.Percentiles, 3)
+	for i, p := range []float64{90, 95, 99} {
+		assert.Equal(t, p, stats.Percentiles[i].Percentile, "the default percentiles")
+		assert.InDelta(t, 4+6*(p/100*3-2), *stats.Percentiles[i].Value, 1e-9)
+	}
+
+	var msg numberspb.NumberStats
+	require.NoError(t, proto.Unmarshal(serve("/numbers/stats?percentiles=50", protobufType).Body.Bytes(), &msg))
+	assert.Equal(t, int64(4), msg.Count)
+	assert.Equal(t, int64(10), msg.GetMax())
+	require.Len(t, msg.Percentiles, 1)
+	assert.Equal(t, 3.5, msg.Percentiles[0].GetValue())
+
+	assert.Contains(t, serve("/numbers/stats?percentiles=50", xmlType).Body.String(),
+		"<percentiles><percentile><percentile>50</percentile><value>3.5</value></percentile></percentiles>")
+	assert.JSONEq(t, `{"jsonapi":{"version":"1.1"},"meta":{"count":4,"min":1,"max":10,"sum":18,"mean":4.5,"median":3.5,
+		"percentiles":[{"percentile":50,"value":3.5}]}}`, serve("/numbers/stats?percentiles=50", jsonAPIType).Body.String())
+}
+
+// TestServer_GetNumberStats_Errors tests that percentiles out of range are rejected
+// before the storage is asked, and that storage failures get their status
+func TestServer_GetNumberStats_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		queries    *fakeQuerier
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "above 100", query: "?percentiles=50&percentiles=100.5", queries: &fakeQuerier{listErr: errRefused},
+			wantStatus: http.StatusBadRequest, wantBody: `{"error":"percentiles must be between 0 and 100, got 100.5"}`},
+		{name: "negative", query: "?percentiles=-1", queries: &fakeQuerier{},
+			wantStatus: http.StatusBadRequest, wantBody: `{"error":"percentiles must be between 0 and 100, got -1"}`},
+		{name: "too many", query: "?percentiles=1" + repeatQuery("&percentiles=1", 20), queries: &fakeQuerier{},
+			wantStatus: http.StatusBadRequest, wantBody: `{"error":"at most 20 percentiles may be asked for, got 21"}`},
+		{name: "not a number", query: "?percentiles=median", queries: &fakeQuerier{}, wantStatus: http.StatusBadRequest},
+		{name: "storage unavailable", queries: &fakeQuerier{listErr: errRefused},
+			wantStatus: http.StatusServiceUnavailable, wantBody: `{"error":"storage unavailable"}`},
+		{name: "storage error", queries: &fakeQuerier{listErr: errors.New("boom")},
+			wantStatus: http.StatusInternalServerError, wantBody: `{"error":"internal error"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			NewHandler(NewServer(service.New(tt.queries))).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/numbers/stats"+tt.query, nil))
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+// TestServer_GetNumberStats_MatchesFake tests that apitest.Server aggregates like the
+// real server
+func TestServer_GetNumberStats_MatchesFake(t *testing.T) {
+	numbers := []int64{7, -2, 7, 1 << 40, 0}
+	queries := &fakeQuerier{numbers: numbers}
+	realHandler := NewHandler(NewServer(service.New(queries)))
+	fakeHandler := apitest.NewServer(7, -2, 7, 1<<40, 0).Handler()
+
+	for _, query := range []string{"", "?percentiles=12.5&percentiles=99.9", "?percentiles=101"} {
+		realRec := httptest.NewRecorder()
+		realHandler.ServeHTTP(realRec, httptest.NewRequest(http.MethodGet, "/numbers/stats"+query, nil))
+		fakeRec := httptest.NewRecorder()
+		fakeHandler.ServeHTTP(fakeRec, httptest.NewRequest(http.MethodGet, "/numbers/stats"+query, nil))
+
+		assert.Equal(t, realRec.Code, fakeRec.Code, "status for %q", query)
+		assert.Equal(t, realRec.Body.String(), fakeRec.Body.String(), "body for %q", query)
+	}
+}
+
+// repeatQuery is s n times
+func repeatQuery(s string, n int) string {
+	var query string
+	for range n {
+		query += s
+	}
+	return query
+}
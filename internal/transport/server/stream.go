@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	openapi_types "github.com/oapi-codegen/runtime/types"
+)
+
+// streamChunk is how much of a streamed list is encoded before it is written out
+const streamChunk = 32 << 10
+
+// maxNumberLen is the length of the longest number with the comma before it, so a
+// chunk never outgrows its buffer
+const maxNumberLen = len(",-9223372036854775808")
+
+// streamedNumbers is the list of numbers as JSON, encoded as the storage yields them
+// so that a list of any length takes a chunk of memory, from a pooled buffer. The body is the one
+// api.AddNumber200JSONResponse encodes to.
+type streamedNumbers struct {
+	ctx    context.Context
+	server *Server
+	// id is the id of the number added, nil when the storage keeps no ids
+	id *openapi_types.UUID
+}
+
+func (n streamedNumbers) VisitAddNumberResponse(w http.ResponseWriter) error {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	buf.Grow(streamChunk + maxNumberLen)
+	buf.WriteByte('{')
+	if n.id != nil {
+		buf.WriteString(`"id":"` + n.id.String() + `",`)
+	}
+	buf.WriteString(`"numbers":[`)
+
+	written, first := false, true
+	flush := func() error {
+		if !written {
+			w.Header().Set("Content-Type", jsonType)
+			w.WriteHeader(http.StatusOK)
+			written = true
+		}
+		_, err := w.Write(buf.Bytes())
+		buf.Reset()
+		return err
+	}
+
+	err := n.server.numbers.Stream(n.ctx, func(number int64) error {
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		buf.Write(strconv.AppendInt(buf.AvailableBuffer(), number, 10))
+		if buf.Len() < streamChunk {
+			return nil
+		}
+		return flush()
+	})
+	if err != nil && !written {
+		return n.server.addError(n.ctx, err).VisitAddNumberResponse(w)
+	}
+	if err != nil {
+		// The status is sent, so the client can only learn of the failure from a
+		// response cut short
+		n.server.errorMessage(n.ctx, err)
+		panic(http.ErrAbortHandler)
+	}
+
+	buf.WriteString("]}\n")
+	return flush()
+}
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	api "golang-test-task/api"
+	"golang-test-task/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// streamingQuerier streams its numbers, failing after the first failAfter when set
+type streamingQuerier struct {
+	fakeQuerier
+	failAfter int
+}
+
+func (s *streamingQuerier) StreamNumbersSorted(ctx context.Context, yield func(int64) error) error {
+	rows, _ := s.GetAllNumbersSorted(ctx)
+	for i, row := range rows {
+		if s.failAfter > 0 && i == s.failAfter {
+			return errors.New("connection reset by peer")
+		}
+		if err := yield(row.Number); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TestStreamedNumbers_MatchesEncoder tests that a list streamed in several chunks
+// is the body encoding the whole list gives
+func TestStreamedNumbers_MatchesEncoder(t *testing.T) {
+	queries := &streamingQuerier{}
+	for i := range 20000 {
+		queries.numbers = append(queries.numbers, int64(i*7919-50000000))
+	}
+
+	rec := httptest.NewRecorder()
+	NewHandler(NewServer(service.New(queries), WithWriteResponse(WriteFull))).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/numbers?number=5", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, jsonType, rec.Header().Get("Content-Type"))
+
+	rows, _ := queries.GetAllNumbersSorted(context.Background())
+	want := make([]int64, len(rows))
+	for i, row := range rows {
+		want[i] = row.Number
+	}
+	encoded, err := json.Marshal(api.CreateNumberResponse{Numbers: &want})
+	require.NoError(t, err)
+	require.Greater(t, len(encoded), 2*streamChunk)
+	assert.Equal(t, string(encoded)+"\n", rec.Body.String())
+}
+
+// TestStreamedNumbers_Failures tests that a list failing before anything is sent is
+// an error response, and one failing partway is cut short
+func TestStreamedNumbers_Failures(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewHandler(NewServer(service.New(&fakeQuerier{listErr: errRefused}), WithWriteResponse(WriteFull))).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/numbers?number=5", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.JSONEq(t, `{"error":"storage unavailable"}`, rec.Body.String())
+
+	queries := &streamingQuerier{failAfter: 15000}
+	for i := range 20000 {
+		queries.numbers = append(queries.numbers, int64(i))
+	}
+	rec = httptest.NewRecorder()
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		NewHandler(NewServer(service.New(queries), WithWriteResponse(WriteFull))).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/numbers?number=5", nil))
+	})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.False(t, json.Valid(rec.Body.Bytes()), "a list cut short must not parse")
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	api "golang-test-task/api"
+)
+
+type operationIDKey struct{}
+
+// OperationID is the id in openapi.yaml of the operation the request of ctx is for,
+// such as AddNumber, within strict middleware and the handlers they wrap
+func OperationID(ctx context.Context) string {
+	id, _ := ctx.Value(operationIDKey{}).(string)
+	return id
+}
+
+// withOperationID records the operation in the context of the middleware it wraps,
+// and for LogRequests and HTTPMetrics outside the handler
+func withOperationID(f api.StrictHandlerFunc, operationID string) api.StrictHandlerFunc {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request, request any) (any, error) {
+		recordOperation(ctx, operationID)
+		return f(context.WithValue(ctx, operationIDKey{}, operationID), w, r, request)
+	}
+}
+
+// statusError is a failure a strict middleware answers the request with, as an
+// ErrorResponse with the status rather than 500
+type statusError struct {
+	status int
+	err    error
+}
+
+func (e *statusError) Error() string { return e.err.Error() }
+
+func (e *statusError) Unwrap() error { return e.err }
+
+// ValidateRequests rejects with 400 every request validate returns an error for,
+// with the error as its message. request is the operation's typed request object,
+// such as api.AddNumberRequestObject.
+func ValidateRequests(validate func(ctx context.Context, operationID string, request any) error) api.StrictMiddlewareFunc {
+	return func(f api.StrictHandlerFunc, operationID string) api.StrictHandlerFunc {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request, request any) (any, error) {
+			if err := validate(ctx, operationID, request); err != nil {
+				return nil, &statusError{http.StatusBadRequest, err}
+			}
+
+			return f(ctx, w, r, request)
+		}
+	}
+}
+
+// ErrForbidden is returned, wrapped or not, by the authenticate function of
+// Authenticate for a caller it knows but who may not call the operation
+var ErrForbidden = errors.New("forbidden")
+
+// Authenticate rejects with 401 every request authenticate returns an error for, or
+// with 403 when the error is ErrForbidden. Otherwise the request goes on with the
+// context authenticate returns, which can carry the caller for the handler.
+func Authenticate(authenticate func(ctx context.Context, r *http.Request, operationID string) (context.Context, error)) api.StrictMiddlewareFunc {
+	return func(f api.StrictHandlerFunc, operationID string) api.StrictHandlerFunc {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request, request any) (any, error) {
+			ctx, err := authenticate(ctx, r, operationID)
+			if errors.Is(err, ErrForbidden) {
+				return nil, &statusError{http.StatusForbidden, err}
+			}
+			if err != nil {
+				return nil, &statusError{http.StatusUnauthorized, err}
+			}
+
+			return f(ctx, w, r, request)
+		}
+	}
+}
+
+// ObserveOperations calls observe after every operation with the status it answers
+// with and how long the middleware and handler inside it took, for per-operation
+// metrics
+func ObserveOperations(observe func(ctx context.Context, operationID string, status int, elapsed time.Duration)) api.StrictMiddlewareFunc {
+	return func(f api.StrictHandlerFunc, operationID string) api.StrictHandlerFunc {
+		return func(ctx context.Context, w http.ResponseWriter, r *http.Request, request any) (any, error) {
+			start := time.Now()
+			response, err := f(ctx, w, r, request)
+			observe(ctx, operationID, responseStatus(response, err), time.Since(start))
+
+			return response, err
+		}
+	}
+}
+
+// responseStatus is the status of the response a strict handler returned
+func responseStatus(response any, err error) int {
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		return statusErr.status
+	}
+	if err != nil {
+		return http.StatusInternalServerError
+	}
+
+	switch response.(type) {
+	case api.DeleteNumber204Response, api.ClearNumbers204Response:
+		return http.StatusNoContent
+	case api.AddNumber400JSONResponse, api.ListNumbers400JSONResponse, api.AddNumberBatch400JSONResponse,
+		api.GetNumberStats400JSONResponse:
+		return http.StatusBadRequest
+	case api.ClearNumbers403JSONResponse:
+		return http.StatusForbidden
+	case api.DeleteNumber404JSONResponse:
+		return http.StatusNotFound
+	case api.AddNumber500JSONResponse, api.ListNumbers500JSONResponse, api.AddNumberBatch500JSONResponse,
+		api.StreamNumbers500JSONResponse, api.DeleteNumber500JSONResponse, api.ClearNumbers500JSONResponse,
+		api.GetNumberStats500JSONResponse:
+		return http.StatusInternalServerError
+	case api.AddNumber503JSONResponse, api.ListNumbers503JSONResponse, api.AddNumberBatch503JSONResponse,
+		api.StreamNumbers503JSONResponse, api.DeleteNumber503JSONResponse, api.ClearNumbers503JSONResponse,
+		api.GetNumberStats503JSONResponse:
+		return http.StatusServiceUnavailable
+	}
+
+	return http.StatusOK
+}
+
+// responseErrorHandler reports the failure of a strict handler, as 500 unless a
+// middleware answered with another status
+func responseErrorHandler(w http.ResponseWriter, r *http.Request, err error) {
+	status := http.StatusInternalServerError
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		status = statusErr.status
+	}
+
+	writeError(w, r, status, err.Error())
+}
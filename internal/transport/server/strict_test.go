@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	api "golang-test-task/api"
+	"golang-test-task/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type callerKey struct{}
+
+// TestStrictMiddlewares tests that middlewares passed to the handler can reject
+// requests with their own status, carry context to the handler and observe every
+// operation, on both routers and in every encoding
+func TestStrictMiddlewares(t *testing.T) {
+	routers := map[string]func(api.StrictServerInterface, ...api.StrictMiddlewareFunc) http.Handler{
+		"stdlib": NewHandler,
+		"chi": func(s api.StrictServerInterface, middlewares ...api.StrictMiddlewareFunc) http.Handler {
+			return NewChiHandler(s, chi.NewRouter(), middlewares...)
+		},
+	}
+
+	for router, newHandler := range routers {
+		t.Run(router, func(t *testing.T) {
+			type observation struct {
+				operationID string
+				status      int
+			}
+			var observed []observation
+			var callers []string
+
+			queries := &fakeQuerier{}
+			handler := newHandler(NewServer(service.New(queries)),
+				ValidateRequests(func(_ context.Context, _ string, request any) error {
+					if request.(api.AddNumberRequestObject).Params.Number == 13 {
+						return errors.New("13 is unlucky")
+					}
+					return nil
+				}),
+				ObserveOperations(func(ctx context.Context, operationID string, status int, _ time.Duration) {
+					if caller, ok := ctx.Value(callerKey{}).(string); ok {
+						callers = append(callers, caller)
+					}
+					observed = append(observed, observation{operationID, status})
+				}),
+				Authenticate(func(ctx context.Context, r *http.Request, operationID string) (context.Context, error) {
+					assert.Equal(t, "AddNumber", OperationID(ctx))
+					caller := r.Header.Get("X-Caller")
+					if caller == "" {
+						return nil, errors.New("caller is unknown")
+					}
+					return context.WithValue(ctx, callerKey{}, caller+" "+operationID), nil
+				}),
+			)
+
+			serve := func(number, caller, accept string) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(http.MethodPost, "/numbers?number="+number, nil)
+				if caller != "" {
+					req.Header.Set("X-Caller", caller)
+				}
+				req.Header.Set("Accept", accept)
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, req)
+				return rec
+			}
+
+			rec := serve("1", "", jsonType)
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+			assert.JSONEq(t, `{"error":"caller is unknown"}`, rec.Body.String())
+
+			rec = serve("13", "alice", xmlType)
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Equal(t, xmlType, rec.Header().Get("Content-Type"))
+			assert.Contains(t, rec.Body.String(), "13 is unlucky")
+
+			rec = serve("2", "alice", msgpackType)
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, msgpackType, rec.Header().Get("Content-Type"))
+
+			rec = serve("99999999999999999999", "alice", jsonType)
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+			require.Equal(t, []int64{2}, queries.numbers)
+			assert.Equal(t, []observation{
+				{"AddNumber", http.StatusBadRequest},
+				{"AddNumber", http.StatusOK},
+			}, observed, "requests rejected by an outer middleware or the parameter decoder are never observed")
+			assert.Equal(t, []string{"alice AddNumber", "alice AddNumber"}, callers)
+		})
+	}
+}
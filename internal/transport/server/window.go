@@ -0,0 +1,58 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	api "golang-test-task/api"
+	"golang-test-task/internal/storage/sqlc"
+)
+
+// addWindowed stores number and answers with where it stands and the numbers next to
+// it. The window is read from either side of the row stored, so it reads no more than
+// its size, but the position counts the rows before it, which grows with the rank.
+func (s *Server) addWindowed(ctx context.Context, number int64, start time.Time, withMeta bool) api.AddNumberResponseObject {
+	var row sqlc.Number
+	err := s.hooked(ctx, number, func() (err error) {
+		row, err = s.numbers.InsertRow(ctx, number)
+		return err
+	})
+	if err != nil {
+		return s.addError(ctx, err)
+	}
+	// The position is the row's, among copies of its number too, so that it is where
+	// the row stands in the window. Numbers added by other requests in between may be
+	// counted by one read and not the other, as in the count response.
+	position, err := s.numbers.Position(ctx, row)
+	if err != nil {
+		return s.addError(ctx, err)
+	}
+	rows, err := s.numbers.Window(ctx, row, s.windowSize)
+	if err != nil {
+		return s.addError(ctx, err)
+	}
+
+	if responseTypeOf(ctx) == jsonAPIType {
+		// Resources need the ids of the numbers, as in the full response
+		return jsonAPIWindow{rows: rows, position: position, added: rowID(row)}
+	}
+
+	window := make(api.Numbers, len(rows))
+	for i, row := range rows {
+		window[i] = row.Number
+	}
+	resp := api.AddNumber200JSONResponse{
+		Id:       rowID(row),
+		Position: &position,
+		Window:   &window,
+	}
+	if withMeta {
+		count, err := s.numbers.Count(ctx)
+		if err != nil {
+			return s.addError(ctx, err)
+		}
+		resp.Meta = s.meta(ctx, count, int64(len(window)), start)
+	}
+
+	return resp
+}
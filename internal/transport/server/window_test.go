@@ -0,0 +1,159 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	api "golang-test-task/api"
+	"golang-test-task/internal/service"
+	"golang-test-task/internal/storage/memstore"
+	"golang-test-task/internal/storage/sqlc"
+	"golang-test-task/numberspb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// unlistedStore is a store that fails the test when the whole list is read
+type unlistedStore struct {
+	*memstore.Store
+	t *testing.T
+}
+
+func (s unlistedStore) GetAllNumbersSorted(ctx context.Context) ([]sqlc.Number, error) {
+	s.t.Error("the whole list is read")
+	return s.Store.GetAllNumbersSorted(ctx)
+}
+
+func (s unlistedStore) StreamNumbersSorted(ctx context.Context, yield func(int64) error) error {
+	s.t.Error("the whole list is streamed")
+	return s.Store.StreamNumbersSorted(ctx, yield)
+}
+
+// TestServer_AddNumber_Window tests that the default response is the number with its
+// neighbours, cut short at the ends of the list, without reading the rest of it, on
+// every router
+func TestServer_AddNumber_Window(t *testing.T) {
+	tests := []struct {
+		name         string
+		number       string
+		wantPosition int64
+		wantWindow   api.Numbers
+	}{
+		{name: "middle", number: "501", wantPosition: 251, wantWindow: api.Numbers{496, 498, 500, 501, 502, 504, 506}},
+		{name: "first", number: "-1", wantPosition: 0, wantWindow: api.Numbers{-1, 0, 2, 4}},
+		{name: "last", number: "5000", wantPosition: 1000, wantWindow: api.Numbers{1994, 1996, 1998, 5000}},
+	}
+
+	for router, newHandler := range handlerRouters {
+		for _, tt := range tests {
+			t.Run(router+"/"+tt.name, func(t *testing.T) {
+				store := memstore.New()
+				for i := range 1000 {
+					_, err := store.InsertNumber(t.Context(), int64(2*i))
+					require.NoError(t, err)
+				}
+				handler := newHandler(NewServer(service.New(unlistedStore{Store: store, t: t}), WithWindowSize(3)))
+
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/numbers?number="+tt.number, nil))
+				require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+				var body api.CreateNumberResponse
+				require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+				require.NotNil(t, body.Position)
+				require.NotNil(t, body.Window)
+				assert.Equal(t, tt.wantPosition, *body.Position)
+				assert.Equal(t, tt.wantWindow, *body.Window)
+				assert.Nil(t, body.Numbers)
+			})
+		}
+	}
+}
+
+// TestServer_AddNumber_WindowEncodings tests that a window is sent in every encoding,
+// JSON:API listing it as resources with the position as meta
+func TestServer_AddNumber_WindowEncodings(t *testing.T) {
+	store := memstore.New()
+	for _, number := range []int64{1, 9} {
+		_, err := store.InsertNumber(t.Context(), number)
+		require.NoError(t, err)
+	}
+	handler := NewHandler(NewServer(service.New(store), WithWindowSize(1)))
+	serve := func(number, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/numbers?number="+number, nil)
+		req.Header.Set("Accept", accept)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		return rec
+	}
+
+	var msg numberspb.AddNumberResponse
+	require.NoError(t, proto.Unmarshal(serve("5", protobufType).Body.Bytes(), &msg))
+	assert.Equal(t, []int64{1, 5, 9}, msg.Window)
+	assert.Equal(t, int64(1), msg.GetPosition())
+	assert.Empty(t, msg.Numbers)
+	assert.Len(t, msg.Id, 36, "the id of the row stored")
+
+	assert.Contains(t, serve("0", xmlType).Body.String(), "<position>0</position><window><number>0</number><number>1</number></window>")
+
+	var doc struct {
+		Data []struct {
+			ID         string
+			Attributes struct{ Number int64 }
+		}
+		Meta struct {
+			Position int64
+			Total    *int64
+			ID       string
+		}
+		Links struct{ Self string }
+	}
+	require.NoError(t, json.NewDecoder(serve("10", jsonAPIType).Body).Decode(&doc))
+	assert.Equal(t, int64(4), doc.Meta.Position)
+	assert.Nil(t, doc.Meta.Total, "a window has no total")
+	assert.Equal(t, "/numbers?number=10", doc.Links.Self)
+	require.Len(t, doc.Data, 2)
+	assert.Equal(t, int64(9), doc.Data[0].Attributes.Number)
+	assert.Equal(t, int64(10), doc.Data[1].Attributes.Number)
+	assert.Len(t, doc.Data[1].ID, 36)
+	assert.Equal(t, doc.Data[1].ID, doc.Meta.ID, "meta names the resource added")
+}
+
+// TestServer_AddNumber_WindowPosition tests that the position of a number added among
+// copies of it is where its row stands in the window, which is ordered by id among
+// them
+func TestServer_AddNumber_WindowPosition(t *testing.T) {
+	store := memstore.New()
+	for _, number := range []int64{1, 7, 7, 7, 7, 9} {
+		_, err := store.InsertNumber(t.Context(), number)
+		require.NoError(t, err)
+	}
+	handler := NewHandler(NewServer(service.New(store), WithWindowSize(2)))
+
+	for range 5 {
+		req := httptest.NewRequest(http.MethodPost, "/numbers?number=7", nil)
+		req.Header.Set("Accept", jsonAPIType)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var doc struct {
+			Data []struct{ ID string }
+			Meta struct {
+				Position int64
+				ID       string
+			}
+		}
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&doc))
+		// The window starts size rows before the row, or at the start of the list
+		i := min(doc.Meta.Position, 2)
+		require.Less(t, int(i), len(doc.Data))
+		assert.Equal(t, doc.Meta.ID, doc.Data[i].ID, "position %d", doc.Meta.Position)
+	}
+}
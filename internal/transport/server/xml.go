@@ -0,0 +1,24 @@
+package server
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+)
+
+// writeXML encodes body, an API model, in the XML shape the OpenAPI spec documents:
+// the root element is named after the schema and the fields after its properties
+func writeXML(w http.ResponseWriter, status int, body any) error {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	buf.WriteString(xml.Header)
+	if err := xml.NewEncoder(buf).Encode(body); err != nil {
+		return fmt.Errorf("failed to encode XML response: %w", err)
+	}
+
+	w.Header().Set("Content-Type", xmlType)
+	w.WriteHeader(status)
+	_, err := w.Write(buf.Bytes())
+
+	return err
+}
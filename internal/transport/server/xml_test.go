@@ -0,0 +1,88 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	api "golang-test-task/api"
+	"golang-test-task/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestXML_Responses tests the XML bodies of successes and errors, on every router
+func TestXML_Responses(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		queries    *fakeQuerier
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "ok", query: "?number=5", queries: &fakeQuerier{numbers: []int64{7, -1}}, wantStatus: http.StatusOK,
+			wantBody: `<CreateNumberResponse><position>1</position><window><number>-1</number><number>5</number><number>7</number></window></CreateNumberResponse>`,
+		},
+		{
+			name: "full", query: "?number=5&full=true", queries: &fakeQuerier{numbers: []int64{7, -1}}, wantStatus: http.StatusOK,
+			wantBody: `<CreateNumberResponse><numbers><number>-1</number><number>5</number><number>7</number></numbers></CreateNumberResponse>`,
+		},
+		{
+			name: "out of range", query: "?number=9223372036854775808", queries: &fakeQuerier{}, wantStatus: http.StatusBadRequest,
+			wantBody: `<ErrorResponse><error>number 9223372036854775808 is out of range [-9223372036854775808, 9223372036854775807]</error></ErrorResponse>`,
+		},
+		{
+			name: "insert error", query: "?number=1", queries: &fakeQuerier{insertErr: errRefused}, wantStatus: http.StatusServiceUnavailable,
+			wantBody: `<ErrorResponse><error>storage unavailable</error></ErrorResponse>`,
+		},
+	}
+
+	for router, newHandler := range handlerRouters {
+		for _, tt := range tests {
+			t.Run(router+"/"+tt.name, func(t *testing.T) {
+				queries := *tt.queries
+				req := httptest.NewRequest(http.MethodPost, "/numbers"+tt.query, nil)
+				req.Header.Set("Accept", "application/xml")
+
+				rec := httptest.NewRecorder()
+				newHandler(NewServer(service.New(&queries))).ServeHTTP(rec, req)
+
+				require.Equal(t, tt.wantStatus, rec.Code)
+				assert.Equal(t, "application/xml", rec.Header().Get("Content-Type"))
+				assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+tt.wantBody, rec.Body.String())
+			})
+		}
+	}
+}
+
+// TestXML_GeneratedClient tests that the generated client decodes the XML the server sends
+func TestXML_GeneratedClient(t *testing.T) {
+	srv := httptest.NewServer(NewHandler(NewServer(service.New(&fakeQuerier{numbers: []int64{3}}))))
+	defer srv.Close()
+
+	client, err := api.NewClientWithResponses(srv.URL, api.WithRequestEditorFn(func(_ context.Context, req *http.Request) error {
+		req.Header.Set("Accept", "application/xml")
+		return nil
+	}))
+	require.NoError(t, err)
+
+	resp, err := client.AddNumberWithResponse(t.Context(), &api.AddNumberParams{Number: 1})
+	require.NoError(t, err)
+	require.NotNil(t, resp.XML200)
+	require.NotNil(t, resp.XML200.Window)
+	assert.Equal(t, []int64{1, 3}, *resp.XML200.Window)
+
+	// The client cannot send a number past int64, so the request is made by hand
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, srv.URL+"/numbers?number=9223372036854775808", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "application/xml")
+	raw, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp, err = api.ParseAddNumberResponse(raw)
+	require.NoError(t, err)
+	require.NotNil(t, resp.XML400)
+	assert.Contains(t, resp.XML400.Error, "out of range")
+}
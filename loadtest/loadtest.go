@@ -0,0 +1,303 @@
+// Package loadtest drives a fixed request rate with a read/write mix against a
+// running service and reports latency percentiles and error rates.
+package loadtest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"golang-test-task/api"
+	"golang-test-task/datagen"
+)
+
+// Config describes a load test run
+type Config struct {
+	// URL of the service under test
+	URL string
+	// RPS is the target request rate
+	RPS int
+	// Duration of the run
+	Duration time.Duration
+	// RampUp grows the rate linearly from zero to RPS over its first part of the
+	// run, so caches and connection pools warm up as they would under real traffic
+	RampUp time.Duration
+	// ReadRatio is the fraction of requests in [0, 1] that are reads
+	ReadRatio float64
+	// ReadPath is requested with GET for reads
+	ReadPath string
+	// MaxInFlight caps concurrent requests; ticks beyond it are counted as dropped
+	MaxInFlight int
+	// Timeout of a single request
+	Timeout time.Duration
+	// Seed makes the written values and the read/write sequence reproducible
+	Seed uint64
+	// Values shapes the written numbers; its Seed is replaced by Seed
+	Values datagen.Config
+}
+
+// DefaultConfig returns a light write-only load
+func DefaultConfig() Config {
+	return Config{
+		RPS:         50,
+		Duration:    10 * time.Second,
+		ReadRatio:   0,
+		ReadPath:    "/numbers",
+		MaxInFlight: 100,
+		Timeout:     5 * time.Second,
+		Seed:        1,
+		Values:      datagen.DefaultConfig(),
+	}
+}
+
+// Op is a kind of request issued by the load test
+type Op string
+
+const (
+	OpWrite Op = "write"
+	OpRead  Op = "read"
+)
+
+// Stats summarizes the requests of one Op
+type Stats struct {
+	Requests int
+	Errors   int
+	P50      time.Duration
+	P90      time.Duration
+	P99      time.Duration
+	Max      time.Duration
+}
+
+// ErrorRate is the fraction of failed requests
+func (s Stats) ErrorRate() float64 {
+	if s.Requests == 0 {
+		return 0
+	}
+	return float64(s.Errors) / float64(s.Requests)
+}
+
+// Report is the outcome of a load test run
+type Report struct {
+	Elapsed time.Duration
+	Dropped int
+	Ops     map[Op]Stats
+}
+
+// String renders the report as a table
+func (r *Report) String() string {
+	var b strings.Builder
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', tabwriter.AlignRight)
+
+	fmt.Fprintln(tw, "op\trequests\terrors\terror rate\tp50\tp90\tp99\tmax\t")
+	total := 0
+	for _, op := range []Op{OpWrite, OpRead} {
+		s, ok := r.Ops[op]
+		if !ok {
+			continue
+		}
+		total += s.Requests
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.2f%%\t%s\t%s\t%s\t%s\t\n",
+			op, s.Requests, s.Errors, s.ErrorRate()*100,
+			s.P50.Round(time.Microsecond), s.P90.Round(time.Microsecond),
+			s.P99.Round(time.Microsecond), s.Max.Round(time.Microsecond))
+	}
+	tw.Flush()
+
+	fmt.Fprintf(&b, "elapsed %s, achieved %.1f rps, dropped %d\n",
+		r.Elapsed.Round(time.Millisecond), float64(total)/r.Elapsed.Seconds(), r.Dropped)
+
+	return b.String()
+}
+
+type sample struct {
+	op      Op
+	latency time.Duration
+	failed  bool
+}
+
+// Run issues requests at cfg.RPS, after cfg.RampUp, until cfg.Duration elapses or
+// ctx is cancelled, then waits for in-flight requests and returns the report
+func Run(ctx context.Context, cfg Config) (*Report, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("URL is required")
+	}
+	if cfg.RPS <= 0 {
+		return nil, fmt.Errorf("RPS must be positive, got %d", cfg.RPS)
+	}
+	if cfg.ReadRatio < 0 || cfg.ReadRatio > 1 {
+		return nil, fmt.Errorf("read ratio must be within [0, 1], got %v", cfg.ReadRatio)
+	}
+	if cfg.MaxInFlight <= 0 {
+		return nil, fmt.Errorf("max in-flight must be positive, got %d", cfg.MaxInFlight)
+	}
+	if cfg.RampUp < 0 || cfg.RampUp > cfg.Duration {
+		return nil, fmt.Errorf("ramp-up must be within [0, duration], got %s", cfg.RampUp)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.Duration)
+	defer cancel()
+
+	client := &http.Client{
+		Timeout: cfg.Timeout,
+		Transport: &http.Transport{
+			MaxIdleConnsPerHost: cfg.MaxInFlight,
+		},
+	}
+	defer client.CloseIdleConnections()
+
+	valuesCfg := cfg.Values
+	valuesCfg.Seed = cfg.Seed
+	values, err := datagen.New(valuesCfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid values config: %w", err)
+	}
+
+	rng := rand.New(rand.NewPCG(cfg.Seed, cfg.Seed))
+	readURL := strings.TrimSuffix(cfg.URL, "/") + cfg.ReadPath
+
+	var (
+		mu      sync.Mutex
+		samples []sample
+		dropped int
+		wg      sync.WaitGroup
+	)
+	inFlight := make(chan struct{}, cfg.MaxInFlight)
+
+	start := time.Now()
+	timer := time.NewTimer(due(1, cfg.RPS, cfg.RampUp))
+	defer timer.Stop()
+loop:
+	for n := 1; ; n++ {
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-timer.C:
+		}
+		// Scheduled from the start rather than the last request, so a late tick
+		// does not lower the rate
+		timer.Reset(time.Until(start.Add(due(n+1, cfg.RPS, cfg.RampUp))))
+
+		op, req, err := nextRequest(rng, values, cfg, readURL)
+		if err != nil {
+			wg.Wait()
+			return nil, err
+		}
+
+		select {
+		case inFlight <- struct{}{}:
+		default:
+			dropped++
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-inFlight }()
+
+			s := do(client, op, req)
+
+			mu.Lock()
+			samples = append(samples, s)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	return &Report{
+		Elapsed: time.Since(start),
+		Dropped: dropped,
+		Ops:     summarize(samples),
+	}, nil
+}
+
+// due is when the n-th request is due after the start. While the rate ramps up
+// linearly to rps, n requests are due after sqrt(2·rampUp·n/rps); after the ramp
+// one is due every 1/rps.
+func due(n, rps int, rampUp time.Duration) time.Duration {
+	var seconds float64
+	if float64(n) < float64(rps)*rampUp.Seconds()/2 {
+		seconds = math.Sqrt(2 * rampUp.Seconds() * float64(n) / float64(rps))
+	} else {
+		seconds = float64(n)/float64(rps) + rampUp.Seconds()/2
+	}
+
+	return time.Duration(math.Round(seconds * float64(time.Second)))
+}
+
+func nextRequest(rng *rand.Rand, values *datagen.Generator, cfg Config, readURL string) (Op, *http.Request, error) {
+	if rng.Float64() < cfg.ReadRatio {
+		req, err := http.NewRequest(http.MethodGet, readURL, nil)
+		return OpRead, req, err
+	}
+
+	req, err := api.NewAddNumberRequest(cfg.URL, &api.AddNumberParams{Number: int64(values.Next())})
+	return OpWrite, req, err
+}
+
+func do(client *http.Client, op Op, req *http.Request) sample {
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return sample{op: op, latency: time.Since(start), failed: true}
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	return sample{
+		op:      op,
+		latency: time.Since(start),
+		failed:  resp.StatusCode < 200 || resp.StatusCode >= 300,
+	}
+}
+
+func summarize(samples []sample) map[Op]Stats {
+	latencies := make(map[Op][]time.Duration)
+	errors := make(map[Op]int)
+
+	for _, s := range samples {
+		latencies[s.op] = append(latencies[s.op], s.latency)
+		if s.failed {
+			errors[s.op]++
+		}
+	}
+
+	stats := make(map[Op]Stats)
+	for op, l := range latencies {
+		stats[op] = NewStats(l, errors[op])
+	}
+
+	return stats
+}
+
+// NewStats summarizes the latencies of requests of which errors failed. It sorts
+// latencies in place.
+func NewStats(latencies []time.Duration, errors int) Stats {
+	st := Stats{Requests: len(latencies), Errors: errors}
+	if len(latencies) == 0 {
+		return st
+	}
+
+	slices.Sort(latencies)
+	st.P50 = percentile(latencies, 0.50)
+	st.P90 = percentile(latencies, 0.90)
+	st.P99 = percentile(latencies, 0.99)
+	st.Max = latencies[len(latencies)-1]
+
+	return st
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	idx := int(float64(len(sorted))*p+0.5) - 1
+	idx = max(0, min(idx, len(sorted)-1))
+	return sorted[idx]
+}
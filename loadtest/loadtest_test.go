@@ -0,0 +1,132 @@
+package loadtest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"golang-test-task/datagen"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRun_ReadWriteMix tests that both operations are issued and failures are attributed per op
+func TestRun_ReadWriteMix(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	cfg := DefaultConfig()
+	cfg.URL = srv.URL
+	cfg.RPS = 200
+	cfg.Duration = 300 * time.Millisecond
+	cfg.ReadRatio = 0.5
+
+	report, err := Run(context.Background(), cfg)
+	require.NoError(t, err)
+
+	write, read := report.Ops[OpWrite], report.Ops[OpRead]
+	assert.Positive(t, write.Requests)
+	assert.Zero(t, write.Errors)
+	assert.Positive(t, read.Requests)
+	assert.Equal(t, read.Requests, read.Errors)
+	assert.LessOrEqual(t, write.P50, write.P99)
+	assert.Contains(t, report.String(), "achieved")
+}
+
+// TestRun_ValuesFollowDistribution tests that written numbers come from the configured generator
+func TestRun_ValuesFollowDistribution(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		written = map[string]bool{}
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		written[r.URL.Query().Get("number")] = true
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	cfg := DefaultConfig()
+	cfg.URL = srv.URL
+	cfg.RPS = 200
+	cfg.Duration = 200 * time.Millisecond
+	cfg.Values.Distribution = datagen.Clustered
+	cfg.Values.Clusters = 1
+
+	report, err := Run(context.Background(), cfg)
+	require.NoError(t, err)
+	require.Positive(t, report.Ops[OpWrite].Requests)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Len(t, written, 1, "a single cluster without spread writes a single value")
+}
+
+// TestRun_InvalidConfig tests that misconfiguration is reported before any request is sent
+func TestRun_InvalidConfig(t *testing.T) {
+	cfg := DefaultConfig()
+	_, err := Run(context.Background(), cfg)
+	assert.Error(t, err)
+
+	cfg.URL = "http://localhost"
+	cfg.ReadRatio = 2
+	_, err = Run(context.Background(), cfg)
+	assert.Error(t, err)
+
+	cfg.ReadRatio = 0
+	cfg.RampUp = cfg.Duration + time.Second
+	_, err = Run(context.Background(), cfg)
+	assert.Error(t, err)
+
+	cfg.RampUp = 0
+	cfg.Values.Distribution = "normal"
+	_, err = Run(context.Background(), cfg)
+	assert.Error(t, err)
+}
+
+// TestDue tests that the rate ramps up linearly, then stays at RPS
+func TestDue(t *testing.T) {
+	assert.Equal(t, 10*time.Millisecond, due(1, 100, 0))
+	assert.Equal(t, time.Second, due(100, 100, 0))
+
+	// 100 rps after a 2s ramp: 100 requests in the ramp, one every 10ms after it
+	assert.Equal(t, time.Second, due(25, 100, 2*time.Second), "a quarter of the ramp's requests by half of it")
+	assert.Equal(t, 2*time.Second, due(100, 100, 2*time.Second))
+	assert.Equal(t, 2*time.Second+10*time.Millisecond, due(101, 100, 2*time.Second))
+}
+
+// TestRun_RampUp tests that fewer requests are sent while ramping up
+func TestRun_RampUp(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	cfg := DefaultConfig()
+	cfg.URL = srv.URL
+	cfg.RPS = 200
+	cfg.Duration = 500 * time.Millisecond
+	cfg.RampUp = cfg.Duration
+
+	report, err := Run(context.Background(), cfg)
+	require.NoError(t, err)
+	assert.InDelta(t, 50, report.Ops[OpWrite].Requests, 15, "half the requests of a flat run")
+}
+
+// TestPercentile tests nearest-rank percentiles
+func TestPercentile(t *testing.T) {
+	sorted := []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+
+	assert.Equal(t, time.Duration(5), percentile(sorted, 0.5))
+	assert.Equal(t, time.Duration(9), percentile(sorted, 0.9))
+	assert.Equal(t, time.Duration(10), percentile(sorted, 0.99))
+	assert.Equal(t, time.Duration(1), percentile(sorted[:1], 0.5))
+}
@@ -0,0 +1,81 @@
+// Package logfile writes logs to a file that is rotated by size and age, for hosts
+// without a log collector reading stderr.
+package logfile
+
+import (
+	"sync"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Writer appends to a file and rotates it once it grows past a size or has been
+// written to for longer than an age. It is safe for concurrent use.
+type Writer struct {
+	file   *lumberjack.Logger
+	maxAge time.Duration
+	now    func() time.Time
+
+	mu     sync.Mutex
+	opened time.Time
+}
+
+// Options control rotation
+type Options struct {
+	// MaxSizeMB rotates the file once it would exceed this many megabytes; 0 means 100
+	MaxSizeMB int
+	// MaxAge rotates the file once it has been written to for this long; 0 disables it
+	MaxAge time.Duration
+	// MaxBackups is the number of rotated files kept; 0 keeps all of them
+	MaxBackups int
+}
+
+// New returns a Writer for path. The file and its directory are created on the first
+// write; rotated files are kept next to it with a timestamp in their name.
+func New(path string, opts Options) *Writer {
+	return &Writer{
+		file: &lumberjack.Logger{
+			Filename:   path,
+			MaxSize:    opts.MaxSizeMB,
+			MaxBackups: opts.MaxBackups,
+		},
+		maxAge: opts.MaxAge,
+		now:    time.Now,
+	}
+}
+
+// Write appends p, rotating first when the current file is older than MaxAge
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := w.now()
+	if w.opened.IsZero() {
+		w.opened = now
+	} else if w.maxAge > 0 && now.Sub(w.opened) >= w.maxAge {
+		if err := w.file.Rotate(); err != nil {
+			return 0, err
+		}
+		w.opened = now
+	}
+
+	return w.file.Write(p)
+}
+
+// Rotate closes the current file and starts a new one
+func (w *Writer) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.opened = w.now()
+
+	return w.file.Rotate()
+}
+
+// Close closes the current file
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.file.Close()
+}
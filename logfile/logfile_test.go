@@ -0,0 +1,90 @@
+package logfile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// backups returns the rotated files next to the log file
+func backups(t *testing.T, dir string) []string {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	var names []string
+	for _, e := range entries {
+		if e.Name() != "app.log" {
+			names = append(names, e.Name())
+		}
+	}
+
+	return names
+}
+
+func TestWriter_RotatesBySize(t *testing.T) {
+	dir := t.TempDir()
+	w := New(filepath.Join(dir, "app.log"), Options{MaxSizeMB: 1})
+	defer w.Close()
+
+	line := []byte(strings.Repeat("x", 1023) + "\n")
+	for range 1025 {
+		_, err := w.Write(line)
+		require.NoError(t, err)
+	}
+
+	assert.Len(t, backups(t, dir), 1)
+
+	info, err := os.Stat(filepath.Join(dir, "app.log"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(line)), info.Size(), "the line that did not fit starts the new file")
+}
+
+func TestWriter_RotatesByAge(t *testing.T) {
+	dir := t.TempDir()
+	w := New(filepath.Join(dir, "app.log"), Options{MaxAge: time.Hour})
+	defer w.Close()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	w.now = func() time.Time { return now }
+
+	_, err := w.Write([]byte("first\n"))
+	require.NoError(t, err)
+
+	now = now.Add(59 * time.Minute)
+	_, err = w.Write([]byte("second\n"))
+	require.NoError(t, err)
+	assert.Empty(t, backups(t, dir), "younger than MaxAge")
+
+	now = now.Add(time.Minute)
+	_, err = w.Write([]byte("third\n"))
+	require.NoError(t, err)
+	assert.Len(t, backups(t, dir), 1)
+
+	data, err := os.ReadFile(filepath.Join(dir, "app.log"))
+	require.NoError(t, err)
+	assert.Equal(t, "third\n", string(data))
+}
+
+func TestWriter_KeepsMaxBackups(t *testing.T) {
+	dir := t.TempDir()
+	w := New(filepath.Join(dir, "app.log"), Options{MaxBackups: 2})
+	defer w.Close()
+
+	for range 4 {
+		_, err := w.Write([]byte("line\n"))
+		require.NoError(t, err)
+		require.NoError(t, w.Rotate())
+		// Backups are named by timestamp with millisecond precision
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	// Old backups are removed in the background
+	assert.Eventually(t, func() bool { return len(backups(t, dir)) == 2 }, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Existing rows get the time of the migration; now() is evaluated once, so the
+-- table is not rewritten
+alter table numbers add column created_at timestamptz not null default now();
+create index idx_numbers_created_at on numbers (created_at);
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+drop index idx_numbers_created_at;
+alter table numbers drop column created_at;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Numbers are 64-bit everywhere from the API to the table. The type change rewrites
+-- the table and its indexes under an exclusive lock, so run it in a quiet window.
+alter table numbers alter column number type bigint;
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+-- Fails while a stored number is outside the range of integer
+alter table numbers alter column number type integer;
+-- +goose StatementEnd
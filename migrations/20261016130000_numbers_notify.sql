@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Instances that keep the numbers in memory LISTEN on numbers_changed. Each inserted
+-- row is sent as JSON; any other change, and inserts of over 1000 rows at once such
+-- as a seed or a restore, as an empty payload, which makes them reload the table.
+-- Notifications are delivered when the transaction commits.
+create function numbers_notify_insert() returns trigger language plpgsql as $$
+begin
+    if (select count(*) from inserted) > 1000 then
+        perform pg_notify('numbers_changed', '');
+    else
+        perform pg_notify('numbers_changed', row_to_json(i)::text) from inserted i;
+    end if;
+    return null;
+end
+$$;
+create function numbers_notify_change() returns trigger language plpgsql as $$
+begin
+    perform pg_notify('numbers_changed', '');
+    return null;
+end
+$$;
+create trigger numbers_notify_insert after insert on numbers
+    referencing new table as inserted
+    for each statement execute function numbers_notify_insert();
+create trigger numbers_notify_change after update or delete or truncate on numbers
+    for each statement execute function numbers_notify_change();
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+drop trigger numbers_notify_change on numbers;
+drop trigger numbers_notify_insert on numbers;
+drop function numbers_notify_change();
+drop function numbers_notify_insert();
+-- +goose StatementEnd
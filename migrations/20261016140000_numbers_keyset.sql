@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Pages are listed in the order of (number, id). The index on number alone serves
+-- none of its queries that this one does not, so it is replaced.
+create index idx_numbers_number_id on numbers (number, id);
+drop index idx_numbers_number;
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+create index idx_numbers_number on numbers (number);
+drop index idx_numbers_number_id;
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Each deleted row is sent as JSON with "deleted": true, so that instances drop that
+-- row rather than reload the table; deletes of over 1000 rows at once, as well as
+-- updates and truncates, still send an empty payload.
+create function numbers_notify_delete() returns trigger language plpgsql as $$
+begin
+    if (select count(*) from deleted) > 1000 then
+        perform pg_notify('numbers_changed', '');
+    else
+        perform pg_notify('numbers_changed', (to_jsonb(d) || '{"deleted": true}')::text) from deleted d;
+    end if;
+    return null;
+end
+$$;
+drop trigger numbers_notify_change on numbers;
+create trigger numbers_notify_change after update or truncate on numbers
+    for each statement execute function numbers_notify_change();
+create trigger numbers_notify_delete after delete on numbers
+    referencing old table as deleted
+    for each statement execute function numbers_notify_delete();
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+drop trigger numbers_notify_delete on numbers;
+drop function numbers_notify_delete();
+drop trigger numbers_notify_change on numbers;
+create trigger numbers_notify_change after update or delete or truncate on numbers
+    for each statement execute function numbers_notify_change();
+-- +goose StatementEnd
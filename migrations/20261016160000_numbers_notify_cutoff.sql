@@ -0,0 +1,49 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A statement is sent row by row only up to 256 rows, the events a follower of the
+-- changes may fall behind before it is dropped, so that no single statement drops
+-- them all; a larger one sends an empty payload.
+create or replace function numbers_notify_insert() returns trigger language plpgsql as $$
+begin
+    if (select count(*) from inserted) > 256 then
+        perform pg_notify('numbers_changed', '');
+    else
+        perform pg_notify('numbers_changed', row_to_json(i)::text) from inserted i;
+    end if;
+    return null;
+end
+$$;
+create or replace function numbers_notify_delete() returns trigger language plpgsql as $$
+begin
+    if (select count(*) from deleted) > 256 then
+        perform pg_notify('numbers_changed', '');
+    else
+        perform pg_notify('numbers_changed', (to_jsonb(d) || '{"deleted": true}')::text) from deleted d;
+    end if;
+    return null;
+end
+$$;
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+create or replace function numbers_notify_insert() returns trigger language plpgsql as $$
+begin
+    if (select count(*) from inserted) > 1000 then
+        perform pg_notify('numbers_changed', '');
+    else
+        perform pg_notify('numbers_changed', row_to_json(i)::text) from inserted i;
+    end if;
+    return null;
+end
+$$;
+create or replace function numbers_notify_delete() returns trigger language plpgsql as $$
+begin
+    if (select count(*) from deleted) > 1000 then
+        perform pg_notify('numbers_changed', '');
+    else
+        perform pg_notify('numbers_changed', (to_jsonb(d) || '{"deleted": true}')::text) from deleted d;
+    end if;
+    return null;
+end
+$$;
+-- +goose StatementEnd
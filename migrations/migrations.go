@@ -0,0 +1,9 @@
+// Package migrations embeds the goose SQL migrations so the server binary can apply them.
+package migrations
+
+import "embed"
+
+// FS holds the migration files at its root
+//
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,853 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: numbers/v1/numbers.proto
+
+package numberspb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Number is a stored number
+type Number struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Number        int64                  `protobuf:"varint,2,opt,name=number,proto3" json:"number,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Number) Reset() {
+	*x = Number{}
+	mi := &file_numbers_v1_numbers_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Number) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Number) ProtoMessage() {}
+
+func (x *Number) ProtoReflect() protoreflect.Message {
+	mi := &file_numbers_v1_numbers_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Number.ProtoReflect.Descriptor instead.
+func (*Number) Descriptor() ([]byte, []int) {
+	return file_numbers_v1_numbers_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Number) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Number) GetNumber() int64 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+func (x *Number) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type AddNumberRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Number        int64                  `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddNumberRequest) Reset() {
+	*x = AddNumberRequest{}
+	mi := &file_numbers_v1_numbers_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddNumberRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddNumberRequest) ProtoMessage() {}
+
+func (x *AddNumberRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_numbers_v1_numbers_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddNumberRequest.ProtoReflect.Descriptor instead.
+func (*AddNumberRequest) Descriptor() ([]byte, []int) {
+	return file_numbers_v1_numbers_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *AddNumberRequest) GetNumber() int64 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+type AddNumberResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// numbers are every stored number in ascending order, like the HTTP API returns
+	Numbers []int64 `protobuf:"varint,1,rep,packed,name=numbers,proto3" json:"numbers,omitempty"`
+	// meta is only set on HTTP responses to requests asking for the envelope
+	Meta *ResponseMeta `protobuf:"bytes,2,opt,name=meta,proto3" json:"meta,omitempty"`
+	// count and position are set instead of numbers on HTTP responses in the count mode
+	Count    *int64 `protobuf:"varint,3,opt,name=count,proto3,oneof" json:"count,omitempty"`
+	Position *int64 `protobuf:"varint,4,opt,name=position,proto3,oneof" json:"position,omitempty"`
+	// window is set with position instead of numbers on HTTP responses in the window
+	// mode
+	Window []int64 `protobuf:"varint,5,rep,packed,name=window,proto3" json:"window,omitempty"`
+	// id is the id of the row stored, empty when the storage keeps none
+	Id            string `protobuf:"bytes,6,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddNumberResponse) Reset() {
+	*x = AddNumberResponse{}
+	mi := &file_numbers_v1_numbers_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddNumberResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddNumberResponse) ProtoMessage() {}
+
+func (x *AddNumberResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_numbers_v1_numbers_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddNumberResponse.ProtoReflect.Descriptor instead.
+func (*AddNumberResponse) Descriptor() ([]byte, []int) {
+	return file_numbers_v1_numbers_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *AddNumberResponse) GetNumbers() []int64 {
+	if x != nil {
+		return x.Numbers
+	}
+	return nil
+}
+
+func (x *AddNumberResponse) GetMeta() *ResponseMeta {
+	if x != nil {
+		return x.Meta
+	}
+	return nil
+}
+
+func (x *AddNumberResponse) GetCount() int64 {
+	if x != nil && x.Count != nil {
+		return *x.Count
+	}
+	return 0
+}
+
+func (x *AddNumberResponse) GetPosition() int64 {
+	if x != nil && x.Position != nil {
+		return *x.Position
+	}
+	return 0
+}
+
+func (x *AddNumberResponse) GetWindow() []int64 {
+	if x != nil {
+		return x.Window
+	}
+	return nil
+}
+
+func (x *AddNumberResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+// ResponseMeta is the meta of the HTTP API envelope
+type ResponseMeta struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Total         int64                  `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`
+	Returned      int64                  `protobuf:"varint,2,opt,name=returned,proto3" json:"returned,omitempty"`
+	ElapsedMs     int64                  `protobuf:"varint,3,opt,name=elapsed_ms,json=elapsedMs,proto3" json:"elapsed_ms,omitempty"`
+	RequestId     string                 `protobuf:"bytes,4,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	NextCursor    string                 `protobuf:"bytes,5,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResponseMeta) Reset() {
+	*x = ResponseMeta{}
+	mi := &file_numbers_v1_numbers_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResponseMeta) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResponseMeta) ProtoMessage() {}
+
+func (x *ResponseMeta) ProtoReflect() protoreflect.Message {
+	mi := &file_numbers_v1_numbers_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResponseMeta.ProtoReflect.Descriptor instead.
+func (*ResponseMeta) Descriptor() ([]byte, []int) {
+	return file_numbers_v1_numbers_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ResponseMeta) GetTotal() int64 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *ResponseMeta) GetReturned() int64 {
+	if x != nil {
+		return x.Returned
+	}
+	return 0
+}
+
+func (x *ResponseMeta) GetElapsedMs() int64 {
+	if x != nil {
+		return x.ElapsedMs
+	}
+	return 0
+}
+
+func (x *ResponseMeta) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *ResponseMeta) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+type ListNumbersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListNumbersRequest) Reset() {
+	*x = ListNumbersRequest{}
+	mi := &file_numbers_v1_numbers_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListNumbersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListNumbersRequest) ProtoMessage() {}
+
+func (x *ListNumbersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_numbers_v1_numbers_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListNumbersRequest.ProtoReflect.Descriptor instead.
+func (*ListNumbersRequest) Descriptor() ([]byte, []int) {
+	return file_numbers_v1_numbers_proto_rawDescGZIP(), []int{4}
+}
+
+type ListNumbersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Numbers       []*Number              `protobuf:"bytes,1,rep,name=numbers,proto3" json:"numbers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListNumbersResponse) Reset() {
+	*x = ListNumbersResponse{}
+	mi := &file_numbers_v1_numbers_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListNumbersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListNumbersResponse) ProtoMessage() {}
+
+func (x *ListNumbersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_numbers_v1_numbers_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListNumbersResponse.ProtoReflect.Descriptor instead.
+func (*ListNumbersResponse) Descriptor() ([]byte, []int) {
+	return file_numbers_v1_numbers_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListNumbersResponse) GetNumbers() []*Number {
+	if x != nil {
+		return x.Numbers
+	}
+	return nil
+}
+
+type StreamNumbersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamNumbersRequest) Reset() {
+	*x = StreamNumbersRequest{}
+	mi := &file_numbers_v1_numbers_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamNumbersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamNumbersRequest) ProtoMessage() {}
+
+func (x *StreamNumbersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_numbers_v1_numbers_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamNumbersRequest.ProtoReflect.Descriptor instead.
+func (*StreamNumbersRequest) Descriptor() ([]byte, []int) {
+	return file_numbers_v1_numbers_proto_rawDescGZIP(), []int{6}
+}
+
+// NumbersPage is a page of GET /numbers sent as application/x-protobuf
+type NumbersPage struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Numbers []int64                `protobuf:"varint,1,rep,packed,name=numbers,proto3" json:"numbers,omitempty"`
+	// next_cursor is empty on the last page
+	NextCursor    string `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NumbersPage) Reset() {
+	*x = NumbersPage{}
+	mi := &file_numbers_v1_numbers_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NumbersPage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NumbersPage) ProtoMessage() {}
+
+func (x *NumbersPage) ProtoReflect() protoreflect.Message {
+	mi := &file_numbers_v1_numbers_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NumbersPage.ProtoReflect.Descriptor instead.
+func (*NumbersPage) Descriptor() ([]byte, []int) {
+	return file_numbers_v1_numbers_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *NumbersPage) GetNumbers() []int64 {
+	if x != nil {
+		return x.Numbers
+	}
+	return nil
+}
+
+func (x *NumbersPage) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+// ErrorResponse is the body of an HTTP API error sent as application/x-protobuf
+type ErrorResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Error         string                 `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ErrorResponse) Reset() {
+	*x = ErrorResponse{}
+	mi := &file_numbers_v1_numbers_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ErrorResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ErrorResponse) ProtoMessage() {}
+
+func (x *ErrorResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_numbers_v1_numbers_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ErrorResponse.ProtoReflect.Descriptor instead.
+func (*ErrorResponse) Descriptor() ([]byte, []int) {
+	return file_numbers_v1_numbers_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ErrorResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// BatchResult answers POST /numbers/batch in protobuf: how many numbers the batch
+// added and the first page of the stored numbers it spans
+type BatchResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Inserted      int64                  `protobuf:"varint,1,opt,name=inserted,proto3" json:"inserted,omitempty"`
+	Window        *NumbersPage           `protobuf:"bytes,2,opt,name=window,proto3" json:"window,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchResult) Reset() {
+	*x = BatchResult{}
+	mi := &file_numbers_v1_numbers_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchResult) ProtoMessage() {}
+
+func (x *BatchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_numbers_v1_numbers_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchResult.ProtoReflect.Descriptor instead.
+func (*BatchResult) Descriptor() ([]byte, []int) {
+	return file_numbers_v1_numbers_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *BatchResult) GetInserted() int64 {
+	if x != nil {
+		return x.Inserted
+	}
+	return 0
+}
+
+func (x *BatchResult) GetWindow() *NumbersPage {
+	if x != nil {
+		return x.Window
+	}
+	return nil
+}
+
+// NumberStats answers GET /numbers/stats in protobuf. min, max, mean, median and the
+// values of the percentiles are unset when no number is stored.
+type NumberStats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Count         int64                  `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"`
+	Min           *int64                 `protobuf:"varint,2,opt,name=min,proto3,oneof" json:"min,omitempty"`
+	Max           *int64                 `protobuf:"varint,3,opt,name=max,proto3,oneof" json:"max,omitempty"`
+	Sum           float64                `protobuf:"fixed64,4,opt,name=sum,proto3" json:"sum,omitempty"`
+	Mean          *float64               `protobuf:"fixed64,5,opt,name=mean,proto3,oneof" json:"mean,omitempty"`
+	Median        *float64               `protobuf:"fixed64,6,opt,name=median,proto3,oneof" json:"median,omitempty"`
+	Percentiles   []*Percentile          `protobuf:"bytes,7,rep,name=percentiles,proto3" json:"percentiles,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NumberStats) Reset() {
+	*x = NumberStats{}
+	mi := &file_numbers_v1_numbers_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NumberStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NumberStats) ProtoMessage() {}
+
+func (x *NumberStats) ProtoReflect() protoreflect.Message {
+	mi := &file_numbers_v1_numbers_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NumberStats.ProtoReflect.Descriptor instead.
+func (*NumberStats) Descriptor() ([]byte, []int) {
+	return file_numbers_v1_numbers_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *NumberStats) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *NumberStats) GetMin() int64 {
+	if x != nil && x.Min != nil {
+		return *x.Min
+	}
+	return 0
+}
+
+func (x *NumberStats) GetMax() int64 {
+	if x != nil && x.Max != nil {
+		return *x.Max
+	}
+	return 0
+}
+
+func (x *NumberStats) GetSum() float64 {
+	if x != nil {
+		return x.Sum
+	}
+	return 0
+}
+
+func (x *NumberStats) GetMean() float64 {
+	if x != nil && x.Mean != nil {
+		return *x.Mean
+	}
+	return 0
+}
+
+func (x *NumberStats) GetMedian() float64 {
+	if x != nil && x.Median != nil {
+		return *x.Median
+	}
+	return 0
+}
+
+func (x *NumberStats) GetPercentiles() []*Percentile {
+	if x != nil {
+		return x.Percentiles
+	}
+	return nil
+}
+
+// Percentile is the value at a percentile, from 0 to 100, of the stored numbers
+type Percentile struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Percentile    float64                `protobuf:"fixed64,1,opt,name=percentile,proto3" json:"percentile,omitempty"`
+	Value         *float64               `protobuf:"fixed64,2,opt,name=value,proto3,oneof" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Percentile) Reset() {
+	*x = Percentile{}
+	mi := &file_numbers_v1_numbers_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Percentile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Percentile) ProtoMessage() {}
+
+func (x *Percentile) ProtoReflect() protoreflect.Message {
+	mi := &file_numbers_v1_numbers_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Percentile.ProtoReflect.Descriptor instead.
+func (*Percentile) Descriptor() ([]byte, []int) {
+	return file_numbers_v1_numbers_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Percentile) GetPercentile() float64 {
+	if x != nil {
+		return x.Percentile
+	}
+	return 0
+}
+
+func (x *Percentile) GetValue() float64 {
+	if x != nil && x.Value != nil {
+		return *x.Value
+	}
+	return 0
+}
+
+var File_numbers_v1_numbers_proto protoreflect.FileDescriptor
+
+const file_numbers_v1_numbers_proto_rawDesc = "" +
+	"\n" +
+	"\x18numbers/v1/numbers.proto\x12\n" +
+	"numbers.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"k\n" +
+	"\x06Number\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06number\x18\x02 \x01(\x03R\x06number\x129\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"*\n" +
+	"\x10AddNumberRequest\x12\x16\n" +
+	"\x06number\x18\x01 \x01(\x03R\x06number\"\xd6\x01\n" +
+	"\x11AddNumberResponse\x12\x18\n" +
+	"\anumbers\x18\x01 \x03(\x03R\anumbers\x12,\n" +
+	"\x04meta\x18\x02 \x01(\v2\x18.numbers.v1.ResponseMetaR\x04meta\x12\x19\n" +
+	"\x05count\x18\x03 \x01(\x03H\x00R\x05count\x88\x01\x01\x12\x1f\n" +
+	"\bposition\x18\x04 \x01(\x03H\x01R\bposition\x88\x01\x01\x12\x16\n" +
+	"\x06window\x18\x05 \x03(\x03R\x06window\x12\x0e\n" +
+	"\x02id\x18\x06 \x01(\tR\x02idB\b\n" +
+	"\x06_countB\v\n" +
+	"\t_position\"\x9f\x01\n" +
+	"\fResponseMeta\x12\x14\n" +
+	"\x05total\x18\x01 \x01(\x03R\x05total\x12\x1a\n" +
+	"\breturned\x18\x02 \x01(\x03R\breturned\x12\x1d\n" +
+	"\n" +
+	"elapsed_ms\x18\x03 \x01(\x03R\telapsedMs\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x04 \x01(\tR\trequestId\x12\x1f\n" +
+	"\vnext_cursor\x18\x05 \x01(\tR\n" +
+	"nextCursor\"\x14\n" +
+	"\x12ListNumbersRequest\"C\n" +
+	"\x13ListNumbersResponse\x12,\n" +
+	"\anumbers\x18\x01 \x03(\v2\x12.numbers.v1.NumberR\anumbers\"\x16\n" +
+	"\x14StreamNumbersRequest\"H\n" +
+	"\vNumbersPage\x12\x18\n" +
+	"\anumbers\x18\x01 \x03(\x03R\anumbers\x12\x1f\n" +
+	"\vnext_cursor\x18\x02 \x01(\tR\n" +
+	"nextCursor\"%\n" +
+	"\rErrorResponse\x12\x14\n" +
+	"\x05error\x18\x01 \x01(\tR\x05error\"Z\n" +
+	"\vBatchResult\x12\x1a\n" +
+	"\binserted\x18\x01 \x01(\x03R\binserted\x12/\n" +
+	"\x06window\x18\x02 \x01(\v2\x17.numbers.v1.NumbersPageR\x06window\"\xf7\x01\n" +
+	"\vNumberStats\x12\x14\n" +
+	"\x05count\x18\x01 \x01(\x03R\x05count\x12\x15\n" +
+	"\x03min\x18\x02 \x01(\x03H\x00R\x03min\x88\x01\x01\x12\x15\n" +
+	"\x03max\x18\x03 \x01(\x03H\x01R\x03max\x88\x01\x01\x12\x10\n" +
+	"\x03sum\x18\x04 \x01(\x01R\x03sum\x12\x17\n" +
+	"\x04mean\x18\x05 \x01(\x01H\x02R\x04mean\x88\x01\x01\x12\x1b\n" +
+	"\x06median\x18\x06 \x01(\x01H\x03R\x06median\x88\x01\x01\x128\n" +
+	"\vpercentiles\x18\a \x03(\v2\x16.numbers.v1.PercentileR\vpercentilesB\x06\n" +
+	"\x04_minB\x06\n" +
+	"\x04_maxB\a\n" +
+	"\x05_meanB\t\n" +
+	"\a_median\"Q\n" +
+	"\n" +
+	"Percentile\x12\x1e\n" +
+	"\n" +
+	"percentile\x18\x01 \x01(\x01R\n" +
+	"percentile\x12\x19\n" +
+	"\x05value\x18\x02 \x01(\x01H\x00R\x05value\x88\x01\x01B\b\n" +
+	"\x06_value2\xf3\x01\n" +
+	"\x0eNumbersService\x12H\n" +
+	"\tAddNumber\x12\x1c.numbers.v1.AddNumberRequest\x1a\x1d.numbers.v1.AddNumberResponse\x12N\n" +
+	"\vListNumbers\x12\x1e.numbers.v1.ListNumbersRequest\x1a\x1f.numbers.v1.ListNumbersResponse\x12G\n" +
+	"\rStreamNumbers\x12 .numbers.v1.StreamNumbersRequest\x1a\x12.numbers.v1.Number0\x01B\x1cZ\x1agolang-test-task/numberspbb\x06proto3"
+
+var (
+	file_numbers_v1_numbers_proto_rawDescOnce sync.Once
+	file_numbers_v1_numbers_proto_rawDescData []byte
+)
+
+func file_numbers_v1_numbers_proto_rawDescGZIP() []byte {
+	file_numbers_v1_numbers_proto_rawDescOnce.Do(func() {
+		file_numbers_v1_numbers_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_numbers_v1_numbers_proto_rawDesc), len(file_numbers_v1_numbers_proto_rawDesc)))
+	})
+	return file_numbers_v1_numbers_proto_rawDescData
+}
+
+var file_numbers_v1_numbers_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_numbers_v1_numbers_proto_goTypes = []any{
+	(*Number)(nil),                // 0: numbers.v1.Number
+	(*AddNumberRequest)(nil),      // 1: numbers.v1.AddNumberRequest
+	(*AddNumberResponse)(nil),     // 2: numbers.v1.AddNumberResponse
+	(*ResponseMeta)(nil),          // 3: numbers.v1.ResponseMeta
+	(*ListNumbersRequest)(nil),    // 4: numbers.v1.ListNumbersRequest
+	(*ListNumbersResponse)(nil),   // 5: numbers.v1.ListNumbersResponse
+	(*StreamNumbersRequest)(nil),  // 6: numbers.v1.StreamNumbersRequest
+	(*NumbersPage)(nil),           // 7: numbers.v1.NumbersPage
+	(*ErrorResponse)(nil),         // 8: numbers.v1.ErrorResponse
+	(*BatchResult)(nil),           // 9: numbers.v1.BatchResult
+	(*NumberStats)(nil),           // 10: numbers.v1.NumberStats
+	(*Percentile)(nil),            // 11: numbers.v1.Percentile
+	(*timestamppb.Timestamp)(nil), // 12: google.protobuf.Timestamp
+}
+var file_numbers_v1_numbers_proto_depIdxs = []int32{
+	12, // 0: numbers.v1.Number.created_at:type_name -> google.protobuf.Timestamp
+	3,  // 1: numbers.v1.AddNumberResponse.meta:type_name -> numbers.v1.ResponseMeta
+	0,  // 2: numbers.v1.ListNumbersResponse.numbers:type_name -> numbers.v1.Number
+	7,  // 3: numbers.v1.BatchResult.window:type_name -> numbers.v1.NumbersPage
+	11, // 4: numbers.v1.NumberStats.percentiles:type_name -> numbers.v1.Percentile
+	1,  // 5: numbers.v1.NumbersService.AddNumber:input_type -> numbers.v1.AddNumberRequest
+	4,  // 6: numbers.v1.NumbersService.ListNumbers:input_type -> numbers.v1.ListNumbersRequest
+	6,  // 7: numbers.v1.NumbersService.StreamNumbers:input_type -> numbers.v1.StreamNumbersRequest
+	2,  // 8: numbers.v1.NumbersService.AddNumber:output_type -> numbers.v1.AddNumberResponse
+	5,  // 9: numbers.v1.NumbersService.ListNumbers:output_type -> numbers.v1.ListNumbersResponse
+	0,  // 10: numbers.v1.NumbersService.StreamNumbers:output_type -> numbers.v1.Number
+	8,  // [8:11] is the sub-list for method output_type
+	5,  // [5:8] is the sub-list for method input_type
+	5,  // [5:5] is the sub-list for extension type_name
+	5,  // [5:5] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_numbers_v1_numbers_proto_init() }
+func file_numbers_v1_numbers_proto_init() {
+	if File_numbers_v1_numbers_proto != nil {
+		return
+	}
+	file_numbers_v1_numbers_proto_msgTypes[2].OneofWrappers = []any{}
+	file_numbers_v1_numbers_proto_msgTypes[10].OneofWrappers = []any{}
+	file_numbers_v1_numbers_proto_msgTypes[11].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_numbers_v1_numbers_proto_rawDesc), len(file_numbers_v1_numbers_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_numbers_v1_numbers_proto_goTypes,
+		DependencyIndexes: file_numbers_v1_numbers_proto_depIdxs,
+		MessageInfos:      file_numbers_v1_numbers_proto_msgTypes,
+	}.Build()
+	File_numbers_v1_numbers_proto = out.File
+	file_numbers_v1_numbers_proto_goTypes = nil
+	file_numbers_v1_numbers_proto_depIdxs = nil
+}
@@ -0,0 +1,211 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: numbers/v1/numbers.proto
+
+package numberspb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	NumbersService_AddNumber_FullMethodName     = "/numbers.v1.NumbersService/AddNumber"
+	NumbersService_ListNumbers_FullMethodName   = "/numbers.v1.NumbersService/ListNumbers"
+	NumbersService_StreamNumbers_FullMethodName = "/numbers.v1.NumbersService/StreamNumbers"
+)
+
+// NumbersServiceClient is the client API for NumbersService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// NumbersService is the gRPC counterpart of the HTTP API, over the same storage
+type NumbersServiceClient interface {
+	// AddNumber stores a number and returns every stored number in ascending order
+	AddNumber(ctx context.Context, in *AddNumberRequest, opts ...grpc.CallOption) (*AddNumberResponse, error)
+	// ListNumbers returns every stored number in ascending order
+	ListNumbers(ctx context.Context, in *ListNumbersRequest, opts ...grpc.CallOption) (*ListNumbersResponse, error)
+	// StreamNumbers sends every stored number in ascending order, one message each
+	StreamNumbers(ctx context.Context, in *StreamNumbersRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Number], error)
+}
+
+type numbersServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewNumbersServiceClient(cc grpc.ClientConnInterface) NumbersServiceClient {
+	return &numbersServiceClient{cc}
+}
+
+func (c *numbersServiceClient) AddNumber(ctx context.Context, in *AddNumberRequest, opts ...grpc.CallOption) (*AddNumberResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddNumberResponse)
+	err := c.cc.Invoke(ctx, NumbersService_AddNumber_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *numbersServiceClient) ListNumbers(ctx context.Context, in *ListNumbersRequest, opts ...grpc.CallOption) (*ListNumbersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListNumbersResponse)
+	err := c.cc.Invoke(ctx, NumbersService_ListNumbers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *numbersServiceClient) StreamNumbers(ctx context.Context, in *StreamNumbersRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Number], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &NumbersService_ServiceDesc.Streams[0], NumbersService_StreamNumbers_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamNumbersRequest, Number]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type NumbersService_StreamNumbersClient = grpc.ServerStreamingClient[Number]
+
+// NumbersServiceServer is the server API for NumbersService service.
+// All implementations must embed UnimplementedNumbersServiceServer
+// for forward compatibility.
+//
+// NumbersService is the gRPC counterpart of the HTTP API, over the same storage
+type NumbersServiceServer interface {
+	// AddNumber stores a number and returns every stored number in ascending order
+	AddNumber(context.Context, *AddNumberRequest) (*AddNumberResponse, error)
+	// ListNumbers returns every stored number in ascending order
+	ListNumbers(context.Context, *ListNumbersRequest) (*ListNumbersResponse, error)
+	// StreamNumbers sends every stored number in ascending order, one message each
+	StreamNumbers(*StreamNumbersRequest, grpc.ServerStreamingServer[Number]) error
+	mustEmbedUnimplementedNumbersServiceServer()
+}
+
+// UnimplementedNumbersServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedNumbersServiceServer struct{}
+
+func (UnimplementedNumbersServiceServer) AddNumber(context.Context, *AddNumberRequest) (*AddNumberResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddNumber not implemented")
+}
+func (UnimplementedNumbersServiceServer) ListNumbers(context.Context, *ListNumbersRequest) (*ListNumbersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListNumbers not implemented")
+}
+func (UnimplementedNumbersServiceServer) StreamNumbers(*StreamNumbersRequest, grpc.ServerStreamingServer[Number]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamNumbers not implemented")
+}
+func (UnimplementedNumbersServiceServer) mustEmbedUnimplementedNumbersServiceServer() {}
+func (UnimplementedNumbersServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeNumbersServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to NumbersServiceServer will
+// result in compilation errors.
+type UnsafeNumbersServiceServer interface {
+	mustEmbedUnimplementedNumbersServiceServer()
+}
+
+func RegisterNumbersServiceServer(s grpc.ServiceRegistrar, srv NumbersServiceServer) {
+	// If the following call pancis, it indicates UnimplementedNumbersServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&NumbersService_ServiceDesc, srv)
+}
+
+func _NumbersService_AddNumber_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddNumberRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NumbersServiceServer).AddNumber(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NumbersService_AddNumber_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NumbersServiceServer).AddNumber(ctx, req.(*AddNumberRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NumbersService_ListNumbers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListNumbersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NumbersServiceServer).ListNumbers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NumbersService_ListNumbers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NumbersServiceServer).ListNumbers(ctx, req.(*ListNumbersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NumbersService_StreamNumbers_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamNumbersRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(NumbersServiceServer).StreamNumbers(m, &grpc.GenericServerStream[StreamNumbersRequest, Number]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type NumbersService_StreamNumbersServer = grpc.ServerStreamingServer[Number]
+
+// NumbersService_ServiceDesc is the grpc.ServiceDesc for NumbersService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var NumbersService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "numbers.v1.NumbersService",
+	HandlerType: (*NumbersServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AddNumber",
+			Handler:    _NumbersService_AddNumber_Handler,
+		},
+		{
+			MethodName: "ListNumbers",
+			Handler:    _NumbersService_ListNumbers_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamNumbers",
+			Handler:       _NumbersService_StreamNumbers_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "numbers/v1/numbers.proto",
+}
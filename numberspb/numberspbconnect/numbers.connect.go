@@ -0,0 +1,181 @@
+// Code generated by protoc-gen-connect-go. DO NOT EDIT.
+//
+// Source: numbers/v1/numbers.proto
+
+package numberspbconnect
+
+import (
+	connect "connectrpc.com/connect"
+	context "context"
+	errors "errors"
+	numberspb "golang-test-task/numberspb"
+	http "net/http"
+	strings "strings"
+)
+
+// This is a compile-time assertion to ensure that this generated file and the connect package are
+// compatible. If you get a compiler error that this constant is not defined, this code was
+// generated with a version of connect newer than the one compiled into your binary. You can fix the
+// problem by either regenerating this code with an older version of connect or updating the connect
+// version compiled into your binary.
+const _ = connect.IsAtLeastVersion1_13_0
+
+const (
+	// NumbersServiceName is the fully-qualified name of the NumbersService service.
+	NumbersServiceName = "numbers.v1.NumbersService"
+)
+
+// These constants are the fully-qualified names of the RPCs defined in this package. They're
+// exposed at runtime as Spec.Procedure and as the final two segments of the HTTP route.
+//
+// Note that these are different from the fully-qualified method names used by
+// google.golang.org/protobuf/reflect/protoreflect. To convert from these constants to
+// reflection-formatted method names, remove the leading slash and convert the remaining slash to a
+// period.
+const (
+	// NumbersServiceAddNumberProcedure is the fully-qualified name of the NumbersService's AddNumber
+	// RPC.
+	NumbersServiceAddNumberProcedure = "/numbers.v1.NumbersService/AddNumber"
+	// NumbersServiceListNumbersProcedure is the fully-qualified name of the NumbersService's
+	// ListNumbers RPC.
+	NumbersServiceListNumbersProcedure = "/numbers.v1.NumbersService/ListNumbers"
+	// NumbersServiceStreamNumbersProcedure is the fully-qualified name of the NumbersService's
+	// StreamNumbers RPC.
+	NumbersServiceStreamNumbersProcedure = "/numbers.v1.NumbersService/StreamNumbers"
+)
+
+// NumbersServiceClient is a client for the numbers.v1.NumbersService service.
+type NumbersServiceClient interface {
+	// AddNumber stores a number and returns every stored number in ascending order
+	AddNumber(context.Context, *numberspb.AddNumberRequest) (*numberspb.AddNumberResponse, error)
+	// ListNumbers returns every stored number in ascending order
+	ListNumbers(context.Context, *numberspb.ListNumbersRequest) (*numberspb.ListNumbersResponse, error)
+	// StreamNumbers sends every stored number in ascending order, one message each
+	StreamNumbers(context.Context, *numberspb.StreamNumbersRequest) (*connect.ServerStreamForClient[numberspb.Number], error)
+}
+
+// NewNumbersServiceClient constructs a client for the numbers.v1.NumbersService service. By
+// default, it uses the Connect protocol with the binary Protobuf Codec, asks for gzipped responses,
+// and sends uncompressed requests. To use the gRPC or gRPC-Web protocols, supply the
+// connect.WithGRPC() or connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewNumbersServiceClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) NumbersServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	numbersServiceMethods := numberspb.File_numbers_v1_numbers_proto.Services().ByName("NumbersService").Methods()
+	return &numbersServiceClient{
+		addNumber: connect.NewClient[numberspb.AddNumberRequest, numberspb.AddNumberResponse](
+			httpClient,
+			baseURL+NumbersServiceAddNumberProcedure,
+			connect.WithSchema(numbersServiceMethods.ByName("AddNumber")),
+			connect.WithClientOptions(opts...),
+		),
+		listNumbers: connect.NewClient[numberspb.ListNumbersRequest, numberspb.ListNumbersResponse](
+			httpClient,
+			baseURL+NumbersServiceListNumbersProcedure,
+			connect.WithSchema(numbersServiceMethods.ByName("ListNumbers")),
+			connect.WithClientOptions(opts...),
+		),
+		streamNumbers: connect.NewClient[numberspb.StreamNumbersRequest, numberspb.Number](
+			httpClient,
+			baseURL+NumbersServiceStreamNumbersProcedure,
+			connect.WithSchema(numbersServiceMethods.ByName("StreamNumbers")),
+			connect.WithClientOptions(opts...),
+		),
+	}
+}
+
+// numbersServiceClient implements NumbersServiceClient.
+type numbersServiceClient struct {
+	addNumber     *connect.Client[numberspb.AddNumberRequest, numberspb.AddNumberResponse]
+	listNumbers   *connect.Client[numberspb.ListNumbersRequest, numberspb.ListNumbersResponse]
+	streamNumbers *connect.Client[numberspb.StreamNumbersRequest, numberspb.Number]
+}
+
+// AddNumber calls numbers.v1.NumbersService.AddNumber.
+func (c *numbersServiceClient) AddNumber(ctx context.Context, req *numberspb.AddNumberRequest) (*numberspb.AddNumberResponse, error) {
+	response, err := c.addNumber.CallUnary(ctx, connect.NewRequest(req))
+	if response != nil {
+		return response.Msg, err
+	}
+	return nil, err
+}
+
+// ListNumbers calls numbers.v1.NumbersService.ListNumbers.
+func (c *numbersServiceClient) ListNumbers(ctx context.Context, req *numberspb.ListNumbersRequest) (*numberspb.ListNumbersResponse, error) {
+	response, err := c.listNumbers.CallUnary(ctx, connect.NewRequest(req))
+	if response != nil {
+		return response.Msg, err
+	}
+	return nil, err
+}
+
+// StreamNumbers calls numbers.v1.NumbersService.StreamNumbers.
+func (c *numbersServiceClient) StreamNumbers(ctx context.Context, req *numberspb.StreamNumbersRequest) (*connect.ServerStreamForClient[numberspb.Number], error) {
+	return c.streamNumbers.CallServerStream(ctx, connect.NewRequest(req))
+}
+
+// NumbersServiceHandler is an implementation of the numbers.v1.NumbersService service.
+type NumbersServiceHandler interface {
+	// AddNumber stores a number and returns every stored number in ascending order
+	AddNumber(context.Context, *numberspb.AddNumberRequest) (*numberspb.AddNumberResponse, error)
+	// ListNumbers returns every stored number in ascending order
+	ListNumbers(context.Context, *numberspb.ListNumbersRequest) (*numberspb.ListNumbersResponse, error)
+	// StreamNumbers sends every stored number in ascending order, one message each
+	StreamNumbers(context.Context, *numberspb.StreamNumbersRequest, *connect.ServerStream[numberspb.Number]) error
+}
+
+// NewNumbersServiceHandler builds an HTTP handler from the service implementation. It returns the
+// path on which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewNumbersServiceHandler(svc NumbersServiceHandler, opts ...connect.HandlerOption) (string, http.Handler) {
+	numbersServiceMethods := numberspb.File_numbers_v1_numbers_proto.Services().ByName("NumbersService").Methods()
+	numbersServiceAddNumberHandler := connect.NewUnaryHandlerSimple(
+		NumbersServiceAddNumberProcedure,
+		svc.AddNumber,
+		connect.WithSchema(numbersServiceMethods.ByName("AddNumber")),
+		connect.WithHandlerOptions(opts...),
+	)
+	numbersServiceListNumbersHandler := connect.NewUnaryHandlerSimple(
+		NumbersServiceListNumbersProcedure,
+		svc.ListNumbers,
+		connect.WithSchema(numbersServiceMethods.ByName("ListNumbers")),
+		connect.WithHandlerOptions(opts...),
+	)
+	numbersServiceStreamNumbersHandler := connect.NewServerStreamHandlerSimple(
+		NumbersServiceStreamNumbersProcedure,
+		svc.StreamNumbers,
+		connect.WithSchema(numbersServiceMethods.ByName("StreamNumbers")),
+		connect.WithHandlerOptions(opts...),
+	)
+	return "/numbers.v1.NumbersService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case NumbersServiceAddNumberProcedure:
+			numbersServiceAddNumberHandler.ServeHTTP(w, r)
+		case NumbersServiceListNumbersProcedure:
+			numbersServiceListNumbersHandler.ServeHTTP(w, r)
+		case NumbersServiceStreamNumbersProcedure:
+			numbersServiceStreamNumbersHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedNumbersServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedNumbersServiceHandler struct{}
+
+func (UnimplementedNumbersServiceHandler) AddNumber(context.Context, *numberspb.AddNumberRequest) (*numberspb.AddNumberResponse, error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("numbers.v1.NumbersService.AddNumber is not implemented"))
+}
+
+func (UnimplementedNumbersServiceHandler) ListNumbers(context.Context, *numberspb.ListNumbersRequest) (*numberspb.ListNumbersResponse, error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("numbers.v1.NumbersService.ListNumbers is not implemented"))
+}
+
+func (UnimplementedNumbersServiceHandler) StreamNumbers(context.Context, *numberspb.StreamNumbersRequest, *connect.ServerStream[numberspb.Number]) error {
+	return connect.NewError(connect.CodeUnimplemented, errors.New("numbers.v1.NumbersService.StreamNumbers is not implemented"))
+}
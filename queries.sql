@@ -1,9 +1,91 @@
 -- name: InsertNumber :one
 INSERT INTO numbers (number)
 VALUES ($1)
-RETURNING id, number;
+RETURNING id, number, created_at;
 
 -- name: GetAllNumbersSorted :many
-SELECT id, number
+SELECT id, number, created_at
 FROM numbers
 ORDER BY number ASC;
+
+-- name: ListNumbersPage :many
+-- Rows are ordered by number, then id, so that a page can start right after the
+-- last row of the one before even among equal numbers
+SELECT id, number, created_at
+FROM numbers
+WHERE number BETWEEN sqlc.arg(min_number)::bigint AND sqlc.arg(max_number)::bigint
+  AND (sqlc.narg(after_number)::bigint IS NULL OR (number, id) > (sqlc.narg(after_number)::bigint, sqlc.narg(after_id)::uuid))
+ORDER BY number ASC, id ASC
+LIMIT sqlc.arg(page_limit);
+
+-- name: CountNumbers :one
+-- count(*) reads every row, or every entry of the index on number when the visibility
+-- map lets it, so it takes as long as the table is large
+SELECT count(*) FROM numbers;
+
+-- name: RankNumber :one
+-- How many numbers are smaller than number, which is where it stands in the sorted
+-- list. A range scan of the index on number up to it, so it grows with the rank.
+SELECT count(*) FROM numbers
+WHERE number < sqlc.arg(number)::bigint;
+
+-- name: RankRow :one
+-- How many rows come before the row of number and id in the order of
+-- ListNumbersPage, which is where it stands among equal numbers too. A range scan of
+-- the (number, id) index up to it, so it grows with the rank.
+SELECT count(*) FROM numbers
+WHERE (number, id) < (sqlc.arg(number)::bigint, sqlc.arg(id)::uuid);
+
+-- name: ContainsNumber :one
+-- One probe of the index on number
+SELECT EXISTS (SELECT 1 FROM numbers WHERE number = sqlc.arg(number)::bigint);
+
+-- name: InsertNumbers :many
+-- The numbers are stored by one statement, so either all of them are or none is
+INSERT INTO numbers (number)
+SELECT unnest(sqlc.arg(numbers)::bigint[])
+RETURNING id, number, created_at;
+
+-- name: NumberWindow :many
+-- The row of number and id with up to size rows on each side of it, in the order of
+-- ListNumbersPage. Each side is one range scan of the (number, id) index, however
+-- large the table is.
+SELECT id, number, created_at
+FROM (
+    (SELECT id, number, created_at
+     FROM numbers
+     WHERE (number, id) < (sqlc.arg(number)::bigint, sqlc.arg(id)::uuid)
+     ORDER BY number DESC, id DESC
+     LIMIT sqlc.arg(size)::int)
+    UNION ALL
+    (SELECT id, number, created_at
+     FROM numbers
+     WHERE (number, id) >= (sqlc.arg(number)::bigint, sqlc.arg(id)::uuid)
+     ORDER BY number ASC, id ASC
+     LIMIT sqlc.arg(size)::int + 1)
+) AS window_rows
+ORDER BY number ASC, id ASC;
+
+-- name: DeleteNumber :one
+DELETE FROM numbers
+WHERE id = $1
+RETURNING id, number, created_at;
+
+-- name: DeleteAllNumbers :exec
+-- TRUNCATE rather than DELETE hands the space of the table back at once and leaves no
+-- dead rows for vacuum, at the cost of an exclusive lock for as long as it takes
+TRUNCATE numbers;
+
+-- name: NumberStats :one
+-- Every aggregate is computed by the database in one scan of the table, so no row
+-- leaves it. An empty table has no min, max or percentiles, so they are coalesced
+-- and count tells them apart. The sum of bigints is summed exactly as a numeric and
+-- only rounded when it is cast, so it is exact up to 2^53.
+SELECT count(*) AS count,
+       coalesce(min(number), 0)::bigint AS min,
+       coalesce(max(number), 0)::bigint AS max,
+       coalesce(sum(number), 0)::float8 AS sum,
+       coalesce(avg(number), 0)::float8 AS mean,
+       coalesce(percentile_cont(0.5) WITHIN GROUP (ORDER BY number), 0)::float8 AS median,
+       coalesce(percentile_cont(sqlc.arg(fractions)::float8[]) WITHIN GROUP (ORDER BY number), '{}')::float8[] AS percentiles
+FROM numbers;
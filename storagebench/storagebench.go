@@ -0,0 +1,151 @@
+// Package storagebench runs the same workload against storage backends, through the
+// sqlc.Querier interface the server uses, to compare their throughput and latency.
+package storagebench
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"golang-test-task/datagen"
+	"golang-test-task/internal/storage/sqlc"
+	"golang-test-task/loadtest"
+)
+
+// Config is the workload
+type Config struct {
+	// Writes is how many numbers are inserted
+	Writes int
+	// Reads is how many times the full sorted list is read once the writes are done
+	Reads int
+	// Concurrency is how many operations run at once
+	Concurrency int
+	// Values generates the inserted numbers
+	Values datagen.Config
+}
+
+// DefaultConfig inserts ten thousand uniform numbers and reads them back a hundred times
+func DefaultConfig() Config {
+	return Config{
+		Writes:      10_000,
+		Reads:       100,
+		Concurrency: 8,
+		Values:      datagen.DefaultConfig(),
+	}
+}
+
+// Result is the outcome of the workload on one backend
+type Result struct {
+	Backend string
+	Writes  Phase
+	Reads   Phase
+}
+
+// Phase is the outcome of the writes or the reads
+type Phase struct {
+	Elapsed time.Duration
+	loadtest.Stats
+}
+
+// Throughput is operations per second
+func (p Phase) Throughput() float64 {
+	if p.Elapsed <= 0 {
+		return 0
+	}
+
+	return float64(p.Requests) / p.Elapsed.Seconds()
+}
+
+// Run inserts cfg.Writes numbers into q, then reads them back cfg.Reads times. It
+// returns the numbers it inserted, so that a persistent backend can be cleaned up.
+func Run(ctx context.Context, backend string, q sqlc.Querier, cfg Config) (Result, []sqlc.Number, error) {
+	g, err := datagen.New(cfg.Values)
+	if err != nil {
+		return Result{}, nil, fmt.Errorf("invalid values config: %w", err)
+	}
+	values := g.Take(cfg.Writes)
+
+	var (
+		mu       sync.Mutex
+		inserted []sqlc.Number
+	)
+	writes := phase(ctx, cfg.Writes, cfg.Concurrency, func(i int) error {
+		row, err := q.InsertNumber(ctx, values[i])
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		inserted = append(inserted, row)
+		mu.Unlock()
+		return nil
+	})
+	reads := phase(ctx, cfg.Reads, cfg.Concurrency, func(int) error {
+		_, err := q.GetAllNumbersSorted(ctx)
+		return err
+	})
+
+	return Result{Backend: backend, Writes: writes, Reads: reads}, inserted, ctx.Err()
+}
+
+// phase runs op n times, concurrency at a time, and times every call
+func phase(ctx context.Context, n, concurrency int, op func(i int) error) Phase {
+	latencies := make([]time.Duration, 0, n)
+	errors := 0
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	inFlight := make(chan struct{}, max(concurrency, 1))
+
+	start := time.Now()
+	for i := range n {
+		if ctx.Err() != nil {
+			break
+		}
+		inFlight <- struct{}{}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-inFlight }()
+
+			began := time.Now()
+			err := op(i)
+			latency := time.Since(began)
+
+			mu.Lock()
+			latencies = append(latencies, latency)
+			if err != nil {
+				errors++
+			}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	return Phase{Elapsed: time.Since(start), Stats: loadtest.NewStats(latencies, errors)}
+}
+
+// WriteTable renders results side by side, one row per backend and phase
+func WriteTable(w io.Writer, results []Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+
+	fmt.Fprintln(tw, "backend\top\tops\terrors\tops/s\tp50\tp90\tp99\tmax\t")
+	for _, r := range results {
+		for _, p := range []struct {
+			op string
+			Phase
+		}{{"write", r.Writes}, {"read", r.Reads}} {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%.0f\t%s\t%s\t%s\t%s\t\n",
+				r.Backend, p.op, p.Requests, p.Errors, p.Throughput(),
+				p.P50.Round(time.Microsecond), p.P90.Round(time.Microsecond),
+				p.P99.Round(time.Microsecond), p.Max.Round(time.Microsecond))
+		}
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,65 @@
+package storagebench
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"golang-test-task/internal/storage/memstore"
+	"golang-test-task/internal/storage/sqlc"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Writes, cfg.Reads = 200, 10
+
+	store := memstore.New()
+	result, inserted, err := Run(context.Background(), "memory", store, cfg)
+	require.NoError(t, err)
+
+	assert.Equal(t, "memory", result.Backend)
+	assert.Equal(t, 200, result.Writes.Requests)
+	assert.Equal(t, 10, result.Reads.Requests)
+	assert.Zero(t, result.Writes.Errors+result.Reads.Errors)
+	assert.Positive(t, result.Writes.Throughput())
+	assert.LessOrEqual(t, result.Writes.P50, result.Writes.Max)
+	assert.Len(t, inserted, 200)
+
+	all, err := store.GetAllNumbersSorted(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, all, 200)
+}
+
+// failingStore fails every insert
+type failingStore struct{ sqlc.Querier }
+
+func (failingStore) InsertNumber(context.Context, int64) (sqlc.Number, error) {
+	return sqlc.Number{}, errors.New("connection refused")
+}
+
+func (failingStore) GetAllNumbersSorted(context.Context) ([]sqlc.Number, error) {
+	return nil, nil
+}
+
+func TestRun_CountsErrors(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Writes, cfg.Reads = 20, 1
+
+	result, inserted, err := Run(context.Background(), "broken", failingStore{}, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, 20, result.Writes.Errors)
+	assert.Empty(t, inserted)
+}
+
+func TestWriteTable(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, WriteTable(&out, []Result{{Backend: "memory"}, {Backend: "postgres"}}))
+
+	lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+	assert.Len(t, lines, 5, "a header and a write and read row per backend")
+	assert.Contains(t, out.String(), "ops/s")
+}
@@ -0,0 +1,77 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"golang-test-task/backup"
+	"golang-test-task/testutil"
+	"golang-test-task/testutil/seed"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackup_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	source := testutil.StartEnv(t)
+	seed.Numbers(t, source.Pool, 5, -3, 5, 12)
+
+	want, err := source.Queries.GetAllNumbersSorted(ctx)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	n, err := backup.Write(ctx, source.Pool, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), n)
+
+	target := testutil.StartEnv(t)
+	n, err = backup.Restore(ctx, target.Pool, bytes.NewReader(buf.Bytes()), false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), n)
+
+	got, err := target.Queries.GetAllNumbersSorted(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, want, got, "ids, numbers and creation times are restored")
+}
+
+func TestBackup_RestoreRefusesNonEmptyTable(t *testing.T) {
+	ctx := context.Background()
+	env := testutil.StartEnv(t)
+	seed.Numbers(t, env.Pool, 1, 2)
+
+	var buf bytes.Buffer
+	_, err := backup.Write(ctx, env.Pool, &buf)
+	require.NoError(t, err)
+	seed.Numbers(t, env.Pool, 3)
+
+	_, err = backup.Restore(ctx, env.Pool, bytes.NewReader(buf.Bytes()), false)
+	assert.ErrorIs(t, err, backup.ErrNotEmpty)
+
+	n, err := backup.Restore(ctx, env.Pool, bytes.NewReader(buf.Bytes()), true)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+
+	got, err := env.Queries.GetAllNumbersSorted(ctx)
+	require.NoError(t, err)
+	assert.Len(t, got, 2, "replace drops the rows added after the backup")
+}
+
+func TestBackup_FailedRestoreChangesNothing(t *testing.T) {
+	ctx := context.Background()
+	env := testutil.StartEnv(t)
+	seed.Numbers(t, env.Pool, 1)
+
+	var buf bytes.Buffer
+	_, err := backup.Write(ctx, env.Pool, &buf)
+	require.NoError(t, err)
+	truncated := buf.Bytes()[:buf.Len()-8]
+
+	_, err = backup.Restore(ctx, env.Pool, bytes.NewReader(truncated), true)
+	assert.Error(t, err)
+
+	got, err := env.Queries.GetAllNumbersSorted(ctx)
+	require.NoError(t, err)
+	assert.Len(t, got, 1, "the truncate is rolled back")
+}
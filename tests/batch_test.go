@@ -0,0 +1,35 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	api "golang-test-task/api"
+	"golang-test-task/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAddNumberBatch_SingleRequest tests that 500 numbers are stored by one request
+// and answered with the window they span
+func TestAddNumberBatch_SingleRequest(t *testing.T) {
+	env := testutil.StartEnv(t)
+	ctx := context.Background()
+
+	numbers := make(api.Numbers, 500)
+	for i := range numbers {
+		numbers[i] = int64(500 - i)
+	}
+	resp, err := env.Client.AddNumberBatchWithResponse(ctx, numbers)
+	require.NoError(t, err)
+	require.NotNil(t, resp.JSON200, "status %d: %s", resp.StatusCode(), resp.Body)
+	assert.Equal(t, int64(500), resp.JSON200.Inserted)
+	assert.Len(t, resp.JSON200.Window.Numbers, 100)
+	assert.Equal(t, int64(1), resp.JSON200.Window.Numbers[0])
+	assert.NotNil(t, resp.JSON200.Window.NextCursor)
+
+	var count int64
+	require.NoError(t, env.Pool.QueryRow(ctx, "select count(*) from numbers").Scan(&count))
+	assert.Equal(t, int64(500), count)
+}
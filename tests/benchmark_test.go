@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"golang-test-task/datagen"
+	"golang-test-task/internal/storage"
+	"golang-test-task/testutil"
+	"golang-test-task/testutil/seed"
+
+	"github.com/stretchr/testify/require"
+)
+
+// benchmarkTableSizes are the row counts each benchmark is run against
+var benchmarkTableSizes = []int{1_000, 100_000, 1_000_000}
+
+// startSeededEnv starts a test environment whose numbers table holds rows values
+// drawn from the default datagen stream, identical on every run
+func startSeededEnv(b *testing.B, rows int) *testutil.Env {
+	b.Helper()
+
+	env := testutil.StartEnv(b)
+	seed.Generate(b, env.Pool, datagen.DefaultConfig(), rows)
+
+	_, err := env.Pool.Exec(context.Background(), "analyze numbers")
+	require.NoError(b, err)
+
+	return env
+}
+
+// BenchmarkAddNumber measures a full AddNumber round trip through the HTTP handler
+func BenchmarkAddNumber(b *testing.B) {
+	for _, rows := range benchmarkTableSizes {
+		b.Run(fmt.Sprintf("rows=%d", rows), func(b *testing.B) {
+			env := startSeededEnv(b, rows)
+
+			client := http.DefaultClient
+			url := env.URL + "/numbers?number=42"
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				resp, err := client.Post(url, "", nil)
+				if err != nil {
+					b.Fatal(err)
+				}
+				io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+				if resp.StatusCode != http.StatusOK {
+					b.Fatalf("unexpected status %d", resp.StatusCode)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkInsertNumber measures the insert query alone
+func BenchmarkInsertNumber(b *testing.B) {
+	for _, rows := range benchmarkTableSizes {
+		b.Run(fmt.Sprintf("rows=%d", rows), func(b *testing.B) {
+			env := startSeededEnv(b, rows)
+			ctx := context.Background()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := env.Queries.InsertNumber(ctx, int64(i)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkGetAllNumbersSorted measures reading the whole table in order
+func BenchmarkGetAllNumbersSorted(b *testing.B) {
+	for _, rows := range benchmarkTableSizes {
+		b.Run(fmt.Sprintf("rows=%d", rows), func(b *testing.B) {
+			env := startSeededEnv(b, rows)
+			ctx := context.Background()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				numbers, err := env.Queries.GetAllNumbersSorted(ctx)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(numbers) < rows {
+					b.Fatalf("expected at least %d rows, got %d", rows, len(numbers))
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkSortedNumbers measures reading the whole table in order into values only,
+// against BenchmarkGetAllNumbersSorted
+func BenchmarkSortedNumbers(b *testing.B) {
+	for _, rows := range benchmarkTableSizes {
+		b.Run(fmt.Sprintf("rows=%d", rows), func(b *testing.B) {
+			env := startSeededEnv(b, rows)
+			queries := storage.NewQueries(env.Pool)
+			ctx := context.Background()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				numbers, err := queries.SortedNumbers(ctx)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(numbers) < rows {
+					b.Fatalf("expected at least %d rows, got %d", rows, len(numbers))
+				}
+			}
+		})
+	}
+}
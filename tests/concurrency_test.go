@@ -0,0 +1,65 @@
+package tests
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+
+	"golang-test-task/api"
+	"golang-test-task/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAddNumber_ConcurrentWrites fires hundreds of simultaneous inserts and checks that no write
+// is lost or duplicated and that every response is sorted and contains its own insert
+func TestAddNumber_ConcurrentWrites(t *testing.T) {
+	env := testutil.StartEnv(t)
+	ctx := context.Background()
+
+	const requests = 500
+
+	// A narrow range forces plenty of duplicates
+	rng := rand.New(rand.NewPCG(1, 2))
+	values := make([]int64, requests)
+	for i := range values {
+		values[i] = rng.Int64N(100) - 50
+	}
+
+	var wg sync.WaitGroup
+	for _, value := range values {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			resp, err := env.Client.AddNumberWithResponse(ctx, &api.AddNumberParams{Number: int64(value)})
+			if !assert.NoError(t, err) || !assert.Equal(t, http.StatusOK, resp.StatusCode()) {
+				return
+			}
+			if !assert.NotNil(t, resp.JSON200) || !assert.NotNil(t, resp.JSON200.Numbers) {
+				return
+			}
+
+			got := *resp.JSON200.Numbers
+			assert.True(t, slices.IsSorted(got), "response is not sorted: %v", got)
+			assert.Contains(t, got, value, "response does not contain the inserted value")
+			assert.LessOrEqual(t, len(got), requests)
+		}()
+	}
+	wg.Wait()
+
+	dbNumbers, err := env.Queries.GetAllNumbersSorted(ctx)
+	require.NoError(t, err)
+
+	stored := make([]int64, len(dbNumbers))
+	for i, num := range dbNumbers {
+		stored[i] = num.Number
+	}
+
+	slices.Sort(values)
+	assert.Equal(t, values, stored)
+}
@@ -0,0 +1,40 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"golang-test-task/internal/storage"
+	"golang-test-task/internal/storage/sqlc"
+	"golang-test-task/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestStreamRowsSorted tests that a cursor streams a table of several batches whole
+// and in order, and that stopping partway ends its transaction
+func TestStreamRowsSorted(t *testing.T) {
+	env := testutil.StartEnv(t)
+	ctx := context.Background()
+
+	_, err := env.Pool.Exec(ctx, "insert into numbers (number) select -n from generate_series(1, 25000) as n")
+	require.NoError(t, err)
+	queries := storage.NewQueries(env.Pool)
+
+	var streamed []int64
+	err = queries.StreamRowsSorted(ctx, func(row sqlc.Number) error {
+		assert.True(t, row.ID.Valid)
+		streamed = append(streamed, row.Number)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, streamed, 25000)
+	assert.Equal(t, int64(-25000), streamed[0])
+	assert.IsNonDecreasing(t, streamed)
+
+	stop := assert.AnError
+	err = queries.StreamRowsSorted(ctx, func(sqlc.Number) error { return stop })
+	assert.ErrorIs(t, err, stop)
+	assert.Zero(t, env.Pool.Stat().AcquiredConns(), "the connection is back in the pool")
+}
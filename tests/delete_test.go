@@ -0,0 +1,67 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"golang-test-task/api"
+	"golang-test-task/testutil"
+	"golang-test-task/testutil/seed"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDeleteNumber tests that the id an add answers with deletes that row alone, of
+// several holding the same number, and only once
+func TestDeleteNumber(t *testing.T) {
+	env := testutil.StartEnv(t)
+	ctx := context.Background()
+
+	seed.Numbers(t, env.Pool, 5, 9)
+	added, err := env.Client.AddNumberWithResponse(ctx, &api.AddNumberParams{Number: 5})
+	require.NoError(t, err)
+	require.NotNil(t, added.JSON200)
+	require.NotNil(t, added.JSON200.Id)
+
+	var stored int
+	require.NoError(t, env.Pool.QueryRow(ctx, "select count(*) from numbers where id = $1", *added.JSON200.Id).Scan(&stored))
+	assert.Equal(t, 1, stored, "the id is the row's")
+
+	resp, err := env.Client.DeleteNumberWithResponse(ctx, *added.JSON200.Id)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode())
+
+	resp, err = env.Client.DeleteNumberWithResponse(ctx, *added.JSON200.Id)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode())
+	require.NotNil(t, resp.JSON404)
+	assert.Equal(t, "not found", resp.JSON404.Error)
+
+	list, err := env.Client.ListNumbersWithResponse(ctx, &api.ListNumbersParams{})
+	require.NoError(t, err)
+	require.NotNil(t, list.JSON200)
+	assert.Equal(t, []int64{5, 9}, list.JSON200.Numbers)
+}
+
+// TestClearNumbers tests that clearing empties the table, after which numbers are
+// added as to a new one
+func TestClearNumbers(t *testing.T) {
+	env := testutil.StartEnv(t)
+	ctx := context.Background()
+
+	seed.Numbers(t, env.Pool, 3, 1, 2)
+	resp, err := env.Client.ClearNumbersWithResponse(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode())
+
+	var count int
+	require.NoError(t, env.Pool.QueryRow(ctx, "select count(*) from numbers").Scan(&count))
+	assert.Zero(t, count)
+
+	added, err := env.Client.AddNumberWithResponse(ctx, &api.AddNumberParams{Number: 4})
+	require.NoError(t, err)
+	require.NotNil(t, added.JSON200)
+	assert.Equal(t, []int64{4}, *added.JSON200.Numbers)
+}
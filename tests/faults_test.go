@@ -0,0 +1,203 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang-test-task/api"
+	"golang-test-task/testutil"
+
+	toxiclient "github.com/Shopify/toxiproxy/v2/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// addNumber posts a number with a bounded wait so a hung database fails the test instead of blocking it
+func addNumber(t *testing.T, env *testutil.FaultyEnv, number int) *api.AddNumberResponse {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	resp, err := env.Client.AddNumberWithResponse(ctx, &api.AddNumberParams{Number: int64(number)})
+	require.NoError(t, err)
+
+	return resp
+}
+
+// requireRecovers waits until the server serves a successful AddNumber again
+func requireRecovers(t *testing.T, env *testutil.FaultyEnv, number int) *api.AddNumberResponse {
+	t.Helper()
+
+	var resp *api.AddNumberResponse
+	require.Eventually(t, func() bool {
+		resp = addNumber(t, env, number)
+		return resp.StatusCode() == http.StatusOK
+	}, 30*time.Second, 200*time.Millisecond, "server did not recover after the fault was removed")
+
+	return resp
+}
+
+// countingDoer counts the requests that reach the server and calls sent, when set,
+// after each
+type countingDoer struct {
+	requests atomic.Int32
+	sent     func(n int32)
+}
+
+func (d *countingDoer) Do(req *http.Request) (*http.Response, error) {
+	resp, err := http.DefaultClient.Do(req)
+	n := d.requests.Add(1)
+	if d.sent != nil {
+		d.sent(n)
+	}
+
+	return resp, err
+}
+
+// TestFaults_Latency tests that a slow database slows responses down without failing them
+func TestFaults_Latency(t *testing.T) {
+	env := testutil.StartFaultyEnv(t)
+
+	_, err := env.Proxy.AddToxic("latency", "latency", "downstream", 1, toxiclient.Attributes{
+		"latency": 300,
+	})
+	require.NoError(t, err)
+
+	start := time.Now()
+	resp := addNumber(t, env, 1)
+	elapsed := time.Since(start)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode())
+	// Insert and select each wait for at least one delayed round trip
+	assert.GreaterOrEqual(t, elapsed, 600*time.Millisecond)
+
+	require.NoError(t, env.Proxy.RemoveToxic("latency"))
+
+	resp = addNumber(t, env, 2)
+	assert.Equal(t, http.StatusOK, resp.StatusCode())
+	require.NotNil(t, resp.JSON200)
+	assert.Equal(t, []int64{1, 2}, *resp.JSON200.Numbers)
+}
+
+// TestFaults_Partition tests that an unreachable database yields JSON 503s and that
+// the pool reconnects once the network heals
+func TestFaults_Partition(t *testing.T) {
+	env := testutil.StartFaultyEnv(t)
+
+	resp := addNumber(t, env, 1)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+
+	require.NoError(t, env.Proxy.Disable())
+
+	resp = addNumber(t, env, 2)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode())
+	require.NotNil(t, resp.JSON503)
+	assert.Equal(t, "storage unavailable", resp.JSON503.Error)
+
+	require.NoError(t, env.Proxy.Enable())
+
+	resp = requireRecovers(t, env, 3)
+	require.NotNil(t, resp.JSON200)
+	assert.Equal(t, []int64{1, 3}, *resp.JSON200.Numbers)
+}
+
+// TestFaults_ConnectionReset tests that reset connections are dropped from the pool
+// and replaced once resets stop
+func TestFaults_ConnectionReset(t *testing.T) {
+	env := testutil.StartFaultyEnv(t)
+
+	resp := addNumber(t, env, 1)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+
+	_, err := env.Proxy.AddToxic("reset", "reset_peer", "upstream", 1, toxiclient.Attributes{
+		"timeout": 0,
+	})
+	require.NoError(t, err)
+
+	resp = addNumber(t, env, 2)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode())
+	require.NotNil(t, resp.JSON503)
+
+	require.NoError(t, env.Proxy.RemoveToxic("reset"))
+
+	resp = requireRecovers(t, env, 3)
+	require.NotNil(t, resp.JSON200)
+
+	// Whatever the reset interrupted, the rows that made it are exactly what the server reports
+	dbNumbers, err := env.Queries.GetAllNumbersSorted(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, *resp.JSON200.Numbers, len(dbNumbers))
+}
+
+// TestFaults_Retry tests that the retrying client retries reads through a partition
+// until its attempts run out, gets through once the network heals between attempts,
+// and never retries an add, which is not idempotent
+func TestFaults_Retry(t *testing.T) {
+	env := testutil.StartFaultyEnv(t)
+	ctx := context.Background()
+	policy := api.RetryPolicy{MaxAttempts: 4, BaseDelay: 50 * time.Millisecond, MaxDelay: time.Second}
+	doer := &countingDoer{}
+	client, err := api.NewRetryingClient(env.URL, policy, api.WithHTTPClient(doer))
+	require.NoError(t, err)
+
+	require.NoError(t, env.Proxy.Disable())
+
+	stats, err := client.GetNumberStatsWithResponse(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, stats.StatusCode())
+	assert.Equal(t, int32(4), doer.requests.Load(), "every attempt is made")
+
+	doer.requests.Store(0)
+	added, err := client.AddNumberWithResponse(ctx, &api.AddNumberParams{Number: 1})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, added.StatusCode())
+	assert.Equal(t, int32(1), doer.requests.Load(), "an add is sent once")
+
+	doer.requests.Store(0)
+	doer.sent = func(n int32) {
+		if n == 1 {
+			assert.NoError(t, env.Proxy.Enable())
+		}
+	}
+	stats, err = client.GetNumberStatsWithResponse(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, stats.StatusCode(), "a retry after the network heals succeeds")
+	assert.GreaterOrEqual(t, doer.requests.Load(), int32(2))
+}
+
+// TestFaults_CircuitBreaker tests that the breaker opens after consecutive failures
+// against a partitioned database, failing fast without reaching the server, and closes
+// again after a successful probe once the network heals
+func TestFaults_CircuitBreaker(t *testing.T) {
+	env := testutil.StartFaultyEnv(t)
+	ctx := context.Background()
+	doer := &countingDoer{}
+	breaker := api.NewCircuitBreaker(doer, api.BreakerPolicy{FailureThreshold: 3, OpenTimeout: 500 * time.Millisecond})
+	policy := api.RetryPolicy{MaxAttempts: 5, BaseDelay: 10 * time.Millisecond, MaxDelay: 100 * time.Millisecond}
+	client, err := api.NewRetryingClient(env.URL, policy, api.WithHTTPClient(breaker))
+	require.NoError(t, err)
+
+	require.NoError(t, env.Proxy.Disable())
+
+	_, err = client.GetNumberStatsWithResponse(ctx, nil)
+	require.ErrorIs(t, err, api.ErrCircuitOpen, "the retries stop once the circuit opens")
+	assert.Equal(t, int32(3), doer.requests.Load(), "only the failures up to the threshold reach the server")
+	assert.True(t, breaker.Open())
+
+	_, err = client.GetNumberStatsWithResponse(ctx, nil)
+	require.ErrorIs(t, err, api.ErrCircuitOpen)
+	assert.Equal(t, int32(3), doer.requests.Load(), "an open circuit fails fast")
+
+	require.NoError(t, env.Proxy.Enable())
+	// A probe is let through every OpenTimeout until one finds the database back
+	require.Eventually(t, func() bool {
+		stats, err := client.GetNumberStatsWithResponse(ctx, nil)
+		return err == nil && stats.StatusCode() == http.StatusOK
+	}, 30*time.Second, 100*time.Millisecond, "the circuit did not close after the fault was removed")
+	assert.False(t, breaker.Open(), "a successful probe closes the circuit")
+	assert.Greater(t, doer.requests.Load(), int32(3))
+}
@@ -0,0 +1,72 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"golang-test-task/internal/storage/feed"
+	"golang-test-task/internal/storage/index"
+	"golang-test-task/testutil"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFeed_FollowsTable tests that the feed hears of the rows other connections
+// insert, one by one or in bulk, of the rows they delete and of a truncate, through the triggers of the numbers
+// table
+func TestFeed_FollowsTable(t *testing.T) {
+	env := testutil.StartEnv(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	f := feed.New()
+	events := f.Subscribe(ctx)
+	go f.Run(ctx, index.FromPool(func() *pgxpool.Pool { return env.Pool }))
+	next := func() feed.Event {
+		t.Helper()
+		select {
+		case event := <-events:
+			return event
+		case <-time.After(10 * time.Second):
+			require.FailNow(t, "no event")
+			return feed.Event{}
+		}
+	}
+
+	// Notifications sent before the feed listens are lost, so insert until one arrives
+	require.Eventually(t, func() bool {
+		_, err := env.Queries.InsertNumber(ctx, 7)
+		require.NoError(t, err)
+		select {
+		case event := <-events:
+			return assert.Equal(t, int64(7), event.Row.Number) && assert.True(t, event.Row.ID.Valid)
+		case <-time.After(100 * time.Millisecond):
+			return false
+		}
+	}, 10*time.Second, time.Millisecond)
+
+	inserted, err := env.Queries.InsertNumber(ctx, -2)
+	require.NoError(t, err)
+	for event := next(); event.Row.ID != inserted.ID; event = next() {
+		// Sevens inserted while waiting for the first may still be arriving
+		require.Equal(t, int64(7), event.Row.Number)
+	}
+
+	// One more row than a subscriber may fall behind
+	_, err = env.Pool.Exec(ctx, "insert into numbers (number) select generate_series(1, 257)")
+	require.NoError(t, err)
+	assert.Equal(t, feed.Event{Reset: true}, next(), "a bulk insert resets")
+
+	_, err = env.Queries.DeleteNumber(ctx, inserted.ID)
+	require.NoError(t, err)
+	event := next()
+	assert.True(t, event.Deleted, "a delete sends the row deleted")
+	assert.Equal(t, inserted.ID, event.Row.ID)
+	assert.Equal(t, int64(-2), event.Row.Number)
+
+	require.NoError(t, env.Queries.DeleteAllNumbers(ctx))
+	assert.Equal(t, feed.Event{Reset: true}, next(), "clearing the numbers resets")
+}
@@ -0,0 +1,56 @@
+package tests
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"golang-test-task/internal/storage"
+	"golang-test-task/internal/storage/index"
+	"golang-test-task/testutil"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestIndex_FollowsTable tests that the index hears of rows inserted, deleted and bulk
+// loaded by other connections through the triggers of the numbers table
+func TestIndex_FollowsTable(t *testing.T) {
+	env := testutil.StartEnv(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	idx := index.New(storage.NewQueries(env.Pool))
+	go idx.Run(ctx, index.FromPool(func() *pgxpool.Pool { return env.Pool }))
+	require.Eventually(t, idx.Ready, 10*time.Second, 10*time.Millisecond)
+
+	sorted := func() []int64 {
+		numbers, err := idx.SortedNumbers(ctx)
+		require.NoError(t, err)
+		return numbers
+	}
+
+	_, err := idx.InsertNumber(ctx, 5)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{5}, sorted())
+
+	// Another instance inserts
+	_, err = env.Queries.InsertNumber(ctx, -3)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return slices.Equal([]int64{-3, 5}, sorted()) }, 10*time.Second, 10*time.Millisecond)
+
+	_, err = env.Pool.Exec(ctx, "delete from numbers where number = 5")
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return slices.Equal([]int64{-3}, sorted()) }, 10*time.Second, 10*time.Millisecond)
+
+	_, err = env.Pool.Exec(ctx, "insert into numbers (number) select generate_series(1, 2000)")
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return len(sorted()) == 2001 }, 10*time.Second, 10*time.Millisecond)
+
+	rank, err := idx.RankNumber(ctx, 100)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), rank)
+	assert.True(t, idx.Ready())
+}
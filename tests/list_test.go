@@ -0,0 +1,46 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	api "golang-test-task/api"
+	"golang-test-task/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestListNumbers_Pages tests that the cursors page through a range of a large table
+// in order, duplicates included, without adding anything to it
+func TestListNumbers_Pages(t *testing.T) {
+	env := testutil.StartEnv(t)
+	ctx := context.Background()
+
+	// Every number from -5000 to 4999 twice
+	_, err := env.Pool.Exec(ctx, "insert into numbers (number) select n / 2 - 5000 from generate_series(0, 19999) as n")
+	require.NoError(t, err)
+
+	limit, low, high := int32(150), int64(-100), int64(99)
+	params := &api.ListNumbersParams{Limit: &limit, Min: &low, Max: &high}
+	var listed []int64
+	for {
+		resp, err := env.Client.ListNumbersWithResponse(ctx, params)
+		require.NoError(t, err)
+		require.NotNil(t, resp.JSON200, "status %d: %s", resp.StatusCode(), resp.Body)
+		listed = append(listed, resp.JSON200.Numbers...)
+		if resp.JSON200.NextCursor == nil {
+			break
+		}
+		params.After = resp.JSON200.NextCursor
+	}
+
+	require.Len(t, listed, 400)
+	assert.Equal(t, int64(-100), listed[0])
+	assert.Equal(t, int64(99), listed[399])
+	assert.IsNonDecreasing(t, listed)
+
+	var count int64
+	require.NoError(t, env.Pool.QueryRow(ctx, "select count(*) from numbers").Scan(&count))
+	assert.Equal(t, int64(20000), count, "listing adds nothing")
+}
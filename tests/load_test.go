@@ -0,0 +1,59 @@
+//go:build load
+
+package tests
+
+import (
+	"context"
+	"flag"
+	"testing"
+	"time"
+
+	"golang-test-task/datagen"
+	"golang-test-task/loadtest"
+	"golang-test-task/testutil"
+
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	loadURL          = flag.String("load.url", "", "target URL; a test environment is started when empty")
+	loadRPS          = flag.Int("load.rps", loadtest.DefaultConfig().RPS, "target requests per second")
+	loadDuration     = flag.Duration("load.duration", loadtest.DefaultConfig().Duration, "duration of the run")
+	loadRampUp       = flag.Duration("load.ramp-up", loadtest.DefaultConfig().RampUp, "grow the rate linearly from zero over this long")
+	loadReadRatio    = flag.Float64("load.read-ratio", loadtest.DefaultConfig().ReadRatio, "fraction of requests that are reads")
+	loadReadPath     = flag.String("load.read-path", loadtest.DefaultConfig().ReadPath, "path requested with GET for reads")
+	loadMaxInFlight  = flag.Int("load.max-in-flight", loadtest.DefaultConfig().MaxInFlight, "maximum concurrent requests")
+	loadSeed         = flag.Uint64("load.seed", loadtest.DefaultConfig().Seed, "seed for generated values and the read/write sequence")
+	loadDistribution = flag.String("load.distribution", string(loadtest.DefaultConfig().Values.Distribution), "distribution of written values: uniform, zipf or clustered")
+)
+
+// TestLoad drives the configured load and prints latency percentiles and error rates.
+//
+//	go test -tags=load ./tests/ -run TestLoad -v -load.url=http://localhost:8080 -load.rps=200 -load.duration=30s
+func TestLoad(t *testing.T) {
+	cfg := loadtest.DefaultConfig()
+	cfg.URL = *loadURL
+	cfg.RPS = *loadRPS
+	cfg.Duration = *loadDuration
+	cfg.RampUp = *loadRampUp
+	cfg.ReadRatio = *loadReadRatio
+	cfg.ReadPath = *loadReadPath
+	cfg.MaxInFlight = *loadMaxInFlight
+	cfg.Seed = *loadSeed
+
+	distribution, err := datagen.ParseDistribution(*loadDistribution)
+	require.NoError(t, err)
+	cfg.Values.Distribution = distribution
+
+	if cfg.URL == "" {
+		cfg.URL = testutil.StartEnv(t).URL
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Duration+time.Minute)
+	defer cancel()
+
+	report, err := loadtest.Run(ctx, cfg)
+	require.NoError(t, err)
+
+	t.Logf("load test against %s at %d rps:\n%s", cfg.URL, cfg.RPS, report)
+}
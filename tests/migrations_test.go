@@ -0,0 +1,128 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"golang-test-task/migrations"
+	"golang-test-task/testutil"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// schemaSnapshotSQL describes every user-visible schema object except goose's own bookkeeping
+const schemaSnapshotSQL = `
+select 'column ' || table_name || '.' || column_name || ' ' || data_type
+	|| ' nullable=' || is_nullable || coalesce(' default ' || column_default, '')
+from information_schema.columns
+where table_schema = 'public' and table_name <> 'goose_db_version'
+union all
+select 'index ' || indexdef
+from pg_indexes
+where schemaname = 'public' and tablename <> 'goose_db_version'
+union all
+select 'constraint ' || conrelid::regclass || ' ' || pg_get_constraintdef(oid)
+from pg_constraint
+where connamespace = 'public'::regnamespace and conrelid::regclass::text <> 'goose_db_version'
+union all
+select 'trigger ' || tgrelid::regclass || ' ' || tgname
+from pg_trigger
+where not tgisinternal
+union all
+select 'function ' || proname
+from pg_proc
+where pronamespace = 'public'::regnamespace
+union all
+select 'extension ' || extname
+from pg_extension
+where extname <> 'plpgsql'
+order by 1`
+
+// schemaSnapshot returns a sorted description of the database schema
+func schemaSnapshot(t *testing.T, db *sql.DB) []string {
+	t.Helper()
+
+	rows, err := db.QueryContext(context.Background(), schemaSnapshotSQL)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	snapshot := []string{}
+	for rows.Next() {
+		var object string
+		require.NoError(t, rows.Scan(&object))
+		snapshot = append(snapshot, object)
+	}
+	require.NoError(t, rows.Err())
+
+	return snapshot
+}
+
+// newMigrationProvider opens an empty database and a goose provider for the embedded migrations
+func newMigrationProvider(t *testing.T) (*goose.Provider, *sql.DB) {
+	t.Helper()
+
+	db, err := sql.Open("pgx", testutil.CreateEmptyDatabase(t))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	provider, err := goose.NewProvider(goose.DialectPostgres, db, migrations.FS)
+	require.NoError(t, err)
+
+	return provider, db
+}
+
+// TestMigrations_RoundTrip applies all ups, all downs and all ups again on an empty database
+func TestMigrations_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	provider, db := newMigrationProvider(t)
+
+	require.NotEmpty(t, provider.ListSources())
+	empty := schemaSnapshot(t, db)
+
+	_, err := provider.Up(ctx)
+	require.NoError(t, err)
+	migrated := schemaSnapshot(t, db)
+	assert.Contains(t, migrated, "column numbers.number bigint nullable=NO")
+
+	// A second up is a no-op
+	results, err := provider.Up(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+	assert.Equal(t, migrated, schemaSnapshot(t, db))
+
+	_, err = provider.DownTo(ctx, 0)
+	require.NoError(t, err)
+	assert.Equal(t, empty, schemaSnapshot(t, db), "down migrations left objects behind")
+
+	_, err = provider.Up(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, migrated, schemaSnapshot(t, db), "re-applying up migrations produced a different schema")
+}
+
+// TestMigrations_EachDownReversesItsUp checks every migration in isolation: applying it
+// and rolling it back must restore the schema of the previous version exactly
+func TestMigrations_EachDownReversesItsUp(t *testing.T) {
+	ctx := context.Background()
+	provider, db := newMigrationProvider(t)
+
+	for _, source := range provider.ListSources() {
+		before := schemaSnapshot(t, db)
+
+		_, err := provider.UpByOne(ctx)
+		require.NoError(t, err, "up %d", source.Version)
+		after := schemaSnapshot(t, db)
+		assert.NotEqual(t, before, after, "migration %d does not change the schema", source.Version)
+
+		_, err = provider.Down(ctx)
+		require.NoError(t, err, "down %d", source.Version)
+		assert.Equal(t, before, schemaSnapshot(t, db), "down %d does not reverse its up", source.Version)
+
+		_, err = provider.UpByOne(ctx)
+		require.NoError(t, err, "re-applying %d", source.Version)
+		assert.Equal(t, after, schemaSnapshot(t, db), "re-applying %d produced a different schema", source.Version)
+	}
+}
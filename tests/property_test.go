@@ -0,0 +1,47 @@
+package tests
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"golang-test-task/api"
+	"golang-test-task/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"pgregory.net/rapid"
+)
+
+// TestAddNumber_SortedPermutationProperty checks that after any sequence of inserts
+// the response is exactly the sorted multiset of everything inserted so far
+func TestAddNumber_SortedPermutationProperty(t *testing.T) {
+	env := testutil.StartEnv(t)
+	ctx := context.Background()
+
+	// Mix the full int64 range with a narrow one so duplicates are common
+	number := rapid.OneOf(rapid.Int64(), rapid.Int64Range(-3, 3))
+
+	rapid.Check(t, func(rt *rapid.T) {
+		cleared, err := env.Client.ClearNumbersWithResponse(ctx)
+		require.NoError(rt, err)
+		require.Equal(rt, 204, cleared.StatusCode())
+
+		values := rapid.SliceOfN(number, 1, 20).Draw(rt, "values")
+
+		var inserted []int64
+		for _, value := range values {
+			resp, err := env.Client.AddNumberWithResponse(ctx, &api.AddNumberParams{Number: value})
+			require.NoError(rt, err)
+			require.Equal(rt, 200, resp.StatusCode())
+			require.NotNil(rt, resp.JSON200)
+			require.NotNil(rt, resp.JSON200.Numbers)
+
+			inserted = append(inserted, value)
+			got := *resp.JSON200.Numbers
+
+			assert.True(rt, slices.IsSorted(got), "response is not sorted: %v", got)
+			assert.ElementsMatch(rt, inserted, got, "response is not a permutation of the inserted values")
+		}
+	})
+}
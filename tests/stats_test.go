@@ -0,0 +1,64 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"golang-test-task/api"
+	"golang-test-task/internal/storage/memstore"
+	"golang-test-task/testutil"
+	"golang-test-task/testutil/seed"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetNumberStats tests that the database aggregates the numbers as the memory
+// store does, extremes included, and that an empty table has only a count and sum
+func TestGetNumberStats(t *testing.T) {
+	env := testutil.StartEnv(t)
+	ctx := context.Background()
+
+	resp, err := env.Client.GetNumberStatsWithResponse(ctx, &api.GetNumberStatsParams{})
+	require.NoError(t, err)
+	require.NotNil(t, resp.JSON200, string(resp.Body))
+	assert.Equal(t, int64(0), resp.JSON200.Count)
+	assert.Nil(t, resp.JSON200.Min)
+	assert.Nil(t, resp.JSON200.Median)
+	require.Len(t, resp.JSON200.Percentiles, 3)
+	assert.Nil(t, resp.JSON200.Percentiles[0].Value)
+
+	numbers := []int64{-9223372036854775808, 9223372036854775807, 7, 7, -3, 1 << 40, 12}
+	seed.Numbers(t, env.Pool, numbers...)
+	store := memstore.New()
+	_, err = store.InsertNumbers(ctx, numbers)
+	require.NoError(t, err)
+	fractions := []float64{0, 0.125, 0.5, 0.999, 1}
+	want, err := store.NumberStats(ctx, fractions)
+	require.NoError(t, err)
+
+	got, err := env.Queries.NumberStats(ctx, fractions)
+	require.NoError(t, err)
+	assert.Equal(t, want.Count, got.Count)
+	assert.Equal(t, want.Min, got.Min)
+	assert.Equal(t, want.Max, got.Max)
+	assert.Equal(t, want.Sum, got.Sum)
+	assert.InDelta(t, want.Mean, got.Mean, 1)
+	assert.Equal(t, want.Median, got.Median)
+	require.Len(t, got.Percentiles, len(fractions))
+	for i := range fractions {
+		assert.InEpsilon(t, want.Percentiles[i], got.Percentiles[i], 1e-12, "fraction %v", fractions[i])
+	}
+
+	resp, err = env.Client.GetNumberStatsWithResponse(ctx, &api.GetNumberStatsParams{Percentiles: &[]float64{50, 100}})
+	require.NoError(t, err)
+	require.NotNil(t, resp.JSON200, string(resp.Body))
+	assert.Equal(t, int64(len(numbers)), resp.JSON200.Count)
+	assert.Equal(t, int64(9223372036854775807), *resp.JSON200.Max)
+	assert.Equal(t, []api.Percentile{{Percentile: 50, Value: &want.Median}, {Percentile: 100, Value: &want.Percentiles[4]}}, resp.JSON200.Percentiles)
+
+	resp, err = env.Client.GetNumberStatsWithResponse(ctx, &api.GetNumberStatsParams{Percentiles: &[]float64{150}})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode())
+}
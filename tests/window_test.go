@@ -0,0 +1,56 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	api "golang-test-task/api"
+	"golang-test-task/testutil"
+	"golang-test-task/testutil/seed"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAddNumber_Window tests that the window response holds the number with its
+// neighbours in PostgreSQL's order, at either end of the table too
+func TestAddNumber_Window(t *testing.T) {
+	env := testutil.StartEnv(t)
+	ctx := context.Background()
+	numbers := make([]int64, 100)
+	for i := range numbers {
+		numbers[i] = int64(10 * i)
+	}
+	seed.Numbers(t, env.Pool, numbers...)
+	window := api.AddNumberParamsResponseWindow
+
+	tests := []struct {
+		number       int64
+		wantPosition int64
+		wantWindow   []int64
+	}{
+		{number: 505, wantPosition: 51, wantWindow: []int64{460, 470, 480, 490, 500, 505, 510, 520, 530, 540, 550}},
+		{number: -1, wantPosition: 0, wantWindow: []int64{-1, 0, 10, 20, 30, 40}},
+		{number: 5000, wantPosition: 102, wantWindow: []int64{950, 960, 970, 980, 990, 5000}},
+	}
+
+	for _, tt := range tests {
+		resp, err := env.Client.AddNumberWithResponse(ctx, &api.AddNumberParams{Number: tt.number, Response: &window})
+		require.NoError(t, err)
+		require.NotNil(t, resp.JSON200, "status %d: %s", resp.StatusCode(), resp.Body)
+		require.NotNil(t, resp.JSON200.Window)
+		assert.Nil(t, resp.JSON200.Numbers)
+		assert.Equal(t, tt.wantPosition, *resp.JSON200.Position, "position of %d", tt.number)
+		assert.Equal(t, tt.wantWindow, *resp.JSON200.Window, "window of %d", tt.number)
+	}
+
+	// Which copy of a duplicate comes first depends on the ids, so only the size of
+	// the window is known
+	resp, err := env.Client.AddNumberWithResponse(ctx, &api.AddNumberParams{Number: 505, Response: &window})
+	require.NoError(t, err)
+	require.NotNil(t, resp.JSON200, "status %d: %s", resp.StatusCode(), resp.Body)
+	assert.Equal(t, int64(52), *resp.JSON200.Position)
+	assert.Len(t, *resp.JSON200.Window, 11)
+	assert.Contains(t, *resp.JSON200.Window, int64(500))
+	assert.Contains(t, *resp.JSON200.Window, int64(510))
+}
@@ -0,0 +1,142 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"golang-test-task/internal/service"
+	"golang-test-task/internal/storage"
+	"golang-test-task/internal/storage/sqlc"
+	"golang-test-task/internal/transport/server"
+
+	toxiclient "github.com/Shopify/toxiproxy/v2/client"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+	"github.com/testcontainers/testcontainers-go/modules/toxiproxy"
+	"github.com/testcontainers/testcontainers-go/network"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+const (
+	toxiproxyImage = "ghcr.io/shopify/toxiproxy:2.12.0"
+
+	// toxiproxyListenPort is the port the testcontainers module assigns to the first proxy
+	toxiproxyListenPort = 8666
+)
+
+// FaultyEnv is an Env whose server reaches the database through Toxiproxy,
+// while Pool and Queries stay connected directly for setup and assertions
+type FaultyEnv struct {
+	*Env
+	// Proxy sits between the server and the database; add toxics or disable it to inject faults
+	Proxy *toxiclient.Proxy
+}
+
+// StartFaultyEnv starts a dedicated PostgreSQL and Toxiproxy pair on a private network
+// and a server whose database traffic is routed through the proxy.
+// Unlike StartEnv nothing is shared between calls, since faults affect the whole proxy.
+func StartFaultyEnv(t testing.TB) *FaultyEnv {
+	t.Helper()
+
+	ctx := context.Background()
+
+	nw, err := network.New(ctx)
+	if err != nil {
+		t.Fatalf("failed to create network (is Docker running?): %v", err)
+	}
+	testcontainers.CleanupNetwork(t, nw)
+
+	pg, err := postgres.Run(ctx,
+		"postgres:16-alpine",
+		postgres.WithDatabase("testdb"),
+		postgres.WithUsername("testuser"),
+		postgres.WithPassword("testpass"),
+		network.WithNetwork([]string{"postgres"}, nw),
+		testcontainers.WithWaitStrategy(
+			wait.ForLog("database system is ready to accept connections").
+				WithOccurrence(2).
+				WithStartupTimeout(60*time.Second)),
+	)
+	testcontainers.CleanupContainer(t, pg)
+	if err != nil {
+		t.Fatalf("failed to start postgres container: %v", err)
+	}
+
+	dsn, err := pg.ConnectionString(ctx, "sslmode=disable")
+	if err != nil {
+		t.Fatalf("failed to get connection string: %v", err)
+	}
+
+	if err := runMigrations(ctx, dsn); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+
+	tp, err := toxiproxy.Run(ctx, toxiproxyImage,
+		toxiproxy.WithProxy("postgres", "postgres:5432"),
+		network.WithNetwork(nil, nw),
+	)
+	testcontainers.CleanupContainer(t, tp)
+	if err != nil {
+		t.Fatalf("failed to start toxiproxy container: %v", err)
+	}
+
+	proxyHost, proxyPort, err := tp.ProxiedEndpoint(toxiproxyListenPort)
+	if err != nil {
+		t.Fatalf("failed to get proxied endpoint: %v", err)
+	}
+
+	proxiedDSN, err := withHost(dsn, net.JoinHostPort(proxyHost, proxyPort))
+	if err != nil {
+		t.Fatalf("failed to build proxied DSN: %v", err)
+	}
+
+	controlURI, err := tp.URI(ctx)
+	if err != nil {
+		t.Fatalf("failed to get toxiproxy control URI: %v", err)
+	}
+
+	proxy, err := toxiclient.NewClient(controlURI).Proxy("postgres")
+	if err != nil {
+		t.Fatalf("failed to get proxy: %v", err)
+	}
+
+	serverPool, err := newPool(ctx, proxiedDSN)
+	if err != nil {
+		t.Fatalf("failed to connect to database through the proxy: %v", err)
+	}
+	t.Cleanup(serverPool.Close)
+
+	srv := NewServer(t, server.NewServer(service.New(storage.NewQueries(serverPool)), server.WithWriteResponse(server.WriteFull), server.WithClear(clearAlways)))
+
+	pool, err := newPool(ctx, dsn)
+	if err != nil {
+		t.Fatalf("failed to connect to database: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	return &FaultyEnv{
+		Env: &Env{
+			DSN:     dsn,
+			Pool:    pool,
+			Queries: sqlc.New(pool),
+			URL:     srv.URL,
+			Client:  NewClient(t, srv.URL),
+		},
+		Proxy: proxy,
+	}
+}
+
+// withHost returns dsn pointing at another host:port
+func withHost(dsn, hostPort string) (string, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse DSN: %w", err)
+	}
+	u.Host = hostPort
+
+	return u.String(), nil
+}
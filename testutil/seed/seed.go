@@ -0,0 +1,52 @@
+// Package seed puts test databases into a known state directly, without going through the API.
+package seed
+
+import (
+	"context"
+	"testing"
+
+	"golang-test-task/datagen"
+	"golang-test-task/internal/storage/sqlc"
+)
+
+// Numbers inserts values into the numbers table in a single statement
+func Numbers(t testing.TB, db sqlc.DBTX, values ...int64) {
+	t.Helper()
+
+	if len(values) == 0 {
+		return
+	}
+
+	_, err := db.Exec(context.Background(),
+		"insert into numbers (number) select unnest($1::bigint[])", values)
+	if err != nil {
+		t.Fatalf("failed to seed numbers: %v", err)
+	}
+}
+
+// Generate inserts n numbers drawn from cfg and returns them in insertion order
+func Generate(t testing.TB, db sqlc.DBTX, cfg datagen.Config, n int) []int64 {
+	t.Helper()
+
+	g, err := datagen.New(cfg)
+	if err != nil {
+		t.Fatalf("invalid generator config: %v", err)
+	}
+
+	values := g.Take(n)
+	Numbers(t, db, values...)
+
+	return values
+}
+
+// RandomNumbers inserts n numbers uniformly drawn from [lo, hi] and returns them in insertion order.
+// The same seed always yields the same numbers, so failures can be reproduced.
+func RandomNumbers(t testing.TB, db sqlc.DBTX, seed uint64, n int, lo, hi int64) []int64 {
+	t.Helper()
+
+	cfg := datagen.DefaultConfig()
+	cfg.Seed = seed
+	cfg.Min, cfg.Max = lo, hi
+
+	return Generate(t, db, cfg, n)
+}
@@ -0,0 +1,281 @@
+// Package testutil bootstraps a PostgreSQL container, per-test databases and
+// an HTTP server for integration tests.
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang-test-task/api"
+	"golang-test-task/internal/service"
+	"golang-test-task/internal/storage"
+	"golang-test-task/internal/storage/sqlc"
+	"golang-test-task/internal/transport/server"
+	"golang-test-task/migrations"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// Env is a running test environment: a freshly migrated database and a server in front of it
+type Env struct {
+	// DSN of the test database
+	DSN string
+	// Pool is connected to the test database and shared by the server
+	Pool *pgxpool.Pool
+	// Queries run directly against the test database
+	Queries *sqlc.Queries
+	// URL of the running server
+	URL string
+	// Client is an API client for the running server
+	Client *api.ClientWithResponses
+}
+
+// templateDatabase holds the migrated schema every test database is cloned from
+const templateDatabase = "numbers_template"
+
+// cluster is the PostgreSQL container shared by all tests in the binary
+type cluster struct {
+	dsn   string
+	admin *pgxpool.Pool
+}
+
+var (
+	clusterOnce sync.Once
+	shared      *cluster
+	clusterErr  error
+
+	databaseSeq atomic.Int64
+)
+
+// StartEnv creates a new database from the migrated template and starts a server on top of it,
+// answering adds with the full list.
+// Every call gets its own database, so tests can't observe each other's data regardless of ordering.
+// The database and server are removed on test cleanup; the container itself is removed
+// by the testcontainers reaper when the test binary exits.
+func StartEnv(t testing.TB) *Env {
+	t.Helper()
+
+	dsn := createDatabase(t, templateDatabase)
+
+	pool, err := newPool(context.Background(), dsn)
+	if err != nil {
+		t.Fatalf("failed to connect to database: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	queries := storage.NewQueries(pool)
+	// The tests check the whole list after every add, so it is what they get unless
+	// they ask for another response
+	srv := NewServer(t, server.NewServer(service.New(queries), server.WithWriteResponse(server.WriteFull), server.WithClear(clearAlways)))
+
+	return &Env{
+		DSN:     dsn,
+		Pool:    pool,
+		Queries: queries.Queries,
+		URL:     srv.URL,
+		Client:  NewClient(t, srv.URL),
+	}
+}
+
+// clearAlways lets the tests clear the numbers without an admin API key
+func clearAlways(context.Context) bool { return true }
+
+// NewServer serves impl through the production handler on an in-process httptest.Server,
+// closed on test cleanup. Use it with a fake implementation when a test needs no database.
+func NewServer(t testing.TB, impl api.StrictServerInterface) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(server.NewHandler(impl))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+// NewClient creates an API client for the server at serverURL
+func NewClient(t testing.TB, serverURL string) *api.ClientWithResponses {
+	t.Helper()
+
+	client, err := api.NewClientWithResponses(serverURL)
+	if err != nil {
+		t.Fatalf("failed to create API client: %v", err)
+	}
+
+	return client
+}
+
+// CreateEmptyDatabase creates a database without any schema and returns its DSN.
+// It is dropped on test cleanup.
+func CreateEmptyDatabase(t testing.TB) string {
+	t.Helper()
+
+	return createDatabase(t, "template0")
+}
+
+// createDatabase clones template into a new database on the shared cluster, dropped on test cleanup
+func createDatabase(t testing.TB, template string) string {
+	t.Helper()
+
+	clusterOnce.Do(func() {
+		shared, clusterErr = startCluster(context.Background())
+	})
+	if clusterErr != nil {
+		t.Fatalf("failed to start test environment (is Docker running?): %v", clusterErr)
+	}
+
+	dsn, err := shared.createDatabase(context.Background(), fmt.Sprintf("test_%d", databaseSeq.Add(1)), template)
+	if err != nil {
+		t.Fatalf("failed to create test database: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := shared.dropDatabase(context.Background(), dsn); err != nil {
+			t.Errorf("failed to drop test database: %v", err)
+		}
+	})
+
+	return dsn
+}
+
+// startCluster starts the container and migrates the template database
+func startCluster(ctx context.Context) (*cluster, error) {
+	_, dsn, err := setupPostgresContainer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	admin, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	if _, err := admin.Exec(ctx, "create database "+templateDatabase); err != nil {
+		admin.Close()
+		return nil, fmt.Errorf("failed to create template database: %w", err)
+	}
+
+	templateDSN, err := withDatabase(dsn, templateDatabase)
+	if err != nil {
+		admin.Close()
+		return nil, err
+	}
+
+	if err := runMigrations(ctx, templateDSN); err != nil {
+		admin.Close()
+		return nil, err
+	}
+
+	return &cluster{dsn: dsn, admin: admin}, nil
+}
+
+// createDatabase clones template into a new database and returns its DSN
+func (c *cluster) createDatabase(ctx context.Context, name, template string) (string, error) {
+	_, err := c.admin.Exec(ctx, fmt.Sprintf("create database %s template %s", name, template))
+	if err != nil {
+		return "", err
+	}
+
+	return withDatabase(c.dsn, name)
+}
+
+// dropDatabase drops a database created by createDatabase, terminating leftover connections
+func (c *cluster) dropDatabase(ctx context.Context, dsn string) error {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.admin.Exec(ctx, fmt.Sprintf("drop database if exists %s with (force)", strings.TrimPrefix(u.Path, "/")))
+	return err
+}
+
+// withDatabase returns dsn pointing at another database on the same server
+func withDatabase(dsn, database string) (string, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse DSN: %w", err)
+	}
+	u.Path = "/" + database
+
+	return u.String(), nil
+}
+
+// setupPostgresContainer starts a PostgreSQL container for testing
+func setupPostgresContainer(ctx context.Context) (*postgres.PostgresContainer, string, error) {
+	container, err := postgres.Run(ctx,
+		"postgres:16-alpine",
+		postgres.WithDatabase("testdb"),
+		postgres.WithUsername("testuser"),
+		postgres.WithPassword("testpass"),
+		testcontainers.WithWaitStrategy(
+			wait.ForLog("database system is ready to accept connections").
+				WithOccurrence(2).
+				WithStartupTimeout(60*time.Second)),
+	)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to start container: %w", err)
+	}
+
+	dsn, err := container.ConnectionString(ctx, "sslmode=disable")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get connection string: %w", err)
+	}
+
+	return container, dsn, nil
+}
+
+// runMigrations applies the embedded migrations the server binary applies, so tests
+// run against the schema production has
+func runMigrations(ctx context.Context, dsn string) error {
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer pool.Close()
+
+	db := stdlib.OpenDBFromPool(pool)
+	defer db.Close()
+
+	provider, err := goose.NewProvider(goose.DialectPostgres, db, migrations.FS)
+	if err != nil {
+		return fmt.Errorf("failed to load migrations: %w", err)
+	}
+	if _, err := provider.Up(ctx); err != nil {
+		return fmt.Errorf("failed to apply migrations: %w", err)
+	}
+
+	return nil
+}
+
+// newPool connects a small pool to the test database
+func newPool(ctx context.Context, dsn string) (*pgxpool.Pool, error) {
+	config, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	config.MaxConns = 10
+	config.MinConns = 2
+
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pool: %w", err)
+	}
+
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	return pool, nil
+}
@@ -0,0 +1,425 @@
+// Command clientgen renders a dependency-free TypeScript or Python client from the
+// OpenAPI spec, for consumers of the API that are not written in Go. It supports
+// the subset of OpenAPI the spec uses: query and path parameters, with arrays of
+// scalars in the query, JSON request and response bodies and component schemas built
+// from objects, arrays and scalars.
+// Operations that answer with a stream of server-sent events are left out, as the
+// clients read every response whole.
+package main
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+var (
+	//go:embed typescript.tmpl
+	typescriptTemplate string
+	//go:embed python.tmpl
+	pythonTemplate string
+)
+
+// languages maps -lang to its template and type renderer
+var languages = map[string]struct {
+	template string
+	typeName func(t typ) string
+}{
+	"typescript": {typescriptTemplate, typescriptType},
+	"python":     {pythonTemplate, pythonType},
+}
+
+func main() {
+	lang := flag.String("lang", "typescript", "client language: typescript or python")
+	out := flag.String("o", "", "output file")
+	flag.Parse()
+
+	if flag.NArg() != 1 || *out == "" {
+		fmt.Fprintln(os.Stderr, "usage: clientgen -lang typescript|python -o FILE SPEC")
+		os.Exit(2)
+	}
+
+	if err := run(*lang, flag.Arg(0), *out); err != nil {
+		fmt.Fprintln(os.Stderr, "clientgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(lang, specPath, out string) error {
+	doc, err := openapi3.NewLoader().LoadFromFile(specPath)
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", specPath, err)
+	}
+	// kin-openapi does not model the OpenAPI 3.1 webhooks yet; they only document
+	// events the service sends, so clients need nothing from them
+	if err := doc.Validate(context.Background(), openapi3.AllowExtraSiblingFields("webhooks")); err != nil {
+		return fmt.Errorf("invalid spec %s: %w", specPath, err)
+	}
+
+	m, err := newModel(doc, filepath.Base(specPath))
+	if err != nil {
+		return err
+	}
+	src, err := render(lang, m)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(out), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(out, src, 0o644)
+}
+
+// render executes the template of lang with m
+func render(lang string, m model) ([]byte, error) {
+	l, ok := languages[lang]
+	if !ok {
+		return nil, fmt.Errorf("unknown language %q, want typescript or python", lang)
+	}
+
+	tmpl, err := template.New(lang).Funcs(template.FuncMap{
+		"type":  l.typeName,
+		"camel": camelCase,
+		"snake": snakeCase,
+	}).Parse(l.template)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, m); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// model is what the templates render
+type model struct {
+	Spec       string
+	Title      string
+	Version    string
+	Schemas    []schema
+	Operations []operation
+}
+
+// schema is a named component schema: an object with fields or an alias of a type
+type schema struct {
+	Name        string
+	Description string
+	Object      bool
+	Fields      []field
+	Type        typ
+}
+
+type field struct {
+	Name        string
+	Description string
+	Required    bool
+	Type        typ
+}
+
+type operation struct {
+	ID          string
+	Method      string
+	Path        string
+	Description string
+	// Params are the required parameters followed by the optional ones
+	Params []param
+	// Body is the type of the JSON request body, with no Kind when there is none
+	Body typ
+	// Result is the type of the success response body
+	Result typ
+}
+
+// PathParams are the parameters substituted into Path
+func (o operation) PathParams() []param {
+	return slices.DeleteFunc(slices.Clone(o.Params), func(p param) bool { return p.In != openapi3.ParameterInPath })
+}
+
+// QueryParams are the parameters sent in the query string
+func (o operation) QueryParams() []param {
+	return slices.DeleteFunc(slices.Clone(o.Params), func(p param) bool { return p.In != openapi3.ParameterInQuery })
+}
+
+type param struct {
+	Name        string
+	In          string
+	Description string
+	Required    bool
+	Type        typ
+}
+
+// typ is a type a field, parameter or response body can have
+type typ struct {
+	// Kind is integer, number, string, boolean, array, ref or any
+	Kind string
+	Elem *typ
+	Ref  string
+}
+
+func newModel(doc *openapi3.T, spec string) (model, error) {
+	m := model{Spec: spec, Title: doc.Info.Title, Version: doc.Info.Version}
+
+	if doc.Components != nil {
+		for _, name := range sortedKeys(doc.Components.Schemas) {
+			s, err := newSchema(name, doc.Components.Schemas[name])
+			if err != nil {
+				return model{}, err
+			}
+			m.Schemas = append(m.Schemas, s)
+		}
+	}
+
+	for _, path := range doc.Paths.InMatchingOrder() {
+		item := doc.Paths.Find(path)
+		for _, method := range sortedKeys(item.Operations()) {
+			if streams(item.Operations()[method]) {
+				continue
+			}
+			op, err := newOperation(method, path, item.Operations()[method], item.Parameters)
+			if err != nil {
+				return model{}, fmt.Errorf("%s %s: %w", method, path, err)
+			}
+			m.Operations = append(m.Operations, op)
+		}
+	}
+	slices.SortFunc(m.Operations, func(a, b operation) int { return strings.Compare(a.ID, b.ID) })
+
+	return m, nil
+}
+
+// streams reports whether op answers with server-sent events
+func streams(op *openapi3.Operation) bool {
+	for _, code := range sortedKeys(op.Responses.Map()) {
+		if strings.HasPrefix(code, "2") {
+			return op.Responses.Map()[code].Value.Content.Get("text/event-stream") != nil
+		}
+	}
+
+	return false
+}
+
+func newSchema(name string, ref *openapi3.SchemaRef) (schema, error) {
+	s := schema{Name: name, Description: ref.Value.Description}
+	if !ref.Value.Type.Is(openapi3.TypeObject) {
+		t, err := newType(ref)
+		if err != nil {
+			return schema{}, fmt.Errorf("schema %s: %w", name, err)
+		}
+		s.Type = t
+		return s, nil
+	}
+
+	s.Object = true
+	for _, prop := range sortedKeys(ref.Value.Properties) {
+		t, err := newType(ref.Value.Properties[prop])
+		if err != nil {
+			return schema{}, fmt.Errorf("schema %s, property %s: %w", name, prop, err)
+		}
+		s.Fields = append(s.Fields, field{
+			Name:        prop,
+			Description: ref.Value.Properties[prop].Value.Description,
+			Required:    slices.Contains(ref.Value.Required, prop),
+			Type:        t,
+		})
+	}
+
+	return s, nil
+}
+
+func newOperation(method, path string, op *openapi3.Operation, shared openapi3.Parameters) (operation, error) {
+	if op.OperationID == "" {
+		return operation{}, errors.New("an operationId is required")
+	}
+
+	o := operation{ID: op.OperationID, Method: method, Path: path, Description: op.Description}
+	if o.Description == "" {
+		o.Description = op.Summary
+	}
+
+	if op.RequestBody != nil {
+		media := op.RequestBody.Value.Content.Get("application/json")
+		if media == nil {
+			return operation{}, errors.New("only JSON request bodies are supported")
+		}
+		t, err := newType(media.Schema)
+		if err != nil {
+			return operation{}, fmt.Errorf("request body: %w", err)
+		}
+		o.Body = t
+	}
+
+	for _, ref := range append(slices.Clone(shared), op.Parameters...) {
+		p := ref.Value
+		if p.In != openapi3.ParameterInQuery && p.In != openapi3.ParameterInPath {
+			return operation{}, fmt.Errorf("parameter %s: %s parameters are not supported", p.Name, p.In)
+		}
+		t, err := newType(p.Schema)
+		if err != nil {
+			return operation{}, fmt.Errorf("parameter %s: %w", p.Name, err)
+		}
+		// Arrays are only sent as the default form style does, the parameter repeated
+		// for each element
+		exploded := p.In == openapi3.ParameterInQuery && (p.Style == "" || p.Style == openapi3.SerializationForm) &&
+			(p.Explode == nil || *p.Explode)
+		if t.Kind == "array" && exploded {
+			if k := t.Elem.Kind; k == "array" || k == "ref" || k == "any" {
+				return operation{}, fmt.Errorf("parameter %s: only arrays of scalars are supported", p.Name)
+			}
+		} else if t.Kind == "array" || t.Kind == "ref" || t.Kind == "any" {
+			return operation{}, fmt.Errorf("parameter %s: only scalar parameters and exploded query arrays are supported", p.Name)
+		}
+		o.Params = append(o.Params, param{Name: p.Name, In: p.In, Description: p.Description, Required: p.Required, Type: t})
+	}
+	slices.SortStableFunc(o.Params, func(a, b param) int {
+		switch {
+		case a.Required == b.Required:
+			return 0
+		case a.Required:
+			return -1
+		default:
+			return 1
+		}
+	})
+
+	o.Result = typ{Kind: "any"}
+	for _, code := range sortedKeys(op.Responses.Map()) {
+		if !strings.HasPrefix(code, "2") {
+			continue
+		}
+		if media := op.Responses.Map()[code].Value.Content.Get("application/json"); media != nil && media.Schema != nil {
+			t, err := newType(media.Schema)
+			if err != nil {
+				return operation{}, fmt.Errorf("response %s: %w", code, err)
+			}
+			o.Result = t
+		}
+		break
+	}
+
+	return o, nil
+}
+
+func newType(ref *openapi3.SchemaRef) (typ, error) {
+	if ref == nil {
+		return typ{Kind: "any"}, nil
+	}
+	if ref.Ref != "" {
+		return typ{Kind: "ref", Ref: ref.Ref[strings.LastIndex(ref.Ref, "/")+1:]}, nil
+	}
+
+	s := ref.Value
+	// A lone allOf wraps a reference so that keywords beside it, which OpenAPI 3.0
+	// ignores next to $ref, apply to the property
+	if len(s.AllOf) == 1 && s.Type == nil {
+		return newType(s.AllOf[0])
+	}
+	switch {
+	case s.Type.Is(openapi3.TypeInteger):
+		return typ{Kind: "integer"}, nil
+	case s.Type.Is(openapi3.TypeNumber):
+		return typ{Kind: "number"}, nil
+	case s.Type.Is(openapi3.TypeString):
+		return typ{Kind: "string"}, nil
+	case s.Type.Is(openapi3.TypeBoolean):
+		return typ{Kind: "boolean"}, nil
+	case s.Type.Is(openapi3.TypeArray):
+		elem, err := newType(s.Items)
+		if err != nil {
+			return typ{}, err
+		}
+		return typ{Kind: "array", Elem: &elem}, nil
+	default:
+		return typ{}, fmt.Errorf("inline schemas of type %v are not supported, use a component schema", s.Type)
+	}
+}
+
+func typescriptType(t typ) string {
+	switch t.Kind {
+	case "integer", "number":
+		return "number"
+	case "string", "boolean":
+		return t.Kind
+	case "array":
+		return typescriptType(*t.Elem) + "[]"
+	case "ref":
+		return t.Ref
+	default:
+		return "unknown"
+	}
+}
+
+// pythonType quotes references, so aliases may refer to schemas defined after them
+func pythonType(t typ) string {
+	switch t.Kind {
+	case "integer":
+		return "int"
+	case "number":
+		return "float"
+	case "string":
+		return "str"
+	case "boolean":
+		return "bool"
+	case "array":
+		return "List[" + pythonType(*t.Elem) + "]"
+	case "ref":
+		return `"` + t.Ref + `"`
+	default:
+		return "Any"
+	}
+}
+
+// camelCase lower-cases the first letter: AddNumber becomes addNumber
+func camelCase(s string) string {
+	if s == "" {
+		return s
+	}
+
+	r := []rune(s)
+	r[0] = unicode.ToLower(r[0])
+
+	return string(r)
+}
+
+// snakeCase splits on upper-case letters: AddNumber becomes add_number
+func snakeCase(s string) string {
+	var b strings.Builder
+	r := []rune(s)
+	for i, c := range r {
+		if unicode.IsUpper(c) {
+			if i > 0 && (unicode.IsLower(r[i-1]) || i+1 < len(r) && unicode.IsLower(r[i+1])) {
+				b.WriteByte('_')
+			}
+			c = unicode.ToLower(c)
+		}
+		if c == '-' {
+			c = '_'
+		}
+		b.WriteRune(c)
+	}
+
+	return b.String()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	return keys
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCase(t *testing.T) {
+	tests := []struct{ in, camel, snake string }{
+		{in: "AddNumber", camel: "addNumber", snake: "add_number"},
+		{in: "GetHTTPStatus", camel: "getHTTPStatus", snake: "get_http_status"},
+		{in: "page-size", camel: "page-size", snake: "page_size"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.camel, camelCase(tt.in))
+		assert.Equal(t, tt.snake, snakeCase(tt.in))
+	}
+}
+
+const spec = `
+openapi: 3.0.3
+info: {title: Test API, version: 1.0.0}
+paths:
+  /numbers/{id}:
+    get:
+      operationId: GetNumber
+      parameters:
+        - {name: verbose, in: query, schema: {type: boolean}}
+        - {name: id, in: path, required: true, schema: {type: integer}}
+      responses:
+        200:
+          description: ok
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Number'}
+components:
+  schemas:
+    Number:
+      type: object
+      required: [value]
+      properties:
+        value: {type: integer}
+        tags: {type: array, items: {type: string}}
+`
+
+func TestRender(t *testing.T) {
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(spec))
+	require.NoError(t, err)
+	m, err := newModel(doc, "spec.yaml")
+	require.NoError(t, err)
+
+	require.Len(t, m.Operations, 1)
+	assert.Equal(t, []string{"id", "verbose"}, []string{m.Operations[0].Params[0].Name, m.Operations[0].Params[1].Name},
+		"required parameters come first")
+
+	ts, err := render("typescript", m)
+	require.NoError(t, err)
+	for _, want := range []string{
+		"tags?: string[];",
+		"value: number;",
+		"async getNumber(params: GetNumberParams, init?: RequestInit): Promise<Number>",
+		`.replace("{id}", encodeURIComponent(String(params.id)))`,
+		`if (params.verbose !== undefined) query.set("verbose", String(params.verbose));`,
+	} {
+		assert.Contains(t, string(ts), want)
+	}
+
+	py, err := render("python", m)
+	require.NoError(t, err)
+	for _, want := range []string{
+		"tags: NotRequired[List[str]]",
+		`def get_number(self, id: int, verbose: bool | None = None) -> "Number":`,
+		`"/numbers/{id}".format(id=urllib.parse.quote(str(id), safe=""))`,
+	} {
+		assert.Contains(t, string(py), want)
+	}
+
+	_, err = render("ruby", m)
+	assert.Error(t, err)
+}
+
+func TestRender_RequestBody(t *testing.T) {
+	body := strings.Replace(spec, "      operationId: GetNumber\n", "      operationId: GetNumber\n      requestBody: {content: {application/json: {schema: {$ref: '#/components/schemas/Number'}}}}\n", 1)
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(body))
+	require.NoError(t, err)
+	m, err := newModel(doc, "spec.yaml")
+	require.NoError(t, err)
+
+	ts, err := render("typescript", m)
+	require.NoError(t, err)
+	assert.Contains(t, string(ts), "async getNumber(body: Number, params: GetNumberParams, init?: RequestInit): Promise<Number>")
+	assert.Contains(t, string(ts), `this.request("GET", path, query, body, init)`)
+
+	py, err := render("python", m)
+	require.NoError(t, err)
+	assert.Contains(t, string(py), `def get_number(self, body: "Number", id: int, verbose: bool | None = None) -> "Number":`)
+	assert.Contains(t, string(py), `self._request("GET", path, query, body)`)
+}
+
+func TestRender_QueryArray(t *testing.T) {
+	arrays := strings.Replace(spec, "        - {name: verbose, in: query, schema: {type: boolean}}\n",
+		"        - {name: fields, in: query, schema: {type: array, items: {type: string}}}\n", 1)
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(arrays))
+	require.NoError(t, err)
+	m, err := newModel(doc, "spec.yaml")
+	require.NoError(t, err)
+
+	ts, err := render("typescript", m)
+	require.NoError(t, err)
+	assert.Contains(t, string(ts), "fields?: string[];")
+	assert.Contains(t, string(ts), `for (const value of params.fields ?? []) query.append("fields", String(value));`)
+
+	py, err := render("python", m)
+	require.NoError(t, err)
+	assert.Contains(t, string(py), `def get_number(self, id: int, fields: List[str] | None = None) -> "Number":`)
+	assert.Contains(t, string(py), "urllib.parse.urlencode(params, doseq=True)", "each element is sent as a parameter")
+
+	unexploded := strings.Replace(arrays, "in: query, schema: {type: array", "in: query, explode: false, schema: {type: array", 1)
+	doc, err = openapi3.NewLoader().LoadFromData([]byte(unexploded))
+	require.NoError(t, err)
+	_, err = newModel(doc, "spec.yaml")
+	assert.ErrorContains(t, err, "parameter fields: only scalar parameters and exploded query arrays are supported")
+}
+
+func TestNewModel_RejectsUnsupportedSpecs(t *testing.T) {
+	body := strings.Replace(spec, "      operationId: GetNumber\n", "      operationId: GetNumber\n      requestBody: {content: {text/plain: {schema: {type: string}}}}\n", 1)
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(body))
+	require.NoError(t, err)
+
+	_, err = newModel(doc, "spec.yaml")
+	assert.ErrorContains(t, err, "only JSON request bodies are supported")
+}
+
+func TestNewModel_SkipsEventStreams(t *testing.T) {
+	body := strings.Replace(spec, "components:\n", `  /numbers/stream:
+    get:
+      operationId: StreamNumbers
+      responses:
+        200:
+          description: ok
+          content:
+            text/event-stream:
+              schema: {type: string}
+components:
+`, 1)
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(body))
+	require.NoError(t, err)
+	m, err := newModel(doc, "spec.yaml")
+	require.NoError(t, err)
+
+	require.Len(t, m.Operations, 1)
+	assert.Equal(t, "GetNumber", m.Operations[0].ID)
+}
+
+func TestNewType_LoneAllOf(t *testing.T) {
+	ref := &openapi3.SchemaRef{Value: &openapi3.Schema{AllOf: openapi3.SchemaRefs{{Ref: "#/components/schemas/Numbers"}}}}
+
+	got, err := newType(ref)
+	require.NoError(t, err)
+	assert.Equal(t, typ{Kind: "ref", Ref: "Numbers"}, got)
+}
@@ -0,0 +1,136 @@
+// Command protogen compiles protobuf files and runs protoc plugins on them, like
+// protoc would, without a protoc binary: the files are compiled in Go with
+// protocompile and each plugin, protoc-gen-NAME, is run as a Go tool of the module.
+// Generated code is then reproducible from go generate alone.
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// stringsFlag collects a repeated flag
+type stringsFlag []string
+
+func (f *stringsFlag) String() string { return strings.Join(*f, ",") }
+
+func (f *stringsFlag) Set(s string) error {
+	*f = append(*f, s)
+	return nil
+}
+
+func main() {
+	var plugins stringsFlag
+	importPath := flag.String("I", ".", "directory the proto files and their imports are relative to")
+	out := flag.String("o", ".", "directory the generated files are written to")
+	opt := flag.String("opt", "", "parameter passed to every plugin, e.g. module=example.com/m")
+	flag.Var(&plugins, "plugin", "plugin to run, NAME for the Go tool protoc-gen-NAME, or NAME:PARAM to add PARAM to -opt for it alone; repeatable")
+	flag.Parse()
+
+	if flag.NArg() == 0 || len(plugins) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: protogen [-I DIR] [-o DIR] [-opt PARAM] -plugin NAME[:PARAM]... FILE.proto...")
+		os.Exit(2)
+	}
+
+	if err := run(*importPath, *out, *opt, plugins, flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, "protogen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(importPath, out, opt string, plugins, files []string) error {
+	req, err := newRequest(importPath, files)
+	if err != nil {
+		return err
+	}
+
+	for _, plugin := range plugins {
+		name, param, _ := strings.Cut(plugin, ":")
+		req.Parameter = proto.String(strings.Trim(opt+","+param, ","))
+		resp, err := runPlugin(name, req)
+		if err != nil {
+			return err
+		}
+		for _, file := range resp.GetFile() {
+			path := filepath.Join(out, filepath.FromSlash(file.GetName()))
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(path, []byte(file.GetContent()), 0o644); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// newRequest compiles files into the request protoc would send a plugin, with every
+// imported file, the well-known types included, ahead of the files importing it
+func newRequest(importPath string, files []string) (*pluginpb.CodeGeneratorRequest, error) {
+	compiler := protocompile.Compiler{
+		Resolver:       protocompile.WithStandardImports(&protocompile.SourceResolver{ImportPaths: []string{importPath}}),
+		SourceInfoMode: protocompile.SourceInfoStandard,
+	}
+	compiled, err := compiler.Compile(context.Background(), files...)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &pluginpb.CodeGeneratorRequest{FileToGenerate: files}
+
+	seen := map[string]bool{}
+	var add func(fd protoreflect.FileDescriptor)
+	add = func(fd protoreflect.FileDescriptor) {
+		if seen[fd.Path()] {
+			return
+		}
+		seen[fd.Path()] = true
+		for i := range fd.Imports().Len() {
+			add(fd.Imports().Get(i).FileDescriptor)
+		}
+		req.ProtoFile = append(req.ProtoFile, protodesc.ToFileDescriptorProto(fd))
+	}
+	for _, fd := range compiled {
+		add(fd)
+	}
+
+	return req, nil
+}
+
+// runPlugin sends req to protoc-gen-NAME and returns its answer
+func runPlugin(name string, req *pluginpb.CodeGeneratorRequest) (*pluginpb.CodeGeneratorResponse, error) {
+	in, err := proto.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("go", "tool", "protoc-gen-"+name)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = bytes.NewReader(in), &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("protoc-gen-%s failed: %w: %s", name, err, stderr.String())
+	}
+
+	resp := &pluginpb.CodeGeneratorResponse{}
+	if err := proto.Unmarshal(stdout.Bytes(), resp); err != nil {
+		return nil, fmt.Errorf("protoc-gen-%s answered garbage: %w", name, err)
+	}
+	if resp.Error != nil {
+		return nil, fmt.Errorf("protoc-gen-%s: %s", name, resp.GetError())
+	}
+
+	return resp, nil
+}
@@ -2,6 +2,10 @@ package tools
 
 //go:generate go tool oapi-codegen -package api -generate std-http-server,strict-server -o ../api/server.go ../openapi.yaml
 //go:generate go tool oapi-codegen -package api -generate client -o ../api/client.go ../openapi.yaml
-//go:generate go tool oapi-codegen -package api -generate models -o ../api/models.go ../openapi.yaml
+//go:generate go tool oapi-codegen -config models.cfg.yaml ../openapi.yaml
+//go:generate go tool oapi-codegen -package chiapi -generate chi-server -o ../internal/transport/server/chiapi/server.go ../openapi.yaml
+//go:generate go run ./clientgen -lang typescript -o ../clients/typescript/client.ts ../openapi.yaml
+//go:generate go run ./clientgen -lang python -o ../clients/python/numbers_client.py ../openapi.yaml
+//go:generate go run ./protogen -I ../proto -o .. -opt module=golang-test-task -plugin go -plugin go-grpc -plugin connect-go:simple numbers/v1/numbers.proto
 
 //go:generate go run github.com/sqlc-dev/sqlc/cmd/sqlc generate -f ../sqlc.yaml